@@ -1,8 +1,10 @@
 package ast
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
 
 	"github.com/VirusTotal/gyp/pb"
@@ -45,22 +47,29 @@ const (
 
 // Group is an Expression that encloses another Expression in parentheses.
 type Group struct {
-	Expression Expression
+	Expression Expression `json:"expression"`
 }
 
 // LiteralInteger is an Expression that represents a literal integer.
 type LiteralInteger struct {
-	Value int64
+	Value int64 `json:"value"`
+	// Radix is the base in which the integer is written in the source code,
+	// 16 or 8 for hexadecimal and octal integers. Decimal integers have a
+	// radix of zero, which is the same as 10.
+	Radix int `json:"radix,omitempty"`
+	// Multiplier is 1024 or 1048576 if the integer is written with the KB or
+	// MB suffix, respectively. Zero means that the integer has no suffix.
+	Multiplier int64 `json:"multiplier,omitempty"`
 }
 
 // LiteralFloat is an Expression that represents a literal float.
 type LiteralFloat struct {
-	Value float64
+	Value float64 `json:"value"`
 }
 
 // LiteralString is an Expression that represents a literal string.
 type LiteralString struct {
-	Value string
+	Value string `json:"value"`
 }
 
 // RegexpModifiers are flags containing the modifiers for a LiteralRegexp.
@@ -79,44 +88,44 @@ const (
 // LiteralRegexp is an Expression that represents a literal regular expression,
 // like for example /ab.*cd/.
 type LiteralRegexp struct {
-	Value     string
-	Modifiers RegexpModifiers
+	Value     string          `json:"value"`
+	Modifiers RegexpModifiers `json:"modifiers,omitempty"`
 }
 
 // Minus is an Expression that represents the unary minus operation.
 type Minus struct {
-	Expression Expression
+	Expression Expression `json:"expression"`
 }
 
 // Not is an Expression that represents the "not" operation.
 type Not struct {
-	Expression Expression
+	Expression Expression `json:"expression"`
 }
 
 // Defined is an Expression that represents the "defined" operation.
 type Defined struct {
-	Expression Expression
+	Expression Expression `json:"expression"`
 }
 
 // BitwiseNot is an Expression that represents the bitwise not operation.
 type BitwiseNot struct {
-	Expression Expression
+	Expression Expression `json:"expression"`
 }
 
 // Range is a Node that represents an integer range. Example: (1..10).
 type Range struct {
-	Start Expression
-	End   Expression
+	Start Expression `json:"start"`
+	End   Expression `json:"end"`
 }
 
 // Enum is a Node that represents an enumeration. Example: (1,2,3,4).
 type Enum struct {
-	Values []Expression
+	Values []Expression `json:"values"`
 }
 
 // Identifier is an Expression that represents an identifier.
 type Identifier struct {
-	Identifier string
+	Identifier string `json:"identifier"`
 }
 
 // StringIdentifier is an Expression that represents a string identifier in
@@ -126,9 +135,9 @@ type Identifier struct {
 // "$a in (0..100)". Notice that the Identifier field doesn't contain the $
 // prefix.
 type StringIdentifier struct {
-	Identifier string
-	At         Expression
-	In         *Range
+	Identifier string     `json:"identifier"`
+	At         Expression `json:"at,omitempty"`
+	In         *Range     `json:"in,omitempty"`
 }
 
 // StringCount is an Expression that represents a string count operation, like
@@ -136,39 +145,39 @@ type StringIdentifier struct {
 // "In" is non-nil if the identifier is accompanied by an "in" condition, like
 // "#a in (0..100) == 2".
 type StringCount struct {
-	Identifier string
-	In         *Range
+	Identifier string `json:"identifier"`
+	In         *Range `json:"in,omitempty"`
 }
 
 // StringOffset is an Expression that represents a string offset operation, like
 // "@a". The "Index" field is non-nil if the count operation is indexed, like
 // in "@a[1]". Notice that the Identifier field doesn't contain the @ prefix.
 type StringOffset struct {
-	Identifier string
-	Index      Expression
+	Identifier string     `json:"identifier"`
+	Index      Expression `json:"index,omitempty"`
 }
 
 // StringLength is an Expression that represents a string length operation, like
 // "!a". The "Index" field is non-nil if the count operation is indexed, like
 // in "!a[1]". Notice that the Identifier field doesn't contain the ! prefix.
 type StringLength struct {
-	Identifier string
-	Index      Expression
+	Identifier string     `json:"identifier"`
+	Index      Expression `json:"index,omitempty"`
 }
 
 // FunctionCall is an Expression that represents a function call.
 type FunctionCall struct {
-	Callable  Expression
-	Arguments []Expression
-	Builtin   bool
+	Callable  Expression   `json:"callable"`
+	Arguments []Expression `json:"arguments"`
+	Builtin   bool         `json:"builtin,omitempty"`
 }
 
 // MemberAccess is an Expression that represents a member access operation (.). For
 // example, in "foo.bar" we have a MemberAccess operation where Node is the
 // "foo" identifier and the member is "bar".
 type MemberAccess struct {
-	Container Expression
-	Member    string
+	Container Expression `json:"container"`
+	Member    string     `json:"member"`
 }
 
 // Subscripting is an Expression that represents an array subscripting operation ([]).
@@ -176,31 +185,47 @@ type MemberAccess struct {
 // a Node representing the "foo" identifier and Index is another Node that
 // represents the expression "1+2".
 type Subscripting struct {
-	Array Expression
-	Index Expression
+	Array Expression `json:"array"`
+	Index Expression `json:"index"`
 }
 
 // Percentage is an Expression used in evaluating string sets. Example:
 //   <expression>% of <string set>
 type Percentage struct {
-	Expression Expression
+	Expression Expression `json:"expression"`
 }
 
 // ForIn is an Expression representing a "for in" loop. Example:
 //   for <quantifier> <variables> in <iterator> : ( <condition> )
 type ForIn struct {
-	Quantifier Expression
-	Variables  []string
-	Iterator   Node
-	Condition  Expression
+	Quantifier Expression `json:"quantifier"`
+	Variables  []string   `json:"variables"`
+	Iterator   Node       `json:"iterator"`
+	Condition  Expression `json:"condition"`
 }
 
 // ForOf is an Expression representing a "for of" loop. Example:
 //   for <quantifier> of <string_set> : ( <condition> )
 type ForOf struct {
-	Quantifier Expression
-	Strings    Node
-	Condition  Expression
+	Quantifier Expression `json:"quantifier"`
+	Strings    Node       `json:"strings"`
+	Condition  Expression `json:"condition"`
+}
+
+// With is an Expression representing a "with" statement, which declares
+// identifiers that can be used in its condition. Example:
+//   with <identifier> = <expression>, ... : ( <condition> )
+type With struct {
+	Declarations []*WithDeclaration `json:"declarations"`
+	Condition    Expression         `json:"condition"`
+}
+
+// WithDeclaration is a Node representing the declaration of an identifier in
+// a "with" statement. Example:
+//   <identifier> = <expression>
+type WithDeclaration struct {
+	Identifier string     `json:"identifier"`
+	Expression Expression `json:"expression"`
 }
 
 // Of is an Expression representing a "of" operation. Example:
@@ -209,12 +234,12 @@ type ForOf struct {
 // If "In" is non-nil there is an "in" condition: 3 of them in (0..100)
 // If "At" is non-nil there is an "at" condition: 1 of them at 0
 type Of struct {
-	Quantifier  Expression
-	Strings     Node
-	Rules       Node
-	TextStrings []string
-	In          *Range
-	At         Expression
+	Quantifier  Expression `json:"quantifier"`
+	Strings     Node       `json:"strings,omitempty"`
+	Rules       Node       `json:"rules,omitempty"`
+	TextStrings []string   `json:"text_strings,omitempty"`
+	In          *Range     `json:"in,omitempty"`
+	At          Expression `json:"at,omitempty"`
 }
 
 // Operation is an Expression representing an operation with two or more operands,
@@ -223,8 +248,8 @@ type Of struct {
 // to have a single operation for representing A - B - C, but for A - (B - C) we
 // need two operations with two operands each.
 type Operation struct {
-	Operator OperatorType
-	Operands []Expression
+	Operator OperatorType `json:"operator"`
+	Operands []Expression `json:"operands"`
 }
 
 func (l *LiteralString) String() string {
@@ -260,9 +285,59 @@ func (g *Group) WriteSource(w io.Writer) error {
 	return err
 }
 
+// FormatInteger returns the source code for an integer written in the given
+// radix (10, 16 or 8) and with the suffix corresponding to the given
+// multiplier (1024 for KB, 1048576 for MB). A radix of zero is the same as
+// 10, and a multiplier of zero means no suffix. The integer is written in
+// decimal if it can't be represented as requested, for example because it is
+// negative or not a multiple of the multiplier.
+func FormatInteger(value int64, radix int, multiplier int64) string {
+	if value >= 0 {
+		switch radix {
+		case 16:
+			return "0x" + strings.ToUpper(strconv.FormatInt(value, 16))
+		case 8:
+			return "0o" + strconv.FormatInt(value, 8)
+		}
+		switch {
+		case multiplier == 1024 && value%1024 == 0:
+			return strconv.FormatInt(value/1024, 10) + "KB"
+		case multiplier == 1048576 && value%1048576 == 0:
+			return strconv.FormatInt(value/1048576, 10) + "MB"
+		}
+	}
+	return strconv.FormatInt(value, 10)
+}
+
+// CanonicalizeIntegers discards the radix and multiplier of all the literal
+// integers in the node's syntax tree, so that they are written in decimal and
+// without suffix. Use it with each of the rules in a RuleSet for
+// canonicalizing the whole RuleSet.
+func CanonicalizeIntegers(node Node) {
+	if node == nil {
+		return
+	}
+	switch n := node.(type) {
+	case *LiteralInteger:
+		n.Radix = 0
+		n.Multiplier = 0
+	case *Of:
+		// The "in" and "at" expressions are not included in Of.Children.
+		if n.In != nil {
+			CanonicalizeIntegers(n.In)
+		}
+		if n.At != nil {
+			CanonicalizeIntegers(n.At)
+		}
+	}
+	for _, child := range node.Children() {
+		CanonicalizeIntegers(child)
+	}
+}
+
 // WriteSource writes the node's source into the writer w.
 func (l *LiteralInteger) WriteSource(w io.Writer) error {
-	_, err := fmt.Fprint(w, l.Value)
+	_, err := io.WriteString(w, FormatInteger(l.Value, l.Radix, l.Multiplier))
 	return err
 }
 
@@ -288,7 +363,7 @@ func (l *LiteralRegexp) WriteSource(w io.Writer) error {
 func (m *Minus) WriteSource(w io.Writer) error {
 	_, err := io.WriteString(w, "-")
 	if err == nil {
-		err = m.Expression.WriteSource(w)
+		err = writeOperand(w, m.Expression, unaryOperandNeedsParens(m.Expression))
 	}
 	return err
 }
@@ -297,7 +372,8 @@ func (m *Minus) WriteSource(w io.Writer) error {
 func (d *Defined) WriteSource(w io.Writer) error {
 	_, err := io.WriteString(w, "defined ")
 	if err == nil {
-		err = d.Expression.WriteSource(w)
+		err = writeOperand(w, d.Expression,
+			expressionPrecedence(d.Expression) < OpPrecedence[OpNot])
 	}
 	return err
 }
@@ -306,7 +382,8 @@ func (d *Defined) WriteSource(w io.Writer) error {
 func (n *Not) WriteSource(w io.Writer) error {
 	_, err := io.WriteString(w, "not ")
 	if err == nil {
-		err = n.Expression.WriteSource(w)
+		err = writeOperand(w, n.Expression,
+			expressionPrecedence(n.Expression) < OpPrecedence[OpNot])
 	}
 	return err
 }
@@ -315,7 +392,7 @@ func (n *Not) WriteSource(w io.Writer) error {
 func (b *BitwiseNot) WriteSource(w io.Writer) error {
 	_, err := io.WriteString(w, "~")
 	if err == nil {
-		err = b.Expression.WriteSource(w)
+		err = writeOperand(w, b.Expression, unaryOperandNeedsParens(b.Expression))
 	}
 	return err
 }
@@ -324,13 +401,13 @@ func (b *BitwiseNot) WriteSource(w io.Writer) error {
 func (r *Range) WriteSource(w io.Writer) error {
 	_, err := io.WriteString(w, "(")
 	if err == nil {
-		err = r.Start.WriteSource(w)
+		err = writeOperand(w, r.Start, !isPrimaryExpression(r.Start))
 	}
 	if err == nil {
 		_, err = io.WriteString(w, "..")
 	}
 	if err == nil {
-		err = r.End.WriteSource(w)
+		err = writeOperand(w, r.End, !isPrimaryExpression(r.End))
 	}
 	if err == nil {
 		_, err = io.WriteString(w, ")")
@@ -364,11 +441,11 @@ func (i *Identifier) WriteSource(w io.Writer) error {
 
 // WriteSource writes the node's source into the writer w.
 func (s *StringIdentifier) WriteSource(w io.Writer) error {
-	_, err := io.WriteString(w, fmt.Sprintf("$%s", s.Identifier))
+	_, err := io.WriteString(w, "$"+s.Identifier)
 	if err == nil && s.At != nil {
 		_, err = io.WriteString(w, " at ")
 		if err == nil {
-			err = s.At.WriteSource(w)
+			err = writeOperand(w, s.At, !isPrimaryExpression(s.At))
 		}
 	}
 	if err == nil && s.In != nil {
@@ -382,7 +459,7 @@ func (s *StringIdentifier) WriteSource(w io.Writer) error {
 
 // WriteSource writes the node's source into the writer w.
 func (s *StringCount) WriteSource(w io.Writer) error {
-	_, err := io.WriteString(w, fmt.Sprintf("#%s", s.Identifier))
+	_, err := io.WriteString(w, "#"+s.Identifier)
 	if err == nil && s.In != nil {
 		_, err = io.WriteString(w, " in ")
 		if err == nil {
@@ -394,7 +471,7 @@ func (s *StringCount) WriteSource(w io.Writer) error {
 
 // WriteSource writes the node's source into the writer w.
 func (s *StringOffset) WriteSource(w io.Writer) error {
-	_, err := io.WriteString(w, fmt.Sprintf("@%s", s.Identifier))
+	_, err := io.WriteString(w, "@"+s.Identifier)
 	if err == nil && s.Index != nil {
 		_, err = io.WriteString(w, "[")
 	}
@@ -409,7 +486,7 @@ func (s *StringOffset) WriteSource(w io.Writer) error {
 
 // WriteSource writes the node's source into the writer w.
 func (s *StringLength) WriteSource(w io.Writer) error {
-	_, err := io.WriteString(w, fmt.Sprintf("!%s", s.Identifier))
+	_, err := io.WriteString(w, "!"+s.Identifier)
 	if err == nil && s.Index != nil {
 		_, err = io.WriteString(w, "[")
 	}
@@ -519,10 +596,48 @@ func (f *ForOf) WriteSource(w io.Writer) error {
 	return err
 }
 
+// WriteSource writes the node's source into the writer w.
+func (w *With) WriteSource(writer io.Writer) error {
+	_, err := io.WriteString(writer, "with ")
+	for i, d := range w.Declarations {
+		if err == nil && i > 0 {
+			_, err = io.WriteString(writer, ", ")
+		}
+		if err == nil {
+			err = d.WriteSource(writer)
+		}
+	}
+	if err == nil {
+		_, err = io.WriteString(writer, " : (")
+	}
+	if err == nil {
+		err = w.Condition.WriteSource(writer)
+	}
+	if err == nil {
+		_, err = io.WriteString(writer, ")")
+	}
+	return err
+}
+
+// WriteSource writes the node's source into the writer w.
+func (d *WithDeclaration) WriteSource(w io.Writer) error {
+	_, err := io.WriteString(w, d.Identifier+" = ")
+	if err == nil {
+		err = d.Expression.WriteSource(w)
+	}
+	return err
+}
+
 // WriteSource writes the node's source into the writer w.
 func (o *Of) WriteSource(w io.Writer) error {
-	if (o.Strings == nil && o.Rules == nil) || (o.Strings != nil && o.Rules != nil) {
-		panic("expecting one string set or rule set in \"of\"")
+	sets := 0
+	for _, set := range []bool{o.Strings != nil, o.Rules != nil, o.TextStrings != nil} {
+		if set {
+			sets++
+		}
+	}
+	if sets != 1 {
+		return errors.New("expecting one string set, rule set or text string set in \"of\"")
 	}
 	err := o.Quantifier.WriteSource(w)
 	if err == nil {
@@ -534,6 +649,9 @@ func (o *Of) WriteSource(w io.Writer) error {
 	if err == nil && o.Rules != nil {
 		err = o.Rules.WriteSource(w)
 	}
+	if err == nil && o.TextStrings != nil {
+		_, err = io.WriteString(w, `("`+strings.Join(o.TextStrings, `", "`)+`")`)
+	}
 	if err == nil && o.In != nil {
 		_, err = io.WriteString(w, " in ")
 		if err == nil {
@@ -561,33 +679,63 @@ func (p *Percentage) WriteSource(w io.Writer) error {
 // WriteSource writes the operation into the writer w.
 func (o *Operation) WriteSource(w io.Writer) error {
 	if len(o.Operands) < 2 {
-		panic("expecting two or more operands")
-	}
-	// N-ary operation, write the operands with the operator in-between.
-	if err := o.Operands[0].WriteSource(w); err != nil {
-		return err
+		return errors.New("expecting two or more operands")
 	}
-	for _, operand := range o.Operands[1:] {
-		if _, err := fmt.Fprintf(w, " %s ", o.Operator); err != nil {
-			return err
+	// N-ary operation, write the operands with the operator in-between. Operands
+	// are enclosed in parentheses only when required for preserving the
+	// semantics of the operation.
+	for i, operand := range o.Operands {
+		if i > 0 {
+			if _, err := io.WriteString(w, " "+string(o.Operator)+" "); err != nil {
+				return err
+			}
 		}
-		if err := operand.WriteSource(w); err != nil {
+		parens := operandNeedsParens(o.Operator, operand, i)
+		if err := writeOperand(w, operand, parens); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// unaryOperandNeedsParens returns true if the operand of an unary operator
+// like "-" or "~" must be enclosed in parentheses. These operators have the
+// highest precedence, so any operation used as operand requires parentheses.
+func unaryOperandNeedsParens(operand Expression) bool {
+	if _, isOperation := operand.(*Operation); isOperation {
+		return true
+	}
+	return !isPrimaryExpression(operand)
+}
+
+// writeOperand writes the source of an operand into the writer w, enclosing it
+// in parentheses if parens is true. Operands that are already a Group are
+// never enclosed in an additional pair of parentheses.
+func writeOperand(w io.Writer, operand Expression, parens bool) error {
+	if _, isGroup := operand.(*Group); isGroup || !parens {
+		return operand.WriteSource(w)
+	}
+	return (&Group{operand}).WriteSource(w)
+}
+
 // Children returns nil as a keyword never has children, this function is
 // required anyways in order to satisfy the Node interface.
 func (k Keyword) Children() []Node {
 	return nil
 }
 
+func (k Keyword) appendChildren(dst []Node) []Node {
+	return dst
+}
+
 // Children returns the group's children, which is the expression inside the
 // group.
 func (g *Group) Children() []Node {
-	return []Node{g.Expression}
+	return g.appendChildren(nil)
+}
+
+func (g *Group) appendChildren(dst []Node) []Node {
+	return append(dst, g.Expression)
 }
 
 // Children returns the Node's children.
@@ -595,150 +743,262 @@ func (l *LiteralInteger) Children() []Node {
 	return nil
 }
 
+func (l *LiteralInteger) appendChildren(dst []Node) []Node {
+	return dst
+}
+
 // Children returns the Node's children.
 func (l *LiteralFloat) Children() []Node {
 	return nil
 }
 
+func (l *LiteralFloat) appendChildren(dst []Node) []Node {
+	return dst
+}
+
 // Children returns the Node's children.
 func (l *LiteralString) Children() []Node {
 	return nil
 }
 
+func (l *LiteralString) appendChildren(dst []Node) []Node {
+	return dst
+}
+
 // Children returns the Node's children.
 func (l *LiteralRegexp) Children() []Node {
 	return nil
 }
 
+func (l *LiteralRegexp) appendChildren(dst []Node) []Node {
+	return dst
+}
+
 // Children returns the Node's children.
 func (i *Identifier) Children() []Node {
 	return nil
 }
 
+func (i *Identifier) appendChildren(dst []Node) []Node {
+	return dst
+}
+
 // Children returns the Node's children.
 func (r *Range) Children() []Node {
-	return []Node{r.Start, r.End}
+	return r.appendChildren(nil)
+}
+
+func (r *Range) appendChildren(dst []Node) []Node {
+	return append(dst, r.Start, r.End)
 }
 
 // Children returns the Node's children.
 func (e *Enum) Children() []Node {
-	nodes := make([]Node, len(e.Values))
-	for i, v := range e.Values {
-		nodes[i] = v
+	return e.appendChildren(make([]Node, 0, len(e.Values)))
+}
+
+func (e *Enum) appendChildren(dst []Node) []Node {
+	for _, v := range e.Values {
+		dst = append(dst, v)
 	}
-	return nodes
+	return dst
 }
 
 // Children returns the Node's children.
 func (s *StringIdentifier) Children() []Node {
-	if s.At == nil && s.In == nil {
-		return nil
-	}
-	children := make([]Node, 0)
+	return s.appendChildren(nil)
+}
+
+func (s *StringIdentifier) appendChildren(dst []Node) []Node {
 	if s.At != nil {
-		children = append(children, s.At)
+		dst = append(dst, s.At)
 	}
 	if s.In != nil {
-		children = append(children, s.In)
+		dst = append(dst, s.In)
 	}
-	return children
+	return dst
 }
 
 // Children returns the Node's children.
 func (s *StringCount) Children() []Node {
+	return s.appendChildren(nil)
+}
+
+func (s *StringCount) appendChildren(dst []Node) []Node {
 	if s.In != nil {
-		return []Node{s.In}
+		dst = append(dst, s.In)
 	}
-	return nil
+	return dst
 }
 
 // Children returns the Node's children.
 func (s *StringOffset) Children() []Node {
+	return s.appendChildren(nil)
+}
+
+func (s *StringOffset) appendChildren(dst []Node) []Node {
 	if s.Index != nil {
-		return []Node{s.Index}
+		dst = append(dst, s.Index)
 	}
-	return nil
+	return dst
 }
 
 // Children returns the Node's children.
 func (s *StringLength) Children() []Node {
+	return s.appendChildren(nil)
+}
+
+func (s *StringLength) appendChildren(dst []Node) []Node {
 	if s.Index != nil {
-		return []Node{s.Index}
+		dst = append(dst, s.Index)
 	}
-	return nil
+	return dst
 }
 
 // Children returns the Node's children.
 func (f *FunctionCall) Children() []Node {
-	expressions := append([]Expression{f.Callable}, f.Arguments...)
-	nodes := make([]Node, len(expressions))
-	for i, e := range expressions {
-		nodes[i] = e
+	return f.appendChildren(make([]Node, 0, len(f.Arguments)+1))
+}
+
+func (f *FunctionCall) appendChildren(dst []Node) []Node {
+	dst = append(dst, f.Callable)
+	for _, a := range f.Arguments {
+		dst = append(dst, a)
 	}
-	return nodes
+	return dst
 }
 
 // Children returns the node's child nodes.
 func (m *MemberAccess) Children() []Node {
-	return []Node{m.Container}
+	return m.appendChildren(nil)
+}
+
+func (m *MemberAccess) appendChildren(dst []Node) []Node {
+	return append(dst, m.Container)
 }
 
 // Children returns the node's child nodes.
 func (s *Subscripting) Children() []Node {
-	return []Node{s.Array, s.Index}
+	return s.appendChildren(nil)
+}
+
+func (s *Subscripting) appendChildren(dst []Node) []Node {
+	return append(dst, s.Array, s.Index)
 }
 
 // Children returns the node's child nodes.
 func (f *ForIn) Children() []Node {
-	return []Node{f.Quantifier, f.Iterator, f.Condition}
+	return f.appendChildren(nil)
+}
+
+func (f *ForIn) appendChildren(dst []Node) []Node {
+	return append(dst, f.Quantifier, f.Iterator, f.Condition)
 }
 
 // Children returns the node's child nodes.
 func (f *ForOf) Children() []Node {
-	return []Node{f.Quantifier, f.Strings, f.Condition}
+	return f.appendChildren(nil)
+}
+
+func (f *ForOf) appendChildren(dst []Node) []Node {
+	return append(dst, f.Quantifier, f.Strings, f.Condition)
+}
+
+// Children returns the node's child nodes.
+func (w *With) Children() []Node {
+	return w.appendChildren(make([]Node, 0, len(w.Declarations)+1))
+}
+
+func (w *With) appendChildren(dst []Node) []Node {
+	for _, d := range w.Declarations {
+		dst = append(dst, d)
+	}
+	return append(dst, w.Condition)
+}
+
+// Children returns the node's child nodes.
+func (d *WithDeclaration) Children() []Node {
+	return d.appendChildren(nil)
+}
+
+func (d *WithDeclaration) appendChildren(dst []Node) []Node {
+	return append(dst, d.Expression)
 }
 
 // Children returns the node's child nodes.
 func (o *Of) Children() []Node {
+	return o.appendChildren(nil)
+}
+
+func (o *Of) appendChildren(dst []Node) []Node {
 	// Because this node can have children that are exclusively rules or
 	// strings we need to only add them if they are non-nil.
-	nodes := []Node{o.Quantifier}
+	dst = append(dst, o.Quantifier)
 	if o.Rules != nil {
-		nodes = append(nodes, o.Rules)
+		dst = append(dst, o.Rules)
 	}
 	if o.Strings != nil {
-		nodes = append(nodes, o.Strings)
+		dst = append(dst, o.Strings)
 	}
-	return nodes
+	if o.In != nil {
+		dst = append(dst, o.In)
+	}
+	if o.At != nil {
+		dst = append(dst, o.At)
+	}
+	return dst
 }
 
 // Children returns the operation's children nodes.
 func (o *Operation) Children() []Node {
-	nodes := make([]Node, len(o.Operands))
-	for i, o := range o.Operands {
-		nodes[i] = o
+	return o.appendChildren(make([]Node, 0, len(o.Operands)))
+}
+
+func (o *Operation) appendChildren(dst []Node) []Node {
+	for _, operand := range o.Operands {
+		dst = append(dst, operand)
 	}
-	return nodes
+	return dst
 }
 
 func (n *Not) Children() []Node {
-	return []Node{n.Expression}
+	return n.appendChildren(nil)
+}
+
+func (n *Not) appendChildren(dst []Node) []Node {
+	return append(dst, n.Expression)
 }
 
 func (m *Minus) Children() []Node {
-	return []Node{m.Expression}
+	return m.appendChildren(nil)
+}
+
+func (m *Minus) appendChildren(dst []Node) []Node {
+	return append(dst, m.Expression)
 }
 
 func (b *BitwiseNot) Children() []Node {
-	return []Node{b.Expression}
+	return b.appendChildren(nil)
+}
+
+func (b *BitwiseNot) appendChildren(dst []Node) []Node {
+	return append(dst, b.Expression)
 }
 
 func (d *Defined) Children() []Node {
-	return []Node{d.Expression}
+	return d.appendChildren(nil)
+}
+
+func (d *Defined) appendChildren(dst []Node) []Node {
+	return append(dst, d.Expression)
 }
 
 func (p *Percentage) Children() []Node {
-	return []Node{p.Expression}
+	return p.appendChildren(nil)
+}
+
+func (p *Percentage) appendChildren(dst []Node) []Node {
+	return append(dst, p.Expression)
 }
 
 // AsProto returns the Expression serialized as a pb.Expression.
@@ -775,11 +1035,21 @@ func (g *Group) AsProto() *pb.Expression {
 
 // AsProto returns the Expression serialized as a pb.Expression.
 func (l *LiteralInteger) AsProto() *pb.Expression {
-	return &pb.Expression{
+	expr := &pb.Expression{
 		Expression: &pb.Expression_NumberValue{
 			NumberValue: l.Value,
 		},
 	}
+	if l.Radix != 0 && l.Radix != 10 || l.Multiplier != 0 {
+		expr.NumberRepresentation = &pb.IntegerRepresentation{}
+		if l.Radix != 0 {
+			expr.NumberRepresentation.Radix = proto.Int32(int32(l.Radix))
+		}
+		if l.Multiplier != 0 {
+			expr.NumberRepresentation.Multiplier = proto.Int64(l.Multiplier)
+		}
+	}
+	return expr
 }
 
 // AsProto returns the Expression serialized as a pb.Expression.
@@ -899,7 +1169,7 @@ func (i *Identifier) AsProto() *pb.Expression {
 func (s *StringIdentifier) AsProto() *pb.Expression {
 	expr := &pb.Expression{
 		Expression: &pb.Expression_StringIdentifier{
-			StringIdentifier: fmt.Sprintf("$%s", s.Identifier),
+			StringIdentifier: "$" + s.Identifier,
 		},
 	}
 	if s.At != nil {
@@ -935,7 +1205,7 @@ func (s *StringIdentifier) AsProto() *pb.Expression {
 func (s *StringCount) AsProto() *pb.Expression {
 	expr := &pb.Expression{
 		Expression: &pb.Expression_StringCount{
-			StringCount: fmt.Sprintf("#%s", s.Identifier),
+			StringCount: "#" + s.Identifier,
 		},
 	}
 	if s.In != nil {
@@ -965,7 +1235,7 @@ func (s *StringOffset) AsProto() *pb.Expression {
 	return &pb.Expression{
 		Expression: &pb.Expression_StringOffset{
 			StringOffset: &pb.StringOffset{
-				StringIdentifier: proto.String(fmt.Sprintf("@%s", s.Identifier)),
+				StringIdentifier: proto.String("@" + s.Identifier),
 				Index:            index,
 			},
 		},
@@ -981,7 +1251,7 @@ func (s *StringLength) AsProto() *pb.Expression {
 	return &pb.Expression{
 		Expression: &pb.Expression_StringLength{
 			StringLength: &pb.StringLength{
-				StringIdentifier: proto.String(fmt.Sprintf("!%s", s.Identifier)),
+				StringIdentifier: proto.String("!" + s.Identifier),
 				Index:            index,
 			},
 		},
@@ -1107,7 +1377,7 @@ func (f *ForOf) AsProto() *pb.Expression {
 		for i, item := range v.Values {
 			identifier := item.(*StringIdentifier).Identifier
 			items[i] = &pb.StringEnumeration_StringEnumerationItem{
-				StringIdentifier: proto.String(fmt.Sprintf("$%s", identifier)),
+				StringIdentifier: proto.String("$" + identifier),
 				HasWildcard:      proto.Bool(strings.HasSuffix(identifier, "*")),
 			}
 		}
@@ -1139,10 +1409,35 @@ func (f *ForOf) AsProto() *pb.Expression {
 	}
 }
 
+// AsProto returns the Expression serialized as a pb.Expression.
+func (w *With) AsProto() *pb.Expression {
+	declarations := make([]*pb.WithDeclaration, len(w.Declarations))
+	for i, d := range w.Declarations {
+		declarations[i] = &pb.WithDeclaration{
+			Identifier: proto.String(d.Identifier),
+			Expression: d.Expression.AsProto(),
+		}
+	}
+	return &pb.Expression{
+		Expression: &pb.Expression_WithExpression{
+			WithExpression: &pb.WithExpression{
+				Declarations: declarations,
+				Expression:   w.Condition.AsProto(),
+			},
+		},
+	}
+}
+
 // AsProto returns the Expression serialized as a pb.Expression.
 func (o *Of) AsProto() *pb.Expression {
-	if (o.Strings == nil && o.Rules == nil) || (o.Strings != nil && o.Rules != nil) {
-		panic("expecting one string set or rule set in \"of\"")
+	sets := 0
+	for _, set := range []bool{o.Strings != nil, o.Rules != nil, o.TextStrings != nil} {
+		if set {
+			sets++
+		}
+	}
+	if sets != 1 {
+		panic("expecting one string set, rule set or text string set in \"of\"")
 	}
 	var s *pb.StringSet
 	var rule_enumeration *pb.RuleEnumeration
@@ -1153,7 +1448,7 @@ func (o *Of) AsProto() *pb.Expression {
 			for i, item := range v.Values {
 				identifier := item.(*StringIdentifier).Identifier
 				items[i] = &pb.StringEnumeration_StringEnumerationItem{
-					StringIdentifier: proto.String(fmt.Sprintf("$%s", identifier)),
+					StringIdentifier: proto.String("$" + identifier),
 					HasWildcard:      proto.Bool(strings.HasSuffix(identifier, "*")),
 				}
 			}
@@ -1182,7 +1477,7 @@ func (o *Of) AsProto() *pb.Expression {
 			for i, item := range v.Values {
 				identifier := item.(*Identifier).Identifier
 				items[i] = &pb.RuleEnumeration_RuleEnumerationItem{
-					RuleIdentifier: proto.String(fmt.Sprintf("%s", identifier)),
+					RuleIdentifier: proto.String(identifier),
 					HasWildcard:    proto.Bool(strings.HasSuffix(identifier, "*")),
 				}
 			}
@@ -1212,6 +1507,7 @@ func (o *Of) AsProto() *pb.Expression {
 				Range:           r,
 				At:              e,
 				RuleEnumeration: rule_enumeration,
+				TextStrings:     o.TextStrings,
 			},
 		},
 	}
@@ -0,0 +1,58 @@
+package ast
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidationError is the error returned when a ruleset, or the protobuf it
+// is created from, is not valid.
+type ValidationError struct {
+	// Identifier of the rule containing the invalid field, empty if the
+	// field is not inside a rule or the rule doesn't have an identifier.
+	Rule string
+	// Path of the invalid field, like "condition.and_expression.terms[1]".
+	// Protobufs fields are named as in yara.proto, and ruleset fields as in
+	// the JSON encoding of the AST, like "condition.operands[1]".
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	if e.Rule != "" {
+		fmt.Fprintf(&b, "rule %s: ", e.Rule)
+	}
+	if e.Field != "" {
+		fmt.Fprintf(&b, "%s: ", e.Field)
+	}
+	b.WriteString(e.Message)
+	return b.String()
+}
+
+// invalid returns a *ValidationError for the given field.
+func invalid(field, format string, args ...interface{}) error {
+	return &ValidationError{
+		Field:   field,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// inField prepends field to the path of the field in err, which must be nil
+// or a *ValidationError. It's used for building the path of the invalid field
+// while returning from nested fields.
+func inField(field string, err error) error {
+	if err == nil || field == "" {
+		return err
+	}
+	e := *err.(*ValidationError)
+	switch {
+	case e.Field == "":
+		e.Field = field
+	case strings.HasPrefix(e.Field, "["):
+		e.Field = field + e.Field
+	default:
+		e.Field = field + "." + e.Field
+	}
+	return &e
+}
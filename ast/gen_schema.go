@@ -0,0 +1,22 @@
+//go:build ignore
+// +build ignore
+
+// This program generates ruleset.schema.json, run it with "go generate".
+package main
+
+import (
+	"io/ioutil"
+	"log"
+
+	"github.com/VirusTotal/gyp/ast"
+)
+
+func main() {
+	schema, err := ast.JSONSchema()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile("ruleset.schema.json", append(schema, '\n'), 0644); err != nil {
+		log.Fatal(err)
+	}
+}
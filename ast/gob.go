@@ -0,0 +1,22 @@
+package ast
+
+import (
+	"encoding/gob"
+	"reflect"
+)
+
+// Register the nodes with encoding/gob, so that rulesets can be encoded with
+// it. Nodes are registered with the names used for them in the JSON encoding,
+// which are shorter than the default ones and don't depend on the package's
+// import path.
+func init() {
+	for name, t := range jsonTypes {
+		// Most nodes are stored in interfaces as pointers, except for those
+		// like keywords, whose methods have value receivers.
+		if implementing(t, nodeType) != t && implementing(t, stringType) != t {
+			t = reflect.PtrTo(t)
+		}
+		gob.RegisterName(name, reflect.Zero(t).Interface())
+	}
+	gob.RegisterName("hex_tokens", HexTokens{})
+}
@@ -0,0 +1,674 @@
+package ast
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+//go:generate go run gen_schema.go
+
+// JSONVersion is the version of the JSON encoding produced by the MarshalJSON
+// methods in this package, and stored in the "version" field of encoded
+// RuleSets. It is increased whenever the encoding changes in a way that is
+// not backwards compatible.
+//
+// In the JSON encoding each node is an object with a "type" field indicating
+// the node's type, like "operation", "literal_integer" or "hex_string", and
+// one field for each of the node's fields, named as in their json tags.
+// Fields with the omitempty option are omitted when they have their zero
+// value. Hex tokens sequences are encoded as arrays, and the bytes and masks
+// in hex strings as arrays of integers. The complete encoding is described by
+// the JSON Schema returned by JSONSchema.
+//
+// Version 2 omits the end of unbounded hex jumps, which version 1 encoded as
+// 0, the end of the zero-length jump [0-0]. Encodings with version 1 are
+// still decoded, see UnmarshalJSON.
+const JSONVersion = 2
+
+var (
+	expressionType = reflect.TypeOf((*Expression)(nil)).Elem()
+	nodeType       = reflect.TypeOf((*Node)(nil)).Elem()
+	stringType     = reflect.TypeOf((*String)(nil)).Elem()
+	hexTokenType   = reflect.TypeOf((*HexToken)(nil)).Elem()
+	byteSliceType  = reflect.TypeOf([]byte(nil))
+)
+
+// jsonTypes maps the values of the "type" field in the JSON encoding to the
+// types of the nodes.
+var jsonTypes = map[string]reflect.Type{
+	"group":             reflect.TypeOf(Group{}),
+	"keyword":           reflect.TypeOf(Keyword("")),
+	"literal_integer":   reflect.TypeOf(LiteralInteger{}),
+	"literal_float":     reflect.TypeOf(LiteralFloat{}),
+	"literal_string":    reflect.TypeOf(LiteralString{}),
+	"literal_regexp":    reflect.TypeOf(LiteralRegexp{}),
+	"minus":             reflect.TypeOf(Minus{}),
+	"not":               reflect.TypeOf(Not{}),
+	"defined":           reflect.TypeOf(Defined{}),
+	"bitwise_not":       reflect.TypeOf(BitwiseNot{}),
+	"range":             reflect.TypeOf(Range{}),
+	"enum":              reflect.TypeOf(Enum{}),
+	"identifier":        reflect.TypeOf(Identifier{}),
+	"string_identifier": reflect.TypeOf(StringIdentifier{}),
+	"string_count":      reflect.TypeOf(StringCount{}),
+	"string_offset":     reflect.TypeOf(StringOffset{}),
+	"string_length":     reflect.TypeOf(StringLength{}),
+	"function_call":     reflect.TypeOf(FunctionCall{}),
+	"member_access":     reflect.TypeOf(MemberAccess{}),
+	"subscripting":      reflect.TypeOf(Subscripting{}),
+	"percentage":        reflect.TypeOf(Percentage{}),
+	"for_in":            reflect.TypeOf(ForIn{}),
+	"for_of":            reflect.TypeOf(ForOf{}),
+	"with":              reflect.TypeOf(With{}),
+	"with_declaration":  reflect.TypeOf(WithDeclaration{}),
+	"of":                reflect.TypeOf(Of{}),
+	"operation":         reflect.TypeOf(Operation{}),
+	"text_string":       reflect.TypeOf(TextString{}),
+	"regexp_string":     reflect.TypeOf(RegexpString{}),
+	"hex_string":        reflect.TypeOf(HexString{}),
+	"hex_jump":          reflect.TypeOf(HexJump{}),
+	"hex_bytes":         reflect.TypeOf(HexBytes{}),
+	"hex_or":            reflect.TypeOf(HexOr{}),
+}
+
+// jsonTypeNames maps the types of the nodes to the values of the "type" field
+// in the JSON encoding. It is the inverse of jsonTypes.
+var jsonTypeNames = make(map[reflect.Type]string)
+
+func init() {
+	for name, t := range jsonTypes {
+		jsonTypeNames[t] = name
+	}
+}
+
+// jsonInterfaceName returns the name used in error messages and in the JSON
+// Schema for an interface type that is implemented by nodes.
+func jsonInterfaceName(t reflect.Type) string {
+	switch t {
+	case expressionType:
+		return "expression"
+	case stringType:
+		return "string"
+	case hexTokenType:
+		return "hex_token"
+	}
+	return "node"
+}
+
+// implementing returns the node type, or the pointer to the node type, that
+// implements the interface t, or nil if none of them does.
+func implementing(node reflect.Type, t reflect.Type) reflect.Type {
+	if node.Implements(t) {
+		return node
+	}
+	if reflect.PtrTo(node).Implements(t) {
+		return reflect.PtrTo(node)
+	}
+	return nil
+}
+
+// jsonField describes a struct field that is included in the JSON encoding.
+type jsonField struct {
+	name      string
+	index     []int
+	omitempty bool
+}
+
+// jsonFields returns the fields of the struct type t that have a json tag,
+// including those in embedded structs.
+func jsonFields(t reflect.Type) []jsonField {
+	var fields []jsonField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, ok := f.Tag.Lookup("json")
+		if f.Anonymous && !ok {
+			for _, ef := range jsonFields(f.Type) {
+				ef.index = append([]int{i}, ef.index...)
+				fields = append(fields, ef)
+			}
+			continue
+		}
+		if !ok {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		fields = append(fields, jsonField{
+			name:      parts[0],
+			index:     []int{i},
+			omitempty: len(parts) > 1 && parts[1] == "omitempty",
+		})
+	}
+	return fields
+}
+
+// isEmptyValue returns true if v is the zero value of its type, or an empty
+// slice.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.String, reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
+
+// encodeJSON returns the JSON encoding of v. Unlike json.Marshal, characters
+// like < and > are not escaped, as they are common in conditions.
+func encodeJSON(v interface{}) ([]byte, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(b.Bytes(), "\n"), nil
+}
+
+// marshalJSON returns the JSON encoding of the struct pointed to by v. If v is
+// a node the encoding includes the "type" field.
+func marshalJSON(v interface{}) ([]byte, error) {
+	rv := reflect.ValueOf(v).Elem()
+	var b bytes.Buffer
+	b.WriteByte('{')
+	if name, ok := jsonTypeNames[rv.Type()]; ok {
+		fmt.Fprintf(&b, `"type":"%s"`, name)
+	}
+	for _, f := range jsonFields(rv.Type()) {
+		fv := rv.FieldByIndex(f.index)
+		if f.omitempty && isEmptyValue(fv) {
+			continue
+		}
+		var value interface{}
+		switch {
+		case fv.Type() == byteSliceType:
+			ints := make([]int, fv.Len())
+			for i := range ints {
+				ints[i] = int(fv.Index(i).Uint())
+			}
+			value = ints
+		case fv.Kind() == reflect.Slice && fv.IsNil():
+			value = []interface{}{}
+		default:
+			value = fv.Interface()
+		}
+		data, err := encodeJSON(value)
+		if err != nil {
+			return nil, err
+		}
+		if b.Len() > 1 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `"%s":`, f.name)
+		b.Write(data)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// unmarshalJSON decodes a JSON object into the struct pointed to by v. Fields
+// without the omitempty option must be present in the object.
+func unmarshalJSON(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v).Elem()
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	for _, f := range jsonFields(rv.Type()) {
+		raw, ok := fields[f.name]
+		if !ok {
+			if f.omitempty {
+				continue
+			}
+			return fmt.Errorf(`missing "%s" field`, f.name)
+		}
+		if err := unmarshalJSONValue(raw, rv.FieldByIndex(f.index)); err != nil {
+			return fmt.Errorf(`invalid "%s" field: %s`, f.name, err)
+		}
+	}
+	return nil
+}
+
+// unmarshalJSONValue decodes a JSON value into v, which must be settable.
+func unmarshalJSONValue(raw json.RawMessage, v reflect.Value) error {
+	t := v.Type()
+	switch {
+	case t == byteSliceType:
+		var ints []int
+		if err := json.Unmarshal(raw, &ints); err != nil {
+			return err
+		}
+		b := make([]byte, len(ints))
+		for i, n := range ints {
+			if n < 0 || n > 255 {
+				return fmt.Errorf("%d is not a byte", n)
+			}
+			b[i] = byte(n)
+		}
+		v.Set(reflect.ValueOf(b))
+	case t.Kind() == reflect.Interface && t.NumMethod() > 0:
+		node, err := unmarshalJSONNode(raw, t)
+		if err != nil {
+			return err
+		}
+		if node.IsValid() {
+			v.Set(node)
+		}
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Interface:
+		var items []json.RawMessage
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return err
+		}
+		s := reflect.MakeSlice(t, len(items), len(items))
+		for i, item := range items {
+			if err := unmarshalJSONValue(item, s.Index(i)); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+	default:
+		return json.Unmarshal(raw, v.Addr().Interface())
+	}
+	return nil
+}
+
+// unmarshalJSONNode decodes a JSON value into a node that implements the
+// interface t, using the "type" field for determining the node's type. The
+// returned value is invalid if the JSON value is null.
+func unmarshalJSONNode(raw json.RawMessage, t reflect.Type) (reflect.Value, error) {
+	raw = bytes.TrimSpace(raw)
+	if bytes.Equal(raw, []byte("null")) {
+		return reflect.Value{}, nil
+	}
+	if len(raw) > 0 && raw[0] == '[' && t == hexTokenType {
+		var tokens HexTokens
+		err := unmarshalJSONValue(raw, reflect.ValueOf(&tokens).Elem())
+		return reflect.ValueOf(tokens), err
+	}
+	var header struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return reflect.Value{}, err
+	}
+	nt, ok := jsonTypes[header.Type]
+	if !ok {
+		return reflect.Value{}, fmt.Errorf(`unknown type "%s"`, header.Type)
+	}
+	it := implementing(nt, t)
+	if it == nil {
+		return reflect.Value{}, fmt.Errorf(`%s is not a valid %s`, header.Type, jsonInterfaceName(t))
+	}
+	p := reflect.New(nt)
+	if err := json.Unmarshal(raw, p.Interface()); err != nil {
+		return reflect.Value{}, err
+	}
+	if it == nt {
+		return p.Elem(), nil
+	}
+	return p, nil
+}
+
+// MarshalJSON returns the JSON encoding of the ruleset, which includes the
+// version of the encoding in the "version" field.
+func (r *RuleSet) MarshalJSON() ([]byte, error) {
+	data, err := marshalJSON(r)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(fmt.Sprintf(`{"version":%d,`, JSONVersion)), data[1:]...), nil
+}
+
+// UnmarshalJSON decodes a ruleset from its JSON encoding. An error is returned
+// if the encoding's version is newer than JSONVersion. In version 1 hex jumps
+// with end 0 are unbounded, and they are decoded as such.
+func (r *RuleSet) UnmarshalJSON(data []byte) error {
+	var header struct {
+		Version *int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return err
+	}
+	if header.Version == nil {
+		return fmt.Errorf(`missing "version" field`)
+	}
+	if *header.Version < 1 || *header.Version > JSONVersion {
+		return fmt.Errorf("unsupported JSON version: %d", *header.Version)
+	}
+	if err := unmarshalJSON(data, r); err != nil {
+		return err
+	}
+	if *header.Version == 1 {
+		for _, rule := range r.Rules {
+			for _, s := range rule.Strings {
+				if hex, ok := s.(*HexString); ok {
+					unboundHexJumps(hex.Tokens)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// unboundHexJumps makes unbounded the jumps in tokens that have end 0, which
+// is how version 1 of the JSON encoding represented unbounded jumps.
+func unboundHexJumps(tokens HexTokens) {
+	for _, t := range tokens {
+		switch v := t.(type) {
+		case *HexJump:
+			if v.End != nil && *v.End == 0 {
+				v.End = nil
+			}
+		case *HexOr:
+			for _, a := range v.Alternatives {
+				if alt, ok := a.(HexTokens); ok {
+					unboundHexJumps(alt)
+				}
+			}
+		}
+	}
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (r *Rule) MarshalJSON() ([]byte, error) { return marshalJSON(r) }
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (r *Rule) UnmarshalJSON(data []byte) error { return unmarshalJSON(data, r) }
+
+// MarshalJSON implements the json.Marshaler interface.
+func (m *Meta) MarshalJSON() ([]byte, error) { return marshalJSON(m) }
+
+// UnmarshalJSON decodes a metadata entry from its JSON encoding. Numbers are
+// decoded as int64.
+func (m *Meta) UnmarshalJSON(data []byte) error {
+	var meta struct {
+		Key   *string     `json:"key"`
+		Value interface{} `json:"value"`
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&meta); err != nil {
+		return err
+	}
+	if meta.Key == nil {
+		return fmt.Errorf(`missing "key" field`)
+	}
+	switch v := meta.Value.(type) {
+	case string, bool:
+		m.Value = v
+	case json.Number:
+		i, err := v.Int64()
+		if err != nil {
+			return fmt.Errorf(`invalid "value" field: %s`, err)
+		}
+		m.Value = i
+	default:
+		return fmt.Errorf(`invalid "value" field: must be a string, integer or boolean`)
+	}
+	m.Key = *meta.Key
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (k Keyword) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf(`{"type":"keyword","keyword":"%s"}`, k)), nil
+}
+
+// keywords contains the valid values for Keyword.
+var keywords = []Keyword{
+	KeywordAll, KeywordAny, KeywordNone, KeywordEntrypoint, KeywordFalse,
+	KeywordFilesize, KeywordThem, KeywordTrue,
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (k *Keyword) UnmarshalJSON(data []byte) error {
+	var keyword struct {
+		Keyword string `json:"keyword"`
+	}
+	if err := json.Unmarshal(data, &keyword); err != nil {
+		return err
+	}
+	for _, kw := range keywords {
+		if string(kw) == keyword.Keyword {
+			*k = kw
+			return nil
+		}
+	}
+	return fmt.Errorf(`invalid keyword "%s"`, keyword.Keyword)
+}
+
+// MarshalJSON encodes the modifiers as a string with the letter of each
+// modifier, like "is".
+func (r RegexpModifiers) MarshalJSON() ([]byte, error) {
+	var modifiers string
+	if r&RegexpCaseInsensitive != 0 {
+		modifiers += "i"
+	}
+	if r&RegexpDotAll != 0 {
+		modifiers += "s"
+	}
+	return json.Marshal(modifiers)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (r *RegexpModifiers) UnmarshalJSON(data []byte) error {
+	var modifiers string
+	if err := json.Unmarshal(data, &modifiers); err != nil {
+		return err
+	}
+	*r = 0
+	for _, m := range modifiers {
+		switch m {
+		case 'i':
+			*r |= RegexpCaseInsensitive
+		case 's':
+			*r |= RegexpDotAll
+		default:
+			return fmt.Errorf(`invalid regexp modifier "%c"`, m)
+		}
+	}
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (g *Group) MarshalJSON() ([]byte, error) { return marshalJSON(g) }
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (g *Group) UnmarshalJSON(data []byte) error { return unmarshalJSON(data, g) }
+
+// MarshalJSON implements the json.Marshaler interface.
+func (l *LiteralInteger) MarshalJSON() ([]byte, error) { return marshalJSON(l) }
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (l *LiteralInteger) UnmarshalJSON(data []byte) error { return unmarshalJSON(data, l) }
+
+// MarshalJSON implements the json.Marshaler interface.
+func (l *LiteralFloat) MarshalJSON() ([]byte, error) { return marshalJSON(l) }
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (l *LiteralFloat) UnmarshalJSON(data []byte) error { return unmarshalJSON(data, l) }
+
+// MarshalJSON implements the json.Marshaler interface.
+func (l *LiteralString) MarshalJSON() ([]byte, error) { return marshalJSON(l) }
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (l *LiteralString) UnmarshalJSON(data []byte) error { return unmarshalJSON(data, l) }
+
+// MarshalJSON implements the json.Marshaler interface.
+func (l *LiteralRegexp) MarshalJSON() ([]byte, error) { return marshalJSON(l) }
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (l *LiteralRegexp) UnmarshalJSON(data []byte) error { return unmarshalJSON(data, l) }
+
+// MarshalJSON implements the json.Marshaler interface.
+func (m *Minus) MarshalJSON() ([]byte, error) { return marshalJSON(m) }
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (m *Minus) UnmarshalJSON(data []byte) error { return unmarshalJSON(data, m) }
+
+// MarshalJSON implements the json.Marshaler interface.
+func (n *Not) MarshalJSON() ([]byte, error) { return marshalJSON(n) }
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (n *Not) UnmarshalJSON(data []byte) error { return unmarshalJSON(data, n) }
+
+// MarshalJSON implements the json.Marshaler interface.
+func (d *Defined) MarshalJSON() ([]byte, error) { return marshalJSON(d) }
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (d *Defined) UnmarshalJSON(data []byte) error { return unmarshalJSON(data, d) }
+
+// MarshalJSON implements the json.Marshaler interface.
+func (b *BitwiseNot) MarshalJSON() ([]byte, error) { return marshalJSON(b) }
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (b *BitwiseNot) UnmarshalJSON(data []byte) error { return unmarshalJSON(data, b) }
+
+// MarshalJSON implements the json.Marshaler interface.
+func (r *Range) MarshalJSON() ([]byte, error) { return marshalJSON(r) }
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (r *Range) UnmarshalJSON(data []byte) error { return unmarshalJSON(data, r) }
+
+// MarshalJSON implements the json.Marshaler interface.
+func (e *Enum) MarshalJSON() ([]byte, error) { return marshalJSON(e) }
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (e *Enum) UnmarshalJSON(data []byte) error { return unmarshalJSON(data, e) }
+
+// MarshalJSON implements the json.Marshaler interface.
+func (i *Identifier) MarshalJSON() ([]byte, error) { return marshalJSON(i) }
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (i *Identifier) UnmarshalJSON(data []byte) error { return unmarshalJSON(data, i) }
+
+// MarshalJSON implements the json.Marshaler interface.
+func (s *StringIdentifier) MarshalJSON() ([]byte, error) { return marshalJSON(s) }
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (s *StringIdentifier) UnmarshalJSON(data []byte) error { return unmarshalJSON(data, s) }
+
+// MarshalJSON implements the json.Marshaler interface.
+func (s *StringCount) MarshalJSON() ([]byte, error) { return marshalJSON(s) }
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (s *StringCount) UnmarshalJSON(data []byte) error { return unmarshalJSON(data, s) }
+
+// MarshalJSON implements the json.Marshaler interface.
+func (s *StringOffset) MarshalJSON() ([]byte, error) { return marshalJSON(s) }
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (s *StringOffset) UnmarshalJSON(data []byte) error { return unmarshalJSON(data, s) }
+
+// MarshalJSON implements the json.Marshaler interface.
+func (s *StringLength) MarshalJSON() ([]byte, error) { return marshalJSON(s) }
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (s *StringLength) UnmarshalJSON(data []byte) error { return unmarshalJSON(data, s) }
+
+// MarshalJSON implements the json.Marshaler interface.
+func (f *FunctionCall) MarshalJSON() ([]byte, error) { return marshalJSON(f) }
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (f *FunctionCall) UnmarshalJSON(data []byte) error { return unmarshalJSON(data, f) }
+
+// MarshalJSON implements the json.Marshaler interface.
+func (m *MemberAccess) MarshalJSON() ([]byte, error) { return marshalJSON(m) }
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (m *MemberAccess) UnmarshalJSON(data []byte) error { return unmarshalJSON(data, m) }
+
+// MarshalJSON implements the json.Marshaler interface.
+func (s *Subscripting) MarshalJSON() ([]byte, error) { return marshalJSON(s) }
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (s *Subscripting) UnmarshalJSON(data []byte) error { return unmarshalJSON(data, s) }
+
+// MarshalJSON implements the json.Marshaler interface.
+func (p *Percentage) MarshalJSON() ([]byte, error) { return marshalJSON(p) }
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (p *Percentage) UnmarshalJSON(data []byte) error { return unmarshalJSON(data, p) }
+
+// MarshalJSON implements the json.Marshaler interface.
+func (f *ForIn) MarshalJSON() ([]byte, error) { return marshalJSON(f) }
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (f *ForIn) UnmarshalJSON(data []byte) error { return unmarshalJSON(data, f) }
+
+// MarshalJSON implements the json.Marshaler interface.
+func (f *ForOf) MarshalJSON() ([]byte, error) { return marshalJSON(f) }
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (f *ForOf) UnmarshalJSON(data []byte) error { return unmarshalJSON(data, f) }
+
+// MarshalJSON implements the json.Marshaler interface.
+func (w *With) MarshalJSON() ([]byte, error) { return marshalJSON(w) }
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (w *With) UnmarshalJSON(data []byte) error { return unmarshalJSON(data, w) }
+
+// MarshalJSON implements the json.Marshaler interface.
+func (d *WithDeclaration) MarshalJSON() ([]byte, error) { return marshalJSON(d) }
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (d *WithDeclaration) UnmarshalJSON(data []byte) error { return unmarshalJSON(data, d) }
+
+// MarshalJSON implements the json.Marshaler interface.
+func (o *Of) MarshalJSON() ([]byte, error) { return marshalJSON(o) }
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (o *Of) UnmarshalJSON(data []byte) error { return unmarshalJSON(data, o) }
+
+// MarshalJSON implements the json.Marshaler interface.
+func (o *Operation) MarshalJSON() ([]byte, error) { return marshalJSON(o) }
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (o *Operation) UnmarshalJSON(data []byte) error { return unmarshalJSON(data, o) }
+
+// MarshalJSON implements the json.Marshaler interface.
+func (t *TextString) MarshalJSON() ([]byte, error) { return marshalJSON(t) }
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (t *TextString) UnmarshalJSON(data []byte) error { return unmarshalJSON(data, t) }
+
+// MarshalJSON implements the json.Marshaler interface.
+func (r *RegexpString) MarshalJSON() ([]byte, error) { return marshalJSON(r) }
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (r *RegexpString) UnmarshalJSON(data []byte) error { return unmarshalJSON(data, r) }
+
+// MarshalJSON implements the json.Marshaler interface.
+func (h *HexString) MarshalJSON() ([]byte, error) { return marshalJSON(h) }
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (h *HexString) UnmarshalJSON(data []byte) error { return unmarshalJSON(data, h) }
+
+// MarshalJSON implements the json.Marshaler interface.
+func (h *HexJump) MarshalJSON() ([]byte, error) { return marshalJSON(h) }
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (h *HexJump) UnmarshalJSON(data []byte) error { return unmarshalJSON(data, h) }
+
+// MarshalJSON implements the json.Marshaler interface.
+func (h *HexBytes) MarshalJSON() ([]byte, error) { return marshalJSON(h) }
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (h *HexBytes) UnmarshalJSON(data []byte) error { return unmarshalJSON(data, h) }
+
+// MarshalJSON implements the json.Marshaler interface.
+func (h *HexOr) MarshalJSON() ([]byte, error) { return marshalJSON(h) }
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (h *HexOr) UnmarshalJSON(data []byte) error { return unmarshalJSON(data, h) }
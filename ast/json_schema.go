@@ -0,0 +1,158 @@
+package ast
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+var (
+	ruleType            = reflect.TypeOf(Rule{})
+	metaType            = reflect.TypeOf(Meta{})
+	operatorType        = reflect.TypeOf(OperatorType(""))
+	regexpModifiersType = reflect.TypeOf(RegexpModifiers(0))
+)
+
+// JSONSchema returns a JSON Schema describing the JSON encoding of a RuleSet.
+// The schema is generated from the types in this package, the one published
+// in ruleset.schema.json is kept up to date with "go generate".
+func JSONSchema() ([]byte, error) {
+	definitions := map[string]interface{}{
+		"rule": objectSchema(ruleType, ""),
+		"meta": map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"key":   map[string]interface{}{"type": "string"},
+				"value": map[string]interface{}{"type": []string{"string", "integer", "boolean"}},
+			},
+			"required":             []string{"key", "value"},
+			"additionalProperties": false,
+		},
+	}
+	for _, iface := range []reflect.Type{expressionType, nodeType, stringType, hexTokenType} {
+		var options []interface{}
+		for _, name := range sortedJSONTypes() {
+			if implementing(jsonTypes[name], iface) != nil {
+				options = append(options, ref(name))
+			}
+		}
+		if iface == hexTokenType {
+			options = append(options, schemaFor(reflect.TypeOf(HexTokens(nil))))
+		}
+		definitions[jsonInterfaceName(iface)] = map[string]interface{}{"oneOf": options}
+	}
+	for name, t := range jsonTypes {
+		if t.Kind() == reflect.Struct {
+			definitions[name] = objectSchema(t, name)
+		}
+	}
+	var keywordNames []string
+	for _, k := range keywords {
+		keywordNames = append(keywordNames, string(k))
+	}
+	definitions["keyword"] = map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"type":    map[string]interface{}{"const": "keyword"},
+			"keyword": map[string]interface{}{"enum": keywordNames},
+		},
+		"required":             []string{"type", "keyword"},
+		"additionalProperties": false,
+	}
+
+	schema := objectSchema(reflect.TypeOf(RuleSet{}), "")
+	properties := schema["properties"].(map[string]interface{})
+	properties["version"] = map[string]interface{}{"const": JSONVersion}
+	schema["required"] = append([]string{"version"}, schema["required"].([]string)...)
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["title"] = "YARA ruleset"
+	schema["definitions"] = definitions
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// sortedJSONTypes returns the keys in jsonTypes, sorted alphabetically.
+func sortedJSONTypes() []string {
+	names := make([]string, 0, len(jsonTypes))
+	for name := range jsonTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func ref(name string) map[string]interface{} {
+	return map[string]interface{}{"$ref": "#/definitions/" + name}
+}
+
+// objectSchema returns the schema for the JSON encoding of the struct type t.
+// If typeName is not empty the object has a "type" field with that value.
+func objectSchema(t reflect.Type, typeName string) map[string]interface{} {
+	properties := make(map[string]interface{})
+	required := []string{}
+	if typeName != "" {
+		properties["type"] = map[string]interface{}{"const": typeName}
+		required = append(required, "type")
+	}
+	for _, f := range jsonFields(t) {
+		properties[f.name] = schemaFor(t.FieldByIndex(f.index).Type)
+		if !f.omitempty {
+			required = append(required, f.name)
+		}
+	}
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+// schemaFor returns the schema for the JSON encoding of a value of type t.
+func schemaFor(t reflect.Type) map[string]interface{} {
+	switch t {
+	case byteSliceType:
+		return map[string]interface{}{
+			"type":  "array",
+			"items": map[string]interface{}{"type": "integer", "minimum": 0, "maximum": 255},
+		}
+	case operatorType:
+		var operators []string
+		for op := range OpPrecedence {
+			// "not" is represented by the Not node, not by an Operation.
+			if op != OpNot {
+				operators = append(operators, string(op))
+			}
+		}
+		sort.Strings(operators)
+		return map[string]interface{}{"type": "string", "enum": operators}
+	case regexpModifiersType:
+		return map[string]interface{}{"type": "string", "pattern": "^i?s?$"}
+	}
+	if name, ok := jsonTypeNames[t]; ok {
+		return ref(name)
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice:
+		return map[string]interface{}{"type": "array", "items": schemaFor(t.Elem())}
+	case reflect.Interface:
+		return ref(jsonInterfaceName(t))
+	case reflect.Ptr:
+		switch t.Elem() {
+		case ruleType:
+			return ref("rule")
+		case metaType:
+			return ref("meta")
+		}
+		return schemaFor(t.Elem())
+	}
+	panic(fmt.Sprintf("no JSON schema for type %s", t))
+}
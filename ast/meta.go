@@ -2,9 +2,10 @@ package ast
 
 import (
 	"fmt"
+	"strconv"
+
 	"github.com/VirusTotal/gyp/pb"
 	"github.com/golang/protobuf/proto"
-	"strconv"
 )
 
 // Meta represents an entry in a rule's metadata section. Each entry is
@@ -12,32 +13,48 @@ import (
 // or a bool. When value is a string it appears exactly as in the source code,
 // escaped characters remain escaped.
 type Meta struct {
-	Key   string
-	Value interface{}
+	Key   string      `json:"key"`
+	Value interface{} `json:"value"`
 }
 
 // String returns the string representation of a metadata entry.
 func (m *Meta) String() string {
-	// With %#v we print the Golang's representation of the value, which
-	// happens to be the same in YARA. For values of string type we simply
-	// use the value as is, because the meta value is the string exactly as
-	// it appears in the YARA source.
-	if s, isString := m.Value.(string); isString {
-		return fmt.Sprintf("%s = \"%s\"", m.Key, s)
+	// For values of string type we simply use the value as is, because the
+	// meta value is the string exactly as it appears in the YARA source.
+	// Integers and booleans are written as in Go, which happens to be the
+	// same in YARA.
+	switch v := m.Value.(type) {
+	case string:
+		return m.Key + " = \"" + v + "\""
+	case int64:
+		return m.Key + " = " + strconv.FormatInt(v, 10)
+	case bool:
+		return m.Key + " = " + strconv.FormatBool(v)
 	}
 	return fmt.Sprintf("%s = %#v", m.Key, m.Value)
 }
 
 // UnescapedValue returns the metadata Value with any escape sequence replaced
-// by the actual character that it represents.
+// by the actual character that it represents. It panics if Value is not a
+// string or contains invalid escape sequences, see Unescape.
 func (m *Meta) UnescapedValue() string {
-	unescaped, err := strconv.Unquote(fmt.Sprintf(`"%s"`, m.Value))
+	unescaped, err := m.Unescape()
 	if err != nil {
 		panic(err)
 	}
 	return unescaped
 }
 
+// Unescape is like UnescapedValue, but returns an error if Value is not a
+// string or contains invalid escape sequences.
+func (m *Meta) Unescape() (string, error) {
+	s, isString := m.Value.(string)
+	if !isString {
+		return "", fmt.Errorf("meta %s is not a string", m.Key)
+	}
+	return unescape(s)
+}
+
 // AsProto returns the meta serialized as a Meta protobuf.
 func (m *Meta) AsProto() *pb.Meta {
 	meta := &pb.Meta{Key: proto.String(m.Key)}
@@ -75,3 +75,61 @@ var OpPrecedence = map[OperatorType]int{
 // OpMaxPrecedence is the maximum possible precedence. This is also the precedence
 // for unary operators "~" and "-".
 const OpMaxPrecedence = 11
+
+// expressionPrecedence returns the precedence level of the given expression.
+// For operations this is the operator's precedence, "not" and "defined" have
+// the precedence of OpNot, any other expression has the maximum possible
+// precedence level.
+func expressionPrecedence(e Expression) int {
+	switch v := e.(type) {
+	case *Operation:
+		return OpPrecedence[v.Operator]
+	case *Not, *Defined:
+		return OpPrecedence[OpNot]
+	}
+	return OpMaxPrecedence
+}
+
+// isPrimaryExpression returns true if the expression is what YARA's grammar
+// calls a primary expression, which are arithmetic and bitwise operations,
+// literals, identifiers, string counts, offsets and lengths, etc. Boolean
+// expressions like "not", "and", "or", comparisons, string identifiers and
+// loops are not primary expressions. The distinction is important because
+// some operators accept only primary expressions as operands, and a boolean
+// expression can't be converted into a primary expression, not even by
+// enclosing it in parentheses.
+func isPrimaryExpression(e Expression) bool {
+	switch v := e.(type) {
+	case *Group:
+		return isPrimaryExpression(v.Expression)
+	case *Operation:
+		return OpPrecedence[v.Operator] >= OpPrecedence[OpBitOr]
+	case Keyword:
+		return v == KeywordFilesize || v == KeywordEntrypoint
+	case *LiteralInteger, *LiteralFloat, *LiteralString, *LiteralRegexp,
+		*Identifier, *MemberAccess, *Subscripting, *FunctionCall,
+		*StringCount, *StringOffset, *StringLength, *Minus, *BitwiseNot:
+		return true
+	}
+	return false
+}
+
+// operandNeedsParens returns true if the i-th operand in an operation with
+// the given operator must be enclosed in parentheses. Operands that are
+// operations with lower precedence than the operator always need parentheses.
+// Operations with the same precedence only need them when they are not the
+// left-most operand, as all binary operators are left-associative. Operators
+// that accept only primary expressions as operands (comparisons, arithmetic
+// and bitwise operators) also need parentheses around other expressions.
+func operandNeedsParens(operator OperatorType, operand Expression, i int) bool {
+	if _, isGroup := operand.(*Group); isGroup {
+		return false
+	}
+	precedence := OpPrecedence[operator]
+	if precedence > OpPrecedence[OpNot] && !isPrimaryExpression(operand) {
+		return true
+	}
+	operandPrecedence := expressionPrecedence(operand)
+	return operandPrecedence < precedence ||
+		operandPrecedence == precedence && i > 0
+}
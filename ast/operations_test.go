@@ -0,0 +1,148 @@
+package ast
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func op(operator OperatorType, operands ...Expression) *Operation {
+	return &Operation{Operator: operator, Operands: operands}
+}
+
+func integer(v int64) *LiteralInteger {
+	return &LiteralInteger{Value: v}
+}
+
+var expressionWriteSourceTests = []struct {
+	Expression     Expression
+	ExpectedSource string
+}{
+	{
+		Expression:     op(OpMul, op(OpAdd, integer(1), integer(2)), integer(3)),
+		ExpectedSource: "(1 + 2) * 3",
+	},
+	{
+		Expression:     op(OpAdd, op(OpMul, integer(1), integer(2)), integer(3)),
+		ExpectedSource: "1 * 2 + 3",
+	},
+	{
+		Expression:     op(OpSub, op(OpSub, integer(1), integer(2)), integer(3)),
+		ExpectedSource: "1 - 2 - 3",
+	},
+	{
+		Expression:     op(OpSub, integer(1), op(OpSub, integer(2), integer(3))),
+		ExpectedSource: "1 - (2 - 3)",
+	},
+	{
+		Expression:     op(OpAdd, integer(1), op(OpSub, integer(2), integer(3))),
+		ExpectedSource: "1 + (2 - 3)",
+	},
+	{
+		Expression:     op(OpBitAnd, op(OpBitOr, integer(1), integer(2)), integer(3)),
+		ExpectedSource: "(1 | 2) & 3",
+	},
+	{
+		Expression:     op(OpBitOr, op(OpBitAnd, integer(1), integer(2)), integer(3)),
+		ExpectedSource: "1 & 2 | 3",
+	},
+	{
+		Expression:     op(OpShiftLeft, integer(1), op(OpAdd, integer(2), integer(3))),
+		ExpectedSource: "1 << 2 + 3",
+	},
+	{
+		Expression: op(OpEqual,
+			op(OpBitOr, integer(1), integer(2)),
+			op(OpAdd, integer(3), integer(4))),
+		ExpectedSource: "1 | 2 == 3 + 4",
+	},
+	{
+		Expression:     op(OpAnd, op(OpOr, KeywordTrue, KeywordFalse), KeywordTrue),
+		ExpectedSource: "(true or false) and true",
+	},
+	{
+		Expression:     op(OpOr, op(OpAnd, KeywordTrue, KeywordFalse), KeywordTrue),
+		ExpectedSource: "true and false or true",
+	},
+	{
+		Expression:     op(OpOr, KeywordTrue, op(OpOr, KeywordFalse, KeywordTrue)),
+		ExpectedSource: "true or (false or true)",
+	},
+	{
+		Expression:     op(OpAnd, &Group{op(OpOr, KeywordTrue, KeywordFalse)}, KeywordTrue),
+		ExpectedSource: "(true or false) and true",
+	},
+	{
+		Expression:     &Not{op(OpAnd, KeywordTrue, KeywordFalse)},
+		ExpectedSource: "not (true and false)",
+	},
+	{
+		Expression:     &Not{op(OpEqual, integer(1), integer(2))},
+		ExpectedSource: "not 1 == 2",
+	},
+	{
+		Expression:     op(OpAnd, &Not{KeywordTrue}, KeywordFalse),
+		ExpectedSource: "not true and false",
+	},
+	{
+		Expression:     &Defined{op(OpOr, KeywordTrue, KeywordFalse)},
+		ExpectedSource: "defined (true or false)",
+	},
+	{
+		Expression:     &Defined{&Identifier{Identifier: "foo"}},
+		ExpectedSource: "defined foo",
+	},
+	{
+		Expression:     &Minus{op(OpAdd, integer(1), integer(2))},
+		ExpectedSource: "-(1 + 2)",
+	},
+	{
+		Expression:     &Minus{op(OpMul, integer(1), integer(2))},
+		ExpectedSource: "-(1 * 2)",
+	},
+	{
+		Expression:     &Minus{&Minus{integer(1)}},
+		ExpectedSource: "--1",
+	},
+	{
+		Expression:     &BitwiseNot{op(OpBitAnd, integer(1), integer(2))},
+		ExpectedSource: "~(1 & 2)",
+	},
+	{
+		Expression:     op(OpMul, &Minus{integer(1)}, &BitwiseNot{integer(2)}),
+		ExpectedSource: "-1 * ~2",
+	},
+	{
+		Expression: &StringIdentifier{
+			Identifier: "a",
+			At:         op(OpAdd, integer(1), integer(2)),
+		},
+		ExpectedSource: "$a at 1 + 2",
+	},
+	{
+		Expression: &StringIdentifier{
+			Identifier: "a",
+			In: &Range{
+				Start: op(OpMul, integer(1), integer(2)),
+				End:   &Identifier{Identifier: "filesize"},
+			},
+		},
+		ExpectedSource: "$a in (1 * 2..filesize)",
+	},
+	{
+		Expression: op(OpOr,
+			&StringIdentifier{Identifier: "a", At: integer(0)},
+			&StringIdentifier{Identifier: "b"}),
+		ExpectedSource: "$a at 0 or $b",
+	},
+}
+
+func TestExpressionWriteSource(t *testing.T) {
+	for _, test := range expressionWriteSourceTests {
+		var b strings.Builder
+		err := test.Expression.WriteSource(&b)
+		assert.NoError(t, err)
+		assert.Equal(t, test.ExpectedSource, b.String())
+	}
+}
@@ -1,10 +1,8 @@
 package ast
 
 import (
-	"fmt"
+	"bytes"
 	"io"
-	"strings"
-	"text/template"
 
 	"github.com/VirusTotal/gyp/pb"
 	"github.com/golang/protobuf/proto"
@@ -13,79 +11,105 @@ import (
 // Rule describes a YARA rule.
 type Rule struct {
 	// Line number where the rule starts
-	LineNo     int
-	Global     bool
-	Private    bool
-	Identifier string
-	Tags       []string
-	Meta       []*Meta
-	Strings    []String
-	Condition  Expression
+	LineNo     int        `json:"line_no,omitempty"`
+	Global     bool       `json:"global,omitempty"`
+	Private    bool       `json:"private,omitempty"`
+	Identifier string     `json:"identifier"`
+	Tags       []string   `json:"tags,omitempty"`
+	Meta       []*Meta    `json:"meta,omitempty"`
+	Strings    []String   `json:"strings,omitempty"`
+	Condition  Expression `json:"condition"`
 }
 
 // RuleSet describes a set of YARA rules.
 type RuleSet struct {
-	Imports  []string
-	Includes []string
-	Rules    []*Rule
+	Imports  []string `json:"imports,omitempty"`
+	Includes []string `json:"includes,omitempty"`
+	Rules    []*Rule  `json:"rules"`
 }
 
-var ruleTmpl = template.Must(template.New("rule").Parse(`
-{{ with .Rule -}}
-{{ if .Global }}global {{ end -}}
-{{ if .Private }}private {{ end -}}
-rule {{ .Identifier }} {{ if .Tags }}: {{ range .Tags }}{{ . }} {{ end }}{{ end }}{
-{{- if .Meta }}
-  meta:
-  {{- range .Meta }}
-    {{ . }}
-  {{- end }}
-{{- end }}
-{{- if .Strings }}
-  strings:
-  {{- range .Strings }}
-    {{ . }}
-  {{- end }}
-{{- end }}
-{{- end }}
-  condition:
-    {{ .Condition }}
-}
-`))
-
 // WriteSource writes the rule's source into the writer w.
 func (r *Rule) WriteSource(w io.Writer) error {
-	if r.Condition == nil {
-		panic("rule without condition")
-	}
-	var condition strings.Builder
-	if err := r.Condition.WriteSource(&condition); err != nil {
+	var b bytes.Buffer
+	return r.writeSource(w, &b)
+}
+
+// writeSource is like WriteSource, but uses b as a buffer for the source,
+// which is written into w with a single call to w.Write. Nothing is written
+// if the rule's condition is not valid, see Validate.
+func (r *Rule) writeSource(w io.Writer, b *bytes.Buffer) error {
+	if err := r.validateCondition(); err != nil {
 		return err
 	}
-	t := struct {
-		Rule      *Rule
-		Condition string
-	}{
-		Rule:      r,
-		Condition: condition.String(),
+	b.Reset()
+	b.WriteString("\n")
+	if r.Global {
+		b.WriteString("global ")
+	}
+	if r.Private {
+		b.WriteString("private ")
 	}
-	return ruleTmpl.Execute(w, t)
+	b.WriteString("rule ")
+	b.WriteString(r.Identifier)
+	b.WriteString(" ")
+	if len(r.Tags) > 0 {
+		b.WriteString(": ")
+		for _, tag := range r.Tags {
+			b.WriteString(tag)
+			b.WriteString(" ")
+		}
+	}
+	b.WriteString("{")
+	if len(r.Meta) > 0 {
+		b.WriteString("\n  meta:")
+		for _, m := range r.Meta {
+			b.WriteString("\n    ")
+			b.WriteString(m.String())
+		}
+	}
+	if len(r.Strings) > 0 {
+		b.WriteString("\n  strings:")
+		for _, s := range r.Strings {
+			b.WriteString("\n    ")
+			// All the strings in this package can write their source without
+			// building a string first. Errors are ignored, as String does.
+			if n, ok := s.(Node); ok {
+				n.WriteSource(b)
+			} else {
+				b.WriteString(s.String())
+			}
+		}
+	}
+	b.WriteString("\n  condition:\n    ")
+	if err := r.Condition.WriteSource(b); err != nil {
+		return err
+	}
+	b.WriteString("\n}\n")
+	_, err := w.Write(b.Bytes())
+	return err
 }
 
 // WriteSource writes the ruleset's source into the writer w.
 func (r *RuleSet) WriteSource(w io.Writer) error {
+	var b bytes.Buffer
 	for _, imp := range r.Imports {
-		if _, err := fmt.Fprintf(w, "import \"%s\"\n", imp); err != nil {
-			return err
-		}
+		b.WriteString("import \"")
+		b.WriteString(imp)
+		b.WriteString("\"\n")
 	}
 	for _, inc := range r.Includes {
-		if _, err := fmt.Fprintf(w, "include \"%s\"\n", inc); err != nil {
+		b.WriteString("include \"")
+		b.WriteString(inc)
+		b.WriteString("\"\n")
+	}
+	if b.Len() > 0 {
+		if _, err := w.Write(b.Bytes()); err != nil {
 			return err
 		}
 	}
+	// The same buffer is reused for all the rules.
 	for _, r := range r.Rules {
-		if err := r.WriteSource(w); err != nil {
+		if err := r.writeSource(w, &b); err != nil {
 			return err
 		}
 	}
@@ -94,10 +118,16 @@ func (r *RuleSet) WriteSource(w io.Writer) error {
 
 // Children returns the node's children.
 func (r *Rule) Children() []Node {
-	return []Node{r.Condition}
+	return r.appendChildren(nil)
+}
+
+func (r *Rule) appendChildren(dst []Node) []Node {
+	return append(dst, r.Condition)
 }
 
-// AsProto returns the rule serialized as a Rule protobuf message.
+// AsProto returns the rule serialized as a Rule protobuf message. It panics
+// if the rule is not valid, use ToProto for rules that don't come from the
+// parser.
 func (r *Rule) AsProto() *pb.Rule {
 	meta := make([]*pb.Meta, len(r.Meta))
 	for i, m := range r.Meta {
@@ -120,7 +150,18 @@ func (r *Rule) AsProto() *pb.Rule {
 	}
 }
 
-// AsProto returns the rule set serialized as the RuleSet protobuf message.
+// ToProto is like AsProto, but returns a *ValidationError if the rule is not
+// valid, see Validate.
+func (r *Rule) ToProto() (*pb.Rule, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return r.AsProto(), nil
+}
+
+// AsProto returns the rule set serialized as the RuleSet protobuf message. It
+// panics if the ruleset is not valid, use ToProto for rulesets that don't come
+// from the parser.
 func (r *RuleSet) AsProto() *pb.RuleSet {
 	rules := make([]*pb.Rule, len(r.Rules))
 	for i, rule := range r.Rules {
@@ -132,3 +173,12 @@ func (r *RuleSet) AsProto() *pb.RuleSet {
 		Rules:    rules,
 	}
 }
+
+// ToProto is like AsProto, but returns a *ValidationError if the ruleset is
+// not valid, see Validate.
+func (r *RuleSet) ToProto() (*pb.RuleSet, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return r.AsProto(), nil
+}
@@ -7,31 +7,94 @@ import (
 	"github.com/VirusTotal/gyp/pb"
 )
 
-// RuleSetFromProto creates a RuleSet from its corresponding protobuf.
+// RuleSetFromProto creates a RuleSet from its corresponding protobuf. It
+// panics if the protobuf is not valid, use NewRuleSetFromProto for protobufs
+// that come from untrusted sources.
 func RuleSetFromProto(rs *pb.RuleSet) *RuleSet {
+	ruleset, err := NewRuleSetFromProto(rs)
+	if err != nil {
+		panic(err)
+	}
+	return ruleset
+}
+
+// NewRuleSetFromProto creates a RuleSet from its corresponding protobuf. If
+// the protobuf is not valid it returns a *ValidationError indicating the
+// invalid field and the rule that contains it.
+func NewRuleSetFromProto(rs *pb.RuleSet) (*RuleSet, error) {
 	pbRules := rs.GetRules()
 	astRules := make([]*Rule, len(pbRules))
 	for i, rule := range pbRules {
-		astRules[i] = RuleFromProto(rule)
+		var err error
+		if astRules[i], err = NewRuleFromProto(rule); err != nil {
+			if e := err.(*ValidationError); e.Rule == "" {
+				return nil, inField(fmt.Sprintf("rules[%d]", i), err)
+			}
+			return nil, err
+		}
 	}
 	return &RuleSet{
 		Imports:  rs.GetImports(),
 		Includes: rs.GetIncludes(),
 		Rules:    astRules,
-	}
+	}, nil
 }
 
-// RuleFromProto creates a Rule from its corresponding protobuf.
+// RuleFromProto creates a Rule from its corresponding protobuf. It panics if
+// the protobuf is not valid, use NewRuleFromProto for protobufs that come
+// from untrusted sources.
 func RuleFromProto(r *pb.Rule) *Rule {
+	rule, err := NewRuleFromProto(r)
+	if err != nil {
+		panic(err)
+	}
+	return rule
+}
+
+// NewRuleFromProto creates a Rule from its corresponding protobuf. If the
+// protobuf is not valid it returns a *ValidationError indicating the invalid
+// field.
+func NewRuleFromProto(r *pb.Rule) (*Rule, error) {
+	if r.GetIdentifier() == "" {
+		return nil, invalid("identifier", "missing rule identifier")
+	}
+	rule, err := ruleFromProto(r)
+	if err != nil {
+		e := *err.(*ValidationError)
+		e.Rule = r.GetIdentifier()
+		return nil, &e
+	}
+	return rule, nil
+}
+
+// NewExpressionFromProto creates an Expression from its corresponding
+// protobuf. If the protobuf is not valid it returns a *ValidationError
+// indicating the invalid field. Protobufs don't have groups, so they are
+// created where required by the operator precedence and YARA's grammar.
+func NewExpressionFromProto(e *pb.Expression) (Expression, error) {
+	return requiredExpressionFromProto("", e)
+}
+
+func ruleFromProto(r *pb.Rule) (*Rule, error) {
 	pbStrings := r.GetStrings()
 	astStrings := make([]String, len(pbStrings))
 	for i, s := range pbStrings {
-		astStrings[i] = stringFromProto(s)
+		var err error
+		if astStrings[i], err = stringFromProto(s); err != nil {
+			return nil, inField(fmt.Sprintf("strings[%d]", i), err)
+		}
 	}
 	pbMeta := r.GetMeta()
 	astMeta := make([]*Meta, len(pbMeta))
 	for i, m := range pbMeta {
-		astMeta[i] = metaFromProto(m)
+		var err error
+		if astMeta[i], err = metaFromProto(m); err != nil {
+			return nil, inField(fmt.Sprintf("meta[%d]", i), err)
+		}
+	}
+	condition, err := requiredExpressionFromProto("condition", r.GetCondition())
+	if err != nil {
+		return nil, err
 	}
 	return &Rule{
 		Global:     r.GetModifiers().GetGlobal(),
@@ -40,18 +103,8 @@ func RuleFromProto(r *pb.Rule) *Rule {
 		Identifier: r.GetIdentifier(),
 		Strings:    astStrings,
 		Meta:       astMeta,
-		Condition:  expressionFromProto(r.GetCondition()),
-	}
-}
-
-// If the expression is an operation returns the operator's precedence level,
-// if not, returns the maximum possible precedence level.
-func expressionPrecedence(e Expression) int {
-	switch v := e.(type) {
-	case *Operation:
-		return OpPrecedence[v.Operator]
-	}
-	return OpMaxPrecedence
+		Condition:  condition,
+	}, nil
 }
 
 // Map for converting operators defined in the protobuf to those used by the AST.
@@ -120,8 +173,11 @@ var astToPb = map[OperatorType]pb.BinaryExpression_Operator{
 // precedence level is the same the operand is also enclosed in parenthesis,
 // except for the left-most operand, which won't require parenthesis if the operator
 // is left-associative. So, this function can be used with left-associative operators.
-func createOperationExpression(operator OperatorType, terms ...*pb.Expression) Expression {
-	operands := expressionsFromProto(terms...)
+func createOperationExpression(operator OperatorType, terms ...*pb.Expression) (Expression, error) {
+	operands, err := expressionsFromProto(terms...)
+	if err != nil {
+		return nil, err
+	}
 	for i, operand := range operands {
 		operandPrecedence := expressionPrecedence(operand)
 		if operandPrecedence < OpPrecedence[operator] ||
@@ -132,14 +188,18 @@ func createOperationExpression(operator OperatorType, terms ...*pb.Expression) E
 	return &Operation{
 		Operator: operator,
 		Operands: operands,
-	}
+	}, nil
 }
 
-func createIdentifierExpression(ident *pb.Identifier) Expression {
+func createIdentifierExpression(ident *pb.Identifier) (Expression, error) {
 	var expr Expression
-	for _, item := range ident.GetItems() {
+	for i, item := range ident.GetItems() {
+		field := fmt.Sprintf("items[%d]", i)
 		switch v := item.GetItem().(type) {
 		case *pb.Identifier_IdentifierItem_Identifier:
+			if v.Identifier == "" {
+				return nil, invalid(field+".identifier", "missing identifier")
+			}
 			if expr != nil {
 				expr = &MemberAccess{Container: expr, Member: v.Identifier}
 			} else {
@@ -147,25 +207,39 @@ func createIdentifierExpression(ident *pb.Identifier) Expression {
 			}
 		case *pb.Identifier_IdentifierItem_Arguments:
 			if expr == nil {
-				panic("arguments can't be the left-most item in an identifer")
+				return nil, invalid(field, "arguments can't be the left-most item in an identifier")
+			}
+			args, err := expressionsFromProto(v.Arguments.GetTerms()...)
+			if err != nil {
+				return nil, inField(field+".arguments.terms", err)
 			}
 			expr = &FunctionCall{
 				Callable:  expr,
-				Arguments: expressionsFromProto(v.Arguments.GetTerms()...)}
+				Arguments: args,
+			}
 		case *pb.Identifier_IdentifierItem_Index:
 			if expr == nil {
-				panic("index can't be the left-most item in an identifer")
+				return nil, invalid(field, "index can't be the left-most item in an identifier")
+			}
+			index, err := requiredExpressionFromProto("index", v.Index)
+			if err != nil {
+				return nil, inField(field, err)
 			}
 			expr = &Subscripting{
 				Array: expr,
-				Index: expressionFromProto(v.Index),
+				Index: index,
 			}
+		default:
+			return nil, invalid(field, "missing item")
 		}
 	}
-	return expr
+	if expr == nil {
+		return nil, invalid("items", "missing identifier")
+	}
+	return expr, nil
 }
 
-func stringFromProto(s *pb.String) String {
+func stringFromProto(s *pb.String) (String, error) {
 	switch v := s.GetValue().(type) {
 	case *pb.String_Text:
 		modifiers := v.Text.GetModifiers()
@@ -185,14 +259,18 @@ func stringFromProto(s *pb.String) String {
 			Base64Wide:     modifiers.GetBase64Wide(),
 			Base64Alphabet: modifiers.GetBase64Alphabet(),
 			Value:          Escape(v.Text.GetText()),
-		}
+		}, nil
 	case *pb.String_Hex:
+		tokens, err := hexTokensFromProto(v.Hex)
+		if err != nil {
+			return nil, inField("hex", err)
+		}
 		return &HexString{
 			BaseString: BaseString{
 				Identifier: strings.TrimPrefix(s.GetId(), "$"),
 			},
-			Tokens: hexTokensFromProto(v.Hex),
-		}
+			Tokens: tokens,
+		}, nil
 	case *pb.String_Regexp:
 		modifiers := v.Regexp.GetModifiers()
 		var regexpm RegexpModifiers
@@ -215,41 +293,74 @@ func stringFromProto(s *pb.String) String {
 				Value:     v.Regexp.GetText(),
 				Modifiers: regexpm,
 			},
-		}
+		}, nil
 	default:
-		panic(fmt.Sprintf(`unexpected string "%T"`, v))
+		return nil, invalid("value", "missing string value")
 	}
 }
 
-func hexTokensFromProto(pbTokens *pb.HexTokens) HexTokens {
+func hexTokensFromProto(pbTokens *pb.HexTokens) (HexTokens, error) {
 	tokens := make(HexTokens, len(pbTokens.GetToken()))
 	for i, token := range pbTokens.GetToken() {
+		field := fmt.Sprintf("token[%d]", i)
 		switch v := token.GetValue().(type) {
 		case *pb.HexToken_Sequence:
+			value := v.Sequence.GetValue()
+			masks := v.Sequence.GetMask()
+			nots := v.Sequence.GetNots()
+			if len(masks) != len(value) {
+				return nil, invalid(field+".sequence.mask", "expecting %d masks, got %d", len(value), len(masks))
+			}
+			for _, mask := range masks {
+				if mask != 0x00 && mask != 0x0F && mask != 0xF0 && mask != 0xFF {
+					return nil, invalid(field+".sequence.mask", "invalid mask %02X", mask)
+				}
+			}
+			// Protobufs created before the "not" operator was introduced
+			// don't have nots.
+			if len(nots) == 0 {
+				nots = make([]bool, len(value))
+			} else if len(nots) != len(value) {
+				return nil, invalid(field+".sequence.nots", "expecting %d nots, got %d", len(value), len(nots))
+			}
 			tokens[i] = &HexBytes{
-				Bytes: v.Sequence.GetValue(),
-				Masks: v.Sequence.GetMask(),
-				Nots: v.Sequence.GetNots(),
+				Bytes: value,
+				Masks: masks,
+				Nots:  nots,
 			}
 		case *pb.HexToken_Alternative:
 			alternatives := make(HexTokens, len(v.Alternative.GetTokens()))
-			for i, a := range v.Alternative.GetTokens() {
-				alternatives[i] = hexTokensFromProto(a)
+			for j, a := range v.Alternative.GetTokens() {
+				var err error
+				if alternatives[j], err = hexTokensFromProto(a); err != nil {
+					return nil, inField(fmt.Sprintf("%s.alternative.tokens[%d]", field, j), err)
+				}
 			}
 			tokens[i] = &HexOr{
 				Alternatives: alternatives,
 			}
 		case *pb.HexToken_Jump:
+			// Jumps without end are unbounded.
+			var end *int
+			if v.Jump.End != nil {
+				e := int(v.Jump.GetEnd())
+				end = &e
+			}
 			tokens[i] = &HexJump{
 				Start: int(v.Jump.GetStart()),
-				End:   int(v.Jump.GetEnd()),
+				End:   end,
 			}
+		default:
+			return nil, invalid(field, "missing token value")
 		}
 	}
-	return tokens
+	return tokens, nil
 }
 
-func metaFromProto(m *pb.Meta) *Meta {
+func metaFromProto(m *pb.Meta) (*Meta, error) {
+	if m.GetKey() == "" {
+		return nil, invalid("key", "missing meta key")
+	}
 	var value interface{}
 	switch v := m.GetValue().(type) {
 	case *pb.Meta_Boolean:
@@ -258,54 +369,108 @@ func metaFromProto(m *pb.Meta) *Meta {
 		value = v.Number
 	case *pb.Meta_Text:
 		value = v.Text
+	default:
+		return nil, invalid("value", "missing meta value")
 	}
-	return &Meta{
+	meta := &Meta{
 		Key:   m.GetKey(),
 		Value: value,
 	}
+	if _, isString := value.(string); isString {
+		if _, err := meta.Unescape(); err != nil {
+			return nil, invalid("text", "%s", err)
+		}
+	}
+	return meta, nil
 }
 
-func rangeFromProto(r *pb.Range) *Range {
+// rangeFromProto returns the Range for a protobuf, which can be nil.
+func rangeFromProto(r *pb.Range) (*Range, error) {
 	if r == nil {
-		return nil
+		return nil, nil
 	}
-	return &Range{
-		Start: expressionFromProto(r.GetStart()),
-		End:   expressionFromProto(r.GetEnd()),
+	start, err := requiredExpressionFromProto("start", r.GetStart())
+	if err != nil {
+		return nil, err
+	}
+	end, err := requiredExpressionFromProto("end", r.GetEnd())
+	if err != nil {
+		return nil, err
+	}
+	// Negative literals can't be used as bounds, and literal integers must be
+	// in increasing order, but the parser accepts them when enclosed in
+	// parentheses.
+	if isNegativeLiteral(start) {
+		start = &Group{start}
 	}
+	if isNegativeLiteral(end) {
+		end = &Group{end}
+	}
+	if s, ok := start.(*LiteralInteger); ok {
+		if e, ok := end.(*LiteralInteger); ok && s.Value >= e.Value {
+			start = &Group{start}
+		}
+	}
+	return &Range{
+		Start: start,
+		End:   end,
+	}, nil
 }
 
-func enumFromProto(e *pb.IntegerEnumeration) *Enum {
-	if e == nil {
-		return nil
+// isNegativeLiteral returns true if e is a negative literal integer, like -1.
+func isNegativeLiteral(e Expression) bool {
+	if m, ok := e.(*Minus); ok {
+		_, ok = m.Expression.(*LiteralInteger)
+		return ok
 	}
-	values := make([]Expression, len(e.GetValues()))
-	for i, v := range e.GetValues() {
-		values[i] = expressionFromProto(v)
+	return false
+}
+
+func enumFromProto(e *pb.IntegerEnumeration) (*Enum, error) {
+	values, err := expressionsFromProto(e.GetValues()...)
+	if err != nil {
+		return nil, inField("values", err)
 	}
 	return &Enum{
 		Values: values,
-	}
+	}, nil
 }
 
-func quantifierFromProto(expr *pb.ForExpression) Expression {
-	if expr == nil {
-		return nil
-	}
-	var q Expression
+func quantifierFromProto(expr *pb.ForExpression) (Expression, error) {
 	switch v := expr.GetFor().(type) {
 	case *pb.ForExpression_Keyword:
-		if v.Keyword == pb.ForKeyword_ALL {
-			q = KeywordAll
-		} else if v.Keyword == pb.ForKeyword_ANY {
-			q = KeywordAny
-		} else if v.Keyword == pb.ForKeyword_NONE {
-			q = KeywordNone
-		}
+		switch v.Keyword {
+		case pb.ForKeyword_ALL:
+			return KeywordAll, nil
+		case pb.ForKeyword_ANY:
+			return KeywordAny, nil
+		case pb.ForKeyword_NONE:
+			return KeywordNone, nil
+		}
+		return nil, invalid("keyword", "unknown keyword %d", v.Keyword)
 	case *pb.ForExpression_Expression:
-		q = expressionFromProto(v.Expression)
+		e, err := requiredExpressionFromProto("expression", v.Expression)
+		if err != nil {
+			return nil, err
+		}
+		// Quantifiers can't be literal strings, regexps, floats or negative
+		// integers, but those are accepted when enclosed in parentheses.
+		switch e.(type) {
+		case *LiteralString, *LiteralRegexp, *LiteralFloat:
+			return &Group{e}, nil
+		}
+		if isNegativeLiteral(e) {
+			return &Group{e}, nil
+		}
+		return e, nil
+	case *pb.ForExpression_Percentage:
+		e, err := requiredExpressionFromProto("expression", v.Percentage.GetExpression())
+		if err != nil {
+			return nil, inField("percentage", err)
+		}
+		return &Percentage{Expression: e}, nil
 	}
-	return q
+	return nil, invalid("", "missing quantifier")
 }
 
 func quantifierToProto(expr Expression) *pb.ForExpression {
@@ -326,7 +491,12 @@ func quantifierToProto(expr Expression) *pb.ForExpression {
 		} else if v == KeywordNone {
 			pbkw = pb.ForKeyword_NONE
 		} else {
-			panic(fmt.Sprintf("unexpected keyword in for: %s", v))
+			// Other keywords, like "filesize", are expressions.
+			return &pb.ForExpression{
+				For: &pb.ForExpression_Expression{
+					Expression: v.AsProto(),
+				},
+			}
 		}
 		quantifier = &pb.ForExpression{
 			For: &pb.ForExpression_Keyword{
@@ -343,29 +513,66 @@ func quantifierToProto(expr Expression) *pb.ForExpression {
 	return quantifier
 }
 
-func forInExpressionFromProto(expr *pb.ForInExpression) *ForIn {
+func forInExpressionFromProto(expr *pb.ForInExpression) (*ForIn, error) {
+	quantifier, err := quantifierFromProto(expr.GetForExpression())
+	if err != nil {
+		return nil, inField("for_expression", err)
+	}
+	if len(expr.GetIdentifiers()) == 0 {
+		return nil, invalid("identifiers", "missing loop variables")
+	}
+	for i, identifier := range expr.GetIdentifiers() {
+		if identifier == "" {
+			return nil, invalid(fmt.Sprintf("identifiers[%d]", i), "missing identifier")
+		}
+	}
 	var iterator Node
 	switch v := expr.GetIterator().GetIterator().(type) {
 	case *pb.Iterator_Identifier:
-		iterator = createIdentifierExpression(v.Identifier)
+		if iterator, err = createIdentifierExpression(v.Identifier); err != nil {
+			return nil, inField("iterator.identifier", err)
+		}
 	case *pb.Iterator_IntegerSet:
-		iterator = rangeFromProto(v.IntegerSet.GetRange())
-		// If not a range it must be an enumeration.
-		if iterator.(*Range) == nil {
-			iterator = enumFromProto(v.IntegerSet.GetIntegerEnumeration())
+		switch s := v.IntegerSet.GetSet().(type) {
+		case *pb.IntegerSet_Range:
+			if iterator, err = rangeFromProto(s.Range); err != nil {
+				return nil, inField("iterator.integer_set.range", err)
+			}
+		case *pb.IntegerSet_IntegerEnumeration:
+			if iterator, err = enumFromProto(s.IntegerEnumeration); err != nil {
+				return nil, inField("iterator.integer_set.integer_enumeration", err)
+			}
+		default:
+			return nil, invalid("iterator.integer_set", "missing integer set")
 		}
+	default:
+		return nil, invalid("iterator", "missing iterator")
+	}
+	condition, err := requiredExpressionFromProto("expression", expr.GetExpression())
+	if err != nil {
+		return nil, err
 	}
 	return &ForIn{
-		Quantifier: quantifierFromProto(expr.GetForExpression()),
+		Quantifier: quantifier,
 		Variables:  expr.GetIdentifiers(),
 		Iterator:   iterator,
-		Condition:  expressionFromProto(expr.GetExpression()),
-	}
+		Condition:  condition,
+	}, nil
 }
 
-func forOfExpressionFromProto(expr *pb.ForOfExpression) Expression {
-	if (expr.GetStringSet() == nil && expr.GetRuleEnumeration() == nil) || (expr.GetStringSet() != nil && expr.GetRuleEnumeration() != nil) {
-		panic("expecting one string set or rule set in \"forOf\"")
+func forOfExpressionFromProto(expr *pb.ForOfExpression) (Expression, error) {
+	sets := 0
+	for _, set := range []bool{expr.GetStringSet() != nil, expr.GetRuleEnumeration() != nil, expr.GetTextStrings() != nil} {
+		if set {
+			sets++
+		}
+	}
+	if sets != 1 {
+		return nil, invalid("", "expecting one string set, rule set or text string set")
+	}
+	quantifier, err := quantifierFromProto(expr.GetForExpression())
+	if err != nil {
+		return nil, inField("for_expression", err)
 	}
 	var strs Node
 	var rules Node
@@ -384,9 +591,11 @@ func forOfExpressionFromProto(expr *pb.ForOfExpression) Expression {
 			strs = enum
 		case *pb.StringSet_Keyword:
 			if v.Keyword != pb.StringSetKeyword_THEM {
-				panic(fmt.Sprintf(`unexpected keyword "%T"`, v))
+				return nil, invalid("string_set.keyword", "unknown keyword %d", v.Keyword)
 			}
 			strs = KeywordThem
+		default:
+			return nil, invalid("string_set", "missing string set")
 		}
 	}
 	if expr.GetRuleEnumeration() != nil {
@@ -395,6 +604,9 @@ func forOfExpressionFromProto(expr *pb.ForOfExpression) Expression {
 			Values: make([]Expression, len(items)),
 		}
 		for i, item := range items {
+			if item.GetRuleIdentifier() == "" {
+				return nil, invalid(fmt.Sprintf("rule_enumeration.items[%d].rule_identifier", i), "missing identifier")
+			}
 			enum.Values[i] = &Identifier{
 				Identifier: item.GetRuleIdentifier(),
 			}
@@ -407,81 +619,201 @@ func forOfExpressionFromProto(expr *pb.ForOfExpression) Expression {
 	// is nil. So, if condition is nil we return a Of expression instead of a
 	// ForOf expression
 	if condition == nil {
-		return &Of{
-			Quantifier: quantifierFromProto(expr.GetForExpression()),
-			Strings:    strs,
-			Rules:      rules,
-			In:         rangeFromProto(expr.GetRange()),
-			At:         expressionFromProto(expr.GetAt()),
+		in, err := rangeFromProto(expr.GetRange())
+		if err != nil {
+			return nil, inField("range", err)
 		}
+		at, err := expressionFromProto(expr.GetAt())
+		if err != nil {
+			return nil, inField("at", err)
+		}
+		return &Of{
+			Quantifier:  quantifier,
+			Strings:     strs,
+			Rules:       rules,
+			TextStrings: expr.GetTextStrings(),
+			In:          in,
+			At:          at,
+		}, nil
+	}
+	if rules != nil {
+		return nil, invalid("rule_enumeration", "rule sets can't be used in for loops")
+	}
+	if expr.GetTextStrings() != nil {
+		return nil, invalid("text_strings", "text string sets can't be used in for loops")
+	}
+	c, err := expressionFromProto(condition)
+	if err != nil {
+		return nil, inField("expression", err)
 	}
 	return &ForOf{
-		Quantifier: quantifierFromProto(expr.GetForExpression()),
+		Quantifier: quantifier,
 		Strings:    strs,
-		Condition:  expressionFromProto(expr.GetExpression()),
+		Condition:  c,
+	}, nil
+}
+
+func withExpressionFromProto(expr *pb.WithExpression) (*With, error) {
+	if len(expr.GetDeclarations()) == 0 {
+		return nil, invalid("declarations", "missing declarations")
+	}
+	declarations := make([]*WithDeclaration, len(expr.GetDeclarations()))
+	for i, d := range expr.GetDeclarations() {
+		field := fmt.Sprintf("declarations[%d]", i)
+		if d.GetIdentifier() == "" {
+			return nil, invalid(field+".identifier", "missing identifier")
+		}
+		e, err := requiredExpressionFromProto("expression", d.GetExpression())
+		if err != nil {
+			return nil, inField(field, err)
+		}
+		declarations[i] = &WithDeclaration{
+			Identifier: d.GetIdentifier(),
+			Expression: e,
+		}
+	}
+	condition, err := requiredExpressionFromProto("expression", expr.GetExpression())
+	if err != nil {
+		return nil, err
 	}
+	return &With{
+		Declarations: declarations,
+		Condition:    condition,
+	}, nil
 }
 
-func binaryExpressionFromProto(expr *pb.BinaryExpression) Expression {
-	switch op := pbToAst[expr.GetOperator()]; op {
+func binaryExpressionFromProto(expr *pb.BinaryExpression) (Expression, error) {
+	op, ok := pbToAst[expr.GetOperator()]
+	if !ok {
+		return nil, invalid("operator", "unknown operator %d", expr.GetOperator())
+	}
+	if expr.GetLeft() == nil {
+		return nil, invalid("left", "missing expression")
+	}
+	if expr.GetRight() == nil {
+		return nil, invalid("right", "missing expression")
+	}
+	switch op {
 	// The "at" and "in" operations are represented as a binary operation
 	// in the protobuf, but in the AST is represented as a field in a
 	// StringIdentifier expression.
 	case opAt:
-		return &StringIdentifier{
-			Identifier: strings.TrimPrefix(expr.GetLeft().GetStringIdentifier(), "$"),
-			At:         expressionFromProto(expr.GetRight()),
+		left, ok := expr.GetLeft().GetExpression().(*pb.Expression_StringIdentifier)
+		if !ok {
+			return nil, invalid("left", "expecting a string identifier")
 		}
+		at, err := expressionFromProto(expr.GetRight())
+		if err != nil {
+			return nil, inField("right", err)
+		}
+		return &StringIdentifier{
+			Identifier: strings.TrimPrefix(left.StringIdentifier, "$"),
+			At:         at,
+		}, nil
 	case opIn:
+		if expr.GetRight().GetRange() == nil {
+			return nil, invalid("right", "expecting a range")
+		}
+		in, err := rangeFromProto(expr.GetRight().GetRange())
+		if err != nil {
+			return nil, inField("right.range", err)
+		}
 		switch v := expr.GetLeft().GetExpression().(type) {
 		case *pb.Expression_StringIdentifier:
 			return &StringIdentifier{
-				Identifier: strings.TrimPrefix(expr.GetLeft().GetStringIdentifier(), "$"),
-				In:         rangeFromProto(expr.GetRight().GetRange()),
-			}
+				Identifier: strings.TrimPrefix(v.StringIdentifier, "$"),
+				In:         in,
+			}, nil
 		case *pb.Expression_StringCount:
 			return &StringCount{
-				Identifier: strings.TrimPrefix(expr.GetLeft().GetStringCount(), "#"),
-				In:         rangeFromProto(expr.GetRight().GetRange()),
-			}
+				Identifier: strings.TrimPrefix(v.StringCount, "#"),
+				In:         in,
+			}, nil
 		default:
-			panic(fmt.Sprintf(`unexpected binary expression "%v"`, v))
+			return nil, invalid("left", "expecting a string identifier or count")
 		}
 	default:
-		return createOperationExpression(op, expr.GetLeft(), expr.GetRight())
+		operation, err := createOperationExpression(op, expr.GetLeft(), expr.GetRight())
+		if err != nil {
+			// The error's field is "[0]" or "[1]", which is left or right.
+			e := *err.(*ValidationError)
+			if strings.HasPrefix(e.Field, "[0]") {
+				e.Field = "left" + strings.TrimPrefix(e.Field, "[0]")
+			} else {
+				e.Field = "right" + strings.TrimPrefix(e.Field, "[1]")
+			}
+			return nil, &e
+		}
+		return operation, nil
 	}
 }
 
-func expressionsFromProto(pbExpressions ...*pb.Expression) []Expression {
+func expressionsFromProto(pbExpressions ...*pb.Expression) ([]Expression, error) {
 	expressions := make([]Expression, len(pbExpressions))
 	for i, e := range pbExpressions {
-		expressions[i] = expressionFromProto(e)
+		var err error
+		if expressions[i], err = requiredExpressionFromProto(fmt.Sprintf("[%d]", i), e); err != nil {
+			return nil, err
+		}
+	}
+	return expressions, nil
+}
+
+// requiredExpressionFromProto is like expressionFromProto, but returns an
+// error for the given field if the expression is nil.
+func requiredExpressionFromProto(field string, e *pb.Expression) (Expression, error) {
+	if e == nil {
+		return nil, invalid(field, "missing expression")
 	}
-	return expressions
+	expr, err := expressionFromProto(e)
+	return expr, inField(field, err)
+}
+
+// integerFunctions contains the names of the built-in functions that read
+// integers from the scanned data.
+var integerFunctions = map[string]bool{
+	"int8": true, "int16": true, "int32": true,
+	"int8be": true, "int16be": true, "int32be": true,
+	"uint8": true, "uint16": true, "uint32": true,
+	"uint8be": true, "uint16be": true, "uint32be": true,
 }
 
-func expressionFromProto(e *pb.Expression) Expression {
+// expressionFromProto returns the Expression for a protobuf, which can be nil.
+func expressionFromProto(e *pb.Expression) (Expression, error) {
 	if e == nil {
-		return nil
+		return nil, nil
 	}
 	switch v := e.GetExpression().(type) {
 	case *pb.Expression_BoolValue:
 		if v.BoolValue {
-			return KeywordTrue
+			return KeywordTrue, nil
 		}
-		return KeywordFalse
+		return KeywordFalse, nil
 	case *pb.Expression_NumberValue:
-		return &LiteralInteger{
-			Value: v.NumberValue,
+		repr := e.GetNumberRepresentation()
+		switch repr.GetRadix() {
+		case 0, 8, 10, 16:
+		default:
+			return nil, invalid("number_representation.radix", "invalid radix %d", repr.GetRadix())
+		}
+		switch repr.GetMultiplier() {
+		case 0, 1024, 1048576:
+		default:
+			return nil, invalid("number_representation.multiplier", "invalid multiplier %d", repr.GetMultiplier())
 		}
+		return &LiteralInteger{
+			Value:      v.NumberValue,
+			Radix:      int(repr.GetRadix()),
+			Multiplier: repr.GetMultiplier(),
+		}, nil
 	case *pb.Expression_DoubleValue:
 		return &LiteralFloat{
 			Value: v.DoubleValue,
-		}
+		}, nil
 	case *pb.Expression_Text:
 		return &LiteralString{
 			Value: v.Text,
-		}
+		}, nil
 	case *pb.Expression_Regexp:
 		var mods RegexpModifiers
 		pbmods := v.Regexp.GetModifiers()
@@ -494,80 +826,127 @@ func expressionFromProto(e *pb.Expression) Expression {
 		return &LiteralRegexp{
 			Value:     v.Regexp.GetText(),
 			Modifiers: mods,
-		}
+		}, nil
 	case *pb.Expression_StringIdentifier:
 		return &StringIdentifier{
 			Identifier: strings.TrimPrefix(v.StringIdentifier, "$"),
-		}
+		}, nil
 	case *pb.Expression_StringCount:
 		return &StringCount{
 			Identifier: strings.TrimPrefix(v.StringCount, "#"),
-		}
+		}, nil
 	case *pb.Expression_StringLength:
+		index, err := expressionFromProto(v.StringLength.GetIndex())
+		if err != nil {
+			return nil, inField("string_length.index", err)
+		}
 		return &StringLength{
 			Identifier: strings.TrimPrefix(v.StringLength.GetStringIdentifier(), "!"),
-			Index:      expressionFromProto(v.StringLength.GetIndex()),
-		}
+			Index:      index,
+		}, nil
 	case *pb.Expression_StringOffset:
+		index, err := expressionFromProto(v.StringOffset.GetIndex())
+		if err != nil {
+			return nil, inField("string_offset.index", err)
+		}
 		return &StringOffset{
 			Identifier: strings.TrimPrefix(v.StringOffset.GetStringIdentifier(), "@"),
-			Index:      expressionFromProto(v.StringOffset.GetIndex()),
-		}
+			Index:      index,
+		}, nil
 	case *pb.Expression_Identifier:
-		return createIdentifierExpression(v.Identifier)
+		expr, err := createIdentifierExpression(v.Identifier)
+		return expr, inField("identifier", err)
+	case *pb.Expression_IntegerFunction:
+		function := v.IntegerFunction.GetFunction()
+		if !integerFunctions[function] {
+			return nil, invalid("integer_function.function", "unknown function %q", function)
+		}
+		arg, err := requiredExpressionFromProto("argument", v.IntegerFunction.GetArgument())
+		if err != nil {
+			return nil, inField("integer_function", err)
+		}
+		return &FunctionCall{
+			Callable:  &Identifier{Identifier: function},
+			Arguments: []Expression{arg},
+			Builtin:   true,
+		}, nil
 	case *pb.Expression_AndExpression:
-		return createOperationExpression(OpAnd, v.AndExpression.GetTerms()...)
+		if len(v.AndExpression.GetTerms()) < 2 {
+			return nil, invalid("and_expression.terms", "expecting two or more terms")
+		}
+		expr, err := createOperationExpression(OpAnd, v.AndExpression.GetTerms()...)
+		return expr, inField("and_expression.terms", err)
 	case *pb.Expression_OrExpression:
-		return createOperationExpression(OpOr, v.OrExpression.GetTerms()...)
+		if len(v.OrExpression.GetTerms()) < 2 {
+			return nil, invalid("or_expression.terms", "expecting two or more terms")
+		}
+		expr, err := createOperationExpression(OpOr, v.OrExpression.GetTerms()...)
+		return expr, inField("or_expression.terms", err)
 	case *pb.Expression_NotExpression:
-		operand := expressionFromProto(v.NotExpression)
+		operand, err := expressionFromProto(v.NotExpression)
+		if err != nil {
+			return nil, inField("not_expression", err)
+		}
 		// If the operand is an operation with lower precedence than "not",
 		// the operand must be enclosed in parentheses.
 		if expressionPrecedence(operand) < OpPrecedence[OpNot] {
 			operand = &Group{operand}
 		}
-		return &Not{operand}
+		return &Not{operand}, nil
 	case *pb.Expression_UnaryExpression:
-		operand := expressionFromProto(v.UnaryExpression.GetExpression())
-		switch op := v.UnaryExpression.Operator; *op {
+		operand, err := requiredExpressionFromProto("expression", v.UnaryExpression.GetExpression())
+		if err != nil {
+			return nil, inField("unary_expression", err)
+		}
+		switch op := v.UnaryExpression.GetOperator(); op {
 		case pb.UnaryExpression_UNARY_MINUS:
 			if expressionPrecedence(operand) < OpMaxPrecedence {
 				operand = &Group{operand}
 			}
-			return &Minus{operand}
+			return &Minus{operand}, nil
 		case pb.UnaryExpression_BITWISE_NOT:
 			if expressionPrecedence(operand) < OpMaxPrecedence {
 				operand = &Group{operand}
 			}
-			return &BitwiseNot{operand}
+			return &BitwiseNot{operand}, nil
 		case pb.UnaryExpression_DEFINED:
 			if expressionPrecedence(operand) < OpPrecedence[OpDefined] {
 				operand = &Group{operand}
 			}
-			return &Defined{operand}
+			return &Defined{operand}, nil
 		default:
-			panic(fmt.Sprintf(`unexpected unary operator "%v"`, op))
+			return nil, invalid("unary_expression.operator", "unknown operator %d", op)
 		}
 	case *pb.Expression_BinaryExpression:
-		return binaryExpressionFromProto(v.BinaryExpression)
+		expr, err := binaryExpressionFromProto(v.BinaryExpression)
+		return expr, inField("binary_expression", err)
 	case *pb.Expression_ForInExpression:
-		return forInExpressionFromProto(v.ForInExpression)
+		expr, err := forInExpressionFromProto(v.ForInExpression)
+		return expr, inField("for_in_expression", err)
 	case *pb.Expression_ForOfExpression:
-		return forOfExpressionFromProto(v.ForOfExpression)
+		expr, err := forOfExpressionFromProto(v.ForOfExpression)
+		return expr, inField("for_of_expression", err)
+	case *pb.Expression_WithExpression:
+		expr, err := withExpressionFromProto(v.WithExpression)
+		return expr, inField("with_expression", err)
 	case *pb.Expression_Keyword:
 		switch keyword := v.Keyword; keyword {
 		case pb.Keyword_ENTRYPOINT:
-			return KeywordEntrypoint
+			return KeywordEntrypoint, nil
 		case pb.Keyword_FILESIZE:
-			return KeywordFilesize
+			return KeywordFilesize, nil
 		default:
-			panic(fmt.Sprintf(`unknown keyword "%T"`, keyword))
+			return nil, invalid("keyword", "unknown keyword %d", keyword)
 		}
 	case *pb.Expression_PercentageExpression:
-		return &Percentage{
-			Expression: expressionFromProto(v.PercentageExpression.Expression),
+		expr, err := requiredExpressionFromProto("expression", v.PercentageExpression.GetExpression())
+		if err != nil {
+			return nil, inField("percentage_expression", err)
 		}
+		return &Percentage{
+			Expression: expr,
+		}, nil
 	default:
-		panic(fmt.Sprintf(`unexpected node "%T"`, v))
+		return nil, invalid("", "missing expression")
 	}
 }
@@ -43,6 +43,16 @@ func Escape(s string) string {
 	return string(ascii)
 }
 
+// unescape is the inverse of Escape, it replaces escape sequences by the
+// characters they represent.
+func unescape(s string) (string, error) {
+	unescaped, err := strconv.Unquote(`"` + s + `"`)
+	if err != nil {
+		return "", fmt.Errorf("invalid escaped string %q", s)
+	}
+	return unescaped, nil
+}
+
 // String is the interface implemented by the different types of strings that
 // are supported by YARA (i.e: text strings, hex strings and regexps).
 type String interface {
@@ -57,9 +67,9 @@ type String interface {
 // RegexpString.
 type BaseString struct {
 	// Identifier for the string, without the $ prefix.
-	Identifier string
+	Identifier string `json:"identifier"`
 	// Line number where the string was defined.
-	LineNo int
+	LineNo int `json:"line_no,omitempty"`
 }
 
 // TextString describes a YARA text string.
@@ -67,18 +77,18 @@ type TextString struct {
 	BaseString
 	// Value contains the string exactly as it appears in the YARA rule. Escape
 	// sequences remain escaped. See the UnescapedValue function.
-	Value          string
-	ASCII          bool
-	Wide           bool
-	Nocase         bool
-	Fullword       bool
-	Private        bool
-	Base64         bool
-	Base64Wide     bool
-	Base64Alphabet string
-	Xor            bool
-	XorMin         int32
-	XorMax         int32
+	Value          string `json:"value"`
+	ASCII          bool   `json:"ascii,omitempty"`
+	Wide           bool   `json:"wide,omitempty"`
+	Nocase         bool   `json:"nocase,omitempty"`
+	Fullword       bool   `json:"fullword,omitempty"`
+	Private        bool   `json:"private,omitempty"`
+	Base64         bool   `json:"base64,omitempty"`
+	Base64Wide     bool   `json:"base64wide,omitempty"`
+	Base64Alphabet string `json:"base64_alphabet,omitempty"`
+	Xor            bool   `json:"xor,omitempty"`
+	XorMin         int32  `json:"xor_min,omitempty"`
+	XorMax         int32  `json:"xor_max,omitempty"`
 }
 
 // RegexpString describes a YARA regexp.
@@ -86,12 +96,12 @@ type RegexpString struct {
 	BaseString
 	// Value contains the string exactly as it appears in the YARA rule. Escape
 	// sequences remain escaped. See the UnescapeValue function.
-	Regexp   *LiteralRegexp
-	ASCII    bool
-	Wide     bool
-	Nocase   bool
-	Fullword bool
-	Private  bool
+	Regexp   *LiteralRegexp `json:"regexp"`
+	ASCII    bool           `json:"ascii,omitempty"`
+	Wide     bool           `json:"wide,omitempty"`
+	Nocase   bool           `json:"nocase,omitempty"`
+	Fullword bool           `json:"fullword,omitempty"`
+	Private  bool           `json:"private,omitempty"`
 }
 
 // HexString describes a YARA hex string. Hex strings have an identifier and
@@ -106,8 +116,8 @@ type RegexpString struct {
 //
 type HexString struct {
 	BaseString
-	Tokens  HexTokens
-	Private bool
+	Tokens  HexTokens `json:"tokens"`
+	Private bool      `json:"private,omitempty"`
 }
 
 // HexToken is the interface implemented by all types of token
@@ -119,11 +129,11 @@ type HexToken interface {
 type HexTokens []HexToken
 
 // HexJump is an HexToken that represents a jump in the hex string, like for
-// example the [10-20] jump in {01 02 [10-20] 03 04}. If End is 0, it means
-// infinite, the jump [20-] has Start=20 and End=0.
+// example the [10-20] jump in {01 02 [10-20] 03 04}. If End is nil the jump
+// is unbounded, like [20-].
 type HexJump struct {
-	Start int
-	End   int
+	Start int  `json:"start"`
+	End   *int `json:"end,omitempty"`
 }
 
 // HexBytes is an HexToken that represents a byte sequence. The bytes are
@@ -136,50 +146,72 @@ type HexJump struct {
 // The Nots array is an array of boolean values that indicate which of the
 // bytes are prefixed with a ~ indicating they should NOT be the given value.
 type HexBytes struct {
-	Bytes []byte
-	Masks []byte
-	Nots  []bool
+	Bytes []byte `json:"bytes"`
+	Masks []byte `json:"masks"`
+	Nots  []bool `json:"nots"`
 }
 
 // HexOr is an HexToken that represents an alternative in the hex string, like
 // the (03 04 | 05 06) alternative in { 01 02 (03 04 | 05 06) 07 08 }. Each
 // item in Alternatives corresponds to an alternative.
 type HexOr struct {
-	Alternatives HexTokens
+	Alternatives HexTokens `json:"alternatives"`
 }
 
 // UnescapedValue returns the string's Value with any escape sequence replaced
-// by the actual character that it represents.
+// by the actual character that it represents. It panics if Value contains
+// invalid escape sequences, see Unescape.
 func (t *TextString) UnescapedValue() string {
-	unescaped, err := strconv.Unquote(fmt.Sprintf(`"%s"`, t.Value))
+	unescaped, err := t.Unescape()
 	if err != nil {
 		panic(err)
 	}
 	return unescaped
 }
 
+// Unescape is like UnescapedValue, but returns an error if Value contains
+// invalid escape sequences.
+func (t *TextString) Unescape() (string, error) {
+	return unescape(t.Value)
+}
+
 // Children returns the Node's children.
 func (h *HexJump) Children() []Node {
 	return []Node{}
 }
 
+func (h *HexJump) appendChildren(dst []Node) []Node {
+	return dst
+}
+
 // Children returns the Node's children.
 func (h *HexBytes) Children() []Node {
 	return []Node{}
 }
 
+func (h *HexBytes) appendChildren(dst []Node) []Node {
+	return dst
+}
+
 // Children returns the Node's children.
 func (h HexTokens) Children() []Node {
 	return []Node{}
 }
 
+func (h HexTokens) appendChildren(dst []Node) []Node {
+	return dst
+}
+
 // Children returns the Node's children.
 func (h *HexOr) Children() []Node {
-	nodes := make([]Node, len(h.Alternatives))
-	for i, a := range h.Alternatives {
-		nodes[i] = a
+	return h.appendChildren(make([]Node, 0, len(h.Alternatives)))
+}
+
+func (h *HexOr) appendChildren(dst []Node) []Node {
+	for _, a := range h.Alternatives {
+		dst = append(dst, a)
 	}
-	return nodes
+	return dst
 }
 
 func (s *BaseString) GetIdentifier() string {
@@ -210,12 +242,12 @@ func (h *HexString) String() string {
 
 // WriteSource writes the node's source into the writer w.
 func (t *TextString) WriteSource(w io.Writer) error {
-	_, err := io.WriteString(w, fmt.Sprintf("$%s", t.Identifier))
+	_, err := io.WriteString(w, "$"+t.Identifier)
 	if err == nil {
 		_, err = io.WriteString(w, " = ")
 	}
 	if err == nil {
-		_, err = io.WriteString(w, fmt.Sprintf(`"%s"`, t.Value))
+		_, err = io.WriteString(w, `"`+t.Value+`"`)
 	}
 	if err == nil && t.ASCII {
 		_, err = io.WriteString(w, " ascii")
@@ -234,21 +266,21 @@ func (t *TextString) WriteSource(w io.Writer) error {
 	}
 	if err == nil && t.Base64 {
 		if _, err = io.WriteString(w, " base64"); err == nil && t.Base64Alphabet != "" {
-			_, err = io.WriteString(w, fmt.Sprintf("(\"%s\")", t.Base64Alphabet))
+			_, err = io.WriteString(w, `("`+t.Base64Alphabet+`")`)
 		}
 	}
 	if err == nil && t.Base64Wide {
 		if _, err = io.WriteString(w, " base64wide"); err == nil && t.Base64Alphabet != "" {
-			_, err = io.WriteString(w, fmt.Sprintf("(\"%s\")", t.Base64Alphabet))
+			_, err = io.WriteString(w, `("`+t.Base64Alphabet+`")`)
 		}
 	}
 	if err == nil && t.Xor {
 		if t.XorMin == 0 && t.XorMax == 255 {
 			_, err = io.WriteString(w, " xor")
 		} else if t.XorMin == t.XorMax {
-			_, err = io.WriteString(w, fmt.Sprintf(" xor(%d)", t.XorMin))
+			_, err = io.WriteString(w, " xor("+strconv.Itoa(int(t.XorMin))+")")
 		} else {
-			_, err = io.WriteString(w, fmt.Sprintf(" xor(%d-%d)", t.XorMin, t.XorMax))
+			_, err = io.WriteString(w, " xor("+strconv.Itoa(int(t.XorMin))+"-"+strconv.Itoa(int(t.XorMax))+")")
 		}
 	}
 	return err
@@ -256,7 +288,7 @@ func (t *TextString) WriteSource(w io.Writer) error {
 
 // WriteSource writes the node's source into the writer w.
 func (r *RegexpString) WriteSource(w io.Writer) (err error) {
-	if _, err = io.WriteString(w, fmt.Sprintf("$%s", r.Identifier)); err != nil {
+	if _, err = io.WriteString(w, "$"+r.Identifier); err != nil {
 		return err
 	}
 	if _, err = io.WriteString(w, " = "); err != nil {
@@ -293,7 +325,7 @@ func (r *RegexpString) WriteSource(w io.Writer) (err error) {
 
 // WriteSource writes the node's source into the writer w.
 func (h *HexString) WriteSource(w io.Writer) (err error) {
-	if _, err = io.WriteString(w, fmt.Sprintf("$%s", h.Identifier)); err != nil {
+	if _, err = io.WriteString(w, "$"+h.Identifier); err != nil {
 		return err
 	}
 	if _, err = io.WriteString(w, " = { "); err != nil {
@@ -311,30 +343,36 @@ func (h *HexString) WriteSource(w io.Writer) (err error) {
 	return err
 }
 
+// Digits used for writing hex bytes.
+const hexDigits = "0123456789ABCDEF"
+
 // WriteSource writes the node's source into the writer w.
 func (h *HexBytes) WriteSource(w io.Writer) error {
+	if len(h.Masks) != len(h.Bytes) || len(h.Nots) != len(h.Bytes) {
+		return fmt.Errorf("expecting %d masks and nots, got %d and %d", len(h.Bytes), len(h.Masks), len(h.Nots))
+	}
+	// The source of all the bytes is written at once.
+	s := make([]byte, 0, 4*len(h.Bytes))
 	for i, b := range h.Bytes {
-		var s string = ""
 		if h.Nots[i] {
-			s += "~"
+			s = append(s, '~')
 		}
 		switch mask := h.Masks[i]; mask {
 		case 0x00:
-			s += "?? "
+			s = append(s, '?', '?', ' ')
 		case 0x0F:
-			s += "?" + fmt.Sprintf("%02X ", b)[1:]
+			s = append(s, '?', hexDigits[b&0x0F], ' ')
 		case 0xF0:
-			s += fmt.Sprintf("%02X", b)[:1] + "? "
+			s = append(s, hexDigits[b>>4], '?', ' ')
 		case 0xFF:
-			s += fmt.Sprintf("%02X ", b)
+			s = append(s, hexDigits[b>>4], hexDigits[b&0x0F], ' ')
 		default:
-			panic(fmt.Errorf(`unexpected byte mask: "%0X"`, mask))
-		}
-		if _, err := io.WriteString(w, s); err != nil {
-			return err
+			w.Write(s)
+			return fmt.Errorf(`unexpected byte mask: "%0X"`, mask)
 		}
 	}
-	return nil
+	_, err := w.Write(s)
+	return err
 }
 
 // WriteSource writes the node's source into the writer w.
@@ -349,16 +387,15 @@ func (h HexTokens) WriteSource(w io.Writer) error {
 
 // WriteSource writes the node's source into the writer w.
 func (h *HexJump) WriteSource(w io.Writer) (err error) {
-	if h.Start == 0 && h.End == 0 {
-		_, err = fmt.Fprintf(w, "[-] ")
-	} else if h.Start == h.End {
-		_, err = fmt.Fprintf(w, "[%d] ", h.Start)
-	} else if h.Start == 0 {
-		_, err = fmt.Fprintf(w, "[0-%d] ", h.End)
-	} else if h.End == 0 {
-		_, err = fmt.Fprintf(w, "[%d-] ", h.Start)
+	if h.End == nil && h.Start == 0 {
+		_, err = io.WriteString(w, "[-] ")
+	} else if h.End == nil {
+		_, err = io.WriteString(w, "["+strconv.Itoa(h.Start)+"-] ")
+	} else if h.Start == *h.End && h.Start > 0 {
+		// [0] is not a valid jump, zero-length jumps are written as [0-0].
+		_, err = io.WriteString(w, "["+strconv.Itoa(h.Start)+"] ")
 	} else {
-		_, err = fmt.Fprintf(w, "[%d-%d] ", h.Start, h.End)
+		_, err = io.WriteString(w, "["+strconv.Itoa(h.Start)+"-"+strconv.Itoa(*h.End)+"] ")
 	}
 	return err
 }
@@ -398,7 +435,7 @@ func (t *TextString) AsProto() *pb.String {
 		Base64Alphabet: proto.String(t.Base64Alphabet),
 	}
 	return &pb.String{
-		Id: proto.String(fmt.Sprintf("$%s", t.Identifier)),
+		Id: proto.String("$" + t.Identifier),
 		Value: &pb.String_Text{
 			Text: &pb.TextString{
 				Text:      proto.String(t.UnescapedValue()),
@@ -418,7 +455,7 @@ func (r *RegexpString) AsProto() *pb.String {
 	m.Nocase = proto.Bool(r.Nocase)
 	m.Private = proto.Bool(r.Private)
 	return &pb.String{
-		Id: proto.String(fmt.Sprintf("$%s", r.Identifier)),
+		Id: proto.String("$" + r.Identifier),
 		Value: &pb.String_Regexp{
 			Regexp: regexp,
 		},
@@ -428,7 +465,7 @@ func (r *RegexpString) AsProto() *pb.String {
 // AsProto returns the string serialized as pb.String.
 func (h *HexString) AsProto() *pb.String {
 	return &pb.String{
-		Id: proto.String(fmt.Sprintf("$%s", h.Identifier)),
+		Id: proto.String("$" + h.Identifier),
 		Value: &pb.String_Hex{
 			Hex: h.Tokens.AsProto(),
 		},
@@ -448,11 +485,11 @@ func (h *HexBytes) AsProto() *pb.BytesSequence {
 func (h *HexJump) AsProto() *pb.Jump {
 	var start *int64
 	var end *int64
-	if h.Start > 0 {
+	if h.Start > 0 || h.End != nil {
 		start = proto.Int64(int64(h.Start))
 	}
-	if h.End > 0 {
-		end = proto.Int64(int64(h.End))
+	if h.End != nil {
+		end = proto.Int64(int64(*h.End))
 	}
 	return &pb.Jump{
 		Start: start,
@@ -32,15 +32,53 @@ func preOrder(v Visitor, n Node) {
 	}
 }
 
+// childAppender is implemented by the nodes in this package. It's like
+// Children, but appends the children to dst instead of returning a new slice.
+type childAppender interface {
+	appendChildren(dst []Node) []Node
+}
+
+// appendChildren appends the children of n to dst.
+func appendChildren(dst []Node, n Node) []Node {
+	if a, ok := n.(childAppender); ok {
+		return a.appendChildren(dst)
+	}
+	// Nodes defined outside this package.
+	return append(dst, n.Children()...)
+}
+
 // DepthFirstSearch performs a depth-first traversal of the given node's syntax
 // tree. It receives a Visitor that must implement PreOrderVisitor,
-// PostOrderVisitor or both.
+// PostOrderVisitor or both. The traversal is not recursive, so it can be used
+// with arbitrarily deep trees.
 func DepthFirstSearch(node Node, v Visitor) {
+	// The children of all the nodes in the stack are stored in the same
+	// slice, which avoids allocating a new slice for each node.
+	type frame struct {
+		node Node
+		// The node's children are children[start:end], and the next one to
+		// be visited is children[next].
+		start, next, end int
+	}
+	var stackBuf [16]frame
 	preOrder(v, node)
-
-	for _, n := range node.Children() {
-		DepthFirstSearch(n, v)
+	children := appendChildren(make([]Node, 0, 32), node)
+	stack := append(stackBuf[:0], frame{node: node, end: len(children)})
+	for len(stack) > 0 {
+		top := &stack[len(stack)-1]
+		if top.next < top.end {
+			child := children[top.next]
+			top.next++
+			preOrder(v, child)
+			start := len(children)
+			children = appendChildren(children, child)
+			stack = append(stack, frame{node: child, start: start, next: start, end: len(children)})
+		} else {
+			postOrder(v, top.node)
+			// The children of the node are the last ones in the slice, as
+			// the children of its descendants were already removed.
+			children = children[:top.start]
+			stack = stack[:len(stack)-1]
+		}
 	}
-
-	postOrder(v, node)
 }
@@ -0,0 +1,377 @@
+package ast
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Validate checks that the ruleset can be written as source code and
+// serialized as a protobuf. Rulesets produced by the parser are always valid,
+// but rulesets built by other means, like decoding them from JSON, may not be.
+// If the ruleset is not valid it returns a *ValidationError indicating the
+// invalid field and the rule that contains it. Fields are named as in the
+// JSON encoding of the AST.
+func (r *RuleSet) Validate() error {
+	for i, rule := range r.Rules {
+		if rule == nil {
+			return invalid(fmt.Sprintf("rules[%d]", i), "missing rule")
+		}
+		if err := rule.Validate(); err != nil {
+			if e := err.(*ValidationError); e.Rule == "" {
+				return inField(fmt.Sprintf("rules[%d]", i), err)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate checks that the rule can be written as source code and serialized
+// as a protobuf. If not, it returns a *ValidationError indicating the invalid
+// field.
+func (r *Rule) Validate() error {
+	if r.Identifier == "" {
+		return invalid("identifier", "missing rule identifier")
+	}
+	for i, m := range r.Meta {
+		if err := inField(fmt.Sprintf("meta[%d]", i), validateMeta(m)); err != nil {
+			return inRule(r, err)
+		}
+	}
+	for i, s := range r.Strings {
+		if err := inField(fmt.Sprintf("strings[%d]", i), validateString(s)); err != nil {
+			return inRule(r, err)
+		}
+	}
+	return r.validateCondition()
+}
+
+// validateCondition is like Validate, but checks only the rule's condition,
+// which is the only part of the rule that WriteSource can't always write.
+func (r *Rule) validateCondition() error {
+	if r.Condition == nil {
+		return inRule(r, invalid("condition", "missing condition"))
+	}
+	return inRule(r, inField("condition", validateExpression(r.Condition)))
+}
+
+// inRule sets the rule in err, which must be nil or a *ValidationError.
+func inRule(r *Rule, err error) error {
+	if err != nil {
+		err.(*ValidationError).Rule = r.Identifier
+	}
+	return err
+}
+
+func validateMeta(m *Meta) error {
+	if m == nil {
+		return invalid("", "missing meta")
+	}
+	switch v := m.Value.(type) {
+	case int64, bool:
+	case string:
+		if _, err := unescape(v); err != nil {
+			return invalid("value", "%v", err)
+		}
+	default:
+		return invalid("value", "unexpected meta type %T", v)
+	}
+	return nil
+}
+
+func validateString(s String) error {
+	switch v := s.(type) {
+	case *TextString:
+		if _, err := v.Unescape(); err != nil {
+			return invalid("value", "%v", err)
+		}
+	case *RegexpString:
+		if v.Regexp == nil {
+			return invalid("regexp", "missing regexp")
+		}
+	case *HexString:
+		return inField("tokens", validateHexTokens(v.Tokens))
+	case nil:
+		return invalid("", "missing string")
+	default:
+		return invalid("", "unexpected string type %T", v)
+	}
+	return nil
+}
+
+func validateHexTokens(tokens HexTokens) error {
+	for i, t := range tokens {
+		field := fmt.Sprintf("[%d]", i)
+		switch v := t.(type) {
+		case *HexBytes:
+			if len(v.Masks) != len(v.Bytes) || len(v.Nots) != len(v.Bytes) {
+				return invalid(field, "expecting %d masks and nots, got %d and %d",
+					len(v.Bytes), len(v.Masks), len(v.Nots))
+			}
+			for j, mask := range v.Masks {
+				switch mask {
+				case 0x00, 0x0F, 0xF0, 0xFF:
+				default:
+					return invalid(fmt.Sprintf("%s.masks[%d]", field, j), "invalid mask %02X", mask)
+				}
+			}
+		case *HexJump:
+			if v.Start < 0 || (v.End != nil && v.Start > *v.End) {
+				var jump strings.Builder
+				v.WriteSource(&jump)
+				return invalid(field, "invalid jump %s", strings.TrimSpace(jump.String()))
+			}
+		case *HexOr:
+			for j, a := range v.Alternatives {
+				alternative := fmt.Sprintf("%s.alternatives[%d]", field, j)
+				tokens, ok := a.(HexTokens)
+				if !ok {
+					return invalid(alternative, "expecting hex tokens, got %T", a)
+				}
+				if err := inField(alternative, validateHexTokens(tokens)); err != nil {
+					return err
+				}
+			}
+		default:
+			return invalid(field, "unexpected hex token type %T", t)
+		}
+	}
+	return nil
+}
+
+// validateExpression checks that e, and all the expressions it contains, can
+// be written as source code and serialized as a protobuf.
+func validateExpression(e Expression) error {
+	switch v := e.(type) {
+	case nil:
+		return invalid("", "missing expression")
+	case Keyword:
+		switch v {
+		case KeywordTrue, KeywordFalse, KeywordEntrypoint, KeywordFilesize:
+			return nil
+		}
+		return invalid("", "unexpected keyword %q", v)
+	case *LiteralInteger, *LiteralFloat, *LiteralString, *LiteralRegexp, *Identifier:
+		return nil
+	case *Group:
+		return inField("expression", validateExpression(v.Expression))
+	case *Minus:
+		return inField("expression", validateExpression(v.Expression))
+	case *Not:
+		return inField("expression", validateExpression(v.Expression))
+	case *Defined:
+		return inField("expression", validateExpression(v.Expression))
+	case *BitwiseNot:
+		return inField("expression", validateExpression(v.Expression))
+	case *Percentage:
+		return inField("expression", validateExpression(v.Expression))
+	case *StringIdentifier:
+		if v.At != nil {
+			if err := inField("at", validateExpression(v.At)); err != nil {
+				return err
+			}
+		}
+		if v.In != nil {
+			return inField("in", validateRange(v.In))
+		}
+		return nil
+	case *StringCount:
+		if v.In != nil {
+			return inField("in", validateRange(v.In))
+		}
+		return nil
+	case *StringOffset:
+		if v.Index != nil {
+			return inField("index", validateExpression(v.Index))
+		}
+		return nil
+	case *StringLength:
+		if v.Index != nil {
+			return inField("index", validateExpression(v.Index))
+		}
+		return nil
+	case *FunctionCall:
+		if err := validateIdentifier("callable", v.Callable); err != nil {
+			return err
+		}
+		return validateExpressions("arguments", v.Arguments)
+	case *MemberAccess:
+		return validateIdentifier("container", v.Container)
+	case *Subscripting:
+		if err := validateIdentifier("array", v.Array); err != nil {
+			return err
+		}
+		return inField("index", validateExpression(v.Index))
+	case *ForIn:
+		if err := validateQuantifier(v.Quantifier); err != nil {
+			return err
+		}
+		if len(v.Variables) == 0 {
+			return invalid("variables", "missing loop variables")
+		}
+		switch it := v.Iterator.(type) {
+		case *Range:
+			if err := inField("iterator", validateRange(it)); err != nil {
+				return err
+			}
+		case *Enum:
+			if err := validateExpressions("iterator.values", it.Values); err != nil {
+				return err
+			}
+		case Expression:
+			if err := validateIdentifier("iterator", it); err != nil {
+				return err
+			}
+		case nil:
+			return invalid("iterator", "missing iterator")
+		default:
+			return invalid("iterator", "unexpected iterator type %T", it)
+		}
+		return inField("condition", validateExpression(v.Condition))
+	case *ForOf:
+		if err := validateQuantifier(v.Quantifier); err != nil {
+			return err
+		}
+		if err := inField("strings", validateStringSet(v.Strings)); err != nil {
+			return err
+		}
+		return inField("condition", validateExpression(v.Condition))
+	case *With:
+		if len(v.Declarations) == 0 {
+			return invalid("declarations", "missing declarations")
+		}
+		for i, d := range v.Declarations {
+			field := fmt.Sprintf("declarations[%d]", i)
+			if d == nil {
+				return invalid(field, "missing declaration")
+			}
+			if d.Identifier == "" {
+				return invalid(field+".identifier", "missing identifier")
+			}
+			if err := inField(field+".expression", validateExpression(d.Expression)); err != nil {
+				return err
+			}
+		}
+		return inField("condition", validateExpression(v.Condition))
+	case *Of:
+		if err := validateQuantifier(v.Quantifier); err != nil {
+			return err
+		}
+		sets := 0
+		for _, set := range []bool{v.Strings != nil, v.Rules != nil, v.TextStrings != nil} {
+			if set {
+				sets++
+			}
+		}
+		if sets != 1 {
+			return invalid("", "expecting one string set, rule set or text string set")
+		}
+		if v.Strings != nil {
+			if err := inField("strings", validateStringSet(v.Strings)); err != nil {
+				return err
+			}
+		}
+		if v.Rules != nil {
+			if err := inField("rules", validateRuleSet(v.Rules)); err != nil {
+				return err
+			}
+		}
+		if v.TextStrings != nil && len(v.TextStrings) == 0 {
+			return invalid("text_strings", "missing text strings")
+		}
+		if v.In != nil {
+			if err := inField("in", validateRange(v.In)); err != nil {
+				return err
+			}
+		}
+		if v.At != nil {
+			return inField("at", validateExpression(v.At))
+		}
+		return nil
+	case *Operation:
+		switch op := v.Operator; op {
+		case OpAnd, OpOr:
+		default:
+			if _, ok := astToPb[op]; !ok {
+				return invalid("operator", "unexpected operator %q", op)
+			}
+		}
+		if len(v.Operands) < 2 {
+			return invalid("operands", "expecting two or more operands")
+		}
+		return validateExpressions("operands", v.Operands)
+	}
+	return invalid("", "unexpected expression type %T", e)
+}
+
+func validateExpressions(field string, exprs []Expression) error {
+	for i, e := range exprs {
+		if err := inField(fmt.Sprintf("%s[%d]", field, i), validateExpression(e)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateRange(r *Range) error {
+	if err := inField("start", validateExpression(r.Start)); err != nil {
+		return err
+	}
+	return inField("end", validateExpression(r.End))
+}
+
+// validateIdentifier checks that e is an identifier, possibly followed by
+// member accesses, subscripts and function calls, like "pe.sections[0].name".
+// Those are the only expressions that can be used as the container of a
+// member access, the array in a subscripting, or the callable in a function
+// call.
+func validateIdentifier(field string, e Expression) error {
+	switch e.(type) {
+	case *Identifier, *MemberAccess, *Subscripting, *FunctionCall:
+		return inField(field, validateExpression(e))
+	case nil:
+		return invalid(field, "missing identifier")
+	}
+	return invalid(field, "expecting an identifier, got %T", e)
+}
+
+func validateQuantifier(q Expression) error {
+	switch q {
+	case KeywordAll, KeywordAny, KeywordNone:
+		return nil
+	}
+	return inField("quantifier", validateExpression(q))
+}
+
+func validateStringSet(n Node) error {
+	switch v := n.(type) {
+	case Keyword:
+		if v != KeywordThem {
+			return invalid("", "unexpected keyword %q", v)
+		}
+	case *Enum:
+		for i, item := range v.Values {
+			if _, ok := item.(*StringIdentifier); !ok {
+				return invalid(fmt.Sprintf("values[%d]", i), "expecting a string identifier, got %T", item)
+			}
+		}
+	case nil:
+		return invalid("", "missing string set")
+	default:
+		return invalid("", "unexpected string set type %T", v)
+	}
+	return nil
+}
+
+func validateRuleSet(n Node) error {
+	enum, ok := n.(*Enum)
+	if !ok {
+		return invalid("", "unexpected rule set type %T", n)
+	}
+	for i, item := range enum.Values {
+		if _, ok := item.(*Identifier); !ok {
+			return invalid(fmt.Sprintf("values[%d]", i), "expecting a rule identifier, got %T", item)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,311 @@
+/*
+Package cache stores parsed rulesets on disk, so that the same rules don't need
+to be parsed again every time they are loaded:
+
+	c, err := cache.New("/var/cache/rules")
+	ruleset, err := c.LoadOrParse("rules/index.yar")
+
+Rulesets are keyed by the SHA-256 of their source code and the parser's
+version, see Key. Rulesets loaded with LoadOrParse are also keyed by the
+cache's limits, if any, so that rulesets parsed with other limits are never
+returned. They are stored in a versioned binary format, which is the
+gob encoding of the ruleset preceded by a header with the SHA-256 of the
+encoded ruleset, which detects corrupted entries. Rulesets loaded from the
+cache are identical to the parsed ones, except that empty slices in them may
+be nil.
+
+Rulesets loaded with LoadOrParse are invalidated when any of the files they
+include, directly or indirectly, changes. The included files are loaded with
+LoadOrParse too, so they are cached as well.
+
+A Cache is safe for concurrent use by multiple goroutines and processes, as
+entries are written to a temporary file which is then renamed.
+*/
+package cache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/VirusTotal/gyp"
+	"github.com/VirusTotal/gyp/ast"
+	"github.com/VirusTotal/gyp/parser"
+)
+
+// Version is the version of the format in which entries are stored. Entries
+// stored with other versions are ignored.
+const Version = 1
+
+// Bytes at the beginning of every entry.
+const magic = "GYPCACHE"
+
+// Size of the header that precedes the encoded ruleset in entries: the magic
+// bytes, the version and the SHA-256 of the encoded ruleset.
+const headerSize = len(magic) + 4 + sha256.Size
+
+// ErrNotFound is returned by Get when there's no entry for the source code, or
+// the entry was invalidated.
+var ErrNotFound = errors.New("cache: entry not found")
+
+// CorruptedError is returned by Get when an entry is corrupted. It matches
+// ErrCorrupted with errors.Is.
+type CorruptedError struct {
+	// Path of the entry.
+	Path   string
+	Reason string
+}
+
+// ErrCorrupted can be used as a target for errors.Is for checking if an error
+// is a CorruptedError.
+var ErrCorrupted = errors.New("cache: corrupted entry")
+
+func (e *CorruptedError) Error() string {
+	return fmt.Sprintf("cache: corrupted entry %s: %s", e.Path, e.Reason)
+}
+
+// Is returns true if target is ErrCorrupted.
+func (e *CorruptedError) Is(target error) bool {
+	return target == ErrCorrupted
+}
+
+// Cache stores rulesets in a directory.
+type Cache struct {
+	dir string
+	// Limits enforced when parsing files in LoadOrParse. Changing them
+	// doesn't invalidate the entries stored with other limits, but they are
+	// not used anymore.
+	Limits parser.Limits
+}
+
+// entry is what is stored in the cache for each ruleset.
+type entry struct {
+	RuleSet *ast.RuleSet
+	// Files included by the ruleset, directly or indirectly.
+	Dependencies []dependency
+}
+
+// dependency is a file included by a ruleset.
+type dependency struct {
+	// Absolute path of the file.
+	Path string
+	// SHA-256 of the file's content, or zero if the file doesn't exist.
+	Sum [sha256.Size]byte
+}
+
+// New returns a cache that stores the rulesets in dir, which is created if it
+// doesn't exist.
+func New(dir string) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &Cache{dir: dir}, nil
+}
+
+// Key returns the key for the ruleset parsed from the given source code, which
+// is the SHA-256 of the parser's version and the source code, in hex.
+func Key(source []byte) string {
+	return keyWithLimits(source, parser.Limits{})
+}
+
+// keyWithLimits is like Key, but includes in the key the limits enforced
+// while parsing the source code, unless there are none.
+func keyWithLimits(source []byte, limits parser.Limits) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "gyp %d\n", parser.Version)
+	if limits != (parser.Limits{}) {
+		fmt.Fprintf(h, "limits %+v\n", limits)
+	}
+	h.Write(source)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// path returns the path of the entry with the given key.
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key+".gyp")
+}
+
+// Get returns the ruleset stored for the given source code. It returns
+// ErrNotFound if there's no entry for the source code, or if any of the files
+// included by the ruleset changed since it was stored, in which case the
+// entry is removed. If the entry is corrupted the error is a CorruptedError.
+func (c *Cache) Get(source []byte) (*ast.RuleSet, error) {
+	e, err := c.get(Key(source))
+	if err != nil {
+		return nil, err
+	}
+	return e.RuleSet, nil
+}
+
+// Put stores a ruleset parsed from the given source code.
+func (c *Cache) Put(source []byte, rs *ast.RuleSet) error {
+	return c.put(Key(source), &entry{RuleSet: rs})
+}
+
+// Remove removes the entry for the given source code, if any.
+func (c *Cache) Remove(source []byte) error {
+	err := os.Remove(c.path(Key(source)))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+func (c *Cache) get(key string) (*entry, error) {
+	path := c.path(key)
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < headerSize || string(data[:len(magic)]) != magic {
+		return nil, &CorruptedError{Path: path, Reason: "invalid header"}
+	}
+	if binary.BigEndian.Uint32(data[len(magic):]) != Version {
+		return nil, ErrNotFound
+	}
+	payload := data[headerSize:]
+	if sum := sha256.Sum256(payload); !bytes.Equal(sum[:], data[headerSize-sha256.Size:headerSize]) {
+		return nil, &CorruptedError{Path: path, Reason: "checksum mismatch"}
+	}
+	e := &entry{}
+	if err := gob.NewDecoder(bytes.NewReader(payload)).Decode(e); err != nil {
+		return nil, &CorruptedError{Path: path, Reason: err.Error()}
+	}
+	if e.RuleSet == nil {
+		return nil, &CorruptedError{Path: path, Reason: "missing ruleset"}
+	}
+	for _, d := range e.Dependencies {
+		sum, err := fileSum(d.Path)
+		if err != nil && !os.IsNotExist(err) || sum != d.Sum {
+			os.Remove(path)
+			return nil, ErrNotFound
+		}
+	}
+	return e, nil
+}
+
+func (c *Cache) put(key string, e *entry) error {
+	var b bytes.Buffer
+	b.WriteString(magic)
+	var version [4]byte
+	binary.BigEndian.PutUint32(version[:], Version)
+	b.Write(version[:])
+	// Placeholder for the checksum.
+	b.Write(make([]byte, sha256.Size))
+	if err := gob.NewEncoder(&b).Encode(e); err != nil {
+		return err
+	}
+	data := b.Bytes()
+	sum := sha256.Sum256(data[headerSize:])
+	copy(data[headerSize-sha256.Size:], sum[:])
+
+	f, err := ioutil.TempFile(c.dir, key+".tmp")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), c.path(key))
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+// LoadOrParse returns the ruleset in the given file, which is loaded from the
+// cache if possible. Otherwise the file is parsed and the ruleset is stored in
+// the cache, unless the file has errors. Corrupted entries are treated as
+// missing, and replaced with the parsed ruleset.
+//
+// Included files are resolved relative to the directory of the file that
+// includes them, as YARA does. They are not part of the returned ruleset, but
+// changing any of them invalidates the entry.
+func (c *Cache) LoadOrParse(path string) (*ast.RuleSet, error) {
+	e, err := c.loadOrParse(path, make(map[string]bool))
+	if err != nil {
+		return nil, err
+	}
+	return e.RuleSet, nil
+}
+
+// loadOrParse implements LoadOrParse. Files in loading are being loaded by
+// some caller, and are not loaded again for avoiding infinite recursion with
+// circular includes.
+func (c *Cache) loadOrParse(path string, loading map[string]bool) (*entry, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	source, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key := keyWithLimits(source, c.Limits)
+	if e, err := c.get(key); err == nil {
+		return e, nil
+	}
+	rs, err := gyp.ParseWithLimits(bytes.NewReader(source), c.Limits)
+	if err != nil {
+		return nil, err
+	}
+	loading[path] = true
+	defer delete(loading, path)
+	e := &entry{RuleSet: rs}
+	seen := make(map[string]bool)
+	addDependency := func(d dependency) {
+		if !seen[d.Path] {
+			seen[d.Path] = true
+			e.Dependencies = append(e.Dependencies, d)
+		}
+	}
+	for _, inc := range rs.Includes {
+		if !filepath.IsAbs(inc) {
+			inc = filepath.Join(filepath.Dir(path), inc)
+		}
+		// A missing file is a dependency too, with a zero checksum, as
+		// creating it changes the ruleset.
+		sum, err := fileSum(inc)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		addDependency(dependency{Path: inc, Sum: sum})
+		if err != nil || loading[inc] {
+			continue
+		}
+		// Errors in included files are not errors in this file, they
+		// are only tracked as dependencies.
+		if included, err := c.loadOrParse(inc, loading); err == nil {
+			for _, d := range included.Dependencies {
+				addDependency(d)
+			}
+		}
+	}
+	if err := c.put(key, e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// fileSum returns the SHA-256 of the file's content, or zero if the file
+// doesn't exist, in which case the error is also returned.
+func fileSum(path string) (sum [sha256.Size]byte, err error) {
+	data, err := ioutil.ReadFile(path)
+	if err == nil {
+		sum = sha256.Sum256(data)
+	}
+	return sum, err
+}
@@ -0,0 +1,187 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/VirusTotal/gyp"
+	"github.com/VirusTotal/gyp/ast"
+	gyperror "github.com/VirusTotal/gyp/error"
+	"github.com/VirusTotal/gyp/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCache returns a temporary directory, and a cache in a subdirectory of
+// it. The directory must be removed by the caller.
+func newCache(t *testing.T) (string, *Cache) {
+	dir, err := ioutil.TempDir("", "gyp")
+	require.NoError(t, err)
+	c, err := New(filepath.Join(dir, "cache"))
+	require.NoError(t, err)
+	return dir, c
+}
+
+func writeFile(t *testing.T, path, content string) {
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+}
+
+// assertEqualRuleSets checks that two rulesets are equal, except for empty
+// slices that are nil in one of them. All the fields of the nodes are included
+// in their JSON encoding.
+func assertEqualRuleSets(t *testing.T, expected, actual *ast.RuleSet) {
+	expectedJSON, err := json.Marshal(expected)
+	require.NoError(t, err)
+	actualJSON, err := json.Marshal(actual)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(expectedJSON), string(actualJSON))
+}
+
+func TestGetPut(t *testing.T) {
+	source, err := ioutil.ReadFile("../tests/testdata/corpus.yar")
+	require.NoError(t, err)
+	rs, err := gyp.ParseString(string(source))
+	require.NoError(t, err)
+	dir, c := newCache(t)
+	defer os.RemoveAll(dir)
+	_, err = c.Get(source)
+	assert.Equal(t, ErrNotFound, err)
+
+	assert.NoError(t, c.Put(source, rs))
+	cached, err := c.Get(source)
+	assert.NoError(t, err)
+	assertEqualRuleSets(t, rs, cached)
+
+	assert.NoError(t, c.Remove(source))
+	_, err = c.Get(source)
+	assert.Equal(t, ErrNotFound, err)
+	assert.NoError(t, c.Remove(source))
+}
+
+func TestCorruptedEntry(t *testing.T) {
+	dir, c := newCache(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "rules.yar")
+	source := "rule foo { strings: $a = \"foo\" condition: $a }"
+	writeFile(t, path, source)
+	rs, err := c.LoadOrParse(path)
+	require.NoError(t, err)
+
+	entryPath := c.path(Key([]byte(source)))
+	data, err := ioutil.ReadFile(entryPath)
+	require.NoError(t, err)
+	corruptions := map[string][]byte{
+		"checksum mismatch": append(append([]byte{}, data[:len(data)-1]...), data[len(data)-1]^1),
+		"invalid header":    data[:headerSize-1],
+	}
+	for reason, corrupted := range corruptions {
+		writeFile(t, entryPath, string(corrupted))
+		_, err = c.Get([]byte(source))
+		assert.True(t, errors.Is(err, ErrCorrupted), reason)
+		assert.Equal(t, &CorruptedError{Path: entryPath, Reason: reason}, err)
+
+		// LoadOrParse parses the file again, and replaces the entry.
+		reparsed, err := c.LoadOrParse(path)
+		assert.NoError(t, err)
+		assertEqualRuleSets(t, rs, reparsed)
+		cached, err := c.Get([]byte(source))
+		assert.NoError(t, err)
+		assertEqualRuleSets(t, rs, cached)
+	}
+
+	// Entries stored with other versions of the format are ignored.
+	data[len(magic)+3]++
+	writeFile(t, entryPath, string(data))
+	_, err = c.Get([]byte(source))
+	assert.Equal(t, ErrNotFound, err)
+}
+
+func TestLoadOrParse(t *testing.T) {
+	dir, c := newCache(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "rules.yar")
+	source := "include \"inc/a.yar\"\nrule foo { condition: true }"
+	writeFile(t, path, source)
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "inc"), 0755))
+	// a.yar includes b.yar, which doesn't exist yet, and c.yar, which
+	// includes a.yar again.
+	writeFile(t, filepath.Join(dir, "inc", "a.yar"), "include \"b.yar\"\ninclude \"c.yar\"\nrule a { condition: true }")
+	writeFile(t, filepath.Join(dir, "inc", "c.yar"), "include \"a.yar\"\nrule c { condition: true }")
+
+	rs, err := c.LoadOrParse(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"inc/a.yar"}, rs.Includes)
+	cached, err := c.Get([]byte(source))
+	assert.NoError(t, err)
+	assertEqualRuleSets(t, rs, cached)
+
+	e, err := c.get(Key([]byte(source)))
+	require.NoError(t, err)
+	var dependencies []string
+	for _, d := range e.Dependencies {
+		dependencies = append(dependencies, d.Path)
+	}
+	assert.Equal(t, []string{
+		filepath.Join(dir, "inc", "a.yar"),
+		filepath.Join(dir, "inc", "b.yar"),
+		filepath.Join(dir, "inc", "c.yar"),
+	}, dependencies)
+
+	// Included files are cached too.
+	_, err = c.Get([]byte("include \"a.yar\"\nrule c { condition: true }"))
+	assert.NoError(t, err)
+
+	// Creating or modifying any of the included files invalidates the entry.
+	for _, name := range []string{"b.yar", "c.yar", "a.yar"} {
+		writeFile(t, filepath.Join(dir, "inc", name), "rule "+name[:1]+" { condition: false }")
+		_, err = c.Get([]byte(source))
+		assert.Equal(t, ErrNotFound, err, name)
+		_, err = c.LoadOrParse(path)
+		assert.NoError(t, err)
+		_, err = c.Get([]byte(source))
+		assert.NoError(t, err)
+	}
+
+	// Removing them too.
+	assert.NoError(t, os.Remove(filepath.Join(dir, "inc", "a.yar")))
+	_, err = c.Get([]byte(source))
+	assert.Equal(t, ErrNotFound, err)
+}
+
+func TestLoadOrParseError(t *testing.T) {
+	dir, c := newCache(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "rules.yar")
+	source := "rule foo { condition: $a }"
+	writeFile(t, path, source)
+	_, err := c.LoadOrParse(path)
+	assert.Error(t, err)
+	_, err = c.Get([]byte(source))
+	assert.Equal(t, ErrNotFound, err)
+}
+
+// Rulesets cached without limits are not returned when the cache has limits
+// that they exceed.
+func TestLoadOrParseLimits(t *testing.T) {
+	dir, c := newCache(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "rules.yar")
+	writeFile(t, path, "rule a { condition: true }\nrule b { condition: true }")
+	_, err := c.LoadOrParse(path)
+	require.NoError(t, err)
+
+	c.Limits = parser.Limits{MaxRules: 1}
+	_, err = c.LoadOrParse(path)
+	assert.True(t, errors.Is(err, gyperror.TooManyRulesError), "got %v", err)
+
+	c.Limits = parser.Limits{MaxRules: 2}
+	rs, err := c.LoadOrParse(path)
+	require.NoError(t, err)
+	assert.Len(t, rs.Rules, 2)
+	_, err = c.get(keyWithLimits([]byte("rule a { condition: true }\nrule b { condition: true }"), c.Limits))
+	assert.NoError(t, err)
+}
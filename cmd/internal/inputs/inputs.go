@@ -0,0 +1,93 @@
+// Package inputs implements the handling of input files shared by the y2j and
+// j2y command-line tools.
+package inputs
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/VirusTotal/gyp/ast"
+)
+
+// Files expands the paths given in the command line into the list of
+// files to read. Directories are walked recursively, taking only the files
+// with one of the given extensions. The path "-", as well as an empty list
+// of paths, stands for the standard input.
+func Files(paths []string, extensions ...string) ([]string, error) {
+	if len(paths) == 0 {
+		return []string{"-"}, nil
+	}
+	var files []string
+	for _, path := range paths {
+		if path == "-" {
+			files = append(files, path)
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		err = filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() && hasExtension(p, extensions) {
+				files = append(files, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+func hasExtension(path string, extensions []string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, e := range extensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// Open opens one of the files returned by Files.
+func Open(path string) (io.ReadCloser, error) {
+	if path == "-" {
+		return ioutil.NopCloser(os.Stdin), nil
+	}
+	return os.Open(path)
+}
+
+// AppendRuleSet appends the rules in src to dst. The imports and includes
+// in src that are not already in dst are appended too.
+func AppendRuleSet(dst, src *ast.RuleSet) {
+	dst.Imports = appendMissing(dst.Imports, src.Imports)
+	dst.Includes = appendMissing(dst.Includes, src.Includes)
+	dst.Rules = append(dst.Rules, src.Rules...)
+}
+
+func appendMissing(dst, src []string) []string {
+	for _, s := range src {
+		found := false
+		for _, d := range dst {
+			if d == s {
+				found = true
+				break
+			}
+		}
+		if !found {
+			dst = append(dst, s)
+		}
+	}
+	return dst
+}
@@ -1,35 +1,48 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
 	"io"
+	"io/ioutil"
 	"os"
+	"strings"
 
 	jsonpb "github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
 
+	"github.com/VirusTotal/gyp"
 	"github.com/VirusTotal/gyp/ast"
+	"github.com/VirusTotal/gyp/cmd/internal/inputs"
+	"github.com/VirusTotal/gyp/convert"
 	"github.com/VirusTotal/gyp/pb"
 )
 
 // global options
 var opts options
 
+// extensions contains the extensions of the files that are read from
+// directories, for each input format.
+var extensions = map[string][]string{
+	"json":   {".json"},
+	"ndjson": {".ndjson", ".jsonl"},
+	"pb":     {".pb"},
+	"yaml":   {".yaml", ".yml"},
+	"toml":   {".toml"},
+}
+
 func main() {
 	opts = getopt()
 
-	jsonFile, err := os.Open(opts.Infile)
+	files, err := inputs.Files(opts.Inputs, extensions[opts.Format]...)
 	if err != nil {
-		perror(`Couldn't open JSON file "%s": %s`, opts.Infile, err)
+		perror(`Couldn't read input files: %s`, err)
 		os.Exit(2)
 	}
-	defer handleErr(jsonFile.Close)
-
-	var pbRuleset pb.RuleSet
-	unmarshaler := jsonpb.Unmarshaler{}
-	err = unmarshaler.Unmarshal(jsonFile, &pbRuleset)
 
-	if err != nil {
-		perror(`Couldn't JSON decode file: %s`, err)
-		os.Exit(3)
+	ruleset := &ast.RuleSet{}
+	for _, file := range files {
+		inputs.AppendRuleSet(ruleset, readFile(file))
 	}
 
 	// Set output to stdout if not specified; otherwise file
@@ -46,10 +59,89 @@ func main() {
 		out = f
 	}
 
-	ruleset := ast.RuleSetFromProto(&pbRuleset)
-
-	if err := ruleset.WriteSource(out); err != nil {
+	if opts.Serializer {
+		serializer := gyp.NewSerializer(out)
+		serializer.SetIndent(opts.Indent)
+		var pbRuleset *pb.RuleSet
+		if pbRuleset, err = ruleset.ToProto(); err == nil {
+			err = serializer.Serialize(pbRuleset)
+		}
+	} else {
+		err = ruleset.WriteSource(out)
+	}
+	if err != nil {
 		perror(`Couldn't write ruleset: %s`, err)
 		os.Exit(6)
 	}
 }
+
+// readFile reads a ruleset from the given file, exiting if the file can't
+// be read or decoded.
+func readFile(path string) *ast.RuleSet {
+	format := strings.ToUpper(opts.Format)
+
+	inFile, err := inputs.Open(path)
+	if err != nil {
+		perror(`Couldn't open %s file "%s": %s`, format, path, err)
+		os.Exit(2)
+	}
+	defer handleErr(inFile.Close)
+
+	var ruleset *ast.RuleSet
+	switch opts.Format {
+	case "ndjson":
+		ruleset, err = readNDJSON(inFile)
+	case "pb":
+		var data []byte
+		if data, err = ioutil.ReadAll(inFile); err == nil {
+			var pbRuleset pb.RuleSet
+			if err = proto.Unmarshal(data, &pbRuleset); err == nil {
+				ruleset, err = ast.NewRuleSetFromProto(&pbRuleset)
+			}
+		}
+	case "yaml":
+		ruleset, err = convert.ReadYAML(inFile)
+	case "toml":
+		ruleset, err = convert.ReadTOML(inFile)
+	default:
+		var pbRuleset pb.RuleSet
+		unmarshaler := jsonpb.Unmarshaler{}
+		if err = unmarshaler.Unmarshal(inFile, &pbRuleset); err == nil {
+			ruleset, err = ast.NewRuleSetFromProto(&pbRuleset)
+		}
+	}
+
+	if err != nil {
+		perror(`Couldn't %s decode file "%s": %s`, format, path, err)
+		os.Exit(3)
+	}
+	return ruleset
+}
+
+// readNDJSON reads a ruleset from newline-delimited JSON, where each line is
+// a RuleSet message. Empty lines are ignored.
+func readNDJSON(r io.Reader) (*ast.RuleSet, error) {
+	ruleset := &ast.RuleSet{}
+	unmarshaler := jsonpb.Unmarshaler{}
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(line)) > 0 {
+			var pbRuleset pb.RuleSet
+			if err := unmarshaler.Unmarshal(bytes.NewReader(line), &pbRuleset); err != nil {
+				return nil, err
+			}
+			rs, err := ast.NewRuleSetFromProto(&pbRuleset)
+			if err != nil {
+				return nil, err
+			}
+			inputs.AppendRuleSet(ruleset, rs)
+		}
+		if err == io.EOF {
+			return ruleset, nil
+		}
+	}
+}
@@ -7,9 +7,11 @@ import (
 )
 
 type options struct {
-	Indent  string
-	Infile  string
-	Outfile string
+	Format     string
+	Indent     string
+	Inputs     []string
+	Outfile    string
+	Serializer bool
 }
 
 func getopt() options {
@@ -18,21 +20,30 @@ func getopt() options {
 		indent int
 	)
 
-	flag.IntVar(&indent, "indent", 2, "Set number of indent spaces")
+	flag.Usage = func() {
+		perror("Usage: %s [options] [file or directory ...]", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.StringVar(&o.Format, "format", "json", "Format of the input: json, ndjson, pb, yaml or toml")
+	flag.IntVar(&indent, "indent", 2, "Set number of indent spaces, used only with -serializer")
 	flag.StringVar(&o.Outfile, "o", "", "YARA output file")
+	flag.BoolVar(&o.Serializer, "serializer", false, "Write the rules with YaraSerializer instead of ast.WriteSource")
 
 	flag.Parse()
 
-	// Set indent
-	o.Indent = strings.Repeat(" ", indent)
-
-	// The JSON file is the only positional argument
-	if n := flag.NArg(); n != 1 {
-		perror("Expected 1 input file; found %d", n)
+	switch o.Format {
+	case "json", "ndjson", "pb", "yaml", "toml":
+	default:
+		perror(`Unknown format "%s"`, o.Format)
 		os.Exit(1)
 	}
 
-	o.Infile = flag.Args()[0]
+	// Set indent
+	o.Indent = strings.Repeat(" ", indent)
+
+	// The input files, or directories containing them, are the positional
+	// arguments. Without them the input is read from the standard input.
+	o.Inputs = flag.Args()
 
 	return o
 }
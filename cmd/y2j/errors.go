@@ -22,3 +22,11 @@ func handleErr(f func() error) {
 		os.Exit(127)
 	}
 }
+
+// exitError is returned by functions that have already reported an error to
+// the user, it contains the code with which the program must exit.
+type exitError int
+
+func (e exitError) Error() string {
+	return fmt.Sprintf("exit code %d", int(e))
+}
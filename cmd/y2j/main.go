@@ -1,12 +1,22 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
 	"io"
+	"io/ioutil"
 	"os"
+	"strings"
 
 	jsonpb "github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
 
 	"github.com/VirusTotal/gyp"
+	"github.com/VirusTotal/gyp/ast"
+	"github.com/VirusTotal/gyp/cmd/internal/inputs"
+	"github.com/VirusTotal/gyp/convert"
+	gyperror "github.com/VirusTotal/gyp/error"
+	"github.com/VirusTotal/gyp/pb"
 )
 
 // global options
@@ -15,18 +25,11 @@ var opts options
 func main() {
 	opts = getopt()
 
-	yaraFile, err := os.Open(opts.Infile)
+	files, err := inputs.Files(opts.Inputs, ".yar", ".yara")
 	if err != nil {
-		perror(`Couldn't open YARA file "%s": %s`, opts.Infile, err)
+		perror(`Couldn't read input files: %s`, err)
 		os.Exit(2)
 	}
-	defer handleErr(yaraFile.Close)
-
-	ruleset, err := gyp.Parse(yaraFile)
-	if err != nil {
-		perror(`Couldn't parse YARA ruleset: %s`, err)
-		os.Exit(3)
-	}
 
 	// Set output to stdout if not specified; otherwise file
 	var out io.Writer
@@ -41,13 +44,115 @@ func main() {
 		defer handleErr(f.Close)
 		out = f
 	}
+	w := bufio.NewWriter(out)
+	defer handleErr(w.Flush)
 
-	marshaler := jsonpb.Marshaler{
-		Indent: opts.Indent,
+	// In NDJSON every rule is written as soon as the file it comes from is
+	// parsed. The other formats produce a single document with the rules
+	// from all the files.
+	ruleset := &ast.RuleSet{}
+	for _, file := range files {
+		rs, err := parseFile(file)
+		if err != nil {
+			// In NDJSON the rules from the previous files may still be
+			// buffered in the writer.
+			handleErr(w.Flush)
+			os.Exit(int(err.(exitError)))
+		}
+		if opts.Format == "ndjson" {
+			if err := writeNDJSON(w, rs); err != nil {
+				perror(`Error writing NDJSON: %s`, err)
+				os.Exit(6)
+			}
+		} else {
+			inputs.AppendRuleSet(ruleset, rs)
+		}
+	}
+
+	switch opts.Format {
+	case "ndjson":
+		return
+	case "pb":
+		var pbRuleset *pb.RuleSet
+		var data []byte
+		if pbRuleset, err = ruleset.ToProto(); err == nil {
+			data, err = proto.Marshal(pbRuleset)
+		}
+		if err == nil {
+			_, err = w.Write(data)
+		}
+	case "yaml":
+		err = convert.WriteYAML(w, ruleset)
+	case "toml":
+		err = convert.WriteTOML(w, ruleset)
+	default:
+		marshaler := jsonpb.Marshaler{
+			Indent: opts.Indent,
+		}
+		var pbRuleset *pb.RuleSet
+		if pbRuleset, err = ruleset.ToProto(); err == nil {
+			err = marshaler.Marshal(w, pbRuleset)
+		}
 	}
-	err = marshaler.Marshal(out, ruleset.AsProto())
 	if err != nil {
-		perror(`Error writing JSON: %s`, err)
+		perror(`Error writing %s: %s`, strings.ToUpper(opts.Format), err)
 		os.Exit(6)
 	}
 }
+
+// parseFile parses the YARA rules in the given file. If the file can't be
+// read or parsed the error is reported, and an exitError is returned.
+func parseFile(path string) (*ast.RuleSet, error) {
+	yaraFile, err := inputs.Open(path)
+	if err != nil {
+		perror(`Couldn't open YARA file "%s": %s`, path, err)
+		return nil, exitError(2)
+	}
+	defer handleErr(yaraFile.Close)
+
+	// The source is kept in memory for showing it in the diagnostics.
+	source, err := ioutil.ReadAll(yaraFile)
+	if err != nil {
+		perror(`Couldn't read YARA file "%s": %s`, path, err)
+		return nil, exitError(2)
+	}
+	ruleset, err := gyp.Parse(bytes.NewReader(source))
+	if yaraErr, ok := err.(gyperror.Error); ok {
+		handleErr(func() error {
+			return gyperror.Render(os.Stderr, source, yaraErr, gyperror.RenderOptions{
+				Filename: path,
+				Color:    opts.Color,
+			})
+		})
+		return nil, exitError(3)
+	} else if err != nil {
+		perror(`Couldn't parse YARA ruleset "%s": %s`, path, err)
+		return nil, exitError(3)
+	}
+	return ruleset, nil
+}
+
+// writeNDJSON writes each rule in the ruleset as a line of JSON. Every line
+// is a complete RuleSet message with a single rule and the imports and
+// includes of the ruleset, so that it can be processed on its own.
+func writeNDJSON(w io.Writer, ruleset *ast.RuleSet) error {
+	marshaler := jsonpb.Marshaler{}
+	for _, rule := range ruleset.Rules {
+		pbRule, err := rule.ToProto()
+		if err != nil {
+			return err
+		}
+		line := &pb.RuleSet{
+			Imports:  ruleset.Imports,
+			Includes: ruleset.Includes,
+			Rules:    []*pb.Rule{pbRule},
+		}
+		if err := marshaler.Marshal(w, line); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, "\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -7,9 +7,11 @@ import (
 )
 
 type options struct {
+	Format  string
 	Indent  string
-	Infile  string
+	Inputs  []string
 	Outfile string
+	Color   bool
 }
 
 func getopt() options {
@@ -18,21 +20,30 @@ func getopt() options {
 		indent int
 	)
 
+	flag.Usage = func() {
+		perror("Usage: %s [options] [file or directory ...]", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.StringVar(&o.Format, "format", "json", "Format of the output: json, ndjson, pb, yaml or toml")
 	flag.IntVar(&indent, "indent", 2, "Set number of indent spaces")
-	flag.StringVar(&o.Outfile, "o", "", "JSON output file")
+	flag.StringVar(&o.Outfile, "o", "", "Output file")
+	flag.BoolVar(&o.Color, "color", false, "Use colours in the diagnostics for parsing errors")
 
 	flag.Parse()
 
-	// Set indent
-	o.Indent = strings.Repeat(" ", indent)
-
-	// The yara file is the only positional argument
-	if n := flag.NArg(); n != 1 {
-		perror("Expected 1 input file; found %d", n)
+	switch o.Format {
+	case "json", "ndjson", "pb", "yaml", "toml":
+	default:
+		perror(`Unknown format "%s"`, o.Format)
 		os.Exit(1)
 	}
 
-	o.Infile = flag.Args()[0]
+	// Set indent
+	o.Indent = strings.Repeat(" ", indent)
+
+	// The YARA files, or directories containing them, are the positional
+	// arguments. Without them the rules are read from the standard input.
+	o.Inputs = flag.Args()
 
 	return o
 }
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/VirusTotal/gyp/parser"
+)
+
+// document is a YARA source file opened in the editor.
+type document struct {
+	uri string
+	doc *parser.Document
+	// Copy of the document's source code.
+	src string
+	// Offsets where each line starts within the source code.
+	lines []int
+	// Symbols in the source code, computed only when required.
+	symbols []parser.Symbol
+	// True if symbols are up-to-date.
+	symbolsReady bool
+}
+
+func newDocument(uri, text string) *document {
+	d := &document{uri: uri, doc: parser.NewDocument(text)}
+	d.update()
+	return d
+}
+
+// apply applies a change received from the editor to the document.
+func (d *document) apply(change textDocumentContentChangeEvent) error {
+	e := parser.Edit{Start: 0, End: len(d.src), Text: change.Text}
+	if change.Range != nil {
+		e.Start = d.offset(change.Range.Start)
+		e.End = d.offset(change.Range.End)
+	}
+	if e.Start > e.End {
+		return fmt.Errorf("invalid range in document change")
+	}
+	// Positions are always within the document, so Apply can fail only due
+	// to errors in the source code, which are reported as diagnostics.
+	d.doc.Apply(e)
+	d.update()
+	return nil
+}
+
+// update must be called after every change to the document's source.
+func (d *document) update() {
+	d.src = d.doc.Source()
+	src := d.src
+	d.lines = append(d.lines[:0], 0)
+	for i := 0; i < len(src); i++ {
+		if src[i] == '\n' {
+			d.lines = append(d.lines, i+1)
+		}
+	}
+	d.symbols = nil
+	d.symbolsReady = false
+}
+
+// getSymbols returns the symbols in the document. If the document contains
+// lexical errors, only the symbols before the first error are returned.
+func (d *document) getSymbols() []parser.Symbol {
+	if !d.symbolsReady {
+		d.symbols, _ = parser.Symbols(strings.NewReader(d.src))
+		d.symbolsReady = true
+	}
+	return d.symbols
+}
+
+// symbolAt returns the symbol at the given offset, or nil if there isn't any.
+// A symbol ending exactly at the offset is also returned, as editors place
+// the cursor after the last character of a word.
+func (d *document) symbolAt(offset int) *parser.Symbol {
+	symbols := d.getSymbols()
+	i := sort.Search(len(symbols), func(i int) bool {
+		return symbols[i].End >= offset
+	})
+	if i < len(symbols) && symbols[i].Start <= offset {
+		return &symbols[i]
+	}
+	return nil
+}
+
+// offset converts a position in the LSP format, where the character is
+// counted in UTF-16 code units, into an offset within the source code.
+func (d *document) offset(p position) int {
+	src := d.src
+	if p.Line < 0 {
+		return 0
+	}
+	if p.Line >= len(d.lines) {
+		return len(src)
+	}
+	offset := d.lines[p.Line]
+	for units := 0; units < p.Character && offset < len(src) && src[offset] != '\n'; {
+		r, size := utf8.DecodeRuneInString(src[offset:])
+		units += len(utf16.Encode([]rune{r}))
+		offset += size
+	}
+	return offset
+}
+
+// position converts an offset within the source code into a position in the
+// LSP format.
+func (d *document) position(offset int) position {
+	line := sort.Search(len(d.lines), func(i int) bool {
+		return d.lines[i] > offset
+	}) - 1
+	units := 0
+	for _, r := range d.src[d.lines[line]:offset] {
+		units += len(utf16.Encode([]rune{r}))
+	}
+	return position{Line: line, Character: units}
+}
+
+// textRange converts a span into a range in the LSP format.
+func (d *document) textRange(s parser.Span) textRange {
+	return textRange{Start: d.position(s.Start), End: d.position(s.End)}
+}
+
+// lineRange returns the range covering the given line, where lines start
+// at 1 as in the errors produced by the parser.
+func (d *document) lineRange(lineno int) textRange {
+	line := lineno - 1
+	if line < 0 {
+		line = 0
+	}
+	if line >= len(d.lines) {
+		line = len(d.lines) - 1
+	}
+	end := len(d.src)
+	if line+1 < len(d.lines) {
+		end = d.lines[line+1] - 1
+	}
+	return textRange{Start: position{Line: line}, End: d.position(end)}
+}
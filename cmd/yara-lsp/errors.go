@@ -0,0 +1,15 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// perror writes a format string and args to stderr
+func perror(s string, a ...interface{}) {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(s, a...))
+	sb.WriteRune('\n')
+	os.Stderr.WriteString(sb.String())
+}
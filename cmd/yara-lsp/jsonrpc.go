@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/textproto"
+	"strconv"
+)
+
+// JSON-RPC error codes used by the server.
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeRequestFailed  = -32803
+)
+
+// maxContentLength is the maximum size in bytes of the messages accepted by
+// the server. Messages can contain whole source files, but nothing close to
+// this size.
+const maxContentLength = 64 << 20
+
+// message is a JSON-RPC message, which can be a request, a response or a
+// notification. Requests have both ID and Method, notifications have only
+// Method, and responses have ID and either Result or Error.
+type message struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  json.RawMessage  `json:"result,omitempty"`
+	Error   *responseError   `json:"error,omitempty"`
+}
+
+// responseError is the error included in a response when a request fails.
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *responseError) Error() string {
+	return e.Message
+}
+
+// readMessage reads a message from r. Each message is preceded by a header
+// that contains at least the Content-Length field, as specified by the
+// Language Server Protocol. Messages with an invalid Content-Length, or
+// larger than maxContentLength, are rejected with a *responseError. The body
+// of messages that are too large is discarded, so that the next message can
+// be read.
+func readMessage(r *bufio.Reader) (*message, error) {
+	header, err := textproto.NewReader(r).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil || length < 0 {
+		return nil, &responseError{
+			Code:    codeInvalidRequest,
+			Message: fmt.Sprintf("invalid Content-Length: %q", header.Get("Content-Length")),
+		}
+	}
+	if length > maxContentLength {
+		if _, err := io.CopyN(ioutil.Discard, r, int64(length)); err != nil {
+			return nil, err
+		}
+		return nil, &responseError{
+			Code:    codeInvalidRequest,
+			Message: fmt.Sprintf("message too large, the maximum is %d bytes", maxContentLength),
+		}
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	var msg message
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return nil, &responseError{Code: codeParseError, Message: err.Error()}
+	}
+	return &msg, nil
+}
+
+// writeMessage writes a message to w, preceded by its header.
+func writeMessage(w io.Writer, msg *message) error {
+	msg.JSONRPC = "2.0"
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "Content-Length: %d\r\n\r\n", len(body)); err != nil {
+		return err
+	}
+	_, err = w.Write(body)
+	return err
+}
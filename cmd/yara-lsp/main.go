@@ -0,0 +1,14 @@
+// yara-lsp is a Language Server Protocol server for YARA rules. It speaks
+// the protocol over the standard input and output.
+package main
+
+import (
+	"os"
+)
+
+func main() {
+	if err := newServer(os.Stdin, os.Stdout).serve(); err != nil {
+		perror(`Error: %s`, err)
+		os.Exit(1)
+	}
+}
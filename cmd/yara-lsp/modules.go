@@ -0,0 +1,248 @@
+package main
+
+// memberKind is the type of a module member.
+type memberKind int
+
+const (
+	memberField memberKind = iota
+	memberFunction
+	memberConstant
+	// A structure, or an array or dictionary of structures. Its members are
+	// accessed with "." after the optional index.
+	memberStruct
+)
+
+// member describes a member in a YARA module, which can be structure that
+// contains other members.
+type member struct {
+	name    string
+	kind    memberKind
+	members []member
+}
+
+func field(name string) member    { return member{name: name, kind: memberField} }
+func function(name string) member { return member{name: name, kind: memberFunction} }
+func constant(name string) member { return member{name: name, kind: memberConstant} }
+
+func structure(name string, members ...member) member {
+	return member{name: name, kind: memberStruct, members: members}
+}
+
+// modules contains the members of YARA's standard modules that are offered
+// by code completion. This is not an exhaustive list, only the most commonly
+// used members are included.
+var modules = map[string]member{
+	"pe": structure("pe",
+		field("machine"),
+		field("subsystem"),
+		field("timestamp"),
+		field("characteristics"),
+		field("entry_point"),
+		field("entry_point_raw"),
+		field("image_base"),
+		field("number_of_sections"),
+		field("number_of_resources"),
+		field("number_of_imports"),
+		field("number_of_exports"),
+		field("number_of_signatures"),
+		field("size_of_image"),
+		field("dll_name"),
+		field("pdb_path"),
+		field("linker_version"),
+		field("version_info"),
+		structure("sections",
+			field("name"),
+			field("characteristics"),
+			field("virtual_address"),
+			field("virtual_size"),
+			field("raw_data_offset"),
+			field("raw_data_size")),
+		structure("resources",
+			field("type"),
+			field("id"),
+			field("language"),
+			field("offset"),
+			field("length"),
+			field("name_string")),
+		structure("signatures",
+			field("issuer"),
+			field("subject"),
+			field("serial"),
+			field("thumbprint"),
+			field("not_before"),
+			field("not_after"),
+			function("valid_on")),
+		structure("rich_signature",
+			field("offset"),
+			field("length"),
+			field("key"),
+			field("raw_data"),
+			field("clear_data"),
+			function("version"),
+			function("toolid")),
+		function("exports"),
+		function("imports"),
+		function("imphash"),
+		function("is_dll"),
+		function("is_32bit"),
+		function("is_64bit"),
+		function("locale"),
+		function("language"),
+		function("section_index"),
+		function("rva_to_offset"),
+		constant("MACHINE_I386"),
+		constant("MACHINE_AMD64"),
+		constant("MACHINE_ARM"),
+		constant("MACHINE_ARM64"),
+		constant("DLL"),
+		constant("EXECUTABLE_IMAGE"),
+		constant("SUBSYSTEM_NATIVE"),
+		constant("SUBSYSTEM_WINDOWS_GUI"),
+		constant("SUBSYSTEM_WINDOWS_CUI"),
+		constant("SECTION_CNT_CODE"),
+		constant("SECTION_MEM_EXECUTE"),
+		constant("SECTION_MEM_READ"),
+		constant("SECTION_MEM_WRITE")),
+	"elf": structure("elf",
+		field("type"),
+		field("machine"),
+		field("entry_point"),
+		field("number_of_sections"),
+		field("number_of_segments"),
+		field("dynamic_section_entries"),
+		field("symtab_entries"),
+		structure("sections",
+			field("name"),
+			field("type"),
+			field("flags"),
+			field("address"),
+			field("size"),
+			field("offset")),
+		structure("segments",
+			field("type"),
+			field("flags"),
+			field("offset"),
+			field("virtual_address"),
+			field("physical_address"),
+			field("file_size"),
+			field("memory_size"),
+			field("alignment")),
+		structure("dynamic",
+			field("type"),
+			field("val")),
+		structure("symtab",
+			field("name"),
+			field("value"),
+			field("size"),
+			field("type"),
+			field("bind"),
+			field("shndx")),
+		constant("ET_NONE"),
+		constant("ET_REL"),
+		constant("ET_EXEC"),
+		constant("ET_DYN"),
+		constant("ET_CORE"),
+		constant("EM_386"),
+		constant("EM_X86_64"),
+		constant("EM_ARM"),
+		constant("EM_AARCH64")),
+	"math": structure("math",
+		function("entropy"),
+		function("monte_carlo_pi"),
+		function("serial_correlation"),
+		function("mean"),
+		function("deviation"),
+		function("in_range"),
+		function("max"),
+		function("min"),
+		function("to_number"),
+		function("abs"),
+		function("count"),
+		function("percentage"),
+		function("mode")),
+	"hash": structure("hash",
+		function("md5"),
+		function("sha1"),
+		function("sha256"),
+		function("checksum32"),
+		function("crc32")),
+	"time": structure("time",
+		function("now")),
+	"console": structure("console",
+		function("log"),
+		function("hex")),
+	"string": structure("string",
+		function("to_int"),
+		function("length")),
+	"magic": structure("magic",
+		function("type"),
+		function("mime_type")),
+	"dotnet": structure("dotnet",
+		field("is_dotnet"),
+		field("version"),
+		field("module_name"),
+		field("typelib"),
+		field("number_of_streams"),
+		field("number_of_guids"),
+		field("number_of_resources"),
+		field("number_of_user_strings"),
+		field("guids"),
+		field("user_strings"),
+		structure("streams",
+			field("name"),
+			field("offset"),
+			field("size")),
+		structure("resources",
+			field("name"),
+			field("offset"),
+			field("length")),
+		structure("assembly",
+			field("name"),
+			field("culture"),
+			structure("version",
+				field("major"),
+				field("minor"),
+				field("build_number"),
+				field("revision_number")))),
+	"cuckoo": structure("cuckoo",
+		structure("network",
+			function("dns_lookup"),
+			function("http_request"),
+			function("http_get"),
+			function("http_post"),
+			function("http_user_agent"),
+			function("host"),
+			function("tcp"),
+			function("udp")),
+		structure("registry",
+			function("key_access")),
+		structure("filesystem",
+			function("file_access")),
+		structure("sync",
+			function("mutex")),
+		structure("process",
+			function("executed_command"))),
+}
+
+// lookupMember returns the member reached by following the given path of
+// member names, starting at a module. Returns nil if the path is not valid.
+func lookupMember(path []string) *member {
+	m, ok := modules[path[0]]
+	if !ok {
+		return nil
+	}
+	for _, name := range path[1:] {
+		found := false
+		for i := range m.members {
+			if m.members[i].name == name && m.members[i].kind == memberStruct {
+				m = m.members[i]
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil
+		}
+	}
+	return &m
+}
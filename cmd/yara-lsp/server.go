@@ -0,0 +1,623 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/VirusTotal/gyp/ast"
+	gyperror "github.com/VirusTotal/gyp/error"
+	"github.com/VirusTotal/gyp/parser"
+	"github.com/VirusTotal/gyp/utils"
+)
+
+// server is a Language Server Protocol server for YARA rules.
+type server struct {
+	in        *bufio.Reader
+	out       io.Writer
+	documents map[string]*document
+}
+
+func newServer(in io.Reader, out io.Writer) *server {
+	return &server{
+		in:        bufio.NewReader(in),
+		out:       out,
+		documents: make(map[string]*document),
+	}
+}
+
+// serve reads messages from the server's input and handles them until the
+// client sends the "exit" notification, or the input is closed.
+func (s *server) serve() error {
+	for {
+		msg, err := readMessage(s.in)
+		if err == io.EOF {
+			return nil
+		}
+		if rerr, ok := err.(*responseError); ok {
+			// The message is not valid JSON or its length is invalid, so
+			// its ID is unknown, which JSON-RPC indicates with a null ID.
+			id := json.RawMessage("null")
+			if err := writeMessage(s.out, &message{ID: &id, Error: rerr}); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if msg.Method == "exit" {
+			return nil
+		}
+		result, err := s.handle(msg)
+		// Notifications don't have an ID and don't receive a response.
+		if msg.ID == nil {
+			continue
+		}
+		response := &message{ID: msg.ID}
+		if err != nil {
+			rerr, ok := err.(*responseError)
+			if !ok {
+				rerr = &responseError{Code: codeRequestFailed, Message: err.Error()}
+			}
+			response.Error = rerr
+		} else if response.Result, err = json.Marshal(result); err != nil {
+			return err
+		}
+		if err := writeMessage(s.out, response); err != nil {
+			return err
+		}
+	}
+}
+
+// notify sends a notification to the client.
+func (s *server) notify(method string, params interface{}) error {
+	p, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return writeMessage(s.out, &message{Method: method, Params: p})
+}
+
+// handle handles a request or notification, returning the result that must
+// be sent back to the client.
+func (s *server) handle(msg *message) (interface{}, error) {
+	switch msg.Method {
+	case "initialize":
+		return s.initialize()
+	case "shutdown":
+		return nil, nil
+	case "textDocument/didOpen":
+		var params didOpenTextDocumentParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return nil, s.didOpen(&params)
+	case "textDocument/didChange":
+		var params didChangeTextDocumentParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return nil, s.didChange(&params)
+	case "textDocument/didClose":
+		var params didCloseTextDocumentParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return nil, s.didClose(&params)
+	case "textDocument/documentSymbol":
+		var params documentSymbolParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return s.documentSymbol(&params)
+	case "textDocument/definition":
+		var params textDocumentPositionParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return s.definition(&params)
+	case "textDocument/references":
+		var params referenceParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return s.references(&params)
+	case "textDocument/rename":
+		var params renameParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return s.rename(&params)
+	case "textDocument/hover":
+		var params textDocumentPositionParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return s.hover(&params)
+	case "textDocument/completion":
+		var params textDocumentPositionParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return s.completion(&params)
+	case "textDocument/formatting":
+		var params documentFormattingParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return s.formatting(&params)
+	}
+	// Unknown notifications are ignored, unknown requests produce an error.
+	if msg.ID == nil {
+		return nil, nil
+	}
+	return nil, &responseError{
+		Code:    codeMethodNotFound,
+		Message: fmt.Sprintf("method not found: %s", msg.Method),
+	}
+}
+
+func decodeParams(msg *message, v interface{}) error {
+	if err := json.Unmarshal(msg.Params, v); err != nil {
+		return &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+func (s *server) getDocument(uri string) (*document, error) {
+	d, ok := s.documents[uri]
+	if !ok {
+		return nil, &responseError{
+			Code:    codeInvalidParams,
+			Message: fmt.Sprintf("unknown document: %s", uri),
+		}
+	}
+	return d, nil
+}
+
+func (s *server) initialize() (interface{}, error) {
+	var result initializeResult
+	result.ServerInfo.Name = "yara-lsp"
+	result.Capabilities = serverCapabilities{
+		TextDocumentSync: textDocumentSyncOptions{
+			OpenClose: true,
+			Change:    syncIncremental,
+		},
+		DocumentSymbolProvider:     true,
+		DefinitionProvider:         true,
+		ReferencesProvider:         true,
+		RenameProvider:             true,
+		HoverProvider:              true,
+		DocumentFormattingProvider: true,
+		CompletionProvider: completionOptions{
+			TriggerCharacters: []string{".", "$", "#", "@", "!"},
+		},
+	}
+	return result, nil
+}
+
+func (s *server) didOpen(params *didOpenTextDocumentParams) error {
+	d := newDocument(params.TextDocument.URI, params.TextDocument.Text)
+	s.documents[d.uri] = d
+	return s.publishDiagnostics(d)
+}
+
+func (s *server) didChange(params *didChangeTextDocumentParams) error {
+	d, err := s.getDocument(params.TextDocument.URI)
+	if err != nil {
+		return err
+	}
+	for _, change := range params.ContentChanges {
+		if err := d.apply(change); err != nil {
+			return err
+		}
+	}
+	return s.publishDiagnostics(d)
+}
+
+func (s *server) didClose(params *didCloseTextDocumentParams) error {
+	delete(s.documents, params.TextDocument.URI)
+	// Clear the diagnostics for the closed document.
+	return s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+		URI:         params.TextDocument.URI,
+		Diagnostics: []diagnostic{},
+	})
+}
+
+// publishDiagnostics sends the errors found in the document to the client.
+func (s *server) publishDiagnostics(d *document) error {
+	diagnostics := []diagnostic{}
+	if err := d.doc.Err(); err != nil {
+		diag := diagnostic{
+			Severity: severityError,
+			Source:   "yara",
+			Message:  err.Error(),
+		}
+		if e, ok := err.(gyperror.Error); ok {
+			diag.Range = d.lineRange(e.Line)
+			if e.EndPos > 0 {
+				diag.Range = d.textRange(parser.Span{Start: e.StartPos, End: e.EndPos})
+			}
+			diag.Code = e.Code.ID()
+			diag.Message = e.Message
+		}
+		diagnostics = append(diagnostics, diag)
+	}
+	return s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+		URI:         d.uri,
+		Diagnostics: diagnostics,
+	})
+}
+
+func (s *server) documentSymbol(params *documentSymbolParams) (interface{}, error) {
+	d, err := s.getDocument(params.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	result := []documentSymbol{}
+	for _, sym := range d.getSymbols() {
+		switch sym.Kind {
+		case parser.RuleDeclaration:
+			span := sym.Span
+			// The rule's span is known only if the document doesn't have
+			// errors, if not, the rule is expanded with its strings below.
+			if d.doc.Err() == nil {
+				span = d.doc.RuleSpan(sym.Rule)
+			}
+			result = append(result, documentSymbol{
+				Name:           sym.Name,
+				Kind:           symbolKindClass,
+				Range:          d.textRange(span),
+				SelectionRange: d.textRange(sym.Span),
+			})
+		case parser.StringDeclaration:
+			if len(result) == 0 {
+				continue
+			}
+			rule := &result[len(result)-1]
+			if d.doc.Err() != nil {
+				rule.Range.End = d.position(sym.End)
+			}
+			rule.Children = append(rule.Children, documentSymbol{
+				Name:           "$" + sym.Name,
+				Kind:           symbolKindVariable,
+				Range:          d.textRange(sym.Span),
+				SelectionRange: d.textRange(sym.Span),
+			})
+		}
+	}
+	return result, nil
+}
+
+// symbolAt returns the symbol at the given position, or an error if there's
+// no symbol at that position.
+func (s *server) symbolAt(params *textDocumentPositionParams) (*document, *parser.Symbol, error) {
+	d, err := s.getDocument(params.TextDocument.URI)
+	if err != nil {
+		return nil, nil, err
+	}
+	return d, d.symbolAt(d.offset(params.Position)), nil
+}
+
+func (s *server) definition(params *textDocumentPositionParams) (interface{}, error) {
+	d, sym, err := s.symbolAt(params)
+	if err != nil || sym == nil {
+		return nil, err
+	}
+	result := []location{}
+	for _, decl := range declarations(d.getSymbols(), sym) {
+		result = append(result, location{URI: d.uri, Range: d.textRange(decl.Span)})
+	}
+	return result, nil
+}
+
+func (s *server) references(params *referenceParams) (interface{}, error) {
+	d, sym, err := s.symbolAt(&params.textDocumentPositionParams)
+	if err != nil || sym == nil {
+		return nil, err
+	}
+	symbols := d.getSymbols()
+	seen := make(map[int]bool)
+	result := []location{}
+	for _, decl := range declarations(symbols, sym) {
+		if params.Context.IncludeDeclaration && !seen[decl.Start] {
+			seen[decl.Start] = true
+			result = append(result, location{URI: d.uri, Range: d.textRange(decl.Span)})
+		}
+		for _, ref := range references(symbols, &decl) {
+			if !seen[ref.Start] {
+				seen[ref.Start] = true
+				result = append(result, location{URI: d.uri, Range: d.textRange(ref.Span)})
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i].Range.Start, result[j].Range.Start
+		return a.Line < b.Line || a.Line == b.Line && a.Character < b.Character
+	})
+	return result, nil
+}
+
+func (s *server) rename(params *renameParams) (interface{}, error) {
+	d, sym, err := s.symbolAt(&params.textDocumentPositionParams)
+	if err != nil {
+		return nil, err
+	}
+	if sym == nil {
+		return nil, fmt.Errorf("no symbol to rename at this position")
+	}
+	symbols := d.getSymbols()
+	decls := declarations(symbols, sym)
+	if len(decls) != 1 {
+		return nil, fmt.Errorf("can't rename %q, it must reference a single declaration", sym.Name)
+	}
+	decl := decls[0]
+	newName := params.NewName
+	if decl.Kind == parser.StringDeclaration {
+		newName = strings.TrimLeft(newName, "$#@!")
+	}
+
+	// The rename is checked by performing it in a copy of the document's
+	// ruleset, the edits are computed from the symbols.
+	if d.doc.RuleSet() == nil {
+		return nil, fmt.Errorf("can't rename %q, the document has errors", sym.Name)
+	}
+	rs, err := parser.Parse(strings.NewReader(d.doc.Source()))
+	if err != nil {
+		return nil, err
+	}
+	if decl.Kind == parser.StringDeclaration {
+		err = utils.RenameString(rs.Rules[decl.Rule], decl.Name, newName)
+	} else {
+		err = utils.RenameRule(rs, decl.Name, newName)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	edits := []textEdit{{Range: d.textRange(decl.NameSpan()), NewText: newName}}
+	for _, ref := range references(symbols, &decl) {
+		edits = append(edits, textEdit{Range: d.textRange(ref.NameSpan()), NewText: newName})
+	}
+	return workspaceEdit{Changes: map[string][]textEdit{d.uri: edits}}, nil
+}
+
+func (s *server) hover(params *textDocumentPositionParams) (interface{}, error) {
+	d, sym, err := s.symbolAt(params)
+	if err != nil || sym == nil {
+		return nil, err
+	}
+	decls := declarations(d.getSymbols(), sym)
+	if len(decls) != 1 {
+		return nil, nil
+	}
+	decl := decls[0]
+	var text string
+	if rs := d.doc.RuleSet(); rs != nil {
+		rule := rs.Rules[decl.Rule]
+		if decl.Kind == parser.StringDeclaration {
+			for _, str := range rule.Strings {
+				if str.GetIdentifier() == decl.Name {
+					text = str.String()
+				}
+			}
+		} else {
+			text = ruleHeader(rule)
+		}
+	} else {
+		// If the document has errors, use the line where the declaration
+		// appears.
+		start := strings.LastIndexByte(d.src[:decl.Start], '\n') + 1
+		end := strings.IndexByte(d.src[decl.Start:], '\n')
+		if end == -1 {
+			end = len(d.src)
+		} else {
+			end += decl.Start
+		}
+		text = strings.TrimSpace(d.src[start:end])
+	}
+	return hover{
+		Contents: markupContent{
+			Kind:  "markdown",
+			Value: "```yara\n" + text + "\n```",
+		},
+		Range: d.textRange(sym.Span),
+	}, nil
+}
+
+// ruleHeader returns the rule's modifiers, identifier and tags.
+func ruleHeader(rule *ast.Rule) string {
+	var b strings.Builder
+	if rule.Global {
+		b.WriteString("global ")
+	}
+	if rule.Private {
+		b.WriteString("private ")
+	}
+	b.WriteString("rule ")
+	b.WriteString(rule.Identifier)
+	if len(rule.Tags) > 0 {
+		b.WriteString(" : ")
+		b.WriteString(strings.Join(rule.Tags, " "))
+	}
+	return b.String()
+}
+
+var (
+	memberAccessRegexp = regexp.MustCompile(
+		`([a-zA-Z_][a-zA-Z0-9_]*(?:\[[^\]]*\])?(?:\.[a-zA-Z_][a-zA-Z0-9_]*(?:\[[^\]]*\])?)*)\.[a-zA-Z0-9_]*$`)
+	indexRegexp  = regexp.MustCompile(`\[[^\]]*\]`)
+	importRegexp = regexp.MustCompile(`(?m)^\s*import\s+"([^"]+)"`)
+)
+
+func (s *server) completion(params *textDocumentPositionParams) (interface{}, error) {
+	d, err := s.getDocument(params.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	offset := d.offset(params.Position)
+	line := d.src[strings.LastIndexByte(d.src[:offset], '\n')+1 : offset]
+	result := completionList{Items: []completionItem{}}
+
+	imported := make(map[string]bool)
+	for _, m := range importRegexp.FindAllStringSubmatch(d.src, -1) {
+		imported[m[1]] = true
+	}
+
+	// Members of modules, like "pe." or "pe.sections[0].".
+	if m := memberAccessRegexp.FindStringSubmatch(line); m != nil {
+		path := strings.Split(indexRegexp.ReplaceAllString(m[1], ""), ".")
+		if imported[path[0]] {
+			if module := lookupMember(path); module != nil {
+				for _, member := range module.members {
+					result.Items = append(result.Items, memberCompletion(member))
+				}
+			}
+		}
+		return result, nil
+	}
+
+	// Strings declared in the current rule.
+	rule := -1
+	for _, sym := range d.getSymbols() {
+		if sym.Start >= offset {
+			break
+		}
+		if sym.Kind == parser.RuleDeclaration {
+			rule = sym.Rule
+		}
+	}
+	if i := strings.LastIndexAny(line, "$#@! \t(),"); i >= 0 && strings.ContainsAny(line[i:i+1], "$#@!") {
+		for _, sym := range d.getSymbols() {
+			if sym.Kind == parser.StringDeclaration && sym.Rule == rule && sym.Name != "" {
+				result.Items = append(result.Items, completionItem{
+					Label: line[i:i+1] + sym.Name,
+					Kind:  completionKindVariable,
+				})
+			}
+		}
+		return result, nil
+	}
+
+	// Rules declared before the current one, imported modules and keywords.
+	for _, sym := range d.getSymbols() {
+		if sym.Kind == parser.RuleDeclaration && sym.Rule < rule {
+			result.Items = append(result.Items, completionItem{
+				Label: sym.Name,
+				Kind:  completionKindClass,
+			})
+		}
+	}
+	for module := range imported {
+		result.Items = append(result.Items, completionItem{
+			Label: module,
+			Kind:  completionKindModule,
+		})
+	}
+	for _, keyword := range utils.Keywords() {
+		result.Items = append(result.Items, completionItem{
+			Label: keyword,
+			Kind:  completionKindKeyword,
+		})
+	}
+	sort.Slice(result.Items, func(i, j int) bool {
+		return result.Items[i].Label < result.Items[j].Label
+	})
+	return result, nil
+}
+
+func memberCompletion(m member) completionItem {
+	item := completionItem{Label: m.name}
+	switch m.kind {
+	case memberField:
+		item.Kind = completionKindField
+	case memberFunction:
+		item.Kind = completionKindFunction
+		item.Detail = "function"
+	case memberConstant:
+		item.Kind = completionKindConstant
+	case memberStruct:
+		item.Kind = completionKindField
+		item.Detail = "structure"
+	}
+	return item
+}
+
+func (s *server) formatting(params *documentFormattingParams) (interface{}, error) {
+	d, err := s.getDocument(params.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	rs := d.doc.RuleSet()
+	if rs == nil {
+		return nil, fmt.Errorf("can't format a document with errors")
+	}
+	var b strings.Builder
+	if err := rs.WriteSource(&b); err != nil {
+		return nil, err
+	}
+	return []textEdit{{
+		Range:   d.textRange(parser.Span{Start: 0, End: len(d.src)}),
+		NewText: b.String(),
+	}}, nil
+}
+
+// declarations returns the declarations of the rules or strings referenced by
+// a symbol. The result can contain more than one declaration if the symbol is
+// a wildcard. If the symbol is a declaration, the symbol itself is returned.
+func declarations(symbols []parser.Symbol, sym *parser.Symbol) []parser.Symbol {
+	var result []parser.Symbol
+	switch sym.Kind {
+	case parser.RuleDeclaration, parser.StringDeclaration:
+		result = append(result, *sym)
+	case parser.StringReference, parser.StringWildcard:
+		// Anonymous strings can't be referenced.
+		if sym.Kind == parser.StringReference && sym.Name == "" {
+			break
+		}
+		for _, s := range symbols {
+			if s.Kind == parser.StringDeclaration && s.Rule == sym.Rule && matches(sym, s.Name) {
+				result = append(result, s)
+			}
+		}
+	case parser.IdentifierReference, parser.RuleWildcard:
+		for _, s := range symbols {
+			if s.Kind == parser.RuleDeclaration && matches(sym, s.Name) {
+				result = append(result, s)
+			}
+		}
+	}
+	return result
+}
+
+// references returns the symbols that reference the given declaration.
+func references(symbols []parser.Symbol, decl *parser.Symbol) []parser.Symbol {
+	var result []parser.Symbol
+	for _, s := range symbols {
+		switch s.Kind {
+		case parser.StringReference, parser.StringWildcard:
+			if decl.Kind == parser.StringDeclaration && s.Rule == decl.Rule && matches(&s, decl.Name) {
+				result = append(result, s)
+			}
+		case parser.IdentifierReference, parser.RuleWildcard:
+			if decl.Kind == parser.RuleDeclaration && matches(&s, decl.Name) {
+				result = append(result, s)
+			}
+		}
+	}
+	return result
+}
+
+// matches returns true if the given name is matched by the symbol, which can
+// be a wildcard or a reference.
+func matches(sym *parser.Symbol, name string) bool {
+	if sym.Kind == parser.StringWildcard || sym.Kind == parser.RuleWildcard {
+		return strings.HasPrefix(name, sym.Name)
+	}
+	return sym.Name == name
+}
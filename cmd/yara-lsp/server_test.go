@@ -0,0 +1,384 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// client is a minimal LSP client that talks with a server running in the
+// same process.
+type client struct {
+	t      *testing.T
+	in     *bufio.Reader
+	out    io.WriteCloser
+	done   chan error
+	nextID int
+	// Notifications received from the server while waiting for responses.
+	notifications []*message
+}
+
+func newClient(t *testing.T) *client {
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+	c := &client{
+		t:    t,
+		in:   bufio.NewReader(clientIn),
+		out:  clientOut,
+		done: make(chan error, 1),
+	}
+	go func() {
+		err := newServer(serverIn, serverOut).serve()
+		serverOut.Close()
+		c.done <- err
+	}()
+	return c
+}
+
+// call sends a request and waits for its response. The response's result
+// is unmarshalled into result.
+func (c *client) call(method string, params, result interface{}) *responseError {
+	c.nextID++
+	idBytes, err := json.Marshal(c.nextID)
+	require.NoError(c.t, err)
+	id := json.RawMessage(idBytes)
+	c.send(&message{ID: &id, Method: method}, params)
+	for {
+		msg, err := readMessage(c.in)
+		require.NoError(c.t, err)
+		if msg.ID == nil {
+			c.notifications = append(c.notifications, msg)
+			continue
+		}
+		require.Equal(c.t, string(id), string(*msg.ID))
+		if msg.Error != nil {
+			return msg.Error
+		}
+		if result != nil {
+			require.NoError(c.t, json.Unmarshal(msg.Result, result))
+		}
+		return nil
+	}
+}
+
+// notify sends a notification to the server.
+func (c *client) notify(method string, params interface{}) {
+	c.send(&message{Method: method}, params)
+}
+
+func (c *client) send(msg *message, params interface{}) {
+	if params != nil {
+		p, err := json.Marshal(params)
+		require.NoError(c.t, err)
+		msg.Params = p
+	}
+	require.NoError(c.t, writeMessage(c.out, msg))
+}
+
+// diagnostics waits for the next diagnostics published by the server.
+func (c *client) diagnostics() publishDiagnosticsParams {
+	var params publishDiagnosticsParams
+	for {
+		var msg *message
+		if len(c.notifications) > 0 {
+			msg, c.notifications = c.notifications[0], c.notifications[1:]
+		} else {
+			var err error
+			msg, err = readMessage(c.in)
+			require.NoError(c.t, err)
+		}
+		if msg.Method == "textDocument/publishDiagnostics" {
+			require.NoError(c.t, json.Unmarshal(msg.Params, &params))
+			return params
+		}
+	}
+}
+
+const testURI = "file:///test.yar"
+
+func at(line, character int) textDocumentPositionParams {
+	return textDocumentPositionParams{
+		TextDocument: textDocumentIdentifier{URI: testURI},
+		Position:     position{Line: line, Character: character},
+	}
+}
+
+func span(line, start, end int) textRange {
+	return textRange{
+		Start: position{Line: line, Character: start},
+		End:   position{Line: line, Character: end},
+	}
+}
+
+const testSource = `import "pe"
+
+rule foo {
+  strings:
+    $a = "foo"
+    $b1 = { 01 02 }
+    $b2 = /bar/
+  condition:
+    $a and #a > 1 and any of ($b*)
+}
+
+rule bar {
+  strings:
+    $a = "bar"
+  condition:
+    foo and @a[1] == 0
+}
+`
+
+func TestServer(t *testing.T) {
+	c := newClient(t)
+
+	var init initializeResult
+	require.Nil(t, c.call("initialize", map[string]interface{}{}, &init))
+	assert.Equal(t, "yara-lsp", init.ServerInfo.Name)
+	assert.True(t, init.Capabilities.RenameProvider)
+	c.notify("initialized", map[string]interface{}{})
+
+	// A document with an error produces a diagnostic in the right line.
+	c.notify("textDocument/didOpen", didOpenTextDocumentParams{
+		TextDocument: textDocumentItem{
+			URI:  testURI,
+			Text: "rule foo {\n  condition:\n    $a }\n",
+		},
+	})
+	diags := c.diagnostics()
+	require.Len(t, diags.Diagnostics, 1)
+	assert.Equal(t, textRange{Start: position{Line: 2, Character: 4}, End: position{Line: 2, Character: 6}}, diags.Diagnostics[0].Range)
+	assert.Equal(t, "E0023", diags.Diagnostics[0].Code)
+	assert.Contains(t, diags.Diagnostics[0].Message, "undefined string identifier")
+
+	// Replacing the whole document fixes the error.
+	c.notify("textDocument/didChange", didChangeTextDocumentParams{
+		TextDocument:   textDocumentIdentifier{URI: testURI},
+		ContentChanges: []textDocumentContentChangeEvent{{Text: testSource}},
+	})
+	assert.Empty(t, c.diagnostics().Diagnostics)
+
+	// An incremental change that introduces an error and another that
+	// removes it.
+	r := span(8, 4, 6)
+	c.notify("textDocument/didChange", didChangeTextDocumentParams{
+		TextDocument:   textDocumentIdentifier{URI: testURI},
+		ContentChanges: []textDocumentContentChangeEvent{{Range: &r, Text: "$x"}},
+	})
+	assert.Len(t, c.diagnostics().Diagnostics, 1)
+	c.notify("textDocument/didChange", didChangeTextDocumentParams{
+		TextDocument:   textDocumentIdentifier{URI: testURI},
+		ContentChanges: []textDocumentContentChangeEvent{{Range: &r, Text: "$a"}},
+	})
+	assert.Empty(t, c.diagnostics().Diagnostics)
+
+	var symbols []documentSymbol
+	require.Nil(t, c.call("textDocument/documentSymbol",
+		documentSymbolParams{TextDocument: textDocumentIdentifier{URI: testURI}}, &symbols))
+	require.Len(t, symbols, 2)
+	assert.Equal(t, "foo", symbols[0].Name)
+	assert.Equal(t, span(2, 5, 8), symbols[0].SelectionRange)
+	assert.Equal(t, 2, symbols[0].Range.Start.Line)
+	assert.Equal(t, 9, symbols[0].Range.End.Line)
+	require.Len(t, symbols[0].Children, 3)
+	assert.Equal(t, "$b1", symbols[0].Children[1].Name)
+	assert.Equal(t, "bar", symbols[1].Name)
+	require.Len(t, symbols[1].Children, 1)
+
+	// Definition of $a and #a in the first rule, and of @a in the second.
+	var locations []location
+	require.Nil(t, c.call("textDocument/definition", at(8, 5), &locations))
+	assert.Equal(t, []location{{URI: testURI, Range: span(4, 4, 6)}}, locations)
+	require.Nil(t, c.call("textDocument/definition", at(8, 12), &locations))
+	assert.Equal(t, []location{{URI: testURI, Range: span(4, 4, 6)}}, locations)
+	require.Nil(t, c.call("textDocument/definition", at(15, 12), &locations))
+	assert.Equal(t, []location{{URI: testURI, Range: span(13, 4, 6)}}, locations)
+
+	// Definition of a rule, and of the strings matched by a wildcard.
+	require.Nil(t, c.call("textDocument/definition", at(15, 5), &locations))
+	assert.Equal(t, []location{{URI: testURI, Range: span(2, 5, 8)}}, locations)
+	require.Nil(t, c.call("textDocument/definition", at(8, 31), &locations))
+	assert.Equal(t, []location{
+		{URI: testURI, Range: span(5, 4, 7)},
+		{URI: testURI, Range: span(6, 4, 7)},
+	}, locations)
+
+	// References to $a in the first rule, which don't include the $a in the
+	// second rule.
+	refs := referenceParams{textDocumentPositionParams: at(4, 5)}
+	refs.Context.IncludeDeclaration = true
+	require.Nil(t, c.call("textDocument/references", refs, &locations))
+	assert.Equal(t, []location{
+		{URI: testURI, Range: span(4, 4, 6)},
+		{URI: testURI, Range: span(8, 4, 6)},
+		{URI: testURI, Range: span(8, 11, 13)},
+	}, locations)
+
+	// References to a rule.
+	refs = referenceParams{textDocumentPositionParams: at(2, 6)}
+	require.Nil(t, c.call("textDocument/references", refs, &locations))
+	assert.Equal(t, []location{{URI: testURI, Range: span(15, 4, 7)}}, locations)
+
+	// Renaming $a from one of its references.
+	var edit workspaceEdit
+	require.Nil(t, c.call("textDocument/rename",
+		renameParams{textDocumentPositionParams: at(8, 12), NewName: "$c"}, &edit))
+	assert.Equal(t, []textEdit{
+		{Range: span(4, 5, 6), NewText: "c"},
+		{Range: span(8, 5, 6), NewText: "c"},
+		{Range: span(8, 12, 13), NewText: "c"},
+	}, edit.Changes[testURI])
+
+	// Renaming a rule.
+	require.Nil(t, c.call("textDocument/rename",
+		renameParams{textDocumentPositionParams: at(15, 4), NewName: "baz"}, &edit))
+	assert.Equal(t, []textEdit{
+		{Range: span(2, 5, 8), NewText: "baz"},
+		{Range: span(15, 4, 7), NewText: "baz"},
+	}, edit.Changes[testURI])
+
+	// Renames that are refused: $b1 is matched by $b*, $a would become
+	// matched by $b*, the new name is already used, or it's a keyword.
+	rerr := c.call("textDocument/rename",
+		renameParams{textDocumentPositionParams: at(5, 5), NewName: "$c"}, nil)
+	require.NotNil(t, rerr)
+	assert.Contains(t, rerr.Message, "meaning of $b*")
+	rerr = c.call("textDocument/rename",
+		renameParams{textDocumentPositionParams: at(4, 5), NewName: "$b3"}, nil)
+	require.NotNil(t, rerr)
+	assert.Contains(t, rerr.Message, "meaning of $b*")
+	rerr = c.call("textDocument/rename",
+		renameParams{textDocumentPositionParams: at(12, 6), NewName: "foo"}, nil)
+	assert.NotNil(t, rerr)
+	rerr = c.call("textDocument/rename",
+		renameParams{textDocumentPositionParams: at(12, 6), NewName: "condition"}, nil)
+	assert.NotNil(t, rerr)
+	rerr = c.call("textDocument/rename",
+		renameParams{textDocumentPositionParams: at(12, 6), NewName: "uint8be"}, nil)
+	assert.NotNil(t, rerr)
+
+	var h hover
+	require.Nil(t, c.call("textDocument/hover", at(8, 5), &h))
+	assert.Equal(t, "```yara\n$a = \"foo\"\n```", h.Contents.Value)
+	require.Nil(t, c.call("textDocument/hover", at(15, 5), &h))
+	assert.Equal(t, "```yara\nrule foo\n```", h.Contents.Value)
+
+	// Completion of rules, modules and keywords.
+	var completions completionList
+	require.Nil(t, c.call("textDocument/completion", at(15, 4), &completions))
+	assert.Contains(t, completions.Items, completionItem{Label: "foo", Kind: completionKindClass})
+	assert.Contains(t, completions.Items, completionItem{Label: "pe", Kind: completionKindModule})
+	assert.Contains(t, completions.Items, completionItem{Label: "filesize", Kind: completionKindKeyword})
+	assert.NotContains(t, completions.Items, completionItem{Label: "bar", Kind: completionKindClass})
+
+	// Completion of module members after typing "pe." in the last rule.
+	r = span(15, 22, 22)
+	c.notify("textDocument/didChange", didChangeTextDocumentParams{
+		TextDocument:   textDocumentIdentifier{URI: testURI},
+		ContentChanges: []textDocumentContentChangeEvent{{Range: &r, Text: " and pe."}},
+	})
+	assert.Len(t, c.diagnostics().Diagnostics, 1)
+	require.Nil(t, c.call("textDocument/completion", at(15, 30), &completions))
+	assert.Contains(t, completions.Items,
+		completionItem{Label: "is_dll", Kind: completionKindFunction, Detail: "function"})
+	assert.Contains(t, completions.Items,
+		completionItem{Label: "sections", Kind: completionKindField, Detail: "structure"})
+
+	// Members of an array of structures.
+	r = span(15, 30, 30)
+	c.notify("textDocument/didChange", didChangeTextDocumentParams{
+		TextDocument:   textDocumentIdentifier{URI: testURI},
+		ContentChanges: []textDocumentContentChangeEvent{{Range: &r, Text: "sections[0].n and"}},
+	})
+	assert.Len(t, c.diagnostics().Diagnostics, 1)
+	require.Nil(t, c.call("textDocument/completion", at(15, 43), &completions))
+	assert.Contains(t, completions.Items, completionItem{Label: "name", Kind: completionKindField})
+
+	// Formatting a document with errors produces no edits, once fixed the
+	// whole document is replaced.
+	rerr = c.call("textDocument/formatting",
+		documentFormattingParams{TextDocument: textDocumentIdentifier{URI: testURI}}, nil)
+	assert.NotNil(t, rerr)
+	c.notify("textDocument/didChange", didChangeTextDocumentParams{
+		TextDocument: textDocumentIdentifier{URI: testURI},
+		ContentChanges: []textDocumentContentChangeEvent{{
+			Text: "rule   foo{condition:true and\n  false}",
+		}},
+	})
+	assert.Empty(t, c.diagnostics().Diagnostics)
+	var edits []textEdit
+	require.Nil(t, c.call("textDocument/formatting",
+		documentFormattingParams{TextDocument: textDocumentIdentifier{URI: testURI}}, &edits))
+	require.Len(t, edits, 1)
+	assert.Equal(t, textRange{End: position{Line: 1, Character: 8}}, edits[0].Range)
+	assert.Equal(t, "\nrule foo {\n  condition:\n    true and false\n}\n", edits[0].NewText)
+
+	// Unknown methods produce an error.
+	rerr = c.call("textDocument/unknown", map[string]interface{}{}, nil)
+	require.NotNil(t, rerr)
+	assert.Equal(t, codeMethodNotFound, rerr.Code)
+
+	c.notify("textDocument/didClose", didCloseTextDocumentParams{
+		TextDocument: textDocumentIdentifier{URI: testURI},
+	})
+	assert.Empty(t, c.diagnostics().Diagnostics)
+
+	require.Nil(t, c.call("shutdown", nil, nil))
+	c.notify("exit", nil)
+	assert.NoError(t, <-c.done)
+}
+
+// readError reads a response with a null ID, which can't be read with
+// readMessage because the ID is not a pointer to a JSON value, and returns
+// its error.
+func (c *client) readError() string {
+	header, err := textproto.NewReader(c.in).ReadMIMEHeader()
+	require.NoError(c.t, err)
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	require.NoError(c.t, err)
+	response := make([]byte, length)
+	_, err = io.ReadFull(c.in, response)
+	require.NoError(c.t, err)
+	var fields map[string]json.RawMessage
+	require.NoError(c.t, json.Unmarshal(response, &fields))
+	assert.Equal(c.t, "null", string(fields["id"]))
+	return string(fields["error"])
+}
+
+func TestParseError(t *testing.T) {
+	c := newClient(t)
+	body := "{not json"
+	_, err := fmt.Fprintf(c.out, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	require.NoError(t, err)
+	assert.Contains(t, c.readError(), fmt.Sprintf(`"code":%d`, codeParseError))
+
+	// The server keeps handling requests after the error.
+	require.Nil(t, c.call("shutdown", nil, nil))
+	c.notify("exit", nil)
+	assert.NoError(t, <-c.done)
+}
+
+func TestInvalidContentLength(t *testing.T) {
+	c := newClient(t)
+	// The body of a message that is too large is discarded.
+	body := strings.Repeat(" ", maxContentLength+1)
+	_, err := fmt.Fprintf(c.out, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	require.NoError(t, err)
+	assert.Contains(t, c.readError(), "message too large")
+	require.Nil(t, c.call("shutdown", nil, nil))
+
+	// Negative lengths are rejected too.
+	_, err = fmt.Fprintf(c.out, "Content-Length: -1\r\n\r\n")
+	require.NoError(t, err)
+	assert.Contains(t, c.readError(), fmt.Sprintf(`"code":%d`, codeInvalidRequest))
+	c.out.Close()
+	assert.NoError(t, <-c.done)
+}
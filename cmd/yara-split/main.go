@@ -0,0 +1,65 @@
+// yara-split splits a YARA ruleset into multiple files that can be compiled
+// on their own, see utils.Split.
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/VirusTotal/gyp"
+	"github.com/VirusTotal/gyp/utils"
+)
+
+// global options
+var opts options
+
+func main() {
+	opts = getopt()
+
+	var in io.Reader = os.Stdin
+	if opts.Infile != "" {
+		yaraFile, err := os.Open(opts.Infile)
+		if err != nil {
+			perror(`Couldn't open YARA file "%s": %s`, opts.Infile, err)
+			os.Exit(2)
+		}
+		defer handleErr(yaraFile.Close)
+		in = yaraFile
+	}
+
+	ruleset, err := gyp.Parse(in)
+	if err != nil {
+		perror(`Couldn't parse YARA ruleset: %s`, err)
+		os.Exit(3)
+	}
+
+	files, err := utils.Split(ruleset, utils.SplitOptions{
+		Group:  opts.Group,
+		Common: opts.Common,
+	})
+	if err != nil {
+		perror(`Couldn't split YARA ruleset: %s`, err)
+		os.Exit(4)
+	}
+
+	if err := os.MkdirAll(opts.Outdir, 0755); err != nil {
+		perror(`Couldn't create output directory "%s": %s`, opts.Outdir, err)
+		os.Exit(5)
+	}
+	for _, file := range files {
+		path := filepath.Join(opts.Outdir, file.Name)
+		f, err := os.Create(path)
+		if err != nil {
+			perror(`Couldn't create output file "%s": %s`, path, err)
+			os.Exit(5)
+		}
+		if err = file.RuleSet.WriteSource(f); err == nil {
+			err = f.Close()
+		}
+		if err != nil {
+			perror(`Couldn't write output file "%s": %s`, path, err)
+			os.Exit(6)
+		}
+	}
+}
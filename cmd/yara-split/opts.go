@@ -0,0 +1,66 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/VirusTotal/gyp/utils"
+)
+
+type options struct {
+	Group  utils.GroupFunc
+	Common string
+	Infile string
+	Outdir string
+}
+
+func getopt() options {
+	var (
+		o  options
+		by string
+	)
+
+	flag.Usage = func() {
+		perror("Usage: %s [options] [file]", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.StringVar(&by, "by", "rule", "How to split the rules: rule, tag, meta=KEY or size=N")
+	flag.StringVar(&o.Common, "common", "", "Move the rules needed by more than one file to this file")
+	flag.StringVar(&o.Outdir, "o", ".", "Output directory")
+
+	flag.Parse()
+
+	switch {
+	case by == "rule":
+		o.Group = utils.ByRule
+	case by == "tag":
+		o.Group = utils.ByTag
+	case strings.HasPrefix(by, "meta="):
+		o.Group = utils.ByMeta(strings.TrimPrefix(by, "meta="))
+	case strings.HasPrefix(by, "size="):
+		n, err := strconv.Atoi(strings.TrimPrefix(by, "size="))
+		if err != nil || n < 1 {
+			perror(`Invalid size "%s"`, strings.TrimPrefix(by, "size="))
+			os.Exit(1)
+		}
+		o.Group = utils.BySize(n)
+	default:
+		perror(`Unknown split mode "%s"`, by)
+		os.Exit(1)
+	}
+
+	// The YARA file is the only positional argument, without it the rules
+	// are read from the standard input.
+	switch n := flag.NArg(); n {
+	case 0:
+	case 1:
+		o.Infile = flag.Args()[0]
+	default:
+		perror("Expected at most 1 input file; found %d", n)
+		os.Exit(1)
+	}
+
+	return o
+}
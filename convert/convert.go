@@ -0,0 +1,358 @@
+/*
+Package convert translates rulesets to and from YAML and TOML documents.
+
+The documents mirror the structure of a ruleset, but conditions, text values,
+hex strings and regular expressions are kept as YARA source code:
+
+	version: 1
+	imports:
+	- pe
+	rules:
+	- identifier: foo
+	  tags: [bar]
+	  meta:
+	  - key: author
+	    value: someone
+	  strings:
+	  - identifier: a
+	    text: foo
+	    wide: true
+	    xor: {min: 1, max: 2}
+	  - identifier: b
+	    hex: 01 ?2 [2-] ( 03 | 04 05 )
+	  condition: '$a and #b > 1'
+
+Converting a ruleset to any of these formats and back produces the same
+ruleset, except for the line numbers, which are not preserved.
+*/
+package convert
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/VirusTotal/gyp"
+	"github.com/VirusTotal/gyp/ast"
+)
+
+// Version is the version of the document format, it is stored in the
+// "version" field of every document and changes whenever the format does.
+const Version = 1
+
+type document struct {
+	Version  int      `yaml:"version" toml:"version"`
+	Imports  []string `yaml:"imports,omitempty" toml:"imports,omitempty"`
+	Includes []string `yaml:"includes,omitempty" toml:"includes,omitempty"`
+	Rules    []*rule  `yaml:"rules" toml:"rules"`
+}
+
+type rule struct {
+	Identifier string       `yaml:"identifier" toml:"identifier"`
+	Global     bool         `yaml:"global,omitempty" toml:"global,omitempty"`
+	Private    bool         `yaml:"private,omitempty" toml:"private,omitempty"`
+	Tags       []string     `yaml:"tags,omitempty" toml:"tags,omitempty"`
+	Meta       []*meta      `yaml:"meta,omitempty" toml:"meta,omitempty"`
+	Strings    []*stringDef `yaml:"strings,omitempty" toml:"strings,omitempty"`
+	Condition  string       `yaml:"condition" toml:"condition"`
+}
+
+type meta struct {
+	Key   string      `yaml:"key" toml:"key"`
+	Value interface{} `yaml:"value" toml:"value"`
+}
+
+// stringDef is a string definition. Exactly one of Text, Hex and Regexp must
+// be set. Text is the value between the quotes, with escape sequences
+// unchanged, Hex is the value between the braces, and Regexp is a regular
+// expression literal including the slashes and modifiers, like /foo/i.
+type stringDef struct {
+	Identifier string          `yaml:"identifier" toml:"identifier"`
+	Text       *string         `yaml:"text,omitempty" toml:"text,omitempty"`
+	Hex        string          `yaml:"hex,omitempty" toml:"hex,omitempty"`
+	Regexp     string          `yaml:"regexp,omitempty" toml:"regexp,omitempty"`
+	ASCII      bool            `yaml:"ascii,omitempty" toml:"ascii,omitempty"`
+	Wide       bool            `yaml:"wide,omitempty" toml:"wide,omitempty"`
+	Nocase     bool            `yaml:"nocase,omitempty" toml:"nocase,omitempty"`
+	Fullword   bool            `yaml:"fullword,omitempty" toml:"fullword,omitempty"`
+	Private    bool            `yaml:"private,omitempty" toml:"private,omitempty"`
+	Base64     *base64Modifier `yaml:"base64,omitempty" toml:"base64"`
+	Base64Wide *base64Modifier `yaml:"base64wide,omitempty" toml:"base64wide"`
+	Xor        *xorModifier    `yaml:"xor,omitempty" toml:"xor"`
+}
+
+type base64Modifier struct {
+	Alphabet string `yaml:"alphabet,omitempty" toml:"alphabet,omitempty"`
+}
+
+type xorModifier struct {
+	Min int32 `yaml:"min" toml:"min"`
+	Max int32 `yaml:"max" toml:"max"`
+}
+
+// newDocument returns the document corresponding to a ruleset.
+func newDocument(rs *ast.RuleSet) (*document, error) {
+	doc := &document{
+		Version:  Version,
+		Imports:  rs.Imports,
+		Includes: rs.Includes,
+		Rules:    make([]*rule, len(rs.Rules)),
+	}
+	for i, r := range rs.Rules {
+		if r.Condition == nil {
+			return nil, &ast.ValidationError{Rule: r.Identifier, Field: "condition", Message: "missing condition"}
+		}
+		var condition strings.Builder
+		if err := r.Condition.WriteSource(&condition); err != nil {
+			return nil, err
+		}
+		doc.Rules[i] = &rule{
+			Identifier: r.Identifier,
+			Global:     r.Global,
+			Private:    r.Private,
+			Tags:       r.Tags,
+			Condition:  condition.String(),
+		}
+		for _, m := range r.Meta {
+			doc.Rules[i].Meta = append(doc.Rules[i].Meta, &meta{Key: m.Key, Value: m.Value})
+		}
+		for _, s := range r.Strings {
+			def, err := newStringDef(s)
+			if err != nil {
+				return nil, err
+			}
+			doc.Rules[i].Strings = append(doc.Rules[i].Strings, def)
+		}
+	}
+	return doc, nil
+}
+
+func newStringDef(s ast.String) (*stringDef, error) {
+	def := &stringDef{Identifier: s.GetIdentifier()}
+	switch v := s.(type) {
+	case *ast.TextString:
+		value := v.Value
+		def.Text = &value
+		def.ASCII = v.ASCII
+		def.Wide = v.Wide
+		def.Nocase = v.Nocase
+		def.Fullword = v.Fullword
+		def.Private = v.Private
+		if v.Base64 {
+			def.Base64 = &base64Modifier{Alphabet: v.Base64Alphabet}
+		}
+		if v.Base64Wide {
+			def.Base64Wide = &base64Modifier{Alphabet: v.Base64Alphabet}
+		}
+		if v.Xor {
+			def.Xor = &xorModifier{Min: v.XorMin, Max: v.XorMax}
+		}
+	case *ast.RegexpString:
+		def.Regexp = v.Regexp.String()
+		def.ASCII = v.ASCII
+		def.Wide = v.Wide
+		def.Nocase = v.Nocase
+		def.Fullword = v.Fullword
+		def.Private = v.Private
+	case *ast.HexString:
+		var tokens strings.Builder
+		if err := v.Tokens.WriteSource(&tokens); err != nil {
+			return nil, err
+		}
+		def.Hex = strings.TrimSpace(tokens.String())
+		def.Private = v.Private
+	default:
+		return nil, fmt.Errorf("unexpected string type %T", s)
+	}
+	return def, nil
+}
+
+// ruleSet returns the ruleset described by the document. The conditions and
+// strings are parsed, and the resulting ruleset is checked for the same
+// errors that YARA would find in it.
+func (doc *document) ruleSet() (*ast.RuleSet, error) {
+	if doc.Version == 0 {
+		return nil, fmt.Errorf(`missing "version" field`)
+	}
+	if doc.Version != Version {
+		return nil, fmt.Errorf("unsupported version: %d", doc.Version)
+	}
+	rs := &ast.RuleSet{
+		Imports:  doc.Imports,
+		Includes: doc.Includes,
+		Rules:    make([]*ast.Rule, len(doc.Rules)),
+	}
+	for i, r := range doc.Rules {
+		rule, err := r.rule()
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %v", r.Identifier, err)
+		}
+		rs.Rules[i] = rule
+	}
+	// The pieces were parsed individually, things like references to
+	// undefined strings or duplicate identifiers can be detected only
+	// by parsing the whole ruleset.
+	var source strings.Builder
+	if err := rs.WriteSource(&source); err != nil {
+		return nil, err
+	}
+	if _, err := gyp.ParseString(source.String()); err != nil {
+		return nil, fmt.Errorf("invalid ruleset: %v", err)
+	}
+	return rs, nil
+}
+
+func (r *rule) rule() (*ast.Rule, error) {
+	if r.Condition == "" {
+		return nil, fmt.Errorf(`missing "condition" field`)
+	}
+	condition, err := gyp.ParseExpression(r.Condition)
+	if err != nil {
+		return nil, fmt.Errorf("invalid condition: %v", err)
+	}
+	rule := &ast.Rule{
+		Identifier: r.Identifier,
+		Global:     r.Global,
+		Private:    r.Private,
+		Tags:       r.Tags,
+		Condition:  condition,
+	}
+	for _, m := range r.Meta {
+		value, err := metaValue(m.Value)
+		if err != nil {
+			return nil, fmt.Errorf("meta %q: %v", m.Key, err)
+		}
+		rule.Meta = append(rule.Meta, &ast.Meta{Key: m.Key, Value: value})
+	}
+	for _, s := range r.Strings {
+		str, err := s.string()
+		if err != nil {
+			return nil, fmt.Errorf("string $%s: %v", s.Identifier, err)
+		}
+		rule.Strings = append(rule.Strings, str)
+	}
+	return rule, nil
+}
+
+// metaValue converts a meta value decoded from YAML or TOML to one of the
+// types used in ast.Meta: int64, bool or string.
+func metaValue(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case int:
+		return int64(v), nil
+	case int64, bool, string:
+		return v, nil
+	case nil:
+		return nil, fmt.Errorf("missing value")
+	}
+	return nil, fmt.Errorf("unsupported value type %T", v)
+}
+
+// string returns the string described by the definition. The definition is
+// written as YARA source and parsed, so that it's validated exactly like the
+// strings in a YARA rule.
+func (s *stringDef) string() (ast.String, error) {
+	var source strings.Builder
+	source.WriteString("$" + s.Identifier + " = ")
+	switch {
+	case s.Text != nil && s.Hex == "" && s.Regexp == "":
+		source.WriteString(`"` + *s.Text + `"`)
+	case s.Text == nil && s.Hex != "" && s.Regexp == "":
+		source.WriteString("{ " + s.Hex + " }")
+	case s.Text == nil && s.Hex == "" && s.Regexp != "":
+		source.WriteString(s.Regexp)
+	default:
+		return nil, fmt.Errorf(`exactly one of "text", "hex" or "regexp" is required`)
+	}
+	for _, m := range []struct {
+		name string
+		set  bool
+	}{
+		{"ascii", s.ASCII},
+		{"wide", s.Wide},
+		{"nocase", s.Nocase},
+		{"fullword", s.Fullword},
+		{"private", s.Private},
+	} {
+		if m.set {
+			source.WriteString(" " + m.name)
+		}
+	}
+	if s.Base64 != nil {
+		source.WriteString(" base64" + s.Base64.source())
+	}
+	if s.Base64Wide != nil {
+		source.WriteString(" base64wide" + s.Base64Wide.source())
+	}
+	if s.Xor != nil {
+		source.WriteString(fmt.Sprintf(" xor(%d-%d)", s.Xor.Min, s.Xor.Max))
+	}
+	str, err := gyp.ParseStringDefinition(source.String())
+	if err != nil {
+		return nil, err
+	}
+	// The text, regexp, hex and alphabet are written as they are, so they
+	// could end prematurely and turn the rest of the field into modifiers,
+	// like a quote in the text or a slash in the regexp. The string is
+	// rejected unless both its value and its modifiers match the definition.
+	switch v := str.(type) {
+	case *ast.TextString:
+		if v.Value != *s.Text {
+			return nil, fmt.Errorf("invalid text: %q", *s.Text)
+		}
+		v.LineNo = 0
+	case *ast.RegexpString:
+		if !sameRegexp(s.Regexp, v.Regexp) {
+			return nil, fmt.Errorf("invalid regexp: %q", s.Regexp)
+		}
+		v.LineNo = 0
+	case *ast.HexString:
+		tokens, err := gyp.ParseHexString("{ " + s.Hex + " }")
+		if err != nil || !reflect.DeepEqual(tokens, v.Tokens) {
+			return nil, fmt.Errorf("invalid hex: %q", s.Hex)
+		}
+		v.LineNo = 0
+	}
+	def, err := newStringDef(str)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range []struct {
+		name     string
+		expected *base64Modifier
+		actual   *base64Modifier
+	}{
+		{"base64", s.Base64, def.Base64},
+		{"base64wide", s.Base64Wide, def.Base64Wide},
+	} {
+		if b.expected != nil && b.actual != nil && b.expected.Alphabet != b.actual.Alphabet {
+			return nil, fmt.Errorf("invalid %s alphabet: %q", b.name, b.expected.Alphabet)
+		}
+	}
+	def.Text, def.Hex, def.Regexp = s.Text, s.Hex, s.Regexp
+	if !reflect.DeepEqual(def, s) {
+		return nil, fmt.Errorf("modifiers don't match the string's fields")
+	}
+	return str, nil
+}
+
+// sameRegexp returns true if source is the source code of the regexp re,
+// allowing its modifiers to be in any order.
+func sameRegexp(source string, re *ast.LiteralRegexp) bool {
+	prefix := "/" + re.Value + "/"
+	if !strings.HasPrefix(source, prefix) {
+		return false
+	}
+	modifiers := []byte(source[len(prefix):])
+	expected := []byte(strings.TrimPrefix(re.String(), prefix))
+	sort.Slice(modifiers, func(i, j int) bool { return modifiers[i] < modifiers[j] })
+	return string(modifiers) == string(expected)
+}
+
+func (b *base64Modifier) source() string {
+	if b.Alphabet == "" {
+		return ""
+	}
+	return `("` + b.Alphabet + `")`
+}
@@ -0,0 +1,32 @@
+package convert
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/BurntSushi/toml"
+	"github.com/VirusTotal/gyp/ast"
+)
+
+// WriteTOML writes the ruleset into the writer w as a TOML document.
+func WriteTOML(w io.Writer, rs *ast.RuleSet) error {
+	doc, err := newDocument(rs)
+	if err != nil {
+		return err
+	}
+	return toml.NewEncoder(w).Encode(doc)
+}
+
+// ReadTOML reads a ruleset from a TOML document. Keys that are not part of
+// the format are reported as errors.
+func ReadTOML(r io.Reader) (*ast.RuleSet, error) {
+	var doc document
+	md, err := toml.NewDecoder(r).Decode(&doc)
+	if err != nil {
+		return nil, err
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return nil, fmt.Errorf("unknown key %q", undecoded[0].String())
+	}
+	return doc.ruleSet()
+}
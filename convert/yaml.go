@@ -0,0 +1,36 @@
+package convert
+
+import (
+	"io"
+	"io/ioutil"
+
+	"github.com/VirusTotal/gyp/ast"
+	"gopkg.in/yaml.v2"
+)
+
+// WriteYAML writes the ruleset into the writer w as a YAML document.
+func WriteYAML(w io.Writer, rs *ast.RuleSet) error {
+	doc, err := newDocument(rs)
+	if err != nil {
+		return err
+	}
+	enc := yaml.NewEncoder(w)
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// ReadYAML reads a ruleset from a YAML document. Fields that are not part of
+// the format are reported as errors.
+func ReadYAML(r io.Reader) (*ast.RuleSet, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var doc document
+	if err := yaml.UnmarshalStrict(data, &doc); err != nil {
+		return nil, err
+	}
+	return doc.ruleSet()
+}
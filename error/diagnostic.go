@@ -0,0 +1,151 @@
+package error
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ANSI escape sequences used by Render in colour mode.
+const (
+	colorReset = "\x1b[0m"
+	colorError = "\x1b[1;31m"
+	colorBold  = "\x1b[1m"
+	colorInfo  = "\x1b[1;34m"
+	colorHint  = "\x1b[1;36m"
+)
+
+// RenderOptions contains the options for Render.
+type RenderOptions struct {
+	// Name of the file shown in the diagnostic. If empty "<input>" is used.
+	Filename string
+	// If true the diagnostic is coloured with ANSI escape sequences.
+	Color bool
+}
+
+// Render writes a diagnostic for err into w, showing the line in source where
+// the error occurred with the error's range underlined, like:
+//
+//	error[E0023 UndefinedStringIdentifier]: undefined string identifier: $abd
+//	 --> rules.yar:3:14
+//	  |
+//	3 |   condition: $abd
+//	  |              ^^^^
+//	  = hint: did you mean `$abc`?
+//
+// The source must be the one that produced the error. If the error's position
+// is unknown only its line is shown, without underlining.
+func Render(w io.Writer, source []byte, err Error, opts RenderOptions) error {
+	r := renderer{opts: opts, w: w}
+	filename := opts.Filename
+	if filename == "" {
+		filename = "<input>"
+	}
+
+	// Find the line where the error starts, and the column within the line in
+	// bytes, as in Error.Column.
+	lineno, column := err.Line, 0
+	var lineStart int
+	if err.EndPos > 0 && err.StartPos <= len(source) {
+		lineStart = bytes.LastIndexByte(source[:err.StartPos], '\n') + 1
+		lineno = bytes.Count(source[:lineStart], []byte("\n")) + 1
+		column = err.StartPos - lineStart + 1
+	} else if lineno > 0 {
+		lineStart = -1
+		for i, n := 0, 1; i <= len(source); i++ {
+			if n == lineno {
+				lineStart = i
+				break
+			}
+			if i < len(source) && source[i] == '\n' {
+				n++
+			}
+		}
+	}
+
+	r.printf(colorError, "error[%s]", err.Code)
+	r.printf(colorBold, ": %s\n", err.Message)
+	location := filename
+	if lineno > 0 {
+		location += ":" + strconv.Itoa(lineno)
+	}
+	if column > 0 {
+		location += ":" + strconv.Itoa(column)
+	}
+
+	// The gutter is as wide as the line number.
+	gutter := strings.Repeat(" ", len(strconv.Itoa(lineno)))
+	r.printf(colorInfo, "%s--> ", gutter)
+	r.printf("", "%s\n", location)
+
+	if lineno > 0 && lineStart >= 0 {
+		lineEnd := bytes.IndexByte(source[lineStart:], '\n')
+		if lineEnd < 0 {
+			lineEnd = len(source)
+		} else {
+			lineEnd += lineStart
+		}
+		line := strings.TrimSuffix(string(source[lineStart:lineEnd]), "\r")
+		r.printf(colorInfo, "%s |\n", gutter)
+		r.printf(colorInfo, "%d | ", lineno)
+		r.printf("", "%s\n", line)
+		if column > 0 {
+			// Underline the part of the range that is within the line, or put
+			// a single caret if the range is empty. Tabs before the range are
+			// preserved so that carets are aligned with the text.
+			start := err.StartPos - lineStart
+			end := err.EndPos - lineStart
+			if start > len(line) {
+				start = len(line)
+			}
+			if end > len(line) {
+				end = len(line)
+			}
+			width := 1
+			if end > start {
+				width = utf8.RuneCountInString(line[start:end])
+			}
+			var padding strings.Builder
+			for _, c := range line[:start] {
+				if c == '\t' {
+					padding.WriteRune('\t')
+				} else {
+					padding.WriteRune(' ')
+				}
+			}
+			r.printf(colorInfo, "%s | ", gutter)
+			r.printf("", "%s", padding.String())
+			r.printf(colorError, "%s\n", strings.Repeat("^", width))
+		}
+	}
+	if err.Hint != "" {
+		r.printf(colorInfo, "%s = ", gutter)
+		r.printf(colorHint, "hint")
+		r.printf("", ": %s\n", err.Hint)
+	}
+	return r.err
+}
+
+// renderer writes text into a writer, optionally coloured. After the first
+// error writing the text is ignored.
+type renderer struct {
+	opts RenderOptions
+	w    io.Writer
+	err  error
+}
+
+func (r *renderer) printf(color, format string, a ...interface{}) {
+	if r.err != nil {
+		return
+	}
+	s := fmt.Sprintf(format, a...)
+	if r.opts.Color && color != "" {
+		// Keep the line breaks out of the coloured text.
+		trimmed := strings.TrimRight(s, "\n")
+		s = color + trimmed + colorReset + s[len(trimmed):]
+	}
+	_, r.err = io.WriteString(r.w, s)
+}
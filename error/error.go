@@ -4,43 +4,146 @@ import (
 	"fmt"
 )
 
+// Code identifies the type of an error. Codes can be used as targets for
+// errors.Is, as in errors.Is(err, UndefinedStringIdentifierError).
 type Code int
 
+// The numeric values of the codes are stable, new codes are added at the end
+// with the next unused number and existing numbers are never reused.
 const (
-	_                 = iota
-	UnknownError Code = iota
-	LexicalError
-	DuplicateRuleError
-	DuplicateTagError
-	DuplicateStringError
-	DuplicateModifierError
-	UnterminatedStringError
-	IllegalEscapeSequenceError
-	InvalidRegexModifierError
-	UnterminatedRegexError
-	InvalidJumpLengthError
-	JumpTooLargeInsideAlternationError
-	NegativeJumpError
-	InvalidJumpRangeError
-	UnboundedJumpInsideAlternationError
-	InvalidCharInHexStringError
-	NumberConversionError
-	IntegerOverflowError
-	InvalidStringModifierError
-	UnevenNumberOfDigitsError
-	InvalidAsciiError
-	InvalidUTF8Error
-	UndefinedStringIdentifierError
-	UndefinedRuleIdentifierError
-	InvalidValueError
+	UnknownError                        Code = 1
+	LexicalError                        Code = 2
+	DuplicateRuleError                  Code = 3
+	DuplicateTagError                   Code = 4
+	DuplicateStringError                Code = 5
+	DuplicateModifierError              Code = 6
+	UnterminatedStringError             Code = 7
+	IllegalEscapeSequenceError          Code = 8
+	InvalidRegexModifierError           Code = 9
+	UnterminatedRegexError              Code = 10
+	InvalidJumpLengthError              Code = 11
+	JumpTooLargeInsideAlternationError  Code = 12
+	NegativeJumpError                   Code = 13
+	InvalidJumpRangeError               Code = 14
+	UnboundedJumpInsideAlternationError Code = 15
+	InvalidCharInHexStringError         Code = 16
+	NumberConversionError               Code = 17
+	IntegerOverflowError                Code = 18
+	InvalidStringModifierError          Code = 19
+	UnevenNumberOfDigitsError           Code = 20
+	InvalidAsciiError                   Code = 21
+	InvalidUTF8Error                    Code = 22
+	UndefinedStringIdentifierError      Code = 23
+	UndefinedRuleIdentifierError        Code = 24
+	InvalidValueError                   Code = 25
+	InputTooLargeError                  Code = 26
+	NestingTooDeepError                 Code = 27
+	TooManyRulesError                   Code = 28
+	TooManyStringsError                 Code = 29
+	TooManyHexTokensError               Code = 30
+	TooManyNodesError                   Code = 31
+	JumpTooLargeError                   Code = 32
+	LoopNestingTooDeepError             Code = 33
+	TooManyAlternativesError            Code = 34
+	IdentifierTooLongError              Code = 35
 )
 
+var codeNames = map[Code]string{
+	UnknownError:                        "Unknown",
+	LexicalError:                        "Lexical",
+	DuplicateRuleError:                  "DuplicateRule",
+	DuplicateTagError:                   "DuplicateTag",
+	DuplicateStringError:                "DuplicateString",
+	DuplicateModifierError:              "DuplicateModifier",
+	UnterminatedStringError:             "UnterminatedString",
+	IllegalEscapeSequenceError:          "IllegalEscapeSequence",
+	InvalidRegexModifierError:           "InvalidRegexModifier",
+	UnterminatedRegexError:              "UnterminatedRegex",
+	InvalidJumpLengthError:              "InvalidJumpLength",
+	JumpTooLargeInsideAlternationError:  "JumpTooLargeInsideAlternation",
+	NegativeJumpError:                   "NegativeJump",
+	InvalidJumpRangeError:               "InvalidJumpRange",
+	UnboundedJumpInsideAlternationError: "UnboundedJumpInsideAlternation",
+	InvalidCharInHexStringError:         "InvalidCharInHexString",
+	NumberConversionError:               "NumberConversion",
+	IntegerOverflowError:                "IntegerOverflow",
+	InvalidStringModifierError:          "InvalidStringModifier",
+	UnevenNumberOfDigitsError:           "UnevenNumberOfDigits",
+	InvalidAsciiError:                   "InvalidAscii",
+	InvalidUTF8Error:                    "InvalidUTF8",
+	UndefinedStringIdentifierError:      "UndefinedStringIdentifier",
+	UndefinedRuleIdentifierError:        "UndefinedRuleIdentifier",
+	InvalidValueError:                   "InvalidValue",
+	InputTooLargeError:                  "InputTooLarge",
+	NestingTooDeepError:                 "NestingTooDeep",
+	TooManyRulesError:                   "TooManyRules",
+	TooManyStringsError:                 "TooManyStrings",
+	TooManyHexTokensError:               "TooManyHexTokens",
+	TooManyNodesError:                   "TooManyNodes",
+	JumpTooLargeError:                   "JumpTooLarge",
+	LoopNestingTooDeepError:             "LoopNestingTooDeep",
+	TooManyAlternativesError:            "TooManyAlternatives",
+	IdentifierTooLongError:              "IdentifierTooLong",
+}
+
+// Name returns the name of the error code, like "UndefinedRuleIdentifier".
+func (c Code) Name() string {
+	if name, ok := codeNames[c]; ok {
+		return name
+	}
+	return "Unknown"
+}
+
+// ID returns the identifier of the error code, like "E0024".
+func (c Code) ID() string {
+	return fmt.Sprintf("E%04d", int(c))
+}
+
+// String returns the identifier and name of the error code, like
+// "E0024 UndefinedRuleIdentifier".
+func (c Code) String() string {
+	return c.ID() + " " + c.Name()
+}
+
+// Error makes Code implement the error interface, which allows using codes as
+// targets for errors.Is.
+func (c Code) Error() string {
+	return c.String()
+}
+
 type Error struct {
 	Code
 	Message string
 	Line    int
+	// Column where the error starts, in bytes and starting at 1, or zero if
+	// unknown.
+	Column int
+	// Position within the source code where the error starts (inclusive) and
+	// ends (exclusive). EndPos is zero if the position is unknown.
+	StartPos int
+	EndPos   int
+	// Identifier of the rule where the error occurred, if any.
+	Rule string
+	// Optional suggestion for fixing the error.
+	Hint string
+	// Error that caused this one, if any. For instance, errors in hex strings
+	// are located at the hex string within the source code, and wrap the
+	// error returned by the hex string parser, which is located within the
+	// hex string.
+	Err error
 }
 
 func (e Error) Error() string {
 	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
 }
+
+// Unwrap returns the error that caused this one, or nil.
+func (e Error) Unwrap() error {
+	return e.Err
+}
+
+// Is returns true if target is the error's code.
+func (e Error) Is(target error) bool {
+	c, ok := target.(Code)
+	return ok && c == e.Code
+}
@@ -0,0 +1,152 @@
+package gyp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"sync"
+
+	"github.com/VirusTotal/gyp/ast"
+	"github.com/VirusTotal/gyp/parser"
+)
+
+// ParseFilesOptions contains the options for ParseFiles.
+type ParseFilesOptions struct {
+	// Maximum number of files parsed concurrently. If zero, the number of
+	// CPUs is used.
+	Workers int
+	// If true, the rules from all the files are merged into a single
+	// ruleset. See ParseFilesResult.RuleSet.
+	Merge bool
+	// Limits enforced while parsing each file.
+	Limits parser.Limits
+}
+
+// FileResult is the result of parsing one of the files passed to ParseFiles.
+type FileResult struct {
+	Path    string
+	RuleSet *ast.RuleSet
+	Err     error
+}
+
+// ParseFilesResult is the result returned by ParseFiles.
+type ParseFilesResult struct {
+	// Results for each file, in the same order in which the files were
+	// passed to ParseFiles.
+	Files []FileResult
+	// Rules from all the files that were parsed without errors, with the
+	// imports and includes from all of them. Only set if the Merge option
+	// was used.
+	RuleSet *ast.RuleSet
+}
+
+// ParseFiles parses the YARA rules in the given files, using a bounded pool
+// of goroutines for parsing several files concurrently. Errors found while
+// reading or parsing a file are reported in the corresponding FileResult
+// instead of being returned. The returned error is not nil only if ctx is
+// done before all the files are parsed, or if the files are being merged and
+// some rule is defined in more than one of them.
+func ParseFiles(ctx context.Context, paths []string, opts ParseFilesOptions) (*ParseFilesResult, error) {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	result := &ParseFilesResult{Files: make([]FileResult, len(paths))}
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Each worker writes only the results for the indexes it
+			// receives, so there's no need to synchronize the writes.
+			for i := range indexes {
+				result.Files[i] = parseFile(ctx, paths[i], opts.Limits)
+			}
+		}()
+	}
+loop:
+	for i := range paths {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break loop
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if opts.Merge {
+		rs, err := mergeFiles(result.Files)
+		if err != nil {
+			return result, err
+		}
+		result.RuleSet = rs
+	}
+	return result, nil
+}
+
+func parseFile(ctx context.Context, path string, limits parser.Limits) FileResult {
+	result := FileResult{Path: path}
+	f, err := os.Open(path)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	defer f.Close()
+	var rules []*ast.Rule
+	rs, err := ParseStreamWithLimits(ctx, f, limits, func(r *ast.Rule) error {
+		rules = append(rules, r)
+		return nil
+	})
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	rs.Rules = append(rs.Rules, rules...)
+	result.RuleSet = rs
+	return result
+}
+
+// mergeFiles merges the rulesets of the files parsed without errors. Rules
+// with the same identifier in different files are reported as an error.
+func mergeFiles(files []FileResult) (*ast.RuleSet, error) {
+	merged := &ast.RuleSet{
+		Imports: make([]string, 0),
+		Rules:   make([]*ast.Rule, 0),
+	}
+	imports := make(map[string]bool)
+	includes := make(map[string]bool)
+	// Maps rule identifiers to the path of the file where they are defined.
+	rules := make(map[string]string)
+	for _, f := range files {
+		if f.Err != nil {
+			continue
+		}
+		for _, imp := range f.RuleSet.Imports {
+			if !imports[imp] {
+				imports[imp] = true
+				merged.Imports = append(merged.Imports, imp)
+			}
+		}
+		for _, inc := range f.RuleSet.Includes {
+			if !includes[inc] {
+				includes[inc] = true
+				merged.Includes = append(merged.Includes, inc)
+			}
+		}
+		for _, r := range f.RuleSet.Rules {
+			if path, ok := rules[r.Identifier]; ok {
+				return nil, fmt.Errorf(
+					`duplicate rule "%s" in "%s" and "%s"`, r.Identifier, path, f.Path)
+			}
+			rules[r.Identifier] = f.Path
+			merged.Rules = append(merged.Rules, r)
+		}
+	}
+	return merged, nil
+}
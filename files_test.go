@@ -0,0 +1,120 @@
+package gyp
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFiles writes each source into a file in a temporary directory and
+// returns the directory and the paths of the files.
+func writeFiles(t *testing.T, sources ...string) (string, []string) {
+	dir, err := ioutil.TempDir("", "gyp")
+	require.NoError(t, err)
+	paths := make([]string, len(sources))
+	for i, source := range sources {
+		paths[i] = filepath.Join(dir, fmt.Sprintf("%d.yar", i))
+		require.NoError(t, ioutil.WriteFile(paths[i], []byte(source), 0644))
+	}
+	return dir, paths
+}
+
+func TestParseFiles(t *testing.T) {
+	var sources []string
+	for i := 0; i < 100; i++ {
+		sources = append(sources, fmt.Sprintf(`
+import "pe"
+import "math"
+rule rule_%d_a { strings: $a = { 01 02 [2-4] 03 } condition: $a and pe.is_dll() }
+rule rule_%d_b { condition: rule_%d_a and math.entropy(0, filesize) > 7 }`, i, i, i))
+	}
+	sources = append(sources, "rule invalid { condition: $a }")
+	dir, paths := writeFiles(t, sources...)
+	defer os.RemoveAll(dir)
+	paths = append(paths, filepath.Join(dir, "missing.yar"))
+
+	result, err := ParseFiles(context.Background(), paths, ParseFilesOptions{Workers: 4, Merge: true})
+	require.NoError(t, err)
+	require.Len(t, result.Files, 102)
+	for i, f := range result.Files[:100] {
+		assert.Equal(t, paths[i], f.Path)
+		assert.NoError(t, f.Err)
+		assert.Equal(t, []string{"pe", "math"}, f.RuleSet.Imports)
+		assert.Equal(t, fmt.Sprintf("rule_%d_a", i), f.RuleSet.Rules[0].Identifier)
+		assert.Equal(t, fmt.Sprintf("rule_%d_b", i), f.RuleSet.Rules[1].Identifier)
+	}
+	assert.EqualError(t, result.Files[100].Err, "line 1: undefined string identifier: $a")
+	assert.True(t, os.IsNotExist(result.Files[101].Err))
+
+	assert.Equal(t, []string{"pe", "math"}, result.RuleSet.Imports)
+	assert.Len(t, result.RuleSet.Rules, 200)
+	var b strings.Builder
+	assert.NoError(t, result.RuleSet.WriteSource(&b))
+	_, err = ParseString(b.String())
+	assert.NoError(t, err)
+}
+
+func TestParseFilesDuplicateRule(t *testing.T) {
+	dir, paths := writeFiles(t,
+		"rule foo { condition: true }",
+		"rule bar { condition: true }",
+		"rule foo { condition: false }")
+	defer os.RemoveAll(dir)
+
+	// Duplicates are fine if the files are not merged.
+	result, err := ParseFiles(context.Background(), paths, ParseFilesOptions{})
+	require.NoError(t, err)
+	assert.Nil(t, result.RuleSet)
+
+	result, err = ParseFiles(context.Background(), paths, ParseFilesOptions{Merge: true})
+	assert.EqualError(t, err, fmt.Sprintf(`duplicate rule "foo" in "%s" and "%s"`, paths[0], paths[2]))
+	assert.Len(t, result.Files, 3)
+	assert.Nil(t, result.RuleSet)
+}
+
+func TestParseFilesCancel(t *testing.T) {
+	dir, paths := writeFiles(t, "rule foo { condition: true }")
+	defer os.RemoveAll(dir)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := ParseFiles(ctx, paths, ParseFilesOptions{})
+	assert.Equal(t, context.Canceled, err)
+}
+
+// TestConcurrentParsing parses from many goroutines at once, run it with
+// -race for detecting any state shared by the parsers.
+func TestConcurrentParsing(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				rs, err := ParseString(`
+rule foo {
+  strings:
+    $a = "foo" wide xor(1-2)
+    $b = { 01 ?2 [2-] ( 03 | 04 05 ) }
+    $c = /ba+r/is
+  condition:
+    $a at 0x10 and #b > 1KB and $c
+}`)
+				assert.NoError(t, err)
+				assert.Equal(t, "foo", rs.Rules[0].Identifier)
+				_, err = ParseHexString("{ 01 02 [2-4] ( 03 | 04 ) }")
+				assert.NoError(t, err)
+				_, err = ParseRule("rule bar { condition: $a }")
+				assert.Error(t, err)
+			}
+		}()
+	}
+	wg.Wait()
+}
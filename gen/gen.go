@@ -0,0 +1,809 @@
+/*
+Package gen generates random rulesets, which are useful for testing code that
+works with them:
+
+	g := gen.New(seed)
+	ruleset := g.RuleSet()
+
+The generated rulesets are syntactically valid, and use every type of node in
+the AST, all the operators, and all the modifiers of the strings, in any of
+the combinations accepted by YARA. Generators created with the same seed
+produce the same rulesets.
+
+The rulesets are exactly what the parser produces for the source code written
+by WriteSource, except for the line numbers, which are zero. That means that
+they contain a Group wherever WriteSource writes parentheses, and chains of
+operations with the same operator are a single Operation.
+*/
+package gen
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/VirusTotal/gyp/ast"
+)
+
+// Generator generates random rulesets.
+type Generator struct {
+	// MaxRules is the maximum number of rules in a ruleset.
+	MaxRules int
+	// MaxDepth is the maximum depth of conditions and hex strings.
+	MaxDepth int
+	rand     *rand.Rand
+	// Rules generated so far in the current ruleset. Rule identifiers are a
+	// word followed by an underscore and the rule's index, and rule sets
+	// with wildcards use the word as prefix, which can't be used in later
+	// rules.
+	rules     []string
+	ruleWords []string
+	wildcards map[string]bool
+	// Identifiers of the strings in the current rule, excluding the
+	// anonymous ones.
+	strings []string
+	// Number of strings in the current rule, including the anonymous ones.
+	numStrings int
+	// Number of "for ... of" loops enclosing the current expression, in
+	// which anonymous string identifiers can be used.
+	forOfs int
+}
+
+// New returns a generator that produces the rulesets determined by seed.
+func New(seed int64) *Generator {
+	return &Generator{
+		MaxRules: 5,
+		MaxDepth: 4,
+		rand:     rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Words that can't be used as identifiers.
+var keywords = map[string]bool{
+	"all": true, "and": true, "any": true, "ascii": true, "at": true,
+	"base64": true, "base64wide": true, "condition": true, "contains": true,
+	"defined": true, "endswith": true, "entrypoint": true, "false": true,
+	"filesize": true, "for": true, "fullword": true, "global": true,
+	"icontains": true, "iendswith": true, "iequals": true, "import": true,
+	"in": true, "include": true, "istartswith": true, "matches": true,
+	"meta": true, "nocase": true, "none": true, "not": true, "of": true,
+	"or": true, "private": true, "rule": true, "startswith": true,
+	"strings": true, "them": true, "true": true, "wide": true, "with": true,
+	"xor": true,
+}
+
+var syllables = []string{
+	"ba", "ce", "di", "fo", "gu", "ka", "le", "mi", "no", "pu", "ra", "se",
+	"ti", "vo", "xu", "ze",
+}
+
+var modules = []string{
+	"console", "cuckoo", "dotnet", "elf", "hash", "magic", "math", "pe",
+	"string", "time",
+}
+
+var integerFunctions = []string{
+	"int8", "int16", "int32", "int8be", "int16be", "int32be",
+	"uint8", "uint16", "uint32", "uint8be", "uint16be", "uint32be",
+}
+
+// Operators whose operands are boolean expressions.
+var booleanOperators = []ast.OperatorType{ast.OpAnd, ast.OpOr}
+
+// Operators that compare two primary expressions, except for "matches",
+// whose right operand must be a regular expression.
+var comparisonOperators = []ast.OperatorType{
+	ast.OpEqual, ast.OpNotEqual, ast.OpLessThan, ast.OpGreaterThan,
+	ast.OpLessOrEqual, ast.OpGreaterOrEqual, ast.OpContains, ast.OpIContains,
+	ast.OpStartsWith, ast.OpIStartsWith, ast.OpEndsWith, ast.OpIEndsWith,
+	ast.OpIEquals,
+}
+
+// Arithmetic and bitwise operators, which produce primary expressions.
+var arithmeticOperators = []ast.OperatorType{
+	ast.OpBitOr, ast.OpBitXor, ast.OpBitAnd, ast.OpShiftLeft,
+	ast.OpShiftRight, ast.OpAdd, ast.OpSub, ast.OpMul, ast.OpDiv, ast.OpMod,
+}
+
+// Characters in text strings, before escaping them.
+const textChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789 .,;:-_()[]{}*/\n\r\t\"\\\x00\x01\x7f\x80\xff"
+
+// Characters in base64 alphabets.
+const base64Chars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+// Pieces of regular expressions.
+var regexpAtoms = []string{
+	"foo", "bar", "a", ".", `\d`, `\w`, `\/`, `\.`, "[0-9a-f]", "[^x]",
+	"(foo|bar)", "(a|b|c)",
+}
+
+var regexpQuantifiers = []string{"", "", "+", "*", "?", "+?", "{2}", "{1,3}"}
+
+// chance returns true with a probability of 1/n.
+func (g *Generator) chance(n int) bool {
+	return g.rand.Intn(n) == 0
+}
+
+// RuleSet returns a random ruleset.
+func (g *Generator) RuleSet() *ast.RuleSet {
+	g.rules = g.rules[:0]
+	g.ruleWords = g.ruleWords[:0]
+	g.wildcards = make(map[string]bool)
+	rs := &ast.RuleSet{Imports: []string{}}
+	for _, i := range g.rand.Perm(len(modules))[:g.rand.Intn(3)] {
+		rs.Imports = append(rs.Imports, modules[i])
+	}
+	for i := g.rand.Intn(3); i > 0; i-- {
+		rs.Includes = append(rs.Includes, g.identifier()+".yar")
+	}
+	for i := 1 + g.rand.Intn(g.MaxRules); i > 0; i-- {
+		rs.Rules = append(rs.Rules, g.rule())
+	}
+	return rs
+}
+
+// HexTokens returns random tokens for a hex string.
+func (g *Generator) HexTokens() ast.HexTokens {
+	return g.hexTokens(g.MaxDepth, false)
+}
+
+func (g *Generator) rule() *ast.Rule {
+	var word string
+	for word == "" || g.wildcards[word] {
+		word = g.identifier()
+	}
+	r := &ast.Rule{
+		Global:     g.chance(4),
+		Private:    g.chance(4),
+		Identifier: word + "_" + strconv.Itoa(len(g.rules)),
+		Tags:       []string{},
+		Meta:       []*ast.Meta{},
+		Strings:    []ast.String{},
+	}
+	tags := make(map[string]bool)
+	for i := g.rand.Intn(3); i > 0; i-- {
+		if tag := g.identifier(); !tags[tag] {
+			tags[tag] = true
+			r.Tags = append(r.Tags, tag)
+		}
+	}
+	for i := g.rand.Intn(4); i > 0; i-- {
+		r.Meta = append(r.Meta, g.meta())
+	}
+	g.strings = g.strings[:0]
+	g.numStrings = 0
+	for i := g.rand.Intn(5); i > 0; i-- {
+		r.Strings = append(r.Strings, g.string())
+	}
+	r.Condition = g.booleanExpression(g.MaxDepth)
+	g.rules = append(g.rules, r.Identifier)
+	g.ruleWords = append(g.ruleWords, word)
+	return r
+}
+
+// identifier returns a random identifier, which is never a keyword.
+func (g *Generator) identifier() string {
+	for {
+		var b strings.Builder
+		for i := g.rand.Intn(3); i >= 0; i-- {
+			b.WriteString(syllables[g.rand.Intn(len(syllables))])
+		}
+		if g.chance(4) {
+			b.WriteString(strconv.Itoa(g.rand.Intn(10)))
+		}
+		if s := b.String(); !keywords[s] {
+			return s
+		}
+	}
+}
+
+// text returns a random text, escaped as in the source code.
+func (g *Generator) text(minLength int) string {
+	s := make([]byte, minLength+g.rand.Intn(8))
+	for i := range s {
+		s[i] = textChars[g.rand.Intn(len(textChars))]
+	}
+	return ast.Escape(string(s))
+}
+
+func (g *Generator) meta() *ast.Meta {
+	m := &ast.Meta{Key: g.identifier()}
+	switch g.rand.Intn(3) {
+	case 0:
+		m.Value = g.text(0)
+	case 1:
+		v := g.rand.Int63n(1 << 32)
+		if g.chance(3) {
+			v = -v
+		}
+		m.Value = v
+	case 2:
+		m.Value = g.chance(2)
+	}
+	return m
+}
+
+func (g *Generator) string() ast.String {
+	var identifier string
+	if g.chance(5) {
+		// Anonymous string.
+		g.numStrings++
+	} else {
+		for identifier == "" || g.stringDefined(identifier) {
+			identifier = g.identifier()
+		}
+		g.strings = append(g.strings, identifier)
+		g.numStrings++
+	}
+	base := ast.BaseString{Identifier: identifier}
+	switch g.rand.Intn(3) {
+	case 0:
+		return &ast.HexString{
+			BaseString: base,
+			Tokens:     g.HexTokens(),
+			Private:    g.chance(4),
+		}
+	case 1:
+		return &ast.RegexpString{
+			BaseString: base,
+			Regexp:     g.regexp(),
+			ASCII:      g.chance(3),
+			Wide:       g.chance(3),
+			Nocase:     g.chance(3),
+			Fullword:   g.chance(3),
+			Private:    g.chance(4),
+		}
+	}
+	s := &ast.TextString{
+		BaseString: base,
+		ASCII:      g.chance(3),
+		Wide:       g.chance(3),
+		Private:    g.chance(4),
+	}
+	// The "xor" and "base64" modifiers can't be used together, nor with
+	// "nocase", and "base64" can't be used with "fullword" either.
+	switch g.rand.Intn(3) {
+	case 0:
+		s.Xor = true
+		s.Fullword = g.chance(3)
+		switch g.rand.Intn(3) {
+		case 0:
+			s.XorMax = 255
+		case 1:
+			s.XorMin = int32(g.rand.Intn(256))
+			s.XorMax = s.XorMin
+		case 2:
+			s.XorMin = int32(g.rand.Intn(256))
+			s.XorMax = s.XorMin + int32(g.rand.Intn(256-int(s.XorMin)))
+		}
+	case 1:
+		s.Base64 = g.chance(2)
+		s.Base64Wide = !s.Base64 || g.chance(2)
+		if g.chance(2) {
+			alphabet := []byte(base64Chars)
+			g.rand.Shuffle(len(alphabet), func(i, j int) {
+				alphabet[i], alphabet[j] = alphabet[j], alphabet[i]
+			})
+			s.Base64Alphabet = string(alphabet)
+		}
+	case 2:
+		s.Nocase = g.chance(3)
+		s.Fullword = g.chance(3)
+	}
+	// YARA requires at least 3 characters in base64 strings.
+	s.Value = g.text(3)
+	return s
+}
+
+func (g *Generator) stringDefined(identifier string) bool {
+	for _, s := range g.strings {
+		if s == identifier {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Generator) regexp() *ast.LiteralRegexp {
+	var b strings.Builder
+	for i := g.rand.Intn(4); i >= 0; i-- {
+		b.WriteString(regexpAtoms[g.rand.Intn(len(regexpAtoms))])
+		b.WriteString(regexpQuantifiers[g.rand.Intn(len(regexpQuantifiers))])
+	}
+	r := &ast.LiteralRegexp{Value: b.String()}
+	if g.chance(3) {
+		r.Modifiers |= ast.RegexpCaseInsensitive
+	}
+	if g.chance(3) {
+		r.Modifiers |= ast.RegexpDotAll
+	}
+	return r
+}
+
+// hexTokens returns random tokens for a hex string or an alternative in a hex
+// string. Jumps inside alternatives must be bounded.
+func (g *Generator) hexTokens(depth int, insideOr bool) ast.HexTokens {
+	var tokens ast.HexTokens
+	for i := g.rand.Intn(4); i >= 0; i-- {
+		var token ast.HexToken
+		if depth > 0 && g.chance(4) {
+			token = g.hexOr(depth - 1)
+		} else {
+			token = g.hexBytes()
+		}
+		// Tokens can be separated by jumps, but not the first or the last
+		// one. Contiguous bytes must be separated by at least one jump, as
+		// the parser would join them otherwise.
+		if len(tokens) > 0 {
+			_, prevIsBytes := tokens[len(tokens)-1].(*ast.HexBytes)
+			_, isBytes := token.(*ast.HexBytes)
+			jumps := g.rand.Intn(2)
+			if prevIsBytes && isBytes {
+				jumps++
+			}
+			for ; jumps > 0; jumps-- {
+				tokens = append(tokens, g.hexJump(insideOr))
+			}
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens
+}
+
+func (g *Generator) hexBytes() *ast.HexBytes {
+	n := 1 + g.rand.Intn(4)
+	h := &ast.HexBytes{
+		Bytes: make([]byte, n),
+		Masks: make([]byte, n),
+		Nots:  make([]bool, n),
+	}
+	for i := 0; i < n; i++ {
+		switch g.rand.Intn(6) {
+		case 0:
+			h.Masks[i] = 0x00
+		case 1:
+			h.Masks[i] = 0x0F
+		case 2:
+			h.Masks[i] = 0xF0
+		default:
+			h.Masks[i] = 0xFF
+		}
+		h.Bytes[i] = byte(g.rand.Intn(256)) & h.Masks[i]
+		// Fully masked bytes (??) can't be negated.
+		h.Nots[i] = h.Masks[i] != 0x00 && g.chance(5)
+	}
+	return h
+}
+
+func (g *Generator) hexJump(insideOr bool) *ast.HexJump {
+	var start, end int
+	switch g.rand.Intn(5) {
+	case 0:
+		start = 1 + g.rand.Intn(16)
+		end = start
+	case 1:
+		start = g.rand.Intn(16)
+		end = start + 1 + g.rand.Intn(16)
+	case 2:
+		// The zero-length jump [0-0].
+	default:
+		// Unbounded jumps are not allowed inside alternatives.
+		if !insideOr {
+			return &ast.HexJump{Start: g.rand.Intn(16)}
+		}
+		start = g.rand.Intn(16)
+		end = start + g.rand.Intn(16)
+	}
+	return &ast.HexJump{Start: start, End: &end}
+}
+
+func (g *Generator) hexOr(depth int) *ast.HexOr {
+	h := &ast.HexOr{}
+	for i := 1 + g.rand.Intn(2); i >= 0; i-- {
+		h.Alternatives = append(h.Alternatives, g.hexTokens(depth, true))
+	}
+	return h
+}
+
+// precedence returns the precedence of an expression when used as operand of
+// an operator, as WriteSource does.
+func precedence(e ast.Expression) int {
+	switch v := e.(type) {
+	case *ast.Operation:
+		return ast.OpPrecedence[v.Operator]
+	case *ast.Not, *ast.Defined:
+		return ast.OpPrecedence[ast.OpNot]
+	}
+	return ast.OpMaxPrecedence
+}
+
+// operation returns an operation with the given operator and operands, which
+// are enclosed in a Group where WriteSource would enclose them in parentheses.
+// The left-most operand is also grouped when it's an operation with the same
+// operator, as the parser would merge both operations otherwise.
+func operation(operator ast.OperatorType, operands ...ast.Expression) *ast.Operation {
+	for i, operand := range operands {
+		p := precedence(operand)
+		if p < ast.OpPrecedence[operator] || p == ast.OpPrecedence[operator] && i > 0 {
+			operands[i] = &ast.Group{Expression: operand}
+		} else if o, ok := operand.(*ast.Operation); ok && o.Operator == operator {
+			operands[i] = &ast.Group{Expression: operand}
+		}
+	}
+	return &ast.Operation{Operator: operator, Operands: operands}
+}
+
+// booleanExpression returns a random expression of any type, which is what
+// the grammar calls a boolean expression.
+func (g *Generator) booleanExpression(depth int) ast.Expression {
+	if depth <= 0 {
+		switch g.rand.Intn(4) {
+		case 0:
+			return ast.KeywordTrue
+		case 1:
+			return ast.KeywordFalse
+		case 2:
+			if len(g.strings) > 0 {
+				return &ast.StringIdentifier{Identifier: g.stringIdentifier()}
+			}
+		}
+		return g.primaryExpression(0)
+	}
+	for {
+		switch g.rand.Intn(12) {
+		case 0, 1:
+			operator := booleanOperators[g.rand.Intn(len(booleanOperators))]
+			operands := make([]ast.Expression, 2+g.rand.Intn(2))
+			for i := range operands {
+				operands[i] = g.booleanExpression(depth - 1)
+			}
+			return operation(operator, operands...)
+		case 2:
+			operand := g.booleanExpression(depth - 1)
+			if precedence(operand) < ast.OpPrecedence[ast.OpNot] {
+				operand = &ast.Group{Expression: operand}
+			}
+			if g.chance(2) {
+				return &ast.Not{Expression: operand}
+			}
+			return &ast.Defined{Expression: operand}
+		case 3:
+			operator := comparisonOperators[g.rand.Intn(len(comparisonOperators))]
+			return operation(operator,
+				g.primaryExpression(depth-1),
+				g.primaryExpression(depth-1))
+		case 4:
+			return operation(ast.OpMatches, g.primaryExpression(depth-1), g.regexp())
+		case 5:
+			if len(g.strings) > 0 {
+				s := &ast.StringIdentifier{Identifier: g.stringIdentifier()}
+				switch g.rand.Intn(3) {
+				case 0:
+					s.At = g.primaryExpression(depth - 1)
+				case 1:
+					s.In = g.rangeExpression(depth - 1)
+				}
+				return s
+			}
+		case 6:
+			return g.of(depth)
+		case 7:
+			return &ast.ForIn{
+				Quantifier: g.quantifier(depth - 1),
+				Variables:  g.identifiers(),
+				Iterator:   g.iterator(depth - 1),
+				Condition:  g.booleanExpression(depth - 1),
+			}
+		case 8:
+			if g.numStrings > 0 {
+				f := &ast.ForOf{
+					Quantifier: g.quantifier(depth - 1),
+					Strings:    g.stringSet(),
+				}
+				g.forOfs++
+				f.Condition = g.booleanExpression(depth - 1)
+				g.forOfs--
+				return f
+			}
+		case 9:
+			w := &ast.With{}
+			for _, identifier := range g.identifiers() {
+				w.Declarations = append(w.Declarations, &ast.WithDeclaration{
+					Identifier: identifier,
+					Expression: g.primaryExpression(depth - 1),
+				})
+			}
+			w.Condition = g.booleanExpression(depth - 1)
+			return w
+		case 10:
+			return g.primaryExpression(depth)
+		case 11:
+			return &ast.Group{Expression: g.booleanExpression(depth - 1)}
+		}
+	}
+}
+
+// of returns a random "of" expression.
+func (g *Generator) of(depth int) *ast.Of {
+	o := &ast.Of{}
+	for {
+		switch g.rand.Intn(3) {
+		case 0:
+			if g.numStrings > 0 {
+				o.Strings = g.stringSet()
+			}
+		case 1:
+			if len(g.rules) > 0 {
+				o.Rules = g.ruleSet()
+			}
+		case 2:
+			for i := g.rand.Intn(3); i >= 0; i-- {
+				o.TextStrings = append(o.TextStrings, g.text(0))
+			}
+		}
+		if o.Strings != nil || o.Rules != nil || o.TextStrings != nil {
+			break
+		}
+	}
+	// A percentage can be used only with sets of strings or rules, without
+	// "in" or "at".
+	if o.TextStrings == nil && g.chance(4) {
+		var e ast.Expression
+		if g.chance(2) {
+			e = &ast.LiteralInteger{Value: 1 + g.rand.Int63n(100)}
+		} else {
+			e = &ast.Identifier{Identifier: g.identifier()}
+		}
+		o.Quantifier = &ast.Percentage{Expression: e}
+		return o
+	}
+	o.Quantifier = g.quantifier(depth - 1)
+	if o.Strings != nil {
+		switch g.rand.Intn(3) {
+		case 0:
+			o.In = g.rangeExpression(depth - 1)
+		case 1:
+			o.At = g.primaryExpression(depth - 1)
+		}
+	}
+	return o
+}
+
+// stringIdentifier returns the identifier of a string in the current rule,
+// or the anonymous identifier inside "for ... of" loops.
+func (g *Generator) stringIdentifier() string {
+	if g.forOfs > 0 && (len(g.strings) == 0 || g.chance(3)) {
+		return ""
+	}
+	return g.strings[g.rand.Intn(len(g.strings))]
+}
+
+// stringSet returns "them" or an enumeration of the strings in the current
+// rule, which must have some.
+func (g *Generator) stringSet() ast.Node {
+	if g.chance(3) {
+		return ast.KeywordThem
+	}
+	enum := &ast.Enum{}
+	for i := g.rand.Intn(3); i >= 0; i-- {
+		var identifier string
+		if len(g.strings) == 0 || g.chance(4) {
+			identifier = "*"
+		} else {
+			identifier = g.strings[g.rand.Intn(len(g.strings))]
+			if g.chance(3) {
+				identifier = identifier[:g.rand.Intn(len(identifier)+1)] + "*"
+			}
+		}
+		enum.Values = append(enum.Values, &ast.StringIdentifier{Identifier: identifier})
+	}
+	return enum
+}
+
+// ruleSet returns an enumeration of previous rules, which must exist.
+func (g *Generator) ruleSet() ast.Node {
+	enum := &ast.Enum{}
+	for i := g.rand.Intn(3); i >= 0; i-- {
+		j := g.rand.Intn(len(g.rules))
+		if g.chance(3) {
+			g.wildcards[g.ruleWords[j]] = true
+			enum.Values = append(enum.Values, &ast.Identifier{Identifier: g.ruleWords[j] + "_*"})
+		} else {
+			enum.Values = append(enum.Values, &ast.Identifier{Identifier: g.rules[j]})
+		}
+	}
+	return enum
+}
+
+// identifiers returns one or two identifiers, for loop variables and "with"
+// declarations.
+func (g *Generator) identifiers() []string {
+	identifiers := []string{g.identifier()}
+	if g.chance(3) {
+		identifiers = append(identifiers, g.identifier())
+	}
+	return identifiers
+}
+
+// quantifier returns the quantifier for "of" and "for" expressions, except
+// percentages.
+func (g *Generator) quantifier(depth int) ast.Expression {
+	switch g.rand.Intn(6) {
+	case 0:
+		return ast.KeywordAll
+	case 1:
+		return ast.KeywordAny
+	case 2:
+		return ast.KeywordNone
+	case 3:
+		return g.integer()
+	case 4:
+		return g.identifierExpression(depth)
+	}
+	return &ast.Group{Expression: g.primaryExpression(depth)}
+}
+
+// iterator returns the iterator for "for ... in" loops.
+func (g *Generator) iterator(depth int) ast.Node {
+	switch g.rand.Intn(3) {
+	case 0:
+		return g.identifierExpression(depth)
+	case 1:
+		enum := &ast.Enum{}
+		for i := g.rand.Intn(3); i >= 0; i-- {
+			enum.Values = append(enum.Values, g.primaryExpression(depth))
+		}
+		return enum
+	}
+	return g.rangeExpression(depth)
+}
+
+// rangeExpression returns a range whose bounds are not negative literals,
+// and if they are both literal integers, the lower one is less than the upper
+// one.
+func (g *Generator) rangeExpression(depth int) *ast.Range {
+	bound := func() ast.Expression {
+		for {
+			e := g.primaryExpression(depth)
+			if _, ok := e.(*ast.Minus); !ok {
+				return e
+			}
+		}
+	}
+	r := &ast.Range{Start: bound(), End: bound()}
+	if start, ok := r.Start.(*ast.LiteralInteger); ok {
+		if end, ok := r.End.(*ast.LiteralInteger); ok && start.Value >= end.Value {
+			start.Value = end.Value
+			end.Value = start.Value + 1
+			start.Radix, start.Multiplier = 0, 0
+			end.Radix, end.Multiplier = 0, 0
+		}
+	}
+	return r
+}
+
+// primaryExpression returns a random expression of the type that the grammar
+// calls a primary expression, which are those that can be operands of
+// comparisons and arithmetic operators.
+func (g *Generator) primaryExpression(depth int) ast.Expression {
+	if depth <= 0 {
+		return g.primaryLeaf()
+	}
+	switch g.rand.Intn(10) {
+	case 0, 1:
+		operator := arithmeticOperators[g.rand.Intn(len(arithmeticOperators))]
+		operands := make([]ast.Expression, 2+g.rand.Intn(2))
+		for i := range operands {
+			operands[i] = g.primaryExpression(depth - 1)
+		}
+		return operation(operator, operands...)
+	case 2:
+		operand := g.primaryExpression(depth - 1)
+		if _, ok := operand.(*ast.Operation); ok {
+			operand = &ast.Group{Expression: operand}
+		}
+		if g.chance(2) {
+			return &ast.Minus{Expression: operand}
+		}
+		return &ast.BitwiseNot{Expression: operand}
+	case 3:
+		return &ast.FunctionCall{
+			Callable:  &ast.Identifier{Identifier: integerFunctions[g.rand.Intn(len(integerFunctions))]},
+			Arguments: []ast.Expression{g.primaryExpression(depth - 1)},
+			Builtin:   true,
+		}
+	case 4:
+		return g.identifierExpression(depth - 1)
+	case 5:
+		if len(g.strings) > 0 || g.forOfs > 0 {
+			identifier := g.stringIdentifier()
+			switch g.rand.Intn(3) {
+			case 0:
+				return &ast.StringCount{Identifier: identifier, In: g.rangeExpression(depth - 1)}
+			case 1:
+				return &ast.StringOffset{Identifier: identifier, Index: g.primaryExpression(depth - 1)}
+			case 2:
+				return &ast.StringLength{Identifier: identifier, Index: g.primaryExpression(depth - 1)}
+			}
+		}
+	case 6:
+		return &ast.Group{Expression: g.primaryExpression(depth - 1)}
+	}
+	return g.primaryLeaf()
+}
+
+// primaryLeaf returns a primary expression without sub-expressions.
+func (g *Generator) primaryLeaf() ast.Expression {
+	switch g.rand.Intn(10) {
+	case 0:
+		return ast.KeywordFilesize
+	case 1:
+		return ast.KeywordEntrypoint
+	case 2:
+		return &ast.LiteralFloat{Value: float64(g.rand.Intn(4000)) / 4}
+	case 3:
+		return &ast.LiteralString{Value: g.text(0)}
+	case 4:
+		return g.regexp()
+	case 5:
+		return &ast.Identifier{Identifier: g.identifier()}
+	case 6:
+		if len(g.strings) > 0 || g.forOfs > 0 {
+			identifier := g.stringIdentifier()
+			switch g.rand.Intn(3) {
+			case 0:
+				return &ast.StringCount{Identifier: identifier}
+			case 1:
+				return &ast.StringOffset{Identifier: identifier}
+			case 2:
+				return &ast.StringLength{Identifier: identifier}
+			}
+		}
+	}
+	return g.integer()
+}
+
+// integer returns a literal integer, in any of the forms supported by YARA.
+func (g *Generator) integer() *ast.LiteralInteger {
+	i := &ast.LiteralInteger{Value: g.rand.Int63n(1 << uint(g.rand.Intn(48)+1))}
+	switch g.rand.Intn(5) {
+	case 0:
+		i.Radix = 16
+	case 1:
+		// The scanner rejects 0o0, as it strips all the leading zeros.
+		if i.Value != 0 {
+			i.Radix = 8
+		}
+	case 2:
+		i.Multiplier = 1024
+		i.Value = i.Value % (1 << 20) * i.Multiplier
+	case 3:
+		i.Multiplier = 1048576
+		i.Value = i.Value % (1 << 20) * i.Multiplier
+	}
+	return i
+}
+
+// identifierExpression returns an identifier, optionally followed by member
+// accesses, subscripts and function calls.
+func (g *Generator) identifierExpression(depth int) ast.Expression {
+	var e ast.Expression = &ast.Identifier{Identifier: g.identifier()}
+	if depth <= 0 {
+		return e
+	}
+	for i := g.rand.Intn(3); i > 0; i-- {
+		switch g.rand.Intn(3) {
+		case 0:
+			e = &ast.MemberAccess{Container: e, Member: g.identifier()}
+		case 1:
+			e = &ast.Subscripting{Array: e, Index: g.primaryExpression(depth - 1)}
+		case 2:
+			f := &ast.FunctionCall{Callable: e, Arguments: []ast.Expression{}}
+			for j := g.rand.Intn(3); j > 0; j-- {
+				f.Arguments = append(f.Arguments, g.booleanExpression(depth-1))
+			}
+			e = f
+		}
+	}
+	return e
+}
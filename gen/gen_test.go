@@ -0,0 +1,179 @@
+package gen
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/VirusTotal/gyp"
+	"github.com/VirusTotal/gyp/ast"
+	"github.com/VirusTotal/gyp/parser"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Number of rulesets generated by each test.
+const iterations = 500
+
+func writeSource(t *testing.T, rs *ast.RuleSet) string {
+	var b strings.Builder
+	require.NoError(t, rs.WriteSource(&b))
+	return b.String()
+}
+
+// clearLineNumbers sets the line numbers of the rules and strings in rs to
+// zero, as in the generated rulesets.
+func clearLineNumbers(rs *ast.RuleSet) {
+	for _, r := range rs.Rules {
+		r.LineNo = 0
+		for _, s := range r.Strings {
+			switch v := s.(type) {
+			case *ast.TextString:
+				v.LineNo = 0
+			case *ast.RegexpString:
+				v.LineNo = 0
+			case *ast.HexString:
+				v.LineNo = 0
+			}
+		}
+	}
+}
+
+// visitor is a PreOrderVisitor that calls the function for every node.
+type visitor func(ast.Node)
+
+func (v visitor) PreOrderVisit(n ast.Node) {
+	v(n)
+}
+
+func TestSeed(t *testing.T) {
+	assert.Equal(t, New(1).RuleSet(), New(1).RuleSet())
+	assert.NotEqual(t, New(1).RuleSet(), New(2).RuleSet())
+	g := New(1)
+	assert.NotEqual(t, g.RuleSet(), g.RuleSet())
+}
+
+// The parser produces the generated ruleset from the source written by
+// WriteSource.
+func TestParseWriteSource(t *testing.T) {
+	for seed := int64(0); seed < iterations; seed++ {
+		rs := New(seed).RuleSet()
+		source := writeSource(t, rs)
+		parsed, err := parser.Parse(strings.NewReader(source))
+		require.NoError(t, err, "seed %d:\n%s", seed, source)
+		clearLineNumbers(parsed)
+		require.Equal(t, rs, parsed, "seed %d:\n%s", seed, source)
+	}
+}
+
+// Converting a ruleset to protobuf and back produces the same ruleset, as far
+// as protobufs can represent it. They don't have groups, for instance, which
+// are created only where they are required.
+func TestProto(t *testing.T) {
+	for seed := int64(0); seed < iterations; seed++ {
+		rs := New(seed).RuleSet()
+		pbRuleSet, err := rs.ToProto()
+		require.NoError(t, err, "seed %d:\n%s", seed, writeSource(t, rs))
+		converted := ast.RuleSetFromProto(pbRuleSet).AsProto()
+		require.True(t, proto.Equal(pbRuleSet, converted),
+			"seed %d:\n%s\n%s", seed, writeSource(t, rs), proto.MarshalTextString(converted))
+	}
+}
+
+// The generated rulesets contain all types of nodes, operators and string
+// modifiers, and modifiers are used only in valid combinations.
+// The parser produces the same ruleset from the source written by the
+// protobuf serializer. The rulesets are compared after converting them to
+// protobuf and back, because "(a and b) and c" is parsed as "a and b and c",
+// which is the same condition with a different protobuf.
+func TestSerialize(t *testing.T) {
+	for seed := int64(0); seed < iterations; seed++ {
+		rs := New(seed).RuleSet()
+		pbRuleSet, err := rs.ToProto()
+		require.NoError(t, err, "seed %d:\n%s", seed, writeSource(t, rs))
+		var b strings.Builder
+		require.NoError(t, gyp.NewSerializer(&b).Serialize(pbRuleSet), "seed %d", seed)
+		parsed, err := parser.Parse(strings.NewReader(b.String()))
+		require.NoError(t, err, "seed %d:\n%s", seed, b.String())
+		require.Equal(t,
+			writeSource(t, ast.RuleSetFromProto(pbRuleSet)),
+			writeSource(t, ast.RuleSetFromProto(parsed.AsProto())),
+			"seed %d:\n%s", seed, b.String())
+	}
+}
+
+func TestCoverage(t *testing.T) {
+	nodes := make(map[string]bool)
+	operators := make(map[ast.OperatorType]bool)
+	modifiers := make(map[string]bool)
+	var addHexTokens func(tokens ast.HexTokens)
+	addHexTokens = func(tokens ast.HexTokens) {
+		for _, token := range tokens {
+			nodes[reflect.TypeOf(token).String()] = true
+			if or, ok := token.(*ast.HexOr); ok {
+				for _, a := range or.Alternatives {
+					addHexTokens(a.(ast.HexTokens))
+				}
+			}
+		}
+	}
+	for seed := int64(0); seed < iterations; seed++ {
+		for _, r := range New(seed).RuleSet().Rules {
+			for _, s := range r.Strings {
+				nodes[reflect.TypeOf(s).String()] = true
+				switch v := s.(type) {
+				case *ast.TextString:
+					assert.False(t, v.Xor && (v.Nocase || v.Base64 || v.Base64Wide), v.String())
+					assert.False(t, (v.Base64 || v.Base64Wide) && (v.Nocase || v.Fullword), v.String())
+					for name, set := range map[string]bool{
+						"ascii":           v.ASCII,
+						"wide":            v.Wide,
+						"nocase":          v.Nocase,
+						"fullword":        v.Fullword,
+						"private":         v.Private,
+						"xor":             v.Xor,
+						"xor(n)":          v.Xor && v.XorMin == v.XorMax,
+						"xor(n-m)":        v.Xor && v.XorMin < v.XorMax && v.XorMax != 255,
+						"base64":          v.Base64,
+						"base64wide":      v.Base64Wide,
+						"base64 alphabet": v.Base64Alphabet != "",
+					} {
+						modifiers[name] = modifiers[name] || set
+					}
+				case *ast.HexString:
+					addHexTokens(v.Tokens)
+				}
+			}
+			ast.DepthFirstSearch(r.Condition, visitor(func(n ast.Node) {
+				nodes[reflect.TypeOf(n).String()] = true
+				if o, ok := n.(*ast.Operation); ok {
+					operators[o.Operator] = true
+				}
+			}))
+		}
+	}
+	for _, node := range []string{
+		"ast.Keyword", "*ast.Group", "*ast.LiteralInteger", "*ast.LiteralFloat",
+		"*ast.LiteralString", "*ast.LiteralRegexp", "*ast.Minus", "*ast.Not",
+		"*ast.Defined", "*ast.BitwiseNot", "*ast.Range", "*ast.Enum",
+		"*ast.Identifier", "*ast.StringIdentifier", "*ast.StringCount",
+		"*ast.StringOffset", "*ast.StringLength", "*ast.FunctionCall",
+		"*ast.MemberAccess", "*ast.Subscripting", "*ast.Percentage",
+		"*ast.ForIn", "*ast.ForOf", "*ast.With", "*ast.WithDeclaration",
+		"*ast.Of", "*ast.Operation", "*ast.TextString", "*ast.RegexpString",
+		"*ast.HexString", "*ast.HexBytes", "*ast.HexJump", "*ast.HexOr",
+	} {
+		assert.True(t, nodes[node], node)
+	}
+	for operator := range ast.OpPrecedence {
+		// "not" is a Not node, not an Operation.
+		if operator != ast.OpNot {
+			assert.True(t, operators[operator], operator)
+		}
+	}
+	assert.Len(t, modifiers, 11)
+	for name, set := range modifiers {
+		assert.True(t, set, name)
+	}
+}
@@ -3,6 +3,7 @@ module github.com/VirusTotal/gyp
 go 1.11
 
 require (
+	github.com/BurntSushi/toml v1.2.1
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/golang/protobuf v1.5.0
 	github.com/google/go-cmp v0.5.8
@@ -10,5 +11,5 @@ require (
 	github.com/stretchr/testify v1.4.0
 	google.golang.org/protobuf v1.27.1
 	gopkg.in/check.v1 v1.0.0-20190902080502-41f04d3bba15 // indirect
-	gopkg.in/yaml.v2 v2.2.8 // indirect
+	gopkg.in/yaml.v2 v2.2.8
 )
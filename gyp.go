@@ -14,14 +14,46 @@ Or you can iterate over the rules and inspect their attributes:
 	for _, rule := ruleset.Rules {
 		fmt.Println(rule.Identifier)
 	}
+
+Large sets of rules can be processed one rule at a time, without keeping all
+of them in memory:
+	_, err := gyp.ParseStream(ctx, os.Stdin, func(rule *ast.Rule) error {
+		fmt.Println(rule.Identifier)
+		return nil
+	})
+
+Many files can be parsed concurrently, optionally merging them into a single
+ruleset:
+	result, err := gyp.ParseFiles(ctx, paths, gyp.ParseFilesOptions{Merge: true})
+
+The parsing functions don't share any state, and are safe to call from
+multiple goroutines.
+
+Rules from untrusted sources can be parsed with limits on the resources used,
+like the input size or the nesting depth of conditions:
+	ruleset, err := gyp.ParseWithLimits(r, parser.Limits{MaxInputSize: 1 << 20, MaxNestingDepth: 100})
+
+Individual pieces of a rule can be parsed on their own too:
+	expr, err := gyp.ParseExpression("$a and filesize < 1MB")
+	str, err := gyp.ParseStringDefinition(`$a = "foo" wide`)
+	tokens, err := gyp.ParseHexString("{ 01 02 ?? 03 }")
+	rule, err := gyp.ParseRule("rule test { condition: true }")
+
+For modifying rules without reformatting the whole source code, parse them as
+a concrete syntax tree, which retains the original formatting and comments:
+	cst, err := gyp.ParseCST(os.Stdin)
+	cst.RuleSet.Rules[0].Private = true
+	err = cst.WriteSource(os.Stdout)
 */
 package gyp
 
 import (
 	"bytes"
+	"context"
 	"io"
 
 	"github.com/VirusTotal/gyp/ast"
+	"github.com/VirusTotal/gyp/hex"
 	"github.com/VirusTotal/gyp/parser"
 )
 
@@ -30,7 +62,57 @@ func Parse(input io.Reader) (rs *ast.RuleSet, err error) {
 	return parser.Parse(input)
 }
 
+// ParseStream parses YARA rules from the provided input source, calling fn
+// with each rule as soon as it's parsed instead of keeping all the rules in
+// memory. The returned ruleset contains only the imports and includes. The
+// parsing stops when fn returns an error or ctx is done.
+func ParseStream(ctx context.Context, input io.Reader, fn func(*ast.Rule) error) (*ast.RuleSet, error) {
+	return parser.ParseStream(ctx, input, fn)
+}
+
+// ParseWithLimits is like Parse, but returns an error as soon as any of the
+// given limits is exceeded. Use it for parsing rules from untrusted sources.
+func ParseWithLimits(input io.Reader, limits parser.Limits) (*ast.RuleSet, error) {
+	return parser.ParseWithLimits(input, limits)
+}
+
+// ParseStreamWithLimits is like ParseStream, but returns an error as soon as
+// any of the given limits is exceeded.
+func ParseStreamWithLimits(ctx context.Context, input io.Reader, limits parser.Limits, fn func(*ast.Rule) error) (*ast.RuleSet, error) {
+	return parser.ParseStreamWithLimits(ctx, input, limits, fn)
+}
+
 // ParseString parses a YARA rule from the provided string.
 func ParseString(s string) (*ast.RuleSet, error) {
 	return Parse(bytes.NewBufferString(s))
 }
+
+// ParseCST parses YARA rules from the provided input source into a concrete
+// syntax tree. See parser.CST for details.
+func ParseCST(input io.Reader) (*parser.CST, error) {
+	return parser.ParseCST(input)
+}
+
+// ParseExpression parses a standalone expression, like the condition of a rule.
+// As the expression is not part of any rule, the strings and rules referenced
+// by it are not required to exist.
+func ParseExpression(s string) (ast.Expression, error) {
+	return parser.ParseExpression(bytes.NewBufferString(s))
+}
+
+// ParseStringDefinition parses a single string definition, like the ones
+// found in the "strings" section of a rule. Example: $a = "foo" wide
+func ParseStringDefinition(s string) (ast.String, error) {
+	return parser.ParseStringDefinition(bytes.NewBufferString(s))
+}
+
+// ParseHexString parses a hex string, including the enclosing braces.
+// Example: { 01 02 ?? [2-4] 03 }
+func ParseHexString(s string) (ast.HexTokens, error) {
+	return hex.Parse(bytes.NewBufferString(s))
+}
+
+// ParseRule parses a single rule.
+func ParseRule(s string) (*ast.Rule, error) {
+	return parser.ParseRule(bytes.NewBufferString(s))
+}
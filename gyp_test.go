@@ -1,6 +1,8 @@
 package gyp
 
 import (
+	"context"
+	"errors"
 	"strings"
 	"testing"
 
@@ -439,6 +441,14 @@ rule foo {
   condition:
     $a
 }
+`,
+	`
+rule foo {
+  strings:
+    $a = { 01 ( 02 [0-0] 03 | 04 ) 05 [0-0] 06 [0-3] 07 }
+  condition:
+    $a
+}
 `,
 	`include "foo"
 
@@ -454,6 +464,14 @@ rule foo {
   condition:
     true
 }
+`,
+	`
+rule foo {
+  strings:
+    $a = "foo"
+  condition:
+    ("a") of them and for (-1) of them : (true) and for any i in ((-1)..(-2)) : (true) and #a in ((2)..1) == 1
+}
 `,
 }
 
@@ -527,3 +545,148 @@ func TestProtos(t *testing.T) {
 		}
 	}
 }
+
+func TestParseExpression(t *testing.T) {
+	expr, err := ParseExpression("$a and #b > 2 or any of ($c*) or all of (foo*)")
+	assert.NoError(t, err)
+	var b strings.Builder
+	assert.NoError(t, expr.WriteSource(&b))
+	assert.Equal(t, "$a and #b > 2 or any of ($c*) or all of (foo*)", b.String())
+
+	_, err = ParseExpression("$a and")
+	assert.Error(t, err)
+
+	_, err = ParseExpression("true } rule foo { condition: true")
+	assert.Error(t, err)
+}
+
+func TestParseStringDefinition(t *testing.T) {
+	s, err := ParseStringDefinition(`$a = "foo" wide nocase`)
+	assert.NoError(t, err)
+	assert.Equal(t, &ast.TextString{
+		BaseString: ast.BaseString{Identifier: "a", LineNo: 1},
+		Value:      "foo",
+		Wide:       true,
+		Nocase:     true,
+	}, s)
+
+	s, err = ParseStringDefinition(`$b = /ba+r/is`)
+	assert.NoError(t, err)
+	assert.IsType(t, &ast.RegexpString{}, s)
+
+	_, err = ParseStringDefinition(`$a = "foo" wide wide`)
+	assert.EqualError(t, err, `line 1: duplicate modifier`)
+}
+
+func TestParseHexString(t *testing.T) {
+	tokens, err := ParseHexString("{ 01 02 [2-4] ( 03 | 04 ) }")
+	assert.NoError(t, err)
+	assert.Len(t, tokens, 3)
+	assert.IsType(t, &ast.HexBytes{}, tokens[0])
+	assert.IsType(t, &ast.HexJump{}, tokens[1])
+	assert.IsType(t, &ast.HexOr{}, tokens[2])
+
+	_, err = ParseHexString("{ 01 0 }")
+	assert.Error(t, err)
+}
+
+func TestParseHexStringJumps(t *testing.T) {
+	tokens, err := ParseHexString("{ 01 [20-] 02 [0-0] 03 [4] 04 }")
+	assert.NoError(t, err)
+	zero, four := 0, 4
+	assert.Equal(t, &ast.HexJump{Start: 20}, tokens[1])
+	assert.Equal(t, &ast.HexJump{Start: 0, End: &zero}, tokens[3])
+	assert.Equal(t, &ast.HexJump{Start: 4, End: &four}, tokens[5])
+
+	var b strings.Builder
+	assert.NoError(t, tokens.WriteSource(&b))
+	assert.Equal(t, "01 [20-] 02 [0-0] 03 [4] 04 ", b.String())
+}
+
+func TestParseHexStringMasks(t *testing.T) {
+	tokens, err := ParseHexString("{ 5? ?5 ~5? ~?5 ~55 }")
+	assert.NoError(t, err)
+	assert.Equal(t, ast.HexTokens{
+		&ast.HexBytes{
+			Bytes: []byte{0x50, 0x05, 0x50, 0x05, 0x55},
+			Masks: []byte{0xF0, 0x0F, 0xF0, 0x0F, 0xFF},
+			Nots:  []bool{false, false, true, true, true},
+		},
+	}, tokens)
+}
+
+func TestParseRule(t *testing.T) {
+	rule, err := ParseRule(`
+rule foo {
+  strings:
+    $a = "foo"
+  condition:
+    $a
+}`)
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", rule.Identifier)
+	assert.Equal(t, 2, rule.LineNo)
+
+	// Undefined strings are not allowed in rules.
+	_, err = ParseRule(`rule foo { condition: $a }`)
+	assert.EqualError(t, err, `line 1: undefined string identifier: $a`)
+
+	// Only a single rule is accepted.
+	_, err = ParseRule(`
+rule foo { condition: true }
+rule bar { condition: true }`)
+	assert.Error(t, err)
+}
+
+func TestParseStream(t *testing.T) {
+	source := `
+import "pe"
+rule foo { condition: pe.is_dll() }
+rule bar { condition: foo }
+rule baz { condition: any of (ba*) }`
+	var identifiers []string
+	rs, err := ParseStream(context.Background(), strings.NewReader(source), func(rule *ast.Rule) error {
+		identifiers = append(identifiers, rule.Identifier)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"foo", "bar", "baz"}, identifiers)
+	assert.Equal(t, []string{"pe"}, rs.Imports)
+	assert.Empty(t, rs.Rules)
+
+	// The checks involving several rules work while streaming.
+	for _, test := range []struct {
+		source string
+		err    string
+	}{
+		{source + "\nrule foo { condition: true }", `line 6: duplicate rule "foo"`},
+		{source + "\nrule bar2 { condition: true }", `line 6: rule identifier "bar2" matches previously used wildcard rule set`},
+		{"rule foo { condition: any of (qux*) }", `line 1: undefined rule identifier: qux*`},
+	} {
+		_, err = ParseStream(context.Background(), strings.NewReader(test.source), func(*ast.Rule) error {
+			return nil
+		})
+		assert.EqualError(t, err, test.err)
+	}
+
+	// Errors returned by the callback stop the parsing.
+	stop := errors.New("stop")
+	identifiers = nil
+	_, err = ParseStream(context.Background(), strings.NewReader(source), func(rule *ast.Rule) error {
+		identifiers = append(identifiers, rule.Identifier)
+		return stop
+	})
+	assert.Equal(t, stop, err)
+	assert.Equal(t, []string{"foo"}, identifiers)
+
+	// So does cancelling the context.
+	ctx, cancel := context.WithCancel(context.Background())
+	identifiers = nil
+	_, err = ParseStream(ctx, strings.NewReader(source), func(rule *ast.Rule) error {
+		identifiers = append(identifiers, rule.Identifier)
+		cancel()
+		return nil
+	})
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, []string{"foo"}, identifiers)
+}
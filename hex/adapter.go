@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"sync"
 
 	"github.com/VirusTotal/gyp/ast"
 	gyperror "github.com/VirusTotal/gyp/error"
@@ -16,9 +17,43 @@ func init() {
 	hexErrorVerbose = true
 }
 
+// The buffers allocated by NewScanner take about 300 KB, which is much more
+// than the size of most hex strings, so scanners are reused across calls to
+// Parse. Each scanner taken from the pool is reset to the state of
+// scannerTemplate, except for its buffers.
+var (
+	scannerPool = sync.Pool{
+		New: func() interface{} { return NewScanner() },
+	}
+	scannerTemplate = *NewScanner()
+)
+
+// getScanner returns a scanner for reading from input. The scanner must be
+// returned to the pool with putScanner when it's not needed anymore.
+func getScanner(input io.Reader) *Scanner {
+	s := scannerPool.Get().(*Scanner)
+	chBuf, stateBuf := s.chBuf, s.stateBuf
+	*s = scannerTemplate
+	s.chBuf, s.stateBuf = chBuf, stateBuf
+	s.In = input
+	s.Out = ioutil.Discard
+	return s
+}
+
+func putScanner(s *Scanner) {
+	s.In = nil
+	s.inputFile = nil
+	scannerPool.Put(s)
+}
+
 // Parse parses an hex string in a YARA rule from the provided input source
 func Parse(input io.Reader) (tokens []ast.HexToken, err error) {
+	lexer := lexer{
+		scanner:   getScanner(input),
+		hexTokens: nil,
+	}
 	defer func() {
+		putScanner(lexer.scanner)
 		if r := recover(); r != nil {
 			if yaraError, ok := r.(gyperror.Error); ok {
 				err = yaraError
@@ -31,13 +66,6 @@ func Parse(input io.Reader) (tokens []ast.HexToken, err error) {
 		}
 	}()
 
-	lexer := lexer{
-		scanner:   *NewScanner(),
-		hexTokens: nil,
-	}
-	lexer.scanner.In = input
-	lexer.scanner.Out = ioutil.Discard
-
 	if result := hexParse(&lexer); result != 0 {
 		err = lexer.err
 	}
@@ -47,7 +75,7 @@ func Parse(input io.Reader) (tokens []ast.HexToken, err error) {
 
 // Lexer is an adapter that fits the flexgo lexer ("Scanner") into goyacc
 type lexer struct {
-	scanner   Scanner
+	scanner   *Scanner
 	insideOr  int
 	err       gyperror.Error
 	hexTokens []ast.HexToken
@@ -0,0 +1,36 @@
+package hex
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/VirusTotal/gyp/ast"
+	"github.com/VirusTotal/gyp/gen"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSource(t testing.TB, tokens ast.HexTokens) string {
+	var b strings.Builder
+	b.WriteString("{ ")
+	require.NoError(t, tokens.WriteSource(&b))
+	b.WriteString("}")
+	return b.String()
+}
+
+// Parsing the source written for the tokens of a hex string produces the same
+// tokens.
+func FuzzParse(f *testing.F) {
+	for seed := int64(0); seed < 50; seed++ {
+		f.Add(writeSource(f, gen.New(seed).HexTokens()))
+	}
+	f.Fuzz(func(t *testing.T, input string) {
+		tokens, err := Parse(strings.NewReader(input))
+		if err != nil {
+			return
+		}
+		source := writeSource(t, tokens)
+		parsed, err := Parse(strings.NewReader(source))
+		require.NoError(t, err, "input: %q\nsource: %q", input, source)
+		require.Equal(t, ast.HexTokens(tokens), ast.HexTokens(parsed), "input: %q\nsource: %q", input, source)
+	})
+}
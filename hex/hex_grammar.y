@@ -160,9 +160,10 @@ range
             `jump too large inside alternation: %d`, $2)
         }
 
+        end := $2
         $$ = &ast.HexJump{
           Start: $2,
-          End: $2,
+          End: &end,
         }
       }
     | _LBRACKET_ _NUMBER_ _HYPHEN_ _NUMBER_ _RBRACKET_
@@ -188,9 +189,10 @@ range
             `jump too large inside alternation: %d-%d`, $2, $4)
         }
 
+        end := $4
         $$ = &ast.HexJump{
           Start: $2,
-          End: $4,
+          End: &end,
         }
       }
     | _LBRACKET_ _NUMBER_ _HYPHEN_ _RBRACKET_
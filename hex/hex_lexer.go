@@ -41,7 +41,7 @@ func (s *Scanner) TokenByte(t int, value, mask byte, not bool) YYtype {
 }
 
 func Error(c gyperror.Code, msg string) YYtype {
-  return YYtype{Error: gyperror.Error{c, msg, 0,}}
+  return YYtype{Error: gyperror.Error{Code: c, Message: msg}}
 }
 
 
@@ -536,7 +536,7 @@ case 8:
     // This shouldn't happen.
     panic(fmt.Sprintf("error parsing byte: %s\n", err))
   }
-  return yy.TokenByte(_MASKED_BYTE_, byte(val), byte(0x0F), true);
+  return yy.TokenByte(_MASKED_BYTE_, byte(val), byte(0xF0), true);
 }
 case 9:
 
@@ -69,7 +69,7 @@ const hexEofCode = 1
 const hexErrCode = 2
 const hexInitialStackSize = 16
 
-//line hex/hex_grammar.y:282
+//line hex/hex_grammar.y:284
 
 //line yacctab:1
 var hexExca = [...]int8{
@@ -100,10 +100,10 @@ var hexAct = [...]int8{
 }
 
 var hexPact = [...]int16{
-	18, -1000, 0, 14, -3, 27, -1000, -1000, -1000, -1000,
-	-1000, -1000, -1000, -3, -1000, -1000, 5, -1000, 0, -1000,
-	-1000, 4, 10, -7, -1000, -1000, 13, -1000, -1000, 0,
-	9, -1000, -1000, -1000,
+	18, -32768, 0, 14, -3, 27, -32768, -32768, -32768, -32768,
+	-32768, -32768, -32768, -3, -32768, -32768, 5, -32768, 0, -32768,
+	-32768, 4, 10, -7, -32768, -32768, 13, -32768, -32768, 0,
+	9, -32768, -32768, -32768,
 }
 
 var hexPgo = [...]int8{
@@ -124,7 +124,7 @@ var hexR2 = [...]int8{
 }
 
 var hexChk = [...]int16{
-	-1000, -9, 8, -1, -4, -7, 14, -8, 4, 6,
+	-32768, -9, 8, -1, -4, -7, 14, -8, 4, 6,
 	5, 9, -4, -2, -3, -6, 10, -8, -10, -4,
 	-3, 7, 13, -5, -1, 12, 13, 12, 15, 16,
 	7, 12, -1, 12,
@@ -189,7 +189,7 @@ func hexNewParser() hexParser {
 	return &hexParserImpl{}
 }
 
-const hexFlag = -1000
+const hexFlag = -32768
 
 func hexTokname(c int) string {
 	if c >= 1 && c-1 < len(hexToknames) {
@@ -572,14 +572,15 @@ hexdefault:
 					`jump too large inside alternation: %d`, hexDollar[2].integer)
 			}
 
+			end := hexDollar[2].integer
 			hexVAL.token = &ast.HexJump{
 				Start: hexDollar[2].integer,
-				End:   hexDollar[2].integer,
+				End:   &end,
 			}
 		}
 	case 13:
 		hexDollar = hexS[hexpt-5 : hexpt+1]
-//line hex/hex_grammar.y:169
+//line hex/hex_grammar.y:170
 		{
 			lexer := asLexer(hexlex)
 
@@ -602,14 +603,15 @@ hexdefault:
 					`jump too large inside alternation: %d-%d`, hexDollar[2].integer, hexDollar[4].integer)
 			}
 
+			end := hexDollar[4].integer
 			hexVAL.token = &ast.HexJump{
 				Start: hexDollar[2].integer,
-				End:   hexDollar[4].integer,
+				End:   &end,
 			}
 		}
 	case 14:
 		hexDollar = hexS[hexpt-4 : hexpt+1]
-//line hex/hex_grammar.y:197
+//line hex/hex_grammar.y:199
 		{
 			lexer := asLexer(hexlex)
 
@@ -631,7 +633,7 @@ hexdefault:
 		}
 	case 15:
 		hexDollar = hexS[hexpt-3 : hexpt+1]
-//line hex/hex_grammar.y:217
+//line hex/hex_grammar.y:219
 		{
 			lexer := asLexer(hexlex)
 
@@ -645,7 +647,7 @@ hexdefault:
 		}
 	case 16:
 		hexDollar = hexS[hexpt-1 : hexpt+1]
-//line hex/hex_grammar.y:233
+//line hex/hex_grammar.y:235
 		{
 			hexVAL.hexor = &ast.HexOr{
 				Alternatives: ast.HexTokens{hexDollar[1].tokens},
@@ -653,14 +655,14 @@ hexdefault:
 		}
 	case 17:
 		hexDollar = hexS[hexpt-3 : hexpt+1]
-//line hex/hex_grammar.y:239
+//line hex/hex_grammar.y:241
 		{
 			hexDollar[1].hexor.Alternatives = append(hexDollar[1].hexor.Alternatives, hexDollar[3].tokens)
 			hexVAL.hexor = hexDollar[1].hexor
 		}
 	case 18:
 		hexDollar = hexS[hexpt-1 : hexpt+1]
-//line hex/hex_grammar.y:252
+//line hex/hex_grammar.y:254
 		{
 			hexVAL.bytes = &ast.HexBytes{
 				Bytes: []byte{hexDollar[1].bm.Value},
@@ -670,7 +672,7 @@ hexdefault:
 		}
 	case 19:
 		hexDollar = hexS[hexpt-2 : hexpt+1]
-//line hex/hex_grammar.y:260
+//line hex/hex_grammar.y:262
 		{
 			hexDollar[1].bytes.Bytes = append(hexDollar[1].bytes.Bytes, hexDollar[2].bm.Value)
 			hexDollar[1].bytes.Masks = append(hexDollar[1].bytes.Masks, hexDollar[2].bm.Mask)
@@ -678,19 +680,19 @@ hexdefault:
 		}
 	case 20:
 		hexDollar = hexS[hexpt-1 : hexpt+1]
-//line hex/hex_grammar.y:269
+//line hex/hex_grammar.y:271
 		{
 			hexVAL.bm = hexDollar[1].bm
 		}
 	case 21:
 		hexDollar = hexS[hexpt-1 : hexpt+1]
-//line hex/hex_grammar.y:273
+//line hex/hex_grammar.y:275
 		{
 			hexVAL.bm = hexDollar[1].bm
 		}
 	case 22:
 		hexDollar = hexS[hexpt-1 : hexpt+1]
-//line hex/hex_grammar.y:277
+//line hex/hex_grammar.y:279
 		{
 			hexVAL.bm = hexDollar[1].bm
 		}
@@ -1,18 +1,116 @@
 package parser
 
 import (
+	"context"
 	"fmt"
 	"github.com/VirusTotal/gyp/ast"
 	gyperror "github.com/VirusTotal/gyp/error"
 	"io"
-	"io/ioutil"
+	"sort"
+	"strings"
 )
 
 func init() {
 	yrErrorVerbose = true
 }
 
+// Version identifies the rulesets produced by the parser. It must be increased
+// whenever a change in the parser or in the AST makes the same source code
+// produce a different ruleset, as it's part of the keys of rulesets cached
+// with package cache.
+const Version = 3
+
 func Parse(input io.Reader) (rs *ast.RuleSet, err error) {
+	l, err := parse(input, 0)
+	return l.ruleSet, err
+}
+
+// ParseStream parses YARA rules from the input, calling fn with each rule as
+// soon as it's parsed. The rules are not retained, the returned ruleset
+// contains only the imports and includes. If fn returns an error, or ctx is
+// done, the parsing stops and the error is returned.
+func ParseStream(ctx context.Context, input io.Reader, fn func(*ast.Rule) error) (*ast.RuleSet, error) {
+	return parseStream(ctx, input, Limits{}, fn)
+}
+
+func parseStream(ctx context.Context, input io.Reader, limits Limits, fn func(*ast.Rule) error) (*ast.RuleSet, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	l := newLexer(0)
+	l.limits = limits
+	l.onRule = func(r *ast.Rule) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return fn(r)
+	}
+	if err := parseWith(input, l); err != nil {
+		return nil, err
+	}
+	return l.ruleSet, nil
+}
+
+// ParseExpression parses a standalone boolean expression, like the ones used
+// in rule conditions. As the expression is not part of a rule, references to
+// strings and rules are not checked for existence.
+func ParseExpression(input io.Reader) (ast.Expression, error) {
+	l, err := parse(input, _START_EXPRESSION_)
+	if err != nil {
+		return nil, err
+	}
+	return l.fragment.(ast.Expression), nil
+}
+
+// ParseStringDefinition parses a single string definition, like the ones in
+// the "strings" section of a rule. Example: $a = "foo" wide
+func ParseStringDefinition(input io.Reader) (ast.String, error) {
+	l, err := parse(input, _START_STRING_)
+	if err != nil {
+		return nil, err
+	}
+	return l.fragment.(ast.String), nil
+}
+
+// ParseRule parses a single rule. The input can't contain anything else, not
+// even imports.
+func ParseRule(input io.Reader) (*ast.Rule, error) {
+	l, err := parse(input, _START_RULE_)
+	if err != nil {
+		return nil, err
+	}
+	return l.fragment.(*ast.Rule), nil
+}
+
+// parse runs the parser on the provided input. If start is non-zero, the
+// token with that value is the first one returned by the lexer, which
+// instructs the parser to accept some fragment of YARA source instead of a
+// complete set of rules.
+func parse(input io.Reader, start int) (*lexer, error) {
+	l := newLexer(start)
+	return l, parseWith(input, l)
+}
+
+// newLexer returns a lexer for parsing from scratch. See parse for the
+// meaning of start.
+func newLexer(start int) *lexer {
+	return &lexer{
+		scanner: *NewScanner(),
+		ruleSet: &ast.RuleSet{
+			Imports: make([]string, 0),
+			Rules:   make([]*ast.Rule, 0),
+		},
+		strings: make(map[string]bool),
+		rules: make(map[string]bool),
+		rule_wildcards: make(map[string]bool),
+		start: start,
+		standalone: start == _START_EXPRESSION_,
+	}
+}
+
+// parseWith runs the parser on the provided input using the given lexer,
+// which allows starting the parser with some pre-existing state.
+func parseWith(input io.Reader, l *lexer) (err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			if yaraError, ok := r.(gyperror.Error); ok {
@@ -24,32 +122,94 @@ func Parse(input io.Reader) (rs *ast.RuleSet, err error) {
 				}
 			}
 		}
+		if yaraError, ok := err.(gyperror.Error); ok {
+			yaraError.Rule = l.currentRule
+			if yaraError.EndPos > 0 {
+				line, start := l.lines.lineAt(yaraError.StartPos)
+				yaraError.Line = line
+				yaraError.Column = yaraError.StartPos - start + 1
+			}
+			err = yaraError
+		}
 	}()
 
-	lexer := &lexer{
-		scanner: *NewScanner(),
-		ruleSet: &ast.RuleSet{
-			Imports: make([]string, 0),
-			Rules:   make([]*ast.Rule, 0),
-		},
-		strings: make(map[string]bool),
-		rules: make(map[string]bool),
-		rule_wildcards: make(map[string]bool),
+	l.lines = &lineReader{
+		r:         input,
+		pos:       l.scanner.Context.Pos,
+		firstLine: l.scanner.Lineno,
+		starts:    []int{l.scanner.Context.Pos},
+		maxSize:   l.limits.MaxInputSize,
 	}
-	lexer.scanner.In = input
-	lexer.scanner.Out = ioutil.Discard
+	l.scanner.In = l.lines
 
 	// yrParse is the function automatically generated by goyacc from grammar.y
 	// this function expects an argument that implements the yrLexer interface
 	// which consists in the Lex(lval *yrSymType) and Error(s string) methods.
-	if result := yrParse(lexer); result != 0 {
-		err = lexer.err
+	if result := yrParse(l); result != 0 {
+		if l.abort != nil {
+			err = l.abort
+		} else {
+			err = l.err
+		}
+	}
+
+	return err
+}
+
+// lineReader is an io.Reader that records the positions where each line
+// starts while reading from another reader.
+type lineReader struct {
+	r   io.Reader
+	pos int
+	// Number of the first line read.
+	firstLine int
+	starts    []int
+	// If not zero, reading more than maxSize bytes panics with an
+	// InputTooLargeError.
+	maxSize int
+	size    int
+}
+
+func (lr *lineReader) Read(p []byte) (int, error) {
+	n, err := lr.r.Read(p)
+	lr.size += n
+	if lr.maxSize > 0 && lr.size > lr.maxSize {
+		panic(gyperror.Error{
+			Code:    gyperror.InputTooLargeError,
+			Message: fmt.Sprintf("input too large, the maximum size is %d bytes", lr.maxSize),
+		})
+	}
+	for i, c := range p[:n] {
+		if c == '\n' {
+			lr.starts = append(lr.starts, lr.pos+i+1)
+		}
 	}
+	lr.pos += n
+	return n, err
+}
+
+// lineAt returns the number of the line containing pos, and the position
+// where the line starts.
+func (lr *lineReader) lineAt(pos int) (int, int) {
+	i := sort.SearchInts(lr.starts, pos+1) - 1
+	return lr.firstLine + i, lr.starts[i]
+}
 
-	return lexer.ruleSet, err
+// hexError returns the token for an error in a hex string, where hexErr is
+// the error returned by the hex string parser. The error is located at the
+// hex string, which is the current token, and wraps hexErr, which is located
+// within the hex string.
+func (s *Scanner) hexError(hexErr gyperror.Error) YYtype {
+	return YYtype{Error: gyperror.Error{
+		Code:     hexErr.Code,
+		Message:  hexErr.Message,
+		StartPos: s.Context.TokenPos,
+		EndPos:   s.Context.Pos,
+		Err:      hexErr,
+	}}
 }
 
-// Lexer is an adapter that fits the flexgo lexer ("Scanner") into goyacc
+// Lexer is an adapter that fits the lexer ("Scanner") into goyacc
 type lexer struct {
 	scanner Scanner
 	err     gyperror.Error
@@ -64,6 +224,39 @@ type lexer struct {
 	// Used as a lookup for rule identifiers with wildcards to check if
 	// a rule is defined _AFTER_ a rule uses it in a wildcard expansion.
 	rule_wildcards map[string]bool
+	// Token returned before any other token, or zero if none. See parse.
+	start int
+	// True while parsing a standalone expression. In that case the strings
+	// and rules referenced by the expression are unknown, and the checks for
+	// undefined identifiers are skipped.
+	standalone bool
+	// The expression, string or rule produced while parsing a fragment.
+	fragment interface{}
+	// Spans for each rule in ruleSet.Rules, and for imports and includes.
+	ruleSpans  []Span
+	otherSpans []Span
+	// If not nil, this function receives each rule as soon as it's parsed,
+	// and the rule is not added to ruleSet. See ParseStream.
+	onRule func(*ast.Rule) error
+	// Error returned by onRule, which aborts the parsing.
+	abort error
+	// Last token returned by Lex.
+	lastToken YYtype
+	// Identifier of the rule being parsed, if any.
+	currentRule string
+	// Positions where each line in the input starts, used for computing the
+	// column where errors occur.
+	lines *lineReader
+	// Limits enforced while parsing, and the number of rules and AST nodes
+	// parsed so far.
+	limits   Limits
+	numRules int
+	numNodes int
+	// Lower bounds of the nesting depth and the number of nodes in the
+	// condition being parsed, see trackLimits.
+	conditionLimits conditionLimits
+	// Allocator for the most common nodes in the AST.
+	nodes nodeArena
 }
 
 // Lex provides the interface expected by the goyacc parser. This function is
@@ -71,27 +264,42 @@ type lexer struct {
 // the token number, and copies the value associated to the token (if any) into
 // the struct pointed by lval.
 func (l *lexer) Lex(lval *yrSymType) int {
-	// Ask the lexer for the next token.
-	r := l.scanner.Lex()
+	if l.start != 0 {
+		start := l.start
+		l.start = 0
+		return start
+	}
+	// Ask the lexer for the next token. If the token has an associated value,
+	// it's stored directly into lval.
+	r := l.scanner.lexInto(lval)
 	if r.Error.Code != 0 {
-		r.Error.Line = l.scanner.Lineno
+		// Errors are located at the current token, unless the lexer action
+		// located them already.
+		if r.Error.EndPos == 0 {
+			r.Error.Line = l.scanner.Lineno
+			r.Error.StartPos = l.scanner.Context.TokenPos
+			r.Error.EndPos = l.scanner.Context.Pos
+		}
 		panic(r.Error)
 	}
-	// If the token has an associated value, copy it into lval.
-	if r.Value != nil {
-		*lval = *r.Value
-	}
-	// Save the token's line number in lval.
+	// Save the token's line number and position in lval.
 	lval.lineno = r.Lineno
+	lval.pos = r.StartPos
+	lval.end = r.EndPos
+	l.lastToken = r
+	l.trackLimits(r)
 	return r.Token
 }
 
-// Error satisfies the interface expected of the goyacc parser.
+// Error satisfies the interface expected of the goyacc parser. It's called
+// for syntax errors, which are reported at the last token returned by Lex.
 func (l *lexer) Error(msg string) {
 	l.err = gyperror.Error{
-		Code:    gyperror.LexicalError,
-		Line:    l.scanner.Lineno,
-		Message: msg,
+		Code:     gyperror.LexicalError,
+		Line:     l.scanner.Lineno,
+		Message:  msg,
+		StartPos: l.lastToken.StartPos,
+		EndPos:   l.lastToken.EndPos,
 	}
 }
 
@@ -120,6 +328,72 @@ func (l *lexer) setErrorWithLineNumber(code gyperror.Code, lineno int, format st
 	return 1
 }
 
+// setUndefinedStringError sets an UndefinedStringIdentifierError for the
+// given string identifier, count, offset or length, which appears in the
+// source code at [pos, end). If a string in the current rule has a similar
+// identifier, it's suggested in the error's hint. Like setError, it returns 1.
+func (l *lexer) setUndefinedStringError(identifier string, pos, end int) int {
+	l.err = gyperror.Error{
+		Code:     gyperror.UndefinedStringIdentifierError,
+		Line:     l.scanner.Lineno,
+		Message:  fmt.Sprintf("undefined string identifier: %s", identifier),
+		StartPos: pos,
+		EndPos:   end,
+	}
+	if suggestion := l.similarString(identifier); suggestion != "" {
+		l.err.Hint = fmt.Sprintf("did you mean `%s`?", suggestion)
+	}
+	return 1
+}
+
+// similarString returns the identifier of the string in the current rule
+// that is most similar to the given one, with the same prefix ($, #, @ or !).
+// If none of them is similar enough it returns an empty string.
+func (l *lexer) similarString(identifier string) string {
+	if len(identifier) < 2 || strings.HasSuffix(identifier, "*") {
+		return ""
+	}
+	prefix, name := identifier[:1], identifier[1:]
+	// Allow one edit for every three characters, rounding up.
+	best, bestDistance := "", (len(name)+2)/3+1
+	for s := range l.strings {
+		if d := editDistance(name, s); d < bestDistance || d == bestDistance && s < best {
+			best, bestDistance = s, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return prefix + best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	row := make([]int, len(b)+1)
+	for j := range row {
+		row[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		prev := row[0]
+		row[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur := prev + cost
+			if row[j]+1 < cur {
+				cur = row[j] + 1
+			}
+			if row[j-1]+1 < cur {
+				cur = row[j-1] + 1
+			}
+			prev, row[j] = row[j], cur
+		}
+	}
+	return row[len(b)]
+}
+
 // Helper function that casts a yrLexer interface to a lexer struct.
 func asLexer(l yrLexer) *lexer {
 	return l.(*lexer)
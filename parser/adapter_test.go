@@ -0,0 +1,39 @@
+package parser
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/VirusTotal/gyp/ast"
+	"github.com/VirusTotal/gyp/gen"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSource(t testing.TB, rs *ast.RuleSet) string {
+	var b strings.Builder
+	require.NoError(t, rs.WriteSource(&b))
+	return b.String()
+}
+
+// The source written for a parsed ruleset can be parsed too, and produces the
+// same source again. The rulesets are not compared directly, as they can
+// differ in line numbers, and in floats that WriteSource rounds.
+func FuzzParse(f *testing.F) {
+	corpus, err := ioutil.ReadFile("../tests/testdata/corpus.yar")
+	require.NoError(f, err)
+	f.Add(string(corpus))
+	for seed := int64(0); seed < 50; seed++ {
+		f.Add(writeSource(f, gen.New(seed).RuleSet()))
+	}
+	f.Fuzz(func(t *testing.T, input string) {
+		rs, err := Parse(strings.NewReader(input))
+		if err != nil {
+			return
+		}
+		source := writeSource(t, rs)
+		rs, err = Parse(strings.NewReader(source))
+		require.NoError(t, err, "input: %q\nsource: %q", input, source)
+		require.Equal(t, source, writeSource(t, rs), "input: %q", input)
+	})
+}
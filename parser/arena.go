@@ -0,0 +1,93 @@
+package parser
+
+import "github.com/VirusTotal/gyp/ast"
+
+// Number of nodes allocated at once by nodeArena.
+const arenaChunkSize = 256
+
+// nodeArena allocates the most common AST nodes in chunks, instead of one at
+// a time. Large rulesets contain millions of these nodes, and allocating them
+// in chunks reduces both the number of allocations and the work done by the
+// garbage collector. The downside is that a chunk is not freed until all the
+// nodes in it are unreachable, which doesn't matter as long as the nodes
+// belong to the same ruleset.
+type nodeArena struct {
+	identifiers       []ast.Identifier
+	memberAccesses    []ast.MemberAccess
+	integers          []ast.LiteralInteger
+	stringIdentifiers []ast.StringIdentifier
+	operations        []ast.Operation
+	operands          []ast.Expression
+}
+
+func (a *nodeArena) newIdentifier(identifier string) *ast.Identifier {
+	if len(a.identifiers) == cap(a.identifiers) {
+		a.identifiers = make([]ast.Identifier, 0, arenaChunkSize)
+	}
+	a.identifiers = append(a.identifiers, ast.Identifier{Identifier: identifier})
+	return &a.identifiers[len(a.identifiers)-1]
+}
+
+func (a *nodeArena) newMemberAccess(container ast.Expression, member string) *ast.MemberAccess {
+	if len(a.memberAccesses) == cap(a.memberAccesses) {
+		a.memberAccesses = make([]ast.MemberAccess, 0, arenaChunkSize)
+	}
+	a.memberAccesses = append(a.memberAccesses, ast.MemberAccess{
+		Container: container,
+		Member:    member,
+	})
+	return &a.memberAccesses[len(a.memberAccesses)-1]
+}
+
+func (a *nodeArena) newLiteralInteger(value int64, radix int, multiplier int64) *ast.LiteralInteger {
+	if len(a.integers) == cap(a.integers) {
+		a.integers = make([]ast.LiteralInteger, 0, arenaChunkSize)
+	}
+	a.integers = append(a.integers, ast.LiteralInteger{
+		Value:      value,
+		Radix:      radix,
+		Multiplier: multiplier,
+	})
+	return &a.integers[len(a.integers)-1]
+}
+
+func (a *nodeArena) newStringIdentifier(identifier string) *ast.StringIdentifier {
+	if len(a.stringIdentifiers) == cap(a.stringIdentifiers) {
+		a.stringIdentifiers = make([]ast.StringIdentifier, 0, arenaChunkSize)
+	}
+	a.stringIdentifiers = append(a.stringIdentifiers, ast.StringIdentifier{Identifier: identifier})
+	return &a.stringIdentifiers[len(a.stringIdentifiers)-1]
+}
+
+// newOperation returns an operation with two operands.
+func (a *nodeArena) newOperation(operator ast.OperatorType, left, right ast.Expression) *ast.Operation {
+	if len(a.operations) == cap(a.operations) {
+		a.operations = make([]ast.Operation, 0, arenaChunkSize)
+	}
+	if len(a.operands)+2 > cap(a.operands) {
+		a.operands = make([]ast.Expression, 0, 2*arenaChunkSize)
+	}
+	n := len(a.operands)
+	a.operands = append(a.operands, left, right)
+	a.operations = append(a.operations, ast.Operation{
+		Operator: operator,
+		// The capacity of the operands is limited to their length, so that
+		// appending more operands to them doesn't overwrite the operands of
+		// other operations.
+		Operands: a.operands[n : n+2 : n+2],
+	})
+	return &a.operations[len(a.operations)-1]
+}
+
+// operation takes an operator and two operands and returns a Expression
+// representing the operation. If the left operand is an operation of the
+// the same kind than the specified by the operator, the right operand is
+// simply appended to that existing operation. This implies that the operator
+// must be left-associative in order to be used with this function.
+func (a *nodeArena) operation(operator ast.OperatorType, left, right ast.Expression) ast.Expression {
+	if operation, ok := left.(*ast.Operation); ok && operation.Operator == operator {
+		operation.Operands = append(operation.Operands, right)
+		return operation
+	}
+	return a.newOperation(operator, left, right)
+}
@@ -0,0 +1,737 @@
+package parser
+
+import (
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/VirusTotal/gyp/ast"
+)
+
+// CST is a concrete syntax tree for YARA source code. Besides the RuleSet
+// parsed from the source code, it retains every token in the source and the
+// trivia (whitespace and comments) between them. The RuleSet can be modified
+// freely, and when the CST is written back with WriteSource, the regions of
+// the source code that were not affected by the modifications are written
+// byte-for-byte as they were originally, while the tokens that changed are
+// formatted as ast.RuleSet.WriteSource would do.
+type CST struct {
+	// RuleSet parsed from the source code.
+	RuleSet *ast.RuleSet
+	// Original source code and its tokens.
+	src    string
+	tokens []cstToken
+	// Source code produced by RuleSet.WriteSource when the CST was created,
+	// and its tokens.
+	normalized       string
+	normalizedTokens []cstToken
+	// For each token in normalizedTokens, the index of the equivalent token
+	// in tokens, or -1 if the token doesn't have an equivalent. Equivalent
+	// tokens can differ in their text, like 0x10 and 16.
+	original []int
+	// For tokens in normalizedTokens that don't have an equivalent, the
+	// range of tokens in the original source code that they replace.
+	replaced []tokenRange
+}
+
+// cstToken is a token in the source code.
+type cstToken struct {
+	Span
+	kind int
+}
+
+// tokenRange is a range of token indexes [Start, End) in the original source
+// code, and the range [NormalizedStart, NormalizedEnd) of tokens that replace
+// them in the normalized source code. Ranges in the same group are either
+// all kept or all replaced when writing the CST.
+type tokenRange struct {
+	Start, End                     int
+	NormalizedStart, NormalizedEnd int
+	Group                          int
+}
+
+// ParseCST parses YARA source code and returns its concrete syntax tree.
+func ParseCST(input io.Reader) (*CST, error) {
+	src, err := ioutil.ReadAll(input)
+	if err != nil {
+		return nil, err
+	}
+	rs, err := Parse(strings.NewReader(string(src)))
+	if err != nil {
+		return nil, err
+	}
+	c := &CST{RuleSet: rs, src: string(src)}
+	if c.tokens, err = tokenize(c.src); err != nil {
+		return nil, err
+	}
+	var b strings.Builder
+	if err := rs.WriteSource(&b); err != nil {
+		return nil, err
+	}
+	c.normalized = b.String()
+	if c.normalizedTokens, err = tokenize(c.normalized); err != nil {
+		return nil, err
+	}
+
+	// Align the tokens in the source code with the tokens in the normalized
+	// source code. Literals are equivalent if they are of the same kind, as
+	// WriteSource can change their text.
+	ops := diff(
+		tokenKeys(c.src, c.tokens, true), tokenKeys(c.normalized, c.normalizedTokens, true),
+		units(c.tokens), units(c.normalizedTokens))
+	c.original = make([]int, len(c.normalizedTokens))
+	i, j := 0, 0
+	for n := 0; n < len(ops); {
+		if ops[n] == diffEqual {
+			c.original[j] = i
+			i, j, n = i+1, j+1, n+1
+			continue
+		}
+		r := tokenRange{Start: i, NormalizedStart: j}
+		for ; n < len(ops) && ops[n] != diffEqual; n++ {
+			if ops[n] == diffDelete {
+				i++
+			} else {
+				c.original[j] = -1
+				j++
+			}
+		}
+		r.End, r.NormalizedEnd = i, j
+		// Ranges in the same line of the normalized source code are grouped
+		// together, as they usually are the result of reordering tokens, like
+		// string modifiers.
+		r.Group = len(c.replaced)
+		if len(c.replaced) > 0 {
+			prev := c.replaced[len(c.replaced)-1]
+			if !strings.Contains(c.normalized[c.normalizedOffset(prev.NormalizedStart):c.normalizedOffset(r.NormalizedStart)], "\n") {
+				r.Group = prev.Group
+			}
+		}
+		c.replaced = append(c.replaced, r)
+	}
+	return c, nil
+}
+
+// normalizedOffset returns the offset within the normalized source code
+// where normalizedTokens[j] starts.
+func (c *CST) normalizedOffset(j int) int {
+	if j < len(c.normalizedTokens) {
+		return c.normalizedTokens[j].Start
+	}
+	return len(c.normalized)
+}
+
+// WriteSource writes the source code for the CST's RuleSet into the writer w,
+// preserving the original source code wherever possible.
+func (c *CST) WriteSource(w io.Writer) error {
+	var b strings.Builder
+	if err := c.RuleSet.WriteSource(&b); err != nil {
+		return err
+	}
+	if b.String() == c.normalized {
+		_, err := io.WriteString(w, c.src)
+		return err
+	}
+	cw := &cstWriter{CST: c, newSrc: b.String(), lastOriginal: -1}
+	var err error
+	if cw.newTokens, err = tokenize(cw.newSrc); err != nil {
+		return err
+	}
+	cw.write()
+	_, err = io.WriteString(w, cw.b.String())
+	return err
+}
+
+// Tokens whose text is changed by WriteSource without changing their meaning.
+var literalTokens = map[int]bool{
+	_NUMBER_:      true,
+	_DOUBLE_:      true,
+	_TEXT_STRING_: true,
+	_REGEXP_:      true,
+	_HEX_STRING_:  true,
+}
+
+// tokenize returns the tokens in the source code.
+func tokenize(src string) ([]cstToken, error) {
+	scanner := NewScanner()
+	scanner.In = strings.NewReader(src)
+	var tokens []cstToken
+	for {
+		t := scanner.Lex()
+		if t.Error.Code != 0 {
+			t.Error.Line = scanner.Lineno
+			return nil, t.Error
+		}
+		if t.Token == eof {
+			return tokens, nil
+		}
+		tokens = append(tokens, cstToken{Span{t.StartPos, t.EndPos}, t.Token})
+	}
+}
+
+// tokenKeys returns the keys used for comparing tokens. If ignoreLiterals is
+// true the key for literal tokens doesn't include their text.
+func tokenKeys(src string, tokens []cstToken, ignoreLiterals bool) []string {
+	keys := make([]string, len(tokens))
+	for i, t := range tokens {
+		if ignoreLiterals && literalTokens[t.kind] {
+			keys[i] = string(rune(t.kind))
+		} else {
+			keys[i] = string(rune(t.kind)) + src[t.Start:t.End]
+		}
+	}
+	return keys
+}
+
+// units returns the indexes of the tokens where each top-level unit (a rule,
+// an import or an include) starts.
+func units(tokens []cstToken) []int {
+	var starts []int
+	depth := 0
+	for i, t := range tokens {
+		switch t.kind {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case _IMPORT_, _INCLUDE_, _GLOBAL_, _PRIVATE_, _RULE_:
+			if depth > 0 {
+				break
+			}
+			if i > 0 && (tokens[i-1].kind == _GLOBAL_ || tokens[i-1].kind == _PRIVATE_) {
+				break
+			}
+			starts = append(starts, i)
+		}
+	}
+	if len(starts) == 0 || starts[0] != 0 {
+		starts = append([]int{0}, starts...)
+	}
+	return starts
+}
+
+// cstWriter writes a CST whose RuleSet has been modified.
+type cstWriter struct {
+	*CST
+	b strings.Builder
+	// Source code produced by RuleSet.WriteSource and its tokens.
+	newSrc    string
+	newTokens []cstToken
+	// Index of the last token from the original source code that was
+	// written, -1 if none.
+	lastOriginal int
+	// True if the last token written was taken from newSrc.
+	lastNew bool
+	// If not nil, the trivia that must precede the next token written.
+	nextTrivia *string
+	// True if tokens were deleted after the last token written.
+	afterDeletion bool
+}
+
+// change is a run of operations in the edit script that are not diffEqual.
+// It starts after the tokens normalizedTokens[j-1] and newTokens[k-1], and
+// ends before normalizedTokens[j+deleted] and newTokens[k+inserted].
+type change struct {
+	j, k              int
+	deleted, inserted int
+}
+
+func (cw *cstWriter) write() {
+	ops := diff(
+		tokenKeys(cw.normalized, cw.normalizedTokens, false), tokenKeys(cw.newSrc, cw.newTokens, false),
+		units(cw.normalizedTokens), units(cw.newTokens))
+
+	// For each token in normalizedTokens, the index of the equal token in
+	// newTokens, or -1 if it was deleted.
+	equal := make([]int, len(cw.normalizedTokens))
+	var changes []change
+	for n, j, k := 0, 0, 0; n < len(ops); n++ {
+		if ops[n] != diffEqual && (n == 0 || ops[n-1] == diffEqual) {
+			changes = append(changes, change{j: j, k: k})
+		}
+		switch ops[n] {
+		case diffEqual:
+			equal[j] = k
+			j, k = j+1, k+1
+		case diffDelete:
+			equal[j] = -1
+			changes[len(changes)-1].deleted++
+			j++
+		case diffInsert:
+			changes[len(changes)-1].inserted++
+			k++
+		}
+	}
+
+	// A group of ranges of original tokens that were replaced while
+	// normalizing the source code is kept only if the tokens that replaced
+	// them, and the ones around them, are kept and remain together.
+	keep := make([]bool, len(cw.replaced))
+	for n := 0; n < len(cw.replaced); {
+		group := n
+		for n < len(cw.replaced) && cw.replaced[n].Group == cw.replaced[group].Group {
+			n++
+		}
+		start, end := cw.replaced[group].NormalizedStart-1, cw.replaced[n-1].NormalizedEnd+1
+		if start < 0 {
+			start = 0
+		}
+		if end > len(equal) {
+			end = len(equal)
+		}
+		kept := true
+		for j := start; j < end; j++ {
+			if equal[j] == -1 || equal[j] != equal[start]+j-start {
+				kept = false
+			}
+		}
+		for g := group; g < n; g++ {
+			keep[g] = kept
+		}
+	}
+
+	if len(cw.tokens) > 0 {
+		cw.b.WriteString(cw.src[:cw.tokens[0].Start])
+	} else {
+		cw.b.WriteString(cw.src)
+	}
+
+	// Writes the original tokens in the n-th replaced range if it's kept and
+	// starts right before normalizedTokens[j].
+	replaced := 0
+	writeReplaced := func(j int) bool {
+		for replaced < len(cw.replaced) && cw.replaced[replaced].NormalizedStart < j {
+			replaced++
+		}
+		if replaced == len(cw.replaced) || cw.replaced[replaced].NormalizedStart != j {
+			return false
+		}
+		r := cw.replaced[replaced]
+		if keep[replaced] {
+			for i := r.Start; i < r.End; i++ {
+				cw.writeOriginal(i, -1)
+			}
+		}
+		return keep[replaced]
+	}
+
+	j, k := 0, 0
+	// Writes the tokens that didn't change until normalizedTokens[end].
+	writeEqual := func(end int) {
+		for ; j < end; j, k = j+1, k+1 {
+			kept := writeReplaced(j)
+			if i := cw.original[j]; i != -1 {
+				cw.writeOriginal(i, k)
+			} else if !kept && !cw.inKeptRange(j, keep) {
+				cw.writeNew(k)
+			}
+		}
+	}
+	for _, c := range changes {
+		writeEqual(c.j)
+		cw.writeChange(c)
+		j, k = c.j+c.deleted, c.k+c.inserted
+	}
+	writeEqual(len(cw.normalizedTokens))
+	writeReplaced(j)
+
+	if len(cw.tokens) > 0 {
+		trailing := cw.src[cw.tokens[len(cw.tokens)-1].End:]
+		if trailing != "" || (cw.lastOriginal == len(cw.tokens)-1 && !cw.lastNew) {
+			cw.b.WriteString(trailing)
+			return
+		}
+	}
+	if len(cw.newTokens) > 0 {
+		cw.b.WriteString(cw.newSrc[cw.newTokens[len(cw.newTokens)-1].End:])
+	}
+}
+
+// inKeptRange returns true if normalizedTokens[j] is in one of the ranges
+// of replaced tokens that are kept.
+func (cw *cstWriter) inKeptRange(j int, keep []bool) bool {
+	for n, r := range cw.replaced {
+		if keep[n] && j >= r.NormalizedStart && j < r.NormalizedEnd {
+			return true
+		}
+	}
+	return false
+}
+
+// writeChange writes the tokens inserted by a change. The trivia around
+// them is chosen so that the original trivia is kept in the same place
+// relative to the tokens that didn't change.
+func (cw *cstWriter) writeChange(c change) {
+	if c.inserted == 0 {
+		cw.afterDeletion = true
+		return
+	}
+	if c.j == 0 || c.j+c.deleted == len(cw.normalizedTokens) {
+		for k := c.k; k < c.k+c.inserted; k++ {
+			cw.writeNew(k)
+		}
+		return
+	}
+	// The change is between two tokens A and B, which must come from the
+	// original source code for the trivia around them to be known.
+	iA, iB := cw.original[c.j-1], cw.original[c.j+c.deleted]
+	if iA == -1 || iB == -1 || iB <= iA {
+		for k := c.k; k < c.k+c.inserted; k++ {
+			cw.writeNew(k)
+		}
+		return
+	}
+	jA, jB := c.j-1, c.j+c.deleted
+	originalA := cw.src[cw.tokens[iA].End:cw.tokens[iA+1].Start]
+	originalB := cw.src[cw.tokens[iB-1].End:cw.tokens[iB].Start]
+	normalizedA := cw.normalized[cw.normalizedTokens[jA].End:cw.normalizedTokens[jA+1].Start]
+	normalizedB := cw.normalized[cw.normalizedTokens[jB-1].End:cw.normalizedTokens[jB].Start]
+	newA := cw.newTrivia(c.k)
+	newB := cw.newTrivia(c.k + c.inserted)
+
+	// The trivia around the tokens is taken from the original source code
+	// if it didn't change in the new source code. When tokens are inserted
+	// and the trivia didn't change on both sides, there's no way to tell
+	// whether they went before or after the original trivia, so the new
+	// trivia is used.
+	leading, trailing := newA, newB
+	switch {
+	case c.deleted > 0:
+		if newA == normalizedA {
+			leading = originalA
+		}
+		if newB == normalizedB {
+			trailing = originalB
+		}
+	case newA == normalizedA && newB != normalizedB:
+		leading = originalA
+	case newB == normalizedB && newA != normalizedA:
+		trailing = originalB
+	}
+	if hasComment(originalB) {
+		trailing = originalB
+	}
+	for k := c.k; k < c.k+c.inserted; k++ {
+		if k == c.k {
+			cw.nextTrivia = &leading
+		}
+		cw.writeNew(k)
+	}
+	cw.nextTrivia = &trailing
+}
+
+// writeOriginal writes the i-th token in the original source code, preceded
+// by the appropriate trivia. The k argument is the index of the equivalent
+// token in the new source code, or -1 if unknown.
+func (cw *cstWriter) writeOriginal(i, k int) {
+	trivia := ""
+	if i > 0 {
+		trivia = cw.src[cw.tokens[i-1].End:cw.tokens[i].Start]
+	}
+	// If the previous token comes from the new source code, the trivia
+	// between them is also taken from the new source code, unless the
+	// original trivia contains comments, or both contain line breaks, in
+	// which case the original indentation is preferred.
+	if cw.lastNew && k != -1 && !hasComment(trivia) {
+		if t := cw.newTrivia(k); !strings.Contains(t, "\n") || !strings.Contains(trivia, "\n") {
+			trivia = t
+		}
+	}
+	// If whole lines were deleted, the line break that ended them is
+	// deleted too.
+	if cw.afterDeletion && cw.atLineStart() {
+		if t := strings.TrimLeft(trivia, " \t"); strings.HasPrefix(t, "\n") {
+			trivia = t[1:]
+		}
+	}
+	cw.writeToken(trivia, cw.src[cw.tokens[i].Start:cw.tokens[i].End])
+	cw.lastOriginal = i
+	cw.lastNew = false
+}
+
+// writeNew writes the k-th token in the new source code, preceded by the
+// trivia that precedes it in the new source code.
+func (cw *cstWriter) writeNew(k int) {
+	cw.writeToken(cw.newTrivia(k), cw.newSrc[cw.newTokens[k].Start:cw.newTokens[k].End])
+	cw.lastNew = true
+}
+
+// newTrivia returns the trivia that precedes the k-th token in the new
+// source code.
+func (cw *cstWriter) newTrivia(k int) string {
+	if k == 0 {
+		if cw.b.Len() == 0 {
+			return cw.newSrc[:cw.newTokens[0].Start]
+		}
+		return "\n"
+	}
+	return cw.newSrc[cw.newTokens[k-1].End:cw.newTokens[k].Start]
+}
+
+// atLineStart returns true if nothing but spaces were written after the last
+// line break.
+func (cw *cstWriter) atLineStart() bool {
+	s := cw.b.String()
+	s = strings.TrimRight(s, " \t")
+	return s == "" || s[len(s)-1] == '\n'
+}
+
+func (cw *cstWriter) writeToken(trivia, text string) {
+	if cw.nextTrivia != nil {
+		trivia = *cw.nextTrivia
+		cw.nextTrivia = nil
+	}
+	cw.afterDeletion = false
+	// Tokens that were not adjacent in the source code could be merged
+	// into a single one if written without any space between them.
+	if trivia == "" && cw.b.Len() > 0 {
+		s := cw.b.String()
+		if needsSpace(s[len(s)-1], text[0]) {
+			trivia = " "
+		}
+	}
+	cw.b.WriteString(trivia)
+	cw.b.WriteString(text)
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// needsSpace returns true if a space is required between two tokens, where
+// a is the last byte of the first one and b the first byte of the second.
+func needsSpace(a, b byte) bool {
+	switch {
+	case isWordByte(a) && (isWordByte(b) || strings.IndexByte("$#@!", b) != -1):
+		return true
+	case a == '/' && (b == '/' || b == '*'):
+		return true
+	case a == '.' && b == '.':
+		return true
+	}
+	return false
+}
+
+func hasComment(trivia string) bool {
+	return strings.Contains(trivia, "//") || strings.Contains(trivia, "/*")
+}
+
+// Operations in the edit script produced by diff.
+type diffOp int
+
+const (
+	diffEqual diffOp = iota
+	diffDelete
+	diffInsert
+)
+
+// Maximum number of insertions and deletions that diff tries to minimize
+// within a region that changed. Regions requiring more than this are
+// replaced entirely.
+const maxDiffCost = 1000
+
+// diff returns an edit script that transforms the sequence of tokens a into
+// b, where tokens are compared by their keys. The tokens are grouped in units
+// starting at the indexes indicated by aUnits and bUnits. Units are compared
+// first, and only the tokens in units that differ are compared individually,
+// which keeps the cost low even when the sequences are long.
+func diff(a, b []string, aUnits, bUnits []int) []diffOp {
+	unitKeys := func(keys []string, starts []int) []string {
+		result := make([]string, len(starts))
+		for u, start := range starts {
+			end := len(keys)
+			if u+1 < len(starts) {
+				end = starts[u+1]
+			}
+			result[u] = strings.Join(keys[start:end], "\x00")
+		}
+		return result
+	}
+	unitEnd := func(keys []string, starts []int, u int) int {
+		if u < len(starts) {
+			return starts[u]
+		}
+		return len(keys)
+	}
+
+	var ops []diffOp
+	unitOps := anchoredDiff(unitKeys(a, aUnits), unitKeys(b, bUnits))
+	i, j := 0, 0
+	for n := 0; n < len(unitOps); {
+		if unitOps[n] == diffEqual {
+			for t := aUnits[i]; t < unitEnd(a, aUnits, i+1); t++ {
+				ops = append(ops, diffEqual)
+			}
+			i, j, n = i+1, j+1, n+1
+			continue
+		}
+		ui, uj := i, j
+		for ; n < len(unitOps) && unitOps[n] != diffEqual; n++ {
+			if unitOps[n] == diffDelete {
+				i++
+			} else {
+				j++
+			}
+		}
+		ops = append(ops, myers(
+			a[unitEnd(a, aUnits, ui):unitEnd(a, aUnits, i)],
+			b[unitEnd(b, bUnits, uj):unitEnd(b, bUnits, j)])...)
+	}
+	return ops
+}
+
+// anchoredDiff returns an edit script that transforms a into b. Elements that
+// appear exactly once in both sequences are used as anchors, as in patience
+// diff, and only the regions between anchors are compared with myers. This
+// keeps the regions small when many units, like most rules in a large file,
+// are modified.
+func anchoredDiff(a, b []string) []diffOp {
+	count := make(map[string]int)
+	posB := make(map[string]int)
+	for _, key := range a {
+		count[key]++
+	}
+	for j, key := range b {
+		count[key] += len(a) + 1
+		posB[key] = j
+	}
+	// Candidate anchors, in the order in which they appear in a.
+	var anchorsA, anchorsB []int
+	for i, key := range a {
+		if count[key] == len(a)+2 {
+			anchorsA = append(anchorsA, i)
+			anchorsB = append(anchorsB, posB[key])
+		}
+	}
+	// Keep the longest subsequence of anchors that is increasing in b.
+	// tails[l] is the index of the anchor ending the best subsequence of
+	// length l+1, prev links each anchor to its predecessor.
+	var tails []int
+	prev := make([]int, len(anchorsB))
+	for n, j := range anchorsB {
+		l := sort.Search(len(tails), func(l int) bool { return anchorsB[tails[l]] >= j })
+		prev[n] = -1
+		if l > 0 {
+			prev[n] = tails[l-1]
+		}
+		if l == len(tails) {
+			tails = append(tails, n)
+		} else {
+			tails[l] = n
+		}
+	}
+	var anchors []int
+	if len(tails) > 0 {
+		for n := tails[len(tails)-1]; n >= 0; n = prev[n] {
+			anchors = append(anchors, n)
+		}
+	}
+	for l, r := 0, len(anchors)-1; l < r; l, r = l+1, r-1 {
+		anchors[l], anchors[r] = anchors[r], anchors[l]
+	}
+
+	var ops []diffOp
+	i, j := 0, 0
+	for _, n := range anchors {
+		ops = append(ops, myers(a[i:anchorsA[n]], b[j:anchorsB[n]])...)
+		ops = append(ops, diffEqual)
+		i, j = anchorsA[n]+1, anchorsB[n]+1
+	}
+	return append(ops, myers(a[i:], b[j:])...)
+}
+
+// myers returns the shortest edit script that transforms a into b, using
+// Myers' algorithm. If the edit script would require more than maxDiffCost
+// insertions and deletions, the differing region is replaced entirely.
+func myers(a, b []string) []diffOp {
+	var ops []diffOp
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		ops = append(ops, diffEqual)
+		a, b = a[1:], b[1:]
+	}
+	suffix := 0
+	for len(a) > 0 && len(b) > 0 && a[len(a)-1] == b[len(b)-1] {
+		a, b = a[:len(a)-1], b[:len(b)-1]
+		suffix++
+	}
+
+	n, m := len(a), len(b)
+	max := n + m
+	if max > maxDiffCost {
+		max = maxDiffCost
+	}
+	// v[offset+k] is the furthest x reached on diagonal k = x - y, trace
+	// contains the values of v[offset-d:offset+d+1] after each step d.
+	offset := max + 1
+	v := make([]int, 2*max+3)
+	var trace [][]int
+	var middle []diffOp
+	found := false
+	for d := 0; d <= max && !found; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || k != d && v[offset+k-1] < v[offset+k+1] {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x, y = x+1, y+1
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+	}
+
+	if found {
+		x, y := n, m
+		for d := len(trace) - 1; d > 0; d-- {
+			prev := trace[d-1]
+			k := x - y
+			var prevK int
+			if k == -d || k != d && prev[k-1+d-1] < prev[k+1+d-1] {
+				prevK = k + 1
+			} else {
+				prevK = k - 1
+			}
+			prevX := prev[prevK+d-1]
+			prevY := prevX - prevK
+			// The step from (prevX, prevY) is followed by a snake of equal
+			// tokens that ends at (x, y).
+			midX, midY, op := prevX+1, prevY, diffDelete
+			if prevK == k+1 {
+				midX, midY, op = prevX, prevY+1, diffInsert
+			}
+			for ; x > midX && y > midY; x, y = x-1, y-1 {
+				middle = append(middle, diffEqual)
+			}
+			middle = append(middle, op)
+			x, y = prevX, prevY
+		}
+		for ; x > 0 && y > 0; x, y = x-1, y-1 {
+			middle = append(middle, diffEqual)
+		}
+		for l, r := 0, len(middle)-1; l < r; l, r = l+1, r-1 {
+			middle[l], middle[r] = middle[r], middle[l]
+		}
+	} else {
+		for i := 0; i < n; i++ {
+			middle = append(middle, diffDelete)
+		}
+		for j := 0; j < m; j++ {
+			middle = append(middle, diffInsert)
+		}
+	}
+
+	ops = append(ops, middle...)
+	for ; suffix > 0; suffix-- {
+		ops = append(ops, diffEqual)
+	}
+	return ops
+}
@@ -0,0 +1,367 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/VirusTotal/gyp/ast"
+)
+
+// Span represents a range of bytes within the source code. Start is inclusive
+// and End is exclusive.
+type Span struct {
+	Start int
+	End   int
+}
+
+// overlaps returns true if the span overlaps the range [start, end], which
+// includes the case in which they are only adjacent.
+func (s Span) overlaps(start, end int) bool {
+	return s.Start <= end && s.End >= start
+}
+
+// Edit describes a change to the source code of a Document. The bytes in the
+// range [Start, End) are replaced with Text. Insertions are represented with
+// Start == End, and deletions with an empty Text.
+type Edit struct {
+	Start int
+	End   int
+	Text  string
+}
+
+// Document is a YARA source file that can be modified by applying edits to it.
+// After each edit the document is parsed again, but only the rules affected
+// by the edit are actually re-parsed, the remaining ones are reused as they
+// are. This is much faster than parsing the whole source code again, which
+// makes it suitable for editors that need an up-to-date AST after every
+// keystroke.
+//
+// The ast.Rule objects for rules that are not affected by an edit are shared
+// between the RuleSet before and after the edit, except for rules whose line
+// numbers change, which are copied. The RuleSet before the edit is never
+// modified.
+type Document struct {
+	src        []byte
+	ruleSet    *ast.RuleSet
+	ruleSpans  []Span
+	otherSpans []Span
+	err        error
+}
+
+// NewDocument creates a new document with the given source code. The returned
+// document is never nil, even if the source code has errors. Use Err for
+// checking if the source code was parsed successfully.
+func NewDocument(src string) *Document {
+	d := &Document{src: []byte(src)}
+	d.parseAll()
+	return d
+}
+
+// Source returns the current source code of the document.
+func (d *Document) Source() string {
+	return string(d.src)
+}
+
+// RuleSet returns the RuleSet resulting from parsing the current source code,
+// or nil if the source code has errors.
+func (d *Document) RuleSet() *ast.RuleSet {
+	return d.ruleSet
+}
+
+// RuleSpan returns the position within the source code of the i-th rule in
+// the document's RuleSet. The span goes from the first modifier or the "rule"
+// keyword to the closing brace.
+func (d *Document) RuleSpan(i int) Span {
+	return d.ruleSpans[i]
+}
+
+// Err returns the error produced while parsing the current source code, if
+// any.
+func (d *Document) Err() error {
+	return d.err
+}
+
+// Apply applies an edit to the document and updates its RuleSet. Returns the
+// error produced while parsing the resulting source code, if any, which is
+// the same error returned by Err. If the edit is out of the document's bounds
+// an error is returned and the document is not modified.
+func (d *Document) Apply(e Edit) error {
+	if e.Start < 0 || e.Start > e.End || e.End > len(d.src) {
+		return fmt.Errorf("invalid edit range [%d, %d) for a document with length %d",
+			e.Start, e.End, len(d.src))
+	}
+
+	oldSrc := d.src
+	d.src = make([]byte, 0, len(oldSrc)-(e.End-e.Start)+len(e.Text))
+	d.src = append(d.src, oldSrc[:e.Start]...)
+	d.src = append(d.src, e.Text...)
+	d.src = append(d.src, oldSrc[e.End:]...)
+
+	// If the previous source code had errors there's nothing to reuse.
+	if d.err != nil || !d.parseIncremental(oldSrc, e) {
+		d.parseAll()
+	}
+
+	return d.err
+}
+
+// parseAll parses the whole source code.
+func (d *Document) parseAll() {
+	l, err := parse(bytes.NewReader(d.src), 0)
+	if err != nil {
+		d.ruleSet, d.ruleSpans, d.otherSpans, d.err = nil, nil, nil, err
+	} else {
+		d.ruleSet, d.ruleSpans, d.otherSpans, d.err = l.ruleSet, l.ruleSpans, l.otherSpans, nil
+	}
+}
+
+// parseIncremental updates the document after an edit by parsing only the
+// region of the source code affected by the edit. The region starts right
+// after the last rule that ends before the edit, and ends right before the
+// first rule that starts after the edit. Returns false if the edit can't be
+// handled incrementally and the whole source code must be parsed again. This
+// happens when the region contains imports or includes, when the region can't
+// be parsed on its own, and when the rules in the region affect the validity
+// of the rules after it.
+func (d *Document) parseIncremental(oldSrc []byte, e Edit) bool {
+	// Find the rules affected by the edit, which are in the [first, last)
+	// range. If no rule is affected first == last, and the edit is in the
+	// space between two rules.
+	first := 0
+	for first < len(d.ruleSpans) && d.ruleSpans[first].End < e.Start {
+		first++
+	}
+	last := first
+	for last < len(d.ruleSpans) && d.ruleSpans[last].overlaps(e.Start, e.End) {
+		last++
+	}
+
+	region := Span{Start: 0, End: len(oldSrc)}
+	if first > 0 {
+		region.Start = d.ruleSpans[first-1].End
+	}
+	if last < len(d.ruleSpans) {
+		region.End = d.ruleSpans[last].Start
+	}
+
+	for _, s := range d.otherSpans {
+		if s.overlaps(region.Start, region.End) {
+			return false
+		}
+	}
+
+	delta := len(e.Text) - (e.End - e.Start)
+	lineDelta := strings.Count(e.Text, "\n") - bytes.Count(oldSrc[e.Start:e.End], []byte("\n"))
+
+	before := d.ruleSet.Rules[:first]
+	after := d.ruleSet.Rules[last:]
+
+	// The region is parsed with a lexer that knows about the rules that come
+	// before the region, so that checks for duplicate rules, undefined rules
+	// and wildcards work as if the whole source code was being parsed.
+	l := newLexer(0)
+	l.ruleSet.Rules = append(l.ruleSet.Rules, before...)
+	for _, r := range before {
+		l.rules[r.Identifier] = true
+		for _, w := range ruleWildcards(r) {
+			l.rule_wildcards[w] = true
+		}
+	}
+	l.scanner.Lineno = bytes.Count(d.src[:region.Start], []byte("\n")) + 1
+	l.scanner.Context.Pos = region.Start
+
+	if err := parseWith(bytes.NewReader(d.src[region.Start:region.End+delta]), l); err != nil {
+		return false
+	}
+	// If the region ends inside a comment, the comment continues beyond the
+	// region, probably commenting out the rules after it. The same applies
+	// to strings and regexps.
+	if l.scanner.state != stateInitial {
+		return false
+	}
+	if len(l.otherSpans) > 0 {
+		return false
+	}
+
+	newRules := l.ruleSet.Rules[len(before):]
+
+	// If the rule identifiers or the wildcards in the region changed, the
+	// rules after the region could be affected. For instance, they could be
+	// duplicates of a new rule, or reference a rule that doesn't exist
+	// anymore. Such errors are not worth handling incrementally.
+	if !sameIdentifiers(d.ruleSet.Rules[first:last], newRules) {
+		seen := make(map[string]bool, len(d.ruleSet.Rules))
+		wildcards := make(map[string]bool)
+		for _, r := range l.ruleSet.Rules {
+			seen[r.Identifier] = true
+			for _, w := range ruleWildcards(r) {
+				wildcards[w] = true
+			}
+		}
+		for _, r := range after {
+			if !validateRule(r, seen, wildcards) {
+				return false
+			}
+		}
+	}
+
+	rules := make([]*ast.Rule, 0, len(before)+len(newRules)+len(after))
+	rules = append(rules, before...)
+	rules = append(rules, newRules...)
+	if lineDelta == 0 {
+		rules = append(rules, after...)
+	} else {
+		// The rules after the region are shared with the previous ruleset,
+		// which may still be in use, so their line numbers are shifted in a
+		// copy.
+		for _, r := range after {
+			rules = append(rules, shiftLines(r, lineDelta))
+		}
+	}
+
+	ruleSpans := make([]Span, 0, len(rules))
+	ruleSpans = append(ruleSpans, d.ruleSpans[:first]...)
+	ruleSpans = append(ruleSpans, l.ruleSpans...)
+	for _, s := range d.ruleSpans[last:] {
+		ruleSpans = append(ruleSpans, Span{s.Start + delta, s.End + delta})
+	}
+	for i, s := range d.otherSpans {
+		if s.Start >= region.End {
+			d.otherSpans[i] = Span{s.Start + delta, s.End + delta}
+		}
+	}
+
+	d.ruleSet = &ast.RuleSet{
+		Imports:  d.ruleSet.Imports,
+		Includes: d.ruleSet.Includes,
+		Rules:    rules,
+	}
+	d.ruleSpans = ruleSpans
+	return true
+}
+
+// sameIdentifiers returns true if both lists of rules have the same rule
+// identifiers and use the same wildcards in their conditions.
+func sameIdentifiers(a, b []*ast.Rule) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Identifier != b[i].Identifier {
+			return false
+		}
+		wa, wb := ruleWildcards(a[i]), ruleWildcards(b[i])
+		if len(wa) != len(wb) {
+			return false
+		}
+		for j := range wa {
+			if wa[j] != wb[j] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// validateRule performs the same checks performed by the parser for rules
+// that reference other rules, given the identifiers of the rules seen so far
+// and the wildcards used by them. If the rule is valid, its identifier and
+// wildcards are added to seen and wildcards respectively.
+func validateRule(r *ast.Rule, seen, wildcards map[string]bool) bool {
+	if seen[r.Identifier] {
+		return false
+	}
+	for w := range wildcards {
+		if strings.HasPrefix(r.Identifier, w) {
+			return false
+		}
+	}
+	seen[r.Identifier] = true
+	for _, ident := range ruleReferences(r) {
+		if prefix := strings.TrimSuffix(ident, "*"); prefix != ident {
+			match := false
+			for s := range seen {
+				if strings.HasPrefix(s, prefix) {
+					match = true
+					break
+				}
+			}
+			if !match {
+				return false
+			}
+			wildcards[prefix] = true
+		} else if !seen[ident] {
+			return false
+		}
+	}
+	return true
+}
+
+// ruleWildcards returns the wildcards used in rule sets in the condition of
+// the given rule, without the trailing asterisk. For instance, for a rule with
+// condition "any of (foo*)" it returns "foo".
+func ruleWildcards(r *ast.Rule) []string {
+	var wildcards []string
+	for _, ident := range ruleReferences(r) {
+		if prefix := strings.TrimSuffix(ident, "*"); prefix != ident {
+			wildcards = append(wildcards, prefix)
+		}
+	}
+	return wildcards
+}
+
+// ruleReferences returns the identifiers in rule sets in the condition of the
+// given rule, like "foo" and "bar*" in "any of (foo, bar*)".
+func ruleReferences(r *ast.Rule) []string {
+	v := &ruleSetVisitor{}
+	if r.Condition != nil {
+		ast.DepthFirstSearch(r.Condition, v)
+	}
+	return v.identifiers
+}
+
+type ruleSetVisitor struct {
+	identifiers []string
+}
+
+func (v *ruleSetVisitor) PreOrderVisit(n ast.Node) {
+	if of, ok := n.(*ast.Of); ok {
+		if enum, ok := of.Rules.(*ast.Enum); ok {
+			for _, value := range enum.Values {
+				if ident, ok := value.(*ast.Identifier); ok {
+					v.identifiers = append(v.identifiers, ident.Identifier)
+				}
+			}
+		}
+	}
+}
+
+// shiftLines returns a shallow copy of a rule with delta added to the line
+// numbers of the rule and its strings. The strings are copied too, but the
+// other fields are shared with the original rule.
+func shiftLines(r *ast.Rule, delta int) *ast.Rule {
+	shifted := *r
+	shifted.LineNo += delta
+	if r.Strings != nil {
+		shifted.Strings = make([]ast.String, len(r.Strings))
+	}
+	for i, s := range r.Strings {
+		switch v := s.(type) {
+		case *ast.TextString:
+			c := *v
+			c.LineNo += delta
+			s = &c
+		case *ast.RegexpString:
+			c := *v
+			c.LineNo += delta
+			s = &c
+		case *ast.HexString:
+			c := *v
+			c.LineNo += delta
+			s = &c
+		}
+		shifted.Strings[i] = s
+	}
+	return &shifted
+}
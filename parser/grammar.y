@@ -60,7 +60,7 @@ type stringModifiers struct {
 
 %}
 
-// yara-parser: we have 'const eof = 0' in lexer.l
+// yara-parser: we have 'const eof = 0' in lexer.go
 // Token that marks the end of the original file.
 // %token _END_OF_FILE_  0
 
@@ -106,6 +106,7 @@ type stringModifiers struct {
 %token _IN_
 %token _OF_
 %token _FOR_
+%token _WITH_
 %token _THEM_
 %token _MATCHES_
 %token _CONTAINS_
@@ -121,6 +122,14 @@ type stringModifiers struct {
 %token _INCLUDE_
 %token _DEFINED_
 
+// Tokens that are never produced by the lexer, one of them is injected as the
+// first token when parsing a standalone expression, string or rule instead of
+// a complete set of rules. See ParseExpression, ParseStringDefinition and
+// ParseRule.
+%token _START_EXPRESSION_
+%token _START_STRING_
+%token _START_RULE_
+
 %left _OR_
 %left _AND_
 %right _NOT_ _DEFINED_
@@ -167,6 +176,8 @@ type stringModifiers struct {
 %type <reg>       regexp
 %type <rng>       range
 %type <ss>        for_variables
+%type <decls>     with_declarations
+%type <decl>      with_declaration
 %type <exprs>     string_enumeration
 %type <si>        string_enumeration_item
 %type <node>      rule_set
@@ -199,6 +210,8 @@ type stringModifiers struct {
     si            *ast.StringIdentifier
     sis           []*ast.StringIdentifier
     ident         *ast.Identifier
+    decl          *ast.WithDeclaration
+    decls         []*ast.WithDeclaration
 
     // lineno is not a symbol type, it's the line number where the symbol
     // appears in the source file. This is a little hack used for passing
@@ -219,27 +232,74 @@ type stringModifiers struct {
     // the N-th symbol in the production rule.
 
     lineno        int
+
+    // pos and end are similar to lineno, but they contain the position within
+    // the source code where the symbol starts (inclusive) and ends (exclusive).
+    // The lexer sets them for every token, but for non-terminal symbols they
+    // must be set explicitly in the rule's actions when required, otherwise
+    // they will have the values of the first symbol in the rule.
+
+    pos           int
+    end           int
+
+    // radix and multiplier are set by the lexer for _NUMBER_ tokens, besides
+    // i64 they describe how the number is written in the source code. See
+    // the fields with the same name in ast.LiteralInteger.
+
+    radix         int
+    multiplier    int64
 }
 
 
+%start input
+
 %%
 
+input
+    : rules
+    | _START_EXPRESSION_ boolean_expression
+      {
+        asLexer(yrlex).fragment = $2
+      }
+    | _START_STRING_ string_declaration
+      {
+        asLexer(yrlex).fragment = $2
+      }
+    | _START_RULE_ rule
+      {
+        asLexer(yrlex).fragment = $2
+      }
+    ;
+
+
 rules
     : /* empty */
     | rules rule
       {
-        ruleSet := asLexer(yrlex).ruleSet
-        ruleSet.Rules = append(ruleSet.Rules, $2)
+        lexer := asLexer(yrlex)
+        // When streaming, rules are passed to the callback instead of being
+        // stored in the ruleset.
+        if lexer.onRule != nil {
+          if err := lexer.onRule($2); err != nil {
+            lexer.abort = err
+            return 1
+          }
+        } else {
+          lexer.ruleSet.Rules = append(lexer.ruleSet.Rules, $2)
+          lexer.ruleSpans = append(lexer.ruleSpans, Span{$<pos>2, $<end>2})
+        }
       }
     | rules import
       {
-        ruleSet := asLexer(yrlex).ruleSet
-        ruleSet.Imports = append(ruleSet.Imports, $2)
+        lexer := asLexer(yrlex)
+        lexer.ruleSet.Imports = append(lexer.ruleSet.Imports, $2)
+        lexer.otherSpans = append(lexer.otherSpans, Span{$<pos>2, $<end>2})
       }
     | rules _INCLUDE_ _TEXT_STRING_
       {
-        ruleSet := asLexer(yrlex).ruleSet
-        ruleSet.Includes = append(ruleSet.Includes, $3)
+        lexer := asLexer(yrlex)
+        lexer.ruleSet.Includes = append(lexer.ruleSet.Includes, $3)
+        lexer.otherSpans = append(lexer.otherSpans, Span{$<pos>2, $<end>3})
       }
     | rules _END_OF_INCLUDED_FILE_
       {
@@ -257,6 +317,7 @@ import
         }
 
         $$ = $2
+        $<end>$ = $<end>2
       }
     ;
 
@@ -266,12 +327,11 @@ rule
       {
         lexer := asLexer(yrlex)
 
-        // Forbid duplicate rules
-        for _, r := range lexer.ruleSet.Rules {
-            if $3 == r.Identifier {
-              return lexer.setError(
-                gyperror.DuplicateRuleError, `duplicate rule "%s"`, $3)
-            }
+        // Forbid duplicate rules. The rules map is used instead of the rules
+        // in the ruleset, which are not retained while streaming.
+        if lexer.rules[$3] {
+          return lexer.setError(
+            gyperror.DuplicateRuleError, `duplicate rule "%s"`, $3)
         }
 
         // Forbid any rule which matches our rules wildcard table. This ensures
@@ -296,14 +356,18 @@ rule
         $<lineno>$ = $<lineno>1
 
         // ... or the line number of the "rule" keyword if the rule doesn't
-        // have any modifiers.
+        // have any modifiers. The same applies to the rule's position.
+        $<pos>$ = $<pos>1
+
         if $<lineno>$ == -1 {
            $<lineno>$  = $<lineno>2
+           $<pos>$ = $<pos>2
         }
 
         // Store the rule identifier for lookup to ensure rule references are
         // always defined.
         lexer.rules[$3] = true
+        lexer.currentRule = $3
 
         $$ = &ast.Rule{
             LineNo: $<lineno>$,
@@ -337,10 +401,17 @@ rule
       condition '}'
       {
         $<rule>4.Condition = $10
+        if !asLexer(yrlex).checkLimits($<rule>4) {
+          return 1
+        }
         $$ = $<rule>4
+        $<pos>$ = $<pos>4
+        $<end>$ = $<end>11
 
         // Clear the strings map for the next rule being parsed.
-        asLexer(yrlex).strings = make(map[string]bool)
+        lexer := asLexer(yrlex)
+        lexer.strings = make(map[string]bool)
+        lexer.currentRule = ""
       }
     ;
 
@@ -382,6 +453,7 @@ rule_modifiers
       {
         $$ = 0
         $<lineno>$ = -1
+        $<pos>$ = -1
       }
     | rule_modifiers rule_modifier
       {
@@ -389,8 +461,10 @@ rule_modifiers
 
         if $<lineno>1 == -1 {
           $<lineno>$ = $<lineno>2
+          $<pos>$ = $<pos>2
         } else {
           $<lineno>$ = $<lineno>1
+          $<pos>$ = $<pos>1
         }
       }
     ;
@@ -401,11 +475,13 @@ rule_modifier
       {
         $$ = ModPrivate
         $<lineno>$ = $<lineno>1
+        $<pos>$ = $<pos>1
       }
     | _GLOBAL_
       {
         $$ = ModGlobal
         $<lineno>$ = $<lineno>1
+        $<pos>$ = $<pos>1
       }
     ;
 
@@ -728,14 +804,11 @@ hex_modifier
 identifier
     : _IDENTIFIER_
       {
-        $$ = &ast.Identifier{Identifier: $1}
+        $$ = asLexer(yrlex).nodes.newIdentifier($1)
       }
     | identifier '.' _IDENTIFIER_
       {
-        $$ = &ast.MemberAccess{
-          Container: $1,
-          Member: $3,
-        }
+        $$ = asLexer(yrlex).nodes.newMemberAccess($1, $3)
       }
     | identifier '[' primary_expression ']'
       {
@@ -805,59 +878,35 @@ expression
       }
     | primary_expression _MATCHES_ regexp
       {
-        $$ = &ast.Operation{
-          Operator: ast.OpMatches,
-          Operands: []ast.Expression{$1, $3},
-        }
+        $$ = asLexer(yrlex).nodes.newOperation(ast.OpMatches, $1, $3)
       }
     | primary_expression _CONTAINS_ primary_expression
       {
-        $$ = &ast.Operation{
-          Operator: ast.OpContains,
-          Operands: []ast.Expression{$1, $3},
-        }
+        $$ = asLexer(yrlex).nodes.newOperation(ast.OpContains, $1, $3)
       }
     | primary_expression _ICONTAINS_ primary_expression
       {
-        $$ = &ast.Operation{
-          Operator: ast.OpIContains,
-          Operands: []ast.Expression{$1, $3},
-        }
+        $$ = asLexer(yrlex).nodes.newOperation(ast.OpIContains, $1, $3)
       }
     | primary_expression _STARTSWITH_ primary_expression
       {
-        $$ = &ast.Operation{
-          Operator: ast.OpStartsWith,
-          Operands: []ast.Expression{$1, $3},
-        }
+        $$ = asLexer(yrlex).nodes.newOperation(ast.OpStartsWith, $1, $3)
       }
      | primary_expression _ISTARTSWITH_ primary_expression
        {
-         $$ = &ast.Operation{
-           Operator: ast.OpIStartsWith,
-           Operands: []ast.Expression{$1, $3},
-         }
+         $$ = asLexer(yrlex).nodes.newOperation(ast.OpIStartsWith, $1, $3)
        }
     | primary_expression _ENDSWITH_ primary_expression
       {
-        $$ = &ast.Operation{
-          Operator: ast.OpEndsWith,
-          Operands: []ast.Expression{$1, $3},
-        }
+        $$ = asLexer(yrlex).nodes.newOperation(ast.OpEndsWith, $1, $3)
       }
      | primary_expression _IENDSWITH_ primary_expression
        {
-         $$ = &ast.Operation{
-           Operator: ast.OpIEndsWith,
-           Operands: []ast.Expression{$1, $3},
-         }
+         $$ = asLexer(yrlex).nodes.newOperation(ast.OpIEndsWith, $1, $3)
        }
      | primary_expression _IEQUALS_ primary_expression
        {
-         $$ = &ast.Operation{
-           Operator: ast.OpIEquals,
-           Operands: []ast.Expression{$1, $3},
-         }
+         $$ = asLexer(yrlex).nodes.newOperation(ast.OpIEquals, $1, $3)
       }
     | _STRING_IDENTIFIER_
       {
@@ -865,15 +914,11 @@ expression
         // Exclude anonymous ($) strings.
         if identifier != "" {
           lexer := asLexer(yrlex)
-          if _, ok := lexer.strings[identifier]; !ok {
-            return lexer.setError(
-              gyperror.UndefinedStringIdentifierError,
-              `undefined string identifier: %s`, $1)
+          if _, ok := lexer.strings[identifier]; !ok && !lexer.standalone {
+            return lexer.setUndefinedStringError($1, $<pos>1, $<end>1)
           }
         }
-        $$ = &ast.StringIdentifier{
-          Identifier: identifier,
-        }
+        $$ = asLexer(yrlex).nodes.newStringIdentifier(identifier)
       }
     | _STRING_IDENTIFIER_ _AT_ primary_expression
       {
@@ -881,10 +926,8 @@ expression
         // Exclude anonymous ($) strings.
         if identifier != "" {
           lexer := asLexer(yrlex)
-          if _, ok := lexer.strings[identifier]; !ok {
-            return lexer.setError(
-              gyperror.UndefinedStringIdentifierError,
-              `undefined string identifier: %s`, $1)
+          if _, ok := lexer.strings[identifier]; !ok && !lexer.standalone {
+            return lexer.setUndefinedStringError($1, $<pos>1, $<end>1)
           }
         }
         $$ = &ast.StringIdentifier{
@@ -898,10 +941,8 @@ expression
         // Exclude anonymous ($) strings.
         if identifier != "" {
           lexer := asLexer(yrlex)
-          if _, ok := lexer.strings[identifier]; !ok {
-            return lexer.setError(
-              gyperror.UndefinedStringIdentifierError,
-              `undefined string identifier: %s`, $1)
+          if _, ok := lexer.strings[identifier]; !ok && !lexer.standalone {
+            return lexer.setUndefinedStringError($1, $<pos>1, $<end>1)
           }
         }
         $$ = &ast.StringIdentifier{
@@ -918,6 +959,13 @@ expression
           Condition: $8,
         }
       }
+    | _WITH_ with_declarations ':' '(' boolean_expression ')'
+      {
+        $$ = &ast.With{
+          Declarations: $2,
+          Condition: $5,
+        }
+      }
     | _FOR_ for_expression _OF_ string_set ':' '(' boolean_expression ')'
       {
         $$ = &ast.ForOf{
@@ -987,53 +1035,35 @@ expression
       }
     | boolean_expression _AND_ boolean_expression
       {
-        $$ = operation(ast.OpAnd, $1, $3)
+        $$ = asLexer(yrlex).nodes.operation(ast.OpAnd, $1, $3)
       }
     | boolean_expression _OR_ boolean_expression
       {
-        $$ = operation(ast.OpOr, $1, $3)
+        $$ = asLexer(yrlex).nodes.operation(ast.OpOr, $1, $3)
       }
     | primary_expression _LT_ primary_expression
       {
-        $$ = &ast.Operation{
-          Operator: ast.OpLessThan,
-          Operands: []ast.Expression{$1, $3},
-        }
+        $$ = asLexer(yrlex).nodes.newOperation(ast.OpLessThan, $1, $3)
       }
     | primary_expression _GT_ primary_expression
       {
-        $$ = &ast.Operation{
-          Operator: ast.OpGreaterThan,
-          Operands: []ast.Expression{$1, $3},
-        }
+        $$ = asLexer(yrlex).nodes.newOperation(ast.OpGreaterThan, $1, $3)
       }
     | primary_expression _LE_ primary_expression
       {
-        $$ = &ast.Operation{
-          Operator: ast.OpLessOrEqual,
-          Operands: []ast.Expression{$1, $3},
-        }
+        $$ = asLexer(yrlex).nodes.newOperation(ast.OpLessOrEqual, $1, $3)
       }
     | primary_expression _GE_ primary_expression
       {
-        $$ = &ast.Operation{
-          Operator: ast.OpGreaterOrEqual,
-          Operands: []ast.Expression{$1, $3},
-        }
+        $$ = asLexer(yrlex).nodes.newOperation(ast.OpGreaterOrEqual, $1, $3)
       }
     | primary_expression _EQ_ primary_expression
       {
-        $$ = &ast.Operation{
-          Operator: ast.OpEqual,
-          Operands: []ast.Expression{$1, $3},
-        }
+        $$ = asLexer(yrlex).nodes.newOperation(ast.OpEqual, $1, $3)
       }
     | primary_expression _NEQ_ primary_expression
       {
-        $$ = &ast.Operation{
-          Operator: ast.OpNotEqual,
-          Operands: []ast.Expression{$1, $3},
-        }
+        $$ = asLexer(yrlex).nodes.newOperation(ast.OpNotEqual, $1, $3)
       }
     | primary_expression
       {
@@ -1118,10 +1148,8 @@ string_set
     | _THEM_
       {
         lexer := asLexer(yrlex)
-        if len(lexer.strings) == 0 {
-          return lexer.setError(
-            gyperror.UndefinedStringIdentifierError,
-            `undefined string identifier: %s`, ast.KeywordThem)
+        if len(lexer.strings) == 0 && !lexer.standalone {
+          return lexer.setUndefinedStringError(string(ast.KeywordThem), $<pos>1, $<end>1)
         }
         $$ = ast.KeywordThem
       }
@@ -1146,10 +1174,8 @@ string_enumeration_item
         identifier := strings.TrimPrefix($1, "$")
         lexer := asLexer(yrlex)
         // Anonymous strings ($) in string enumerations are an error.
-        if _, ok := lexer.strings[identifier]; !ok || identifier == "" {
-          return lexer.setError(
-            gyperror.UndefinedStringIdentifierError,
-            `undefined string identifier: %s`, $1)
+        if _, ok := lexer.strings[identifier]; !ok && !lexer.standalone || identifier == "" {
+          return lexer.setUndefinedStringError($1, $<pos>1, $<end>1)
         }
         $$ = &ast.StringIdentifier{
           Identifier: identifier,
@@ -1160,10 +1186,8 @@ string_enumeration_item
       identifier := strings.TrimSuffix($1, "*")
       lexer := asLexer(yrlex)
       // There must be at least one defined string.
-      if len(identifier) == 0 && len(lexer.strings) == 0 {
-          return lexer.setError(
-            gyperror.UndefinedStringIdentifierError,
-            `undefined string identifier: %s`, $1)
+      if len(identifier) == 0 && len(lexer.strings) == 0 && !lexer.standalone {
+          return lexer.setUndefinedStringError($1, $<pos>1, $<end>1)
       }
 
       // There must be at least one string that will match the wildcard.
@@ -1175,10 +1199,8 @@ string_enumeration_item
           break
         }
       }
-      if !match {
-        return lexer.setError(
-          gyperror.UndefinedStringIdentifierError,
-          `undefined string identifier: %s`, $1)
+      if !match && !lexer.standalone {
+        return lexer.setUndefinedStringError($1, $<pos>1, $<end>1)
       }
       // Can't use "identifier" here as that has the asterisk stripped already.
       $$ = &ast.StringIdentifier{
@@ -1219,7 +1241,7 @@ rule_enumeration_item
             break
           }
         }
-        if !match {
+        if !match && !lexer.standalone {
           return lexer.setError(
             gyperror.UndefinedRuleIdentifierError,
             `undefined rule identifier: %s`, $1)
@@ -1238,7 +1260,7 @@ rule_enumeration_item
             break
           }
         }
-        if !match {
+        if !match && !lexer.standalone {
           return lexer.setError(
             gyperror.UndefinedRuleIdentifierError,
             `undefined rule identifier: %s`, $1 + "*")
@@ -1336,6 +1358,27 @@ for_variables
       }
     ;
 
+with_declarations
+    : with_declaration
+      {
+        $$ = []*ast.WithDeclaration{$1}
+      }
+    | with_declarations ',' with_declaration
+      {
+        $$ = append($1, $3)
+      }
+    ;
+
+with_declaration
+    : _IDENTIFIER_ '=' expression
+      {
+        $$ = &ast.WithDeclaration{
+          Identifier: $1,
+          Expression: $3,
+        }
+      }
+    ;
+
 iterator
     : identifier
       {
@@ -1371,7 +1414,7 @@ primary_expression
       }
     | _NUMBER_
       {
-        $$ = &ast.LiteralInteger{$1}
+        $$ = asLexer(yrlex).nodes.newLiteralInteger($1, $<radix>1, $<multiplier>1)
       }
     | _DOUBLE_
       {
@@ -1391,10 +1434,8 @@ primary_expression
         identifier := strings.TrimPrefix($1, "#")
         if identifier != "" {
           lexer := asLexer(yrlex)
-          if _, ok := lexer.strings[identifier]; !ok {
-            return lexer.setError(
-              gyperror.UndefinedStringIdentifierError,
-              `undefined string identifier: %s`, $1)
+          if _, ok := lexer.strings[identifier]; !ok && !lexer.standalone {
+            return lexer.setUndefinedStringError($1, $<pos>1, $<end>1)
           }
         }
         $$ = &ast.StringCount{
@@ -1407,10 +1448,8 @@ primary_expression
         identifier := strings.TrimPrefix($1, "#")
         if identifier != "" {
           lexer := asLexer(yrlex)
-          if _, ok := lexer.strings[identifier]; !ok {
-            return lexer.setError(
-              gyperror.UndefinedStringIdentifierError,
-              `undefined string identifier: %s`, $1)
+          if _, ok := lexer.strings[identifier]; !ok && !lexer.standalone {
+            return lexer.setUndefinedStringError($1, $<pos>1, $<end>1)
           }
         }
         $$ = &ast.StringCount{
@@ -1422,10 +1461,8 @@ primary_expression
         identifier := strings.TrimPrefix($1, "@")
         if identifier != "" {
           lexer := asLexer(yrlex)
-          if _, ok := lexer.strings[identifier]; !ok {
-            return lexer.setError(
-              gyperror.UndefinedStringIdentifierError,
-              `undefined string identifier: %s`, $1)
+          if _, ok := lexer.strings[identifier]; !ok && !lexer.standalone {
+            return lexer.setUndefinedStringError($1, $<pos>1, $<end>1)
           }
         }
         $$ = &ast.StringOffset{
@@ -1438,10 +1475,8 @@ primary_expression
         identifier := strings.TrimPrefix($1, "@")
         if identifier != "" {
           lexer := asLexer(yrlex)
-          if _, ok := lexer.strings[identifier]; !ok {
-            return lexer.setError(
-              gyperror.UndefinedStringIdentifierError,
-              `undefined string identifier: %s`, $1)
+          if _, ok := lexer.strings[identifier]; !ok && !lexer.standalone {
+            return lexer.setUndefinedStringError($1, $<pos>1, $<end>1)
           }
         }
         $$ = &ast.StringOffset{
@@ -1453,10 +1488,8 @@ primary_expression
         identifier := strings.TrimPrefix($1, "!")
         if identifier != "" {
           lexer := asLexer(yrlex)
-          if _, ok := lexer.strings[identifier]; !ok {
-            return lexer.setError(
-              gyperror.UndefinedStringIdentifierError,
-              `undefined string identifier: %s`, $1)
+          if _, ok := lexer.strings[identifier]; !ok && !lexer.standalone {
+            return lexer.setUndefinedStringError($1, $<pos>1, $<end>1)
           }
         }
         $$ = &ast.StringLength{
@@ -1469,10 +1502,8 @@ primary_expression
         identifier := strings.TrimPrefix($1, "!")
         if identifier != "" {
           lexer := asLexer(yrlex)
-          if _, ok := lexer.strings[identifier]; !ok {
-            return lexer.setError(
-              gyperror.UndefinedStringIdentifierError,
-              `undefined string identifier: %s`, $1)
+          if _, ok := lexer.strings[identifier]; !ok && !lexer.standalone {
+            return lexer.setUndefinedStringError($1, $<pos>1, $<end>1)
           }
         }
         $$ = &ast.StringLength{
@@ -1489,35 +1520,35 @@ primary_expression
       }
     | primary_expression '+' primary_expression
       {
-        $$ = operation(ast.OpAdd, $1, $3)
+        $$ = asLexer(yrlex).nodes.operation(ast.OpAdd, $1, $3)
       }
     | primary_expression '-' primary_expression
       {
-        $$ = operation(ast.OpSub, $1, $3)
+        $$ = asLexer(yrlex).nodes.operation(ast.OpSub, $1, $3)
       }
     | primary_expression '*' primary_expression
       {
-        $$ = operation(ast.OpMul, $1, $3)
+        $$ = asLexer(yrlex).nodes.operation(ast.OpMul, $1, $3)
       }
     | primary_expression '\\' primary_expression
       {
-        $$ = operation(ast.OpDiv, $1, $3)
+        $$ = asLexer(yrlex).nodes.operation(ast.OpDiv, $1, $3)
       }
     | primary_expression '%' primary_expression
       {
-        $$ = operation(ast.OpMod, $1, $3)
+        $$ = asLexer(yrlex).nodes.operation(ast.OpMod, $1, $3)
       }
     | primary_expression '^' primary_expression
       {
-        $$ = operation(ast.OpBitXor, $1, $3)
+        $$ = asLexer(yrlex).nodes.operation(ast.OpBitXor, $1, $3)
       }
     | primary_expression '&' primary_expression
       {
-        $$ = operation(ast.OpBitAnd, $1, $3)
+        $$ = asLexer(yrlex).nodes.operation(ast.OpBitAnd, $1, $3)
       }
     | primary_expression '|' primary_expression
       {
-        $$ = operation(ast.OpBitOr, $1, $3)
+        $$ = asLexer(yrlex).nodes.operation(ast.OpBitOr, $1, $3)
       }
     | '~' primary_expression
       {
@@ -1525,11 +1556,11 @@ primary_expression
       }
     | primary_expression _SHIFT_LEFT_ primary_expression
       {
-        $$ = operation(ast.OpShiftLeft, $1, $3)
+        $$ = asLexer(yrlex).nodes.operation(ast.OpShiftLeft, $1, $3)
       }
     | primary_expression _SHIFT_RIGHT_ primary_expression
       {
-        $$ = operation(ast.OpShiftRight, $1, $3)
+        $$ = asLexer(yrlex).nodes.operation(ast.OpShiftRight, $1, $3)
       }
     | regexp
       {
@@ -1538,22 +1569,3 @@ primary_expression
     ;
 
 %%
-
-
-// This function takes an operator and two operands and returns a Expression
-// representing the operation. If the left operand is an operation of the
-// the same kind than the specified by the operator, the right operand is
-// simply appended to that existing operation. This implies that the operator
-// must be left-associative in order to be used with this function.
-func operation(operator ast.OperatorType, left, right ast.Expression) (n ast.Expression) {
-  if operation, ok := left.(*ast.Operation); ok && operation.Operator == operator {
-    operation.Operands = append(operation.Operands, right)
-    n = operation
-  } else {
-    n = &ast.Operation{
-      Operator: operator,
-      Operands: []ast.Expression{left, right},
-    }
-  }
-  return n
-}
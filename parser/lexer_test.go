@@ -0,0 +1,233 @@
+package parser
+
+import (
+	"fmt"
+	goast "go/ast"
+	goparser "go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// scanResult contains the tokens produced by a scanner, and its state after
+// producing each of them.
+type scanResult struct {
+	Tokens   []YYtype
+	Linenos  []int
+	Contexts []YYcontext
+	// True if the input ends inside a comment, string or regexp.
+	Unterminated bool
+}
+
+func scanWithFlex(input string) scanResult {
+	var r scanResult
+	s := newFlexScanner()
+	s.In = strings.NewReader(input)
+	s.Out = &strings.Builder{}
+	for {
+		t := s.Lex()
+		r.Tokens = append(r.Tokens, t)
+		r.Linenos = append(r.Linenos, s.Lineno)
+		r.Contexts = append(r.Contexts, s.Context)
+		if t.Error.Code != 0 {
+			return r
+		}
+		if t.Token == eof {
+			r.Unterminated = s.start != 1+2*yyInitial
+			return r
+		}
+	}
+}
+
+func scan(input string) scanResult {
+	var r scanResult
+	s := NewScanner()
+	s.In = strings.NewReader(input)
+	for {
+		t := s.Lex()
+		r.Tokens = append(r.Tokens, t)
+		r.Linenos = append(r.Linenos, s.Lineno)
+		r.Contexts = append(r.Contexts, s.Context)
+		if t.Error.Code != 0 {
+			return r
+		}
+		if t.Token == eof {
+			r.Unterminated = s.state != stateInitial
+			return r
+		}
+	}
+}
+
+// testCorpus returns the string literals in the Go test files of this
+// module, which include all the YARA source code used in tests.
+func testCorpus(t *testing.T) []string {
+	var corpus []string
+	fset := token.NewFileSet()
+	err := filepath.Walk("..", func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && strings.HasPrefix(info.Name(), ".") && path != ".." {
+			return filepath.SkipDir
+		}
+		if !strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		f, err := goparser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+		goast.Inspect(f, func(n goast.Node) bool {
+			if lit, ok := n.(*goast.BasicLit); ok && lit.Kind == token.STRING {
+				if s, err := strconv.Unquote(lit.Value); err == nil {
+					corpus = append(corpus, s)
+				}
+			}
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return corpus
+}
+
+// Inputs for corner cases of the lexer, many of them are not valid YARA.
+var lexerCornerCases = []string{
+	"",
+	" \t\r\n ",
+	"rules rule ruleX _rule with without uint8 uint8be int16be uint32x uuint8 int64",
+	"$ $a $a* $* $a** # #a @ @a! ! !a != !== = == <= << < >= >> > . .. ...",
+	"1 12KB 12MB 12K 12MBx 1.5 1. 1..2 0x 0x1F 0x1g 0o 0o17 0o8 0o0 00o7 0x7fffffffffffffff 0x8000000000000000",
+	"99999999999999999999",
+	"9007199254740992KB",
+	"1.5.5 0.0 01.10",
+	`"foo" "\t\r\n\"\\" "\x4a" "\x4" "\q" "\` + "\n" + `"`,
+	`"unterminated` + "\n",
+	`"unterminated`,
+	`"ends with backslash\`,
+	"/foo/ /foo/i /foo/s /foo/is /foo/si /\\// /\\ / /a\\\n/",
+	"/unterminated\n",
+	"/unterminated",
+	"/ends with backslash\\",
+	"/\xff/",
+	"\"\xff\"",
+	"// comment\n/* multi\nline */ /**/ /***/ /*/ */ /* unterminated\n",
+	"// comment at the end",
+	"/*",
+	"{ 01 02 }",
+	"{ 01 ?? [1-2] ( 03 | 04 ) ~05 }",
+	"{ 01 // comment }\n 02 }",
+	"{ 01 /* comment } */ 02 }",
+	"{ 01 // comment at the end }",
+	"{ 01 /* unterminated }",
+	"{}",
+	"{ }",
+	"{ abc }",
+	"{ condition: true }",
+	"{ 0 }",
+	"{ 01 02 ",
+	"{ 01 [-] 02 }",
+	"{ 0g }",
+	"rule a { strings: $a = { 01 } condition: $a }",
+	"a\x00b",
+	"a\x01b",
+	"a\x7fb",
+	"\xc3\xa1",
+	"+-*\\%&|^~(),:;[]?",
+}
+
+// TestScannerMatchesFlex checks that the scanner produces exactly the same
+// tokens as the flex scanner it replaced.
+func TestScannerMatchesFlex(t *testing.T) {
+	inputs := append(testCorpus(t), lexerCornerCases...)
+	for _, input := range inputs {
+		assert.Equal(t, scanWithFlex(input), scan(input), "input: %q", input)
+	}
+}
+
+func FuzzScannerMatchesFlex(f *testing.F) {
+	for _, input := range lexerCornerCases {
+		f.Add(input)
+	}
+	f.Fuzz(func(t *testing.T, input string) {
+		assert.Equal(t, scanWithFlex(input), scan(input), "input: %q", input)
+	})
+}
+
+// benchmarkRules returns a large ruleset for benchmarking the scanners. It
+// doesn't contain hex strings, as both scanners use the hex package for
+// parsing them, which would dominate the results.
+func benchmarkRules() string {
+	var b strings.Builder
+	b.WriteString("import \"pe\"\n\n")
+	for i := 0; i < 2000; i++ {
+		fmt.Fprintf(&b, `
+/*
+  Multi-line comment for rule %[1]d.
+*/
+rule rule_%[1]d : tag1 tag2 {
+  meta:
+    author = "John Doe"
+    version = %[1]d
+  strings:
+    $a = "text string with \x41 escapes\n" wide ascii nocase
+    $b = "MZ" xor(1-255)  // single-line comment
+    $c = /regexp[a-z]+\.(exe|dll)/is
+  condition:
+    uint16(0) == 0x5A4D and filesize < 10MB and
+    #a > 2 and @b[1] < 0x1000 and
+    for any i in (1..#c) : (!c[i] > 10) and
+    pe.number_of_sections >= 3 and 2.5 > 1
+}
+`, i)
+	}
+	return b.String()
+}
+
+// The benchmarks get the tokens as the parser does: the scanner stores their
+// values into the same yrSymType, while the values returned by the flex
+// scanner are copied into it.
+
+var flexValue yrSymType
+
+func BenchmarkScanner(b *testing.B) {
+	rules := benchmarkRules()
+	b.SetBytes(int64(len(rules)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var lval yrSymType
+		s := NewScanner()
+		s.In = strings.NewReader(rules)
+		for s.lexInto(&lval).Token != eof {
+		}
+	}
+}
+
+func BenchmarkFlexScanner(b *testing.B) {
+	rules := benchmarkRules()
+	b.SetBytes(int64(len(rules)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s := newFlexScanner()
+		s.In = strings.NewReader(rules)
+		s.Out = &strings.Builder{}
+		for {
+			t := s.Lex()
+			if t.Token == eof {
+				break
+			}
+			if t.Value != nil {
+				flexValue = *t.Value
+			}
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/VirusTotal/gyp/ast"
+	gyperror "github.com/VirusTotal/gyp/error"
+)
+
+// Limits restricts the resources used while parsing, which is useful for
+// parsing rules that come from untrusted sources. A zero value in any of the
+// fields means no limit.
+type Limits struct {
+	// Maximum size of the input in bytes.
+	MaxInputSize int
+	// Maximum depth of the expression tree in a rule's condition. Each
+	// operator, function call, parenthesized expression and so on adds one
+	// level to the tree.
+	MaxNestingDepth int
+	// Maximum number of rules.
+	MaxRules int
+	// Maximum number of strings in a rule.
+	MaxStringsPerRule int
+	// Maximum number of tokens in a hex string. Each byte, jump and
+	// alternative is a token.
+	MaxHexTokensPerString int
+	// Maximum number of nodes in the AST, counting the rules, their meta
+	// entries, strings and hex tokens, and the nodes in their conditions.
+	MaxNodes int
+}
+
+// ParseWithLimits is like Parse, but returns an error as soon as any of the
+// given limits is exceeded.
+func ParseWithLimits(input io.Reader, limits Limits) (*ast.RuleSet, error) {
+	l := newLexer(0)
+	l.limits = limits
+	if err := parseWith(input, l); err != nil {
+		return nil, err
+	}
+	return l.ruleSet, nil
+}
+
+// ParseStreamWithLimits is like ParseStream, but returns an error as soon as
+// any of the given limits is exceeded.
+func ParseStreamWithLimits(ctx context.Context, input io.Reader, limits Limits, fn func(*ast.Rule) error) (*ast.RuleSet, error) {
+	return parseStream(ctx, input, limits, fn)
+}
+
+// conditionLimits tracks lower bounds of the nesting depth and the number of
+// nodes in a condition while its tokens are read, so that conditions that
+// exceed the limits are rejected before they are parsed completely. The exact
+// values are computed by checkLimits once the rule has been parsed.
+type conditionLimits struct {
+	// True while reading the tokens of a condition.
+	inCondition bool
+	// Depth of the condition at each open parenthesis or bracket.
+	opens []int
+	// Depth at the innermost open parenthesis or bracket, or zero.
+	depth int
+	// Number of unary operators preceding the current token.
+	unary int
+	// Number of tokens that produce at least one node.
+	nodes int
+}
+
+// trackLimits updates the lower bounds of the nesting depth and the number of
+// nodes in the condition with the token that has been just read. It panics if
+// any of them exceeds the lexer's limits. Every parenthesis, bracket and unary
+// operator adds a level to the tree, and every literal, keyword and string
+// identifier adds a node.
+func (l *lexer) trackLimits(token YYtype) {
+	limits := l.limits
+	if limits.MaxNestingDepth == 0 && limits.MaxNodes == 0 {
+		return
+	}
+	c := &l.conditionLimits
+	switch token.Token {
+	case _CONDITION_:
+		*c = conditionLimits{inCondition: true, opens: c.opens[:0]}
+		return
+	case '}':
+		c.inCondition = false
+	}
+	if !c.inCondition {
+		return
+	}
+	switch token.Token {
+	case _NOT_, _DEFINED_, '-', '~':
+		c.unary++
+	case '(', '[':
+		c.opens = append(c.opens, c.depth)
+		c.depth += c.unary + 1
+		c.unary = 0
+	case ')', ']':
+		if n := len(c.opens); n > 0 {
+			c.depth = c.opens[n-1]
+			c.opens = c.opens[:n-1]
+		}
+		c.unary = 0
+	case _NUMBER_, _DOUBLE_, _REGEXP_, _TRUE_, _FALSE_,
+		_FILESIZE_, _ENTRYPOINT_, _THEM_, _INTEGER_FUNCTION_,
+		_STRING_IDENTIFIER_, _STRING_IDENTIFIER_WITH_WILDCARD_,
+		_STRING_COUNT_, _STRING_OFFSET_, _STRING_LENGTH_:
+		c.nodes++
+		c.unary = 0
+	default:
+		c.unary = 0
+	}
+	var err gyperror.Error
+	if limits.MaxNestingDepth > 0 && c.depth+c.unary > limits.MaxNestingDepth {
+		err = gyperror.Error{
+			Code: gyperror.NestingTooDeepError,
+			Message: fmt.Sprintf(`condition in rule "%s" is nested too deeply, the maximum depth is %d`,
+				l.currentRule, limits.MaxNestingDepth),
+		}
+	} else if limits.MaxNodes > 0 && l.numNodes+c.nodes > limits.MaxNodes {
+		err = gyperror.Error{
+			Code:    gyperror.TooManyNodesError,
+			Message: fmt.Sprintf("too many nodes in the syntax tree, the maximum is %d", limits.MaxNodes),
+		}
+	} else {
+		return
+	}
+	err.Line = token.Lineno
+	err.StartPos = token.StartPos
+	err.EndPos = token.EndPos
+	panic(err)
+}
+
+// limitsVisitor computes the number of nodes in a condition and its depth.
+type limitsVisitor struct {
+	nodes    int
+	depth    int
+	maxDepth int
+}
+
+func (v *limitsVisitor) PreOrderVisit(n ast.Node) {
+	v.nodes++
+	v.depth++
+	if v.depth > v.maxDepth {
+		v.maxDepth = v.depth
+	}
+}
+
+func (v *limitsVisitor) PostOrderVisit(n ast.Node) {
+	v.depth--
+}
+
+// checkLimits checks that a rule that has been just parsed doesn't exceed
+// the lexer's limits. If it does the lexer error is set and it returns false.
+func (l *lexer) checkLimits(r *ast.Rule) bool {
+	limits := l.limits
+	l.numRules++
+	if limits.MaxRules > 0 && l.numRules > limits.MaxRules {
+		l.setError(gyperror.TooManyRulesError,
+			"too many rules, the maximum is %d", limits.MaxRules)
+		return false
+	}
+	if limits.MaxStringsPerRule > 0 && len(r.Strings) > limits.MaxStringsPerRule {
+		l.setError(gyperror.TooManyStringsError,
+			`rule "%s" has too many strings, the maximum is %d`, r.Identifier, limits.MaxStringsPerRule)
+		return false
+	}
+	l.numNodes += 1 + len(r.Meta) + len(r.Strings)
+	for _, s := range r.Strings {
+		hex, ok := s.(*ast.HexString)
+		if !ok {
+			continue
+		}
+		n := countHexTokens(hex.Tokens)
+		if limits.MaxHexTokensPerString > 0 && n > limits.MaxHexTokensPerString {
+			l.setError(gyperror.TooManyHexTokensError,
+				`string $%s in rule "%s" has too many hex tokens, the maximum is %d`,
+				hex.Identifier, r.Identifier, limits.MaxHexTokensPerString)
+			return false
+		}
+		l.numNodes += n
+	}
+	if limits.MaxNestingDepth > 0 || limits.MaxNodes > 0 {
+		v := &limitsVisitor{}
+		ast.DepthFirstSearch(r.Condition, v)
+		if limits.MaxNestingDepth > 0 && v.maxDepth > limits.MaxNestingDepth {
+			l.setError(gyperror.NestingTooDeepError,
+				`condition in rule "%s" is nested too deeply, the maximum depth is %d`,
+				r.Identifier, limits.MaxNestingDepth)
+			return false
+		}
+		l.numNodes += v.nodes
+	}
+	if limits.MaxNodes > 0 && l.numNodes > limits.MaxNodes {
+		l.setError(gyperror.TooManyNodesError,
+			"too many nodes in the syntax tree, the maximum is %d", limits.MaxNodes)
+		return false
+	}
+	return true
+}
+
+// countHexTokens returns the number of tokens in a hex string, counting each
+// byte in a sequence as a token.
+func countHexTokens(tokens ast.HexTokens) int {
+	count := 0
+	pending := []ast.HexTokens{tokens}
+	for len(pending) > 0 {
+		tokens := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		for _, t := range tokens {
+			switch v := t.(type) {
+			case *ast.HexBytes:
+				count += len(v.Bytes)
+			case *ast.HexOr:
+				count++
+				for _, alt := range v.Alternatives {
+					if alt, ok := alt.(ast.HexTokens); ok {
+						pending = append(pending, alt)
+					} else {
+						pending = append(pending, ast.HexTokens{alt})
+					}
+				}
+			default:
+				count++
+			}
+		}
+	}
+	return count
+}
@@ -35,7 +35,7 @@ type stringModifiers struct {
 	Base64Alphabet string
 }
 
-//line parser/grammar.y:180
+//line parser/grammar.y:191
 type yrSymType struct {
 	yys       int
 	i64       int64
@@ -59,6 +59,8 @@ type yrSymType struct {
 	si        *ast.StringIdentifier
 	sis       []*ast.StringIdentifier
 	ident     *ast.Identifier
+	decl      *ast.WithDeclaration
+	decls     []*ast.WithDeclaration
 
 	// lineno is not a symbol type, it's the line number where the symbol
 	// appears in the source file. This is a little hack used for passing
@@ -79,6 +81,22 @@ type yrSymType struct {
 	// the N-th symbol in the production rule.
 
 	lineno int
+
+	// pos and end are similar to lineno, but they contain the position within
+	// the source code where the symbol starts (inclusive) and ends (exclusive).
+	// The lexer sets them for every token, but for non-terminal symbols they
+	// must be set explicitly in the rule's actions when required, otherwise
+	// they will have the values of the first symbol in the rule.
+
+	pos int
+	end int
+
+	// radix and multiplier are set by the lexer for _NUMBER_ tokens, besides
+	// i64 they describe how the number is written in the source code. See
+	// the fields with the same name in ast.LiteralInteger.
+
+	radix      int
+	multiplier int64
 }
 
 const _END_OF_INCLUDED_FILE_ = 57346
@@ -117,32 +135,36 @@ const _NONE_ = 57378
 const _IN_ = 57379
 const _OF_ = 57380
 const _FOR_ = 57381
-const _THEM_ = 57382
-const _MATCHES_ = 57383
-const _CONTAINS_ = 57384
-const _ICONTAINS_ = 57385
-const _STARTSWITH_ = 57386
-const _ISTARTSWITH_ = 57387
-const _ENDSWITH_ = 57388
-const _IENDSWITH_ = 57389
-const _IEQUALS_ = 57390
-const _IMPORT_ = 57391
-const _TRUE_ = 57392
-const _FALSE_ = 57393
-const _INCLUDE_ = 57394
-const _DEFINED_ = 57395
-const _OR_ = 57396
-const _AND_ = 57397
-const _NOT_ = 57398
-const _EQ_ = 57399
-const _NEQ_ = 57400
-const _LT_ = 57401
-const _LE_ = 57402
-const _GT_ = 57403
-const _GE_ = 57404
-const _SHIFT_LEFT_ = 57405
-const _SHIFT_RIGHT_ = 57406
-const UNARY_MINUS = 57407
+const _WITH_ = 57382
+const _THEM_ = 57383
+const _MATCHES_ = 57384
+const _CONTAINS_ = 57385
+const _ICONTAINS_ = 57386
+const _STARTSWITH_ = 57387
+const _ISTARTSWITH_ = 57388
+const _ENDSWITH_ = 57389
+const _IENDSWITH_ = 57390
+const _IEQUALS_ = 57391
+const _IMPORT_ = 57392
+const _TRUE_ = 57393
+const _FALSE_ = 57394
+const _INCLUDE_ = 57395
+const _DEFINED_ = 57396
+const _START_EXPRESSION_ = 57397
+const _START_STRING_ = 57398
+const _START_RULE_ = 57399
+const _OR_ = 57400
+const _AND_ = 57401
+const _NOT_ = 57402
+const _EQ_ = 57403
+const _NEQ_ = 57404
+const _LT_ = 57405
+const _LE_ = 57406
+const _GT_ = 57407
+const _GE_ = 57408
+const _SHIFT_LEFT_ = 57409
+const _SHIFT_RIGHT_ = 57410
+const UNARY_MINUS = 57411
 
 var yrToknames = [...]string{
 	"$end",
@@ -184,6 +206,7 @@ var yrToknames = [...]string{
 	"_IN_",
 	"_OF_",
 	"_FOR_",
+	"_WITH_",
 	"_THEM_",
 	"_MATCHES_",
 	"_CONTAINS_",
@@ -198,6 +221,9 @@ var yrToknames = [...]string{
 	"_FALSE_",
 	"_INCLUDE_",
 	"_DEFINED_",
+	"_START_EXPRESSION_",
+	"_START_STRING_",
+	"_START_RULE_",
 	"_OR_",
 	"_AND_",
 	"_NOT_",
@@ -237,256 +263,254 @@ const yrEofCode = 1
 const yrErrCode = 2
 const yrInitialStackSize = 16
 
-//line parser/grammar.y:1540
-
-// This function takes an operator and two operands and returns a Expression
-// representing the operation. If the left operand is an operation of the
-// the same kind than the specified by the operator, the right operand is
-// simply appended to that existing operation. This implies that the operator
-// must be left-associative in order to be used with this function.
-func operation(operator ast.OperatorType, left, right ast.Expression) (n ast.Expression) {
-	if operation, ok := left.(*ast.Operation); ok && operation.Operator == operator {
-		operation.Operands = append(operation.Operands, right)
-		n = operation
-	} else {
-		n = &ast.Operation{
-			Operator: operator,
-			Operands: []ast.Expression{left, right},
-		}
-	}
-	return n
-}
+//line parser/grammar.y:1571
 
 //line yacctab:1
 var yrExca = [...]int16{
 	-1, 1,
 	1, -1,
-	-2, 15,
-	-1, 52,
-	38, 124,
-	-2, 102,
-	-1, 116,
-	38, 124,
-	-2, 102,
-	-1, 180,
-	80, 66,
-	84, 66,
-	-2, 69,
-	-1, 242,
-	80, 67,
-	84, 67,
-	-2, 69,
+	-2, 0,
+	-1, 2,
+	1, 1,
+	-2, 19,
+	-1, 16,
+	38, 129,
+	-2, 107,
+	-1, 89,
+	38, 129,
+	-2, 107,
+	-1, 155,
+	84, 70,
+	88, 70,
+	-2, 73,
+	-1, 170,
+	81, 137,
+	88, 137,
+	-2, 73,
+	-1, 212,
+	84, 71,
+	88, 71,
+	-2, 73,
 }
 
 const yrPrivate = 57344
 
-const yrLast = 484
+const yrLast = 497
 
 var yrAct = [...]int16{
-	52, 205, 204, 203, 49, 165, 68, 158, 166, 83,
-	84, 85, 86, 87, 88, 89, 90, 259, 239, 237,
-	235, 260, 240, 238, 236, 104, 102, 103, 96, 97,
-	92, 94, 93, 95, 105, 106, 98, 99, 100, 101,
-	91, 124, 215, 122, 123, 121, 71, 120, 171, 82,
-	81, 82, 81, 271, 265, 110, 263, 262, 214, 116,
-	170, 75, 258, 115, 194, 264, 250, 245, 117, 244,
-	125, 126, 243, 159, 169, 270, 169, 268, 169, 118,
-	42, 38, 247, 232, 40, 133, 134, 135, 136, 137,
-	138, 139, 141, 142, 143, 144, 145, 146, 147, 148,
-	149, 150, 151, 152, 153, 154, 155, 156, 157, 48,
-	28, 195, 164, 197, 26, 192, 17, 168, 39, 172,
-	18, 174, 175, 241, 177, 113, 114, 173, 229, 180,
-	132, 81, 75, 53, 65, 66, 67, 55, 62, 63,
-	61, 64, 162, 76, 82, 81, 190, 76, 112, 191,
-	130, 131, 59, 60, 72, 73, 74, 255, 119, 54,
-	193, 100, 101, 163, 141, 30, 199, 196, 161, 254,
-	50, 51, 198, 57, 102, 103, 56, 98, 99, 100,
-	101, 163, 105, 106, 98, 99, 100, 101, 163, 69,
-	5, 107, 109, 70, 209, 37, 13, 108, 8, 58,
-	234, 227, 230, 104, 102, 103, 233, 269, 35, 77,
-	79, 78, 105, 106, 98, 99, 100, 101, 163, 256,
-	242, 80, 33, 36, 208, 75, 246, 65, 66, 67,
-	249, 62, 63, 61, 64, 7, 76, 231, 4, 176,
-	251, 252, 253, 41, 31, 59, 60, 72, 73, 74,
-	83, 84, 85, 86, 87, 88, 89, 90, 208, 206,
-	206, 267, 23, 207, 207, 20, 104, 102, 103, 96,
-	97, 92, 94, 93, 95, 105, 106, 98, 99, 100,
-	101, 91, 69, 14, 25, 22, 70, 104, 102, 103,
-	189, 44, 111, 127, 43, 27, 105, 106, 98, 99,
-	100, 101, 163, 105, 106, 98, 99, 100, 101, 163,
-	9, 11, 12, 213, 15, 1, 202, 167, 201, 261,
-	104, 102, 103, 46, 47, 200, 160, 266, 228, 105,
-	106, 98, 99, 100, 101, 163, 208, 206, 104, 102,
-	103, 207, 45, 226, 248, 209, 212, 105, 106, 98,
-	99, 100, 101, 163, 75, 178, 65, 66, 67, 103,
-	62, 63, 61, 64, 211, 76, 105, 106, 98, 99,
-	100, 101, 163, 179, 59, 60, 32, 6, 10, 75,
-	140, 65, 66, 67, 129, 62, 63, 61, 64, 188,
-	76, 128, 182, 104, 102, 103, 181, 216, 34, 59,
-	60, 24, 105, 106, 98, 99, 100, 101, 163, 225,
-	21, 69, 29, 19, 16, 70, 257, 187, 104, 102,
-	103, 111, 2, 3, 0, 0, 0, 105, 106, 98,
-	99, 100, 101, 163, 184, 183, 69, 185, 186, 0,
-	70, 210, 104, 102, 103, 0, 111, 0, 0, 0,
-	0, 105, 106, 98, 99, 100, 101, 163, 0, 0,
-	221, 104, 102, 103, 0, 171, 0, 0, 0, 0,
-	105, 106, 98, 99, 100, 101, 163, 218, 217, 224,
-	219, 220, 222, 223,
+	90, 16, 42, 266, 12, 178, 177, 176, 13, 140,
+	130, 33, 83, 141, 248, 209, 188, 207, 249, 210,
+	80, 208, 86, 87, 205, 89, 288, 137, 206, 74,
+	72, 73, 88, 164, 138, 94, 98, 99, 75, 76,
+	68, 69, 70, 71, 135, 97, 93, 95, 96, 270,
+	269, 187, 102, 103, 145, 186, 105, 106, 107, 108,
+	109, 110, 111, 113, 114, 115, 116, 117, 118, 119,
+	120, 121, 122, 123, 124, 125, 126, 127, 128, 129,
+	253, 144, 252, 136, 165, 40, 52, 51, 251, 247,
+	272, 52, 51, 147, 144, 149, 150, 144, 152, 52,
+	51, 271, 229, 148, 131, 168, 155, 40, 17, 30,
+	31, 32, 268, 27, 28, 26, 29, 259, 41, 91,
+	278, 139, 160, 167, 100, 230, 161, 24, 25, 37,
+	38, 39, 281, 163, 18, 19, 162, 113, 264, 143,
+	256, 226, 201, 166, 193, 280, 14, 15, 170, 22,
+	222, 169, 74, 72, 73, 21, 198, 70, 71, 135,
+	211, 75, 76, 68, 69, 70, 71, 135, 34, 202,
+	52, 51, 35, 51, 204, 199, 196, 36, 23, 85,
+	134, 40, 203, 30, 31, 32, 92, 27, 28, 26,
+	29, 261, 41, 3, 4, 5, 225, 212, 41, 260,
+	228, 24, 25, 37, 38, 39, 133, 172, 74, 72,
+	73, 182, 50, 171, 231, 232, 233, 75, 76, 68,
+	69, 70, 71, 135, 181, 179, 74, 72, 73, 180,
+	250, 104, 45, 182, 185, 75, 76, 68, 69, 70,
+	71, 135, 34, 290, 77, 279, 35, 73, 257, 246,
+	78, 258, 81, 43, 75, 76, 68, 69, 70, 71,
+	135, 68, 69, 70, 71, 135, 219, 276, 262, 277,
+	53, 54, 55, 56, 57, 58, 59, 60, 282, 9,
+	181, 179, 289, 216, 215, 180, 217, 218, 267, 74,
+	72, 73, 66, 67, 62, 64, 63, 65, 75, 76,
+	68, 69, 70, 71, 61, 75, 76, 68, 69, 70,
+	71, 135, 146, 53, 54, 55, 56, 57, 58, 59,
+	60, 284, 179, 245, 283, 11, 180, 181, 8, 156,
+	158, 157, 74, 72, 73, 66, 67, 62, 64, 63,
+	65, 75, 76, 68, 69, 70, 71, 61, 74, 72,
+	73, 224, 200, 84, 286, 287, 151, 75, 76, 68,
+	69, 70, 71, 135, 40, 101, 30, 31, 32, 20,
+	27, 28, 26, 29, 184, 41, 285, 274, 255, 46,
+	48, 49, 221, 244, 24, 25, 189, 263, 79, 40,
+	112, 30, 31, 32, 159, 27, 28, 26, 29, 194,
+	41, 6, 2, 74, 72, 73, 1, 44, 175, 24,
+	25, 142, 75, 76, 68, 69, 70, 71, 135, 174,
+	173, 82, 132, 197, 195, 34, 183, 227, 153, 35,
+	74, 72, 73, 154, 273, 81, 10, 47, 191, 75,
+	76, 68, 69, 70, 71, 135, 220, 190, 214, 213,
+	34, 234, 275, 146, 35, 74, 72, 73, 254, 243,
+	81, 265, 223, 192, 75, 76, 68, 69, 70, 71,
+	135, 72, 73, 239, 7, 0, 0, 0, 0, 75,
+	76, 68, 69, 70, 71, 135, 0, 0, 0, 0,
+	236, 235, 242, 237, 238, 240, 241,
 }
 
 var yrPact = [...]int16{
-	-1000, 186, -1000, -1000, 177, -1000, 304, 175, -1000, 271,
-	-1000, -1000, -1000, -1000, -1000, 39, 45, 253, 276, 250,
-	-1000, 274, 37, -1000, -1000, 33, 232, 211, 210, 232,
-	-1000, 3, 42, 7, 210, -1000, 2, -1000, 273, -1000,
-	120, -1000, 188, -1000, -1000, 203, -1000, -1000, 90, -1000,
-	-1000, -1000, 209, 160, 213, 110, 120, 120, 120, -1000,
-	-1000, 0, -1000, -1000, -1000, 121, -35, -37, -38, 367,
-	367, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
-	-1000, 120, 120, 124, 367, 367, 367, 367, 367, 367,
-	367, 342, 367, 367, 367, 367, 367, 367, 367, 367,
-	367, 367, 367, 367, 367, 367, 367, 367, -6, 130,
-	146, 367, 38, -1000, -1000, -20, -32, 90, 367, -6,
-	367, 367, 227, 367, 120, -1000, -1000, -1000, 410, 283,
-	-1000, 76, -1000, 146, 146, 146, 146, 146, 146, 146,
-	36, -1000, 146, 146, 146, 146, 146, 146, 91, 91,
-	-1000, -1000, 300, 237, 116, 109, 109, 146, -1000, 367,
-	27, 34, -1000, 367, 385, 135, -1000, -1000, 324, -1000,
-	-1000, -1000, 361, -1000, 281, 263, -1000, 230, -22, -42,
-	-1000, 453, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
-	-1000, -1000, 246, 404, 49, 225, 6, 247, -6, 367,
-	-60, -61, -62, -1000, -1000, -1000, -1000, -1000, 53, -1000,
-	-1000, -1000, -1000, -1000, -1000, 120, -1000, -1000, -1000, -1000,
-	-1000, -1000, -7, -10, -12, 367, 5, -38, -1000, 367,
-	-1000, -1000, -13, -1000, 146, -1000, 247, -1000, 212, -1000,
-	173, -1000, -1000, 148, 136, 201, 336, -17, -63, 404,
-	120, -1000, -1000, -1000, -23, -24, -15, -1000, 120, -1000,
-	367, -3, -1000, -1000, -1000, 189, -5, 146, -1000, -27,
-	-1000, -1000,
+	138, -32768, 275, 95, 240, -32768, -32768, -32768, 211, -32768,
+	373, 191, 112, -32768, -32768, -32768, 271, 213, 169, 341,
+	141, 95, 95, 95, -32768, -32768, 36, -32768, -32768, -32768,
+	149, -40, -51, -38, 377, 377, -32768, -32768, -32768, -32768,
+	-32768, -32768, -32768, 42, -32768, -32768, 353, -32768, -32768, -32768,
+	-32768, 95, 95, 175, 377, 377, 377, 377, 377, 377,
+	377, 352, 377, 377, 377, 377, 377, 377, 377, 377,
+	377, 377, 377, 377, 377, 377, 377, 377, 21, 168,
+	91, 377, -54, -32768, 39, 56, -32768, -32768, -30, 228,
+	112, 377, 21, 377, 377, 344, 377, 95, -32768, -32768,
+	308, -32768, -32768, 114, -32768, 91, 91, 91, 91, 91,
+	91, 91, 53, -32768, 91, 91, 91, 91, 91, 91,
+	83, 83, -32768, -32768, 184, 235, 409, 189, 189, 91,
+	-32768, 377, -4, 40, -32768, 377, 369, 22, 341, 95,
+	176, -32768, -32768, 212, -32768, -32768, -32768, 342, -32768, 287,
+	147, -32768, -32, -33, -72, -32768, -32768, -32768, -32768, 63,
+	-32768, -32768, 268, 394, 73, 340, 61, 309, 95, -32768,
+	-32768, 21, 377, -60, -67, -69, -32768, -32768, -32768, -32768,
+	-32768, 86, -32768, -32768, -32768, -32768, -32768, -32768, 95, -32768,
+	259, 375, 71, 339, 377, 60, -38, -32768, 377, -32768,
+	-32768, 19, 41, -32768, 91, -32768, 309, -32768, 315, -32768,
+	190, -32768, -32768, 466, -32768, -32768, -32768, -32768, -32768, -32768,
+	-32768, -32768, 374, 311, -32768, 165, 6, -70, 394, 95,
+	-32768, -32768, -32768, -32768, -32768, -32768, -32768, -32768, -32768, -32768,
+	5, -1, -3, 368, 59, -32768, -32768, 95, -32768, 377,
+	33, 178, 170, 250, -32768, 57, 276, 28, 91, -32768,
+	-34, -35, 17, 366, 240, 276, -32768, 38, -32768, -32768,
+	-32768, -32768, 227, 65, 51, 240, -32768, -32768, 303, -58,
+	-32768, 95, -32768, -32768, -32768, 225, -32768, -32768, -32768, 112,
+	-32768,
 }
 
 var yrPgo = [...]int16{
-	0, 423, 422, 414, 413, 165, 412, 410, 401, 208,
-	398, 397, 396, 392, 391, 389, 384, 378, 377, 376,
-	4, 68, 0, 6, 373, 355, 137, 344, 343, 328,
-	5, 46, 7, 326, 325, 3, 8, 318, 2, 317,
-	316, 1, 315, 314, 295, 293,
+	0, 474, 401, 463, 462, 3, 461, 459, 458, 2,
+	452, 451, 449, 448, 447, 446, 438, 437, 436, 434,
+	8, 0, 1, 11, 433, 428, 369, 427, 424, 423,
+	9, 177, 10, 422, 421, 12, 420, 7, 13, 419,
+	6, 411, 408, 5, 406, 402, 394, 387, 386,
 }
 
 var yrR1 = [...]int8{
-	0, 42, 42, 42, 42, 42, 1, 43, 44, 2,
-	7, 7, 8, 8, 19, 18, 18, 17, 17, 3,
-	3, 4, 4, 6, 6, 5, 5, 5, 5, 5,
-	10, 10, 45, 9, 9, 9, 12, 12, 11, 11,
-	11, 11, 11, 11, 11, 11, 11, 11, 11, 11,
-	14, 14, 13, 13, 13, 13, 13, 16, 16, 15,
-	23, 23, 23, 23, 25, 25, 24, 24, 31, 21,
-	20, 20, 20, 20, 20, 20, 20, 20, 20, 20,
+	0, 44, 44, 44, 44, 45, 45, 45, 45, 45,
+	1, 46, 47, 2, 7, 7, 8, 8, 19, 18,
+	18, 17, 17, 3, 3, 4, 4, 6, 6, 5,
+	5, 5, 5, 5, 10, 10, 48, 9, 9, 9,
+	12, 12, 11, 11, 11, 11, 11, 11, 11, 11,
+	11, 11, 11, 11, 14, 14, 13, 13, 13, 13,
+	13, 16, 16, 15, 23, 23, 23, 23, 25, 25,
+	24, 24, 31, 21, 20, 20, 20, 20, 20, 20,
 	20, 20, 20, 20, 20, 20, 20, 20, 20, 20,
 	20, 20, 20, 20, 20, 20, 20, 20, 20, 20,
-	20, 20, 20, 20, 29, 29, 32, 27, 27, 30,
-	30, 34, 34, 35, 35, 36, 37, 37, 38, 38,
-	39, 40, 40, 41, 26, 26, 26, 26, 33, 33,
-	28, 28, 22, 22, 22, 22, 22, 22, 22, 22,
+	20, 20, 20, 20, 20, 20, 20, 20, 20, 29,
+	29, 32, 27, 27, 30, 30, 36, 36, 37, 37,
+	38, 39, 39, 40, 40, 41, 42, 42, 43, 26,
+	26, 26, 26, 33, 33, 34, 34, 35, 28, 28,
+	22, 22, 22, 22, 22, 22, 22, 22, 22, 22,
 	22, 22, 22, 22, 22, 22, 22, 22, 22, 22,
-	22, 22, 22, 22, 22, 22, 22, 22, 22,
+	22, 22, 22, 22, 22, 22, 22,
 }
 
 var yrR2 = [...]int8{
-	0, 0, 2, 2, 3, 2, 2, 0, 0, 11,
-	0, 3, 0, 3, 3, 0, 2, 1, 1, 0,
-	2, 1, 2, 1, 2, 3, 3, 4, 3, 3,
-	1, 2, 0, 5, 4, 4, 0, 2, 1, 1,
-	1, 1, 1, 1, 1, 4, 4, 1, 4, 6,
-	0, 2, 1, 1, 1, 1, 1, 0, 2, 1,
-	1, 3, 4, 4, 0, 1, 1, 3, 1, 1,
-	1, 1, 3, 3, 3, 3, 3, 3, 3, 3,
-	1, 3, 3, 9, 8, 5, 5, 3, 3, 3,
-	4, 4, 2, 2, 3, 3, 3, 3, 3, 3,
-	3, 3, 1, 3, 3, 1, 5, 1, 3, 3,
-	1, 1, 3, 1, 1, 3, 1, 3, 1, 2,
-	3, 1, 3, 1, 1, 1, 1, 1, 1, 3,
-	1, 1, 3, 1, 1, 4, 1, 1, 1, 3,
-	1, 4, 1, 4, 1, 1, 2, 3, 3, 3,
-	3, 3, 3, 3, 3, 2, 3, 3, 1,
+	0, 1, 2, 2, 2, 0, 2, 2, 3, 2,
+	2, 0, 0, 11, 0, 3, 0, 3, 3, 0,
+	2, 1, 1, 0, 2, 1, 2, 1, 2, 3,
+	3, 4, 3, 3, 1, 2, 0, 5, 4, 4,
+	0, 2, 1, 1, 1, 1, 1, 1, 1, 4,
+	4, 1, 4, 6, 0, 2, 1, 1, 1, 1,
+	1, 0, 2, 1, 1, 3, 4, 4, 0, 1,
+	1, 3, 1, 1, 1, 1, 3, 3, 3, 3,
+	3, 3, 3, 3, 1, 3, 3, 9, 6, 8,
+	5, 5, 3, 3, 3, 4, 4, 2, 2, 3,
+	3, 3, 3, 3, 3, 3, 3, 1, 3, 3,
+	1, 5, 1, 3, 3, 1, 1, 3, 1, 1,
+	3, 1, 3, 1, 2, 3, 1, 3, 1, 1,
+	1, 1, 1, 1, 3, 1, 3, 3, 1, 1,
+	3, 1, 1, 4, 1, 1, 1, 3, 1, 4,
+	1, 4, 1, 1, 2, 3, 3, 3, 3, 3,
+	3, 3, 3, 2, 3, 3, 1,
 }
 
 var yrChk = [...]int16{
-	-1000, -42, -2, -1, 52, 4, -18, 49, 21, 6,
-	-17, 7, 8, 21, 12, -43, -3, 77, 75, -4,
-	12, -7, 9, 12, -8, 10, 77, -44, 77, -6,
-	-5, 12, -19, 11, -10, -9, 13, -5, 78, 76,
-	77, -9, 78, 21, 18, 69, 50, 51, -21, -20,
-	50, 51, -22, 13, 39, -26, 56, 53, 79, 32,
-	33, 20, 18, 19, 21, 14, 15, 16, -23, 69,
-	73, -31, 34, 35, 36, 12, 23, 21, 23, 22,
-	18, 55, 54, 41, 42, 43, 44, 45, 46, 47,
-	48, 72, 62, 64, 63, 65, 60, 61, 68, 69,
-	70, 71, 58, 59, 57, 66, 67, 31, 37, -26,
-	-22, 79, 38, -21, -21, -20, -22, -21, 79, 37,
-	82, 82, 81, 82, 79, -22, -22, -45, -14, -16,
-	-21, -21, -31, -22, -22, -22, -22, -22, -22, -22,
-	38, -22, -22, -22, -22, -22, -22, -22, -22, -22,
-	-22, -22, -22, -22, -22, -22, -22, -22, -32, 79,
-	-33, 38, 12, 72, -22, -30, -36, -39, 79, 40,
-	80, 80, -22, -32, -22, -22, 12, -22, -25, -24,
-	-20, -12, -13, 25, 24, 27, 28, 7, -15, 7,
-	-30, -36, 79, -22, 37, 84, -30, 79, 37, 31,
-	-34, -37, -40, -35, -38, -41, 13, 17, 12, 21,
-	80, 83, 83, 83, 80, 84, -11, 25, 24, 27,
-	28, 7, 29, 30, 26, 5, -28, -23, -29, 79,
-	-32, 12, 77, -32, -22, 80, 84, 80, 84, 80,
-	84, 70, -20, 79, 79, 79, -22, 77, -27, -22,
-	79, -35, -38, -41, 21, 21, 18, 80, 79, 80,
-	84, -21, 80, 80, 80, 69, -21, -22, 80, 18,
-	80, 80,
+	-32768, -44, -45, 55, 56, 57, -2, -1, 53, 4,
+	-18, 50, -21, -20, 51, 52, -22, 13, 39, 40,
+	-26, 60, 54, 83, 32, 33, 20, 18, 19, 21,
+	14, 15, 16, -23, 73, 77, -31, 34, 35, 36,
+	12, 23, -9, 13, -2, 21, 6, -17, 7, 8,
+	21, 59, 58, 42, 43, 44, 45, 46, 47, 48,
+	49, 76, 66, 68, 67, 69, 64, 65, 72, 73,
+	74, 75, 62, 63, 61, 70, 71, 31, 37, -26,
+	-22, 83, -34, -35, 12, 38, -21, -21, -20, -22,
+	-21, 83, 37, 86, 86, 85, 86, 83, -22, -22,
+	82, 12, -21, -21, -31, -22, -22, -22, -22, -22,
+	-22, -22, 38, -22, -22, -22, -22, -22, -22, -22,
+	-22, -22, -22, -22, -22, -22, -22, -22, -22, -22,
+	-32, 83, -33, 38, 12, 76, -22, 81, 88, 82,
+	-30, -38, -41, 83, 41, 84, 84, -22, -32, -22,
+	-22, 12, -22, -25, -24, -20, 21, 23, 22, -46,
+	-30, -38, 83, -22, 37, 88, -30, 83, 83, -35,
+	-20, 37, 31, -36, -39, -42, -37, -40, -43, 13,
+	17, 12, 21, 84, 87, 87, 87, 84, 88, -48,
+	-14, -16, -3, 81, 5, -28, -23, -29, 83, -32,
+	12, 81, -21, -32, -22, 84, 88, 84, 88, 84,
+	88, 74, -20, -12, -13, 25, 24, 27, 28, 7,
+	-15, 7, 79, -4, 12, -22, 81, -27, -22, 83,
+	84, -37, -40, -43, -11, 25, 24, 27, 28, 7,
+	29, 30, 26, -7, 9, 12, 84, 83, 84, 88,
+	-21, 83, 83, 83, -8, 10, 81, -21, -22, 84,
+	21, 21, 18, -47, 81, -6, -5, 12, 84, 84,
+	84, 84, 73, -19, 11, -10, -9, -5, 82, 18,
+	80, 81, -9, 21, 18, 73, 51, 52, 84, -21,
+	18,
 }
 
 var yrDef = [...]int16{
-	1, -2, 2, 3, 0, 5, 0, 0, 4, 0,
-	16, 17, 18, 6, 7, 19, 0, 0, 10, 20,
-	21, 12, 0, 22, 8, 0, 0, 0, 0, 11,
-	23, 0, 0, 0, 13, 30, 0, 24, 0, 9,
-	0, 31, 0, 25, 26, 0, 28, 29, 14, 69,
-	70, 71, -2, 80, 0, 0, 0, 0, 0, 133,
-	134, 0, 136, 137, 138, 140, 142, 144, 145, 0,
-	0, 158, 125, 126, 127, 60, 68, 32, 50, 57,
-	27, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	5, -2, -2, 0, 0, 19, 6, 7, 0, 9,
+	0, 0, 2, 73, 74, 75, -2, 84, 0, 0,
+	0, 0, 0, 0, 141, 142, 0, 144, 145, 146,
+	148, 150, 152, 153, 0, 0, 166, 130, 131, 132,
+	64, 72, 3, 0, 4, 8, 0, 20, 21, 22,
+	10, 0, 0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
-	124, 0, 0, 92, 93, 69, -2, 0, 0, 0,
-	0, 0, 0, 0, 64, 146, 155, 36, 34, 35,
-	94, 95, 72, 73, 74, 75, 76, 77, 78, 79,
-	0, 151, 96, 97, 98, 99, 100, 101, 147, 148,
-	149, 150, 152, 153, 154, 156, 157, 81, 82, 0,
-	0, 0, 128, 0, 0, 87, 88, 89, 0, 110,
-	103, 132, 0, 139, 0, 0, 61, 0, 0, 65,
-	-2, 33, 51, 52, 53, 54, 55, 56, 58, 59,
-	90, 91, 0, 0, 0, 0, 0, 0, 0, 0,
-	0, 0, 0, 111, 116, 121, 113, 114, 118, 123,
-	135, 141, 143, 62, 63, 0, 37, 38, 39, 40,
-	41, 42, 43, 44, 47, 0, 0, 130, 131, 0,
-	105, 129, 0, 85, 86, 109, 0, 115, 0, 120,
-	0, 119, -2, 0, 0, 0, 0, 0, 0, 107,
-	0, 112, 117, 122, 0, 0, 0, 106, 0, 104,
-	0, 0, 45, 46, 48, 0, 0, 108, 84, 0,
-	83, 49,
+	129, 0, 0, 135, 0, 0, 97, 98, 73, -2,
+	0, 0, 0, 0, 0, 0, 0, 68, 154, 163,
+	0, 11, 99, 100, 76, 77, 78, 79, 80, 81,
+	82, 83, 0, 159, 101, 102, 103, 104, 105, 106,
+	155, 156, 157, 158, 160, 161, 162, 164, 165, 85,
+	86, 0, 0, 0, 133, 0, 0, 0, 0, 0,
+	92, 93, 94, 0, 115, 108, 140, 0, 147, 0,
+	0, 65, 0, 0, 69, -2, 36, 54, 61, 23,
+	95, 96, 0, 0, 0, 0, 0, 0, 0, 136,
+	-2, 0, 0, 0, 0, 0, 116, 121, 126, 118,
+	119, 123, 128, 143, 149, 151, 66, 67, 0, 40,
+	38, 39, 0, 0, 0, 0, 138, 139, 0, 110,
+	134, 0, 0, 90, 91, 114, 0, 120, 0, 125,
+	0, 124, -2, 37, 55, 56, 57, 58, 59, 60,
+	62, 63, 14, 24, 25, 0, 0, 0, 112, 0,
+	88, 117, 122, 127, 41, 42, 43, 44, 45, 46,
+	47, 48, 51, 16, 0, 26, 111, 0, 109, 0,
+	0, 0, 0, 0, 12, 0, 0, 0, 113, 89,
+	0, 0, 0, 0, 0, 15, 27, 0, 87, 49,
+	50, 52, 0, 0, 0, 17, 34, 28, 0, 0,
+	13, 0, 35, 29, 30, 0, 32, 33, 53, 18,
+	31,
 }
 
 var yrTok1 = [...]int8{
 	1, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
-	3, 3, 3, 3, 3, 3, 3, 72, 59, 3,
-	79, 80, 70, 68, 84, 69, 81, 3, 3, 3,
-	3, 3, 3, 3, 3, 3, 3, 3, 77, 3,
-	3, 78, 3, 3, 3, 3, 3, 3, 3, 3,
+	3, 3, 3, 3, 3, 3, 3, 76, 63, 3,
+	83, 84, 74, 72, 88, 73, 85, 3, 3, 3,
+	3, 3, 3, 3, 3, 3, 3, 3, 81, 3,
+	3, 82, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
-	3, 82, 71, 83, 58, 3, 3, 3, 3, 3,
+	3, 86, 75, 87, 62, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
-	3, 3, 3, 75, 57, 76, 73,
+	3, 3, 3, 79, 61, 80, 77,
 }
 
 var yrTok2 = [...]int8{
@@ -495,8 +519,8 @@ var yrTok2 = [...]int8{
 	22, 23, 24, 25, 26, 27, 28, 29, 30, 31,
 	32, 33, 34, 35, 36, 37, 38, 39, 40, 41,
 	42, 43, 44, 45, 46, 47, 48, 49, 50, 51,
-	52, 53, 54, 55, 56, 60, 61, 62, 63, 64,
-	65, 66, 67, 74,
+	52, 53, 54, 55, 56, 57, 58, 59, 60, 64,
+	65, 66, 67, 68, 69, 70, 71, 78,
 }
 
 var yrTok3 = [...]int8{
@@ -542,7 +566,7 @@ func yrNewParser() yrParser {
 	return &yrParserImpl{}
 }
 
-const yrFlag = -1000
+const yrFlag = -32768
 
 func yrTokname(c int) string {
 	if c >= 1 && c-1 < len(yrToknames) {
@@ -842,34 +866,64 @@ yrdefault:
 
 	case 2:
 		yrDollar = yrS[yrpt-2 : yrpt+1]
-//line parser/grammar.y:230
+//line parser/grammar.y:261
 		{
-			ruleSet := asLexer(yrlex).ruleSet
-			ruleSet.Rules = append(ruleSet.Rules, yrDollar[2].rule)
+			asLexer(yrlex).fragment = yrDollar[2].expr
 		}
 	case 3:
 		yrDollar = yrS[yrpt-2 : yrpt+1]
-//line parser/grammar.y:235
+//line parser/grammar.y:265
 		{
-			ruleSet := asLexer(yrlex).ruleSet
-			ruleSet.Imports = append(ruleSet.Imports, yrDollar[2].s)
+			asLexer(yrlex).fragment = yrDollar[2].ys
 		}
 	case 4:
+		yrDollar = yrS[yrpt-2 : yrpt+1]
+//line parser/grammar.y:269
+		{
+			asLexer(yrlex).fragment = yrDollar[2].rule
+		}
+	case 6:
+		yrDollar = yrS[yrpt-2 : yrpt+1]
+//line parser/grammar.y:278
+		{
+			lexer := asLexer(yrlex)
+			// When streaming, rules are passed to the callback instead of being
+			// stored in the ruleset.
+			if lexer.onRule != nil {
+				if err := lexer.onRule(yrDollar[2].rule); err != nil {
+					lexer.abort = err
+					return 1
+				}
+			} else {
+				lexer.ruleSet.Rules = append(lexer.ruleSet.Rules, yrDollar[2].rule)
+				lexer.ruleSpans = append(lexer.ruleSpans, Span{yrDollar[2].pos, yrDollar[2].end})
+			}
+		}
+	case 7:
+		yrDollar = yrS[yrpt-2 : yrpt+1]
+//line parser/grammar.y:293
+		{
+			lexer := asLexer(yrlex)
+			lexer.ruleSet.Imports = append(lexer.ruleSet.Imports, yrDollar[2].s)
+			lexer.otherSpans = append(lexer.otherSpans, Span{yrDollar[2].pos, yrDollar[2].end})
+		}
+	case 8:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:240
+//line parser/grammar.y:299
 		{
-			ruleSet := asLexer(yrlex).ruleSet
-			ruleSet.Includes = append(ruleSet.Includes, yrDollar[3].s)
+			lexer := asLexer(yrlex)
+			lexer.ruleSet.Includes = append(lexer.ruleSet.Includes, yrDollar[3].s)
+			lexer.otherSpans = append(lexer.otherSpans, Span{yrDollar[2].pos, yrDollar[3].end})
 		}
-	case 5:
+	case 9:
 		yrDollar = yrS[yrpt-2 : yrpt+1]
-//line parser/grammar.y:245
+//line parser/grammar.y:305
 		{
 
 		}
-	case 6:
+	case 10:
 		yrDollar = yrS[yrpt-2 : yrpt+1]
-//line parser/grammar.y:253
+//line parser/grammar.y:313
 		{
 			if err := validateAscii(yrDollar[2].s); err != nil {
 				return asLexer(yrlex).setError(
@@ -877,19 +931,19 @@ yrdefault:
 			}
 
 			yrVAL.s = yrDollar[2].s
+			yrVAL.end = yrDollar[2].end
 		}
-	case 7:
+	case 11:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:266
+//line parser/grammar.y:327
 		{
 			lexer := asLexer(yrlex)
 
-			// Forbid duplicate rules
-			for _, r := range lexer.ruleSet.Rules {
-				if yrDollar[3].s == r.Identifier {
-					return lexer.setError(
-						gyperror.DuplicateRuleError, `duplicate rule "%s"`, yrDollar[3].s)
-				}
+			// Forbid duplicate rules. The rules map is used instead of the rules
+			// in the ruleset, which are not retained while streaming.
+			if lexer.rules[yrDollar[3].s] {
+				return lexer.setError(
+					gyperror.DuplicateRuleError, `duplicate rule "%s"`, yrDollar[3].s)
 			}
 
 			// Forbid any rule which matches our rules wildcard table. This ensures
@@ -914,14 +968,18 @@ yrdefault:
 			yrVAL.lineno = yrDollar[1].lineno
 
 			// ... or the line number of the "rule" keyword if the rule doesn't
-			// have any modifiers.
+			// have any modifiers. The same applies to the rule's position.
+			yrVAL.pos = yrDollar[1].pos
+
 			if yrVAL.lineno == -1 {
 				yrVAL.lineno = yrDollar[2].lineno
+				yrVAL.pos = yrDollar[2].pos
 			}
 
 			// Store the rule identifier for lookup to ensure rule references are
 			// always defined.
 			lexer.rules[yrDollar[3].s] = true
+			lexer.currentRule = yrDollar[3].s
 
 			yrVAL.rule = &ast.Rule{
 				LineNo:     yrVAL.lineno,
@@ -930,9 +988,9 @@ yrdefault:
 				Identifier: yrDollar[3].s,
 			}
 		}
-	case 8:
+	case 12:
 		yrDollar = yrS[yrpt-8 : yrpt+1]
-//line parser/grammar.y:316
+//line parser/grammar.y:380
 		{
 			// Check for duplicate strings.
 			m := make(map[string]bool)
@@ -954,100 +1012,112 @@ yrdefault:
 			yrDollar[4].rule.Meta = yrDollar[7].metas
 			yrDollar[4].rule.Strings = yrDollar[8].yss
 		}
-	case 9:
+	case 13:
 		yrDollar = yrS[yrpt-11 : yrpt+1]
-//line parser/grammar.y:338
+//line parser/grammar.y:402
 		{
 			yrDollar[4].rule.Condition = yrDollar[10].expr
+			if !asLexer(yrlex).checkLimits(yrDollar[4].rule) {
+				return 1
+			}
 			yrVAL.rule = yrDollar[4].rule
+			yrVAL.pos = yrDollar[4].pos
+			yrVAL.end = yrDollar[11].end
 
 			// Clear the strings map for the next rule being parsed.
-			asLexer(yrlex).strings = make(map[string]bool)
+			lexer := asLexer(yrlex)
+			lexer.strings = make(map[string]bool)
+			lexer.currentRule = ""
 		}
-	case 10:
+	case 14:
 		yrDollar = yrS[yrpt-0 : yrpt+1]
-//line parser/grammar.y:350
+//line parser/grammar.y:421
 		{
 			yrVAL.metas = []*ast.Meta{}
 		}
-	case 11:
+	case 15:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:354
+//line parser/grammar.y:425
 		{
 			yrVAL.metas = yrDollar[3].metas
 		}
-	case 12:
+	case 16:
 		yrDollar = yrS[yrpt-0 : yrpt+1]
-//line parser/grammar.y:362
+//line parser/grammar.y:433
 		{
 			yrVAL.yss = []ast.String{}
 		}
-	case 13:
+	case 17:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:366
+//line parser/grammar.y:437
 		{
 			yrVAL.yss = yrDollar[3].yss
 		}
-	case 14:
+	case 18:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:374
+//line parser/grammar.y:445
 		{
 			yrVAL.expr = yrDollar[3].expr
 		}
-	case 15:
+	case 19:
 		yrDollar = yrS[yrpt-0 : yrpt+1]
-//line parser/grammar.y:382
+//line parser/grammar.y:453
 		{
 			yrVAL.mod = 0
 			yrVAL.lineno = -1
+			yrVAL.pos = -1
 		}
-	case 16:
+	case 20:
 		yrDollar = yrS[yrpt-2 : yrpt+1]
-//line parser/grammar.y:387
+//line parser/grammar.y:459
 		{
 			yrVAL.mod = yrDollar[1].mod | yrDollar[2].mod
 
 			if yrDollar[1].lineno == -1 {
 				yrVAL.lineno = yrDollar[2].lineno
+				yrVAL.pos = yrDollar[2].pos
 			} else {
 				yrVAL.lineno = yrDollar[1].lineno
+				yrVAL.pos = yrDollar[1].pos
 			}
 		}
-	case 17:
+	case 21:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:401
+//line parser/grammar.y:475
 		{
 			yrVAL.mod = ModPrivate
 			yrVAL.lineno = yrDollar[1].lineno
+			yrVAL.pos = yrDollar[1].pos
 		}
-	case 18:
+	case 22:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:406
+//line parser/grammar.y:481
 		{
 			yrVAL.mod = ModGlobal
 			yrVAL.lineno = yrDollar[1].lineno
+			yrVAL.pos = yrDollar[1].pos
 		}
-	case 19:
+	case 23:
 		yrDollar = yrS[yrpt-0 : yrpt+1]
-//line parser/grammar.y:415
+//line parser/grammar.y:491
 		{
 			yrVAL.ss = []string{}
 		}
-	case 20:
+	case 24:
 		yrDollar = yrS[yrpt-2 : yrpt+1]
-//line parser/grammar.y:419
+//line parser/grammar.y:495
 		{
 			yrVAL.ss = yrDollar[2].ss
 		}
-	case 21:
+	case 25:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:427
+//line parser/grammar.y:503
 		{
 			yrVAL.ss = []string{yrDollar[1].s}
 		}
-	case 22:
+	case 26:
 		yrDollar = yrS[yrpt-2 : yrpt+1]
-//line parser/grammar.y:431
+//line parser/grammar.y:507
 		{
 			lexer := asLexer(yrlex)
 
@@ -1060,91 +1130,91 @@ yrdefault:
 
 			yrVAL.ss = append(yrDollar[1].ss, yrDollar[2].s)
 		}
-	case 23:
+	case 27:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:448
+//line parser/grammar.y:524
 		{
 			yrVAL.metas = []*ast.Meta{yrDollar[1].meta}
 		}
-	case 24:
+	case 28:
 		yrDollar = yrS[yrpt-2 : yrpt+1]
-//line parser/grammar.y:452
+//line parser/grammar.y:528
 		{
 			yrVAL.metas = append(yrDollar[1].metas, yrDollar[2].meta)
 		}
-	case 25:
+	case 29:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:460
+//line parser/grammar.y:536
 		{
 			yrVAL.meta = &ast.Meta{
 				Key:   yrDollar[1].s,
 				Value: yrDollar[3].s,
 			}
 		}
-	case 26:
+	case 30:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:467
+//line parser/grammar.y:543
 		{
 			yrVAL.meta = &ast.Meta{
 				Key:   yrDollar[1].s,
 				Value: yrDollar[3].i64,
 			}
 		}
-	case 27:
+	case 31:
 		yrDollar = yrS[yrpt-4 : yrpt+1]
-//line parser/grammar.y:474
+//line parser/grammar.y:550
 		{
 			yrVAL.meta = &ast.Meta{
 				Key:   yrDollar[1].s,
 				Value: -yrDollar[4].i64,
 			}
 		}
-	case 28:
+	case 32:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:481
+//line parser/grammar.y:557
 		{
 			yrVAL.meta = &ast.Meta{
 				Key:   yrDollar[1].s,
 				Value: true,
 			}
 		}
-	case 29:
+	case 33:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:488
+//line parser/grammar.y:564
 		{
 			yrVAL.meta = &ast.Meta{
 				Key:   yrDollar[1].s,
 				Value: false,
 			}
 		}
-	case 30:
+	case 34:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:499
+//line parser/grammar.y:575
 		{
 			lexer := asLexer(yrlex)
 			lexer.strings[yrDollar[1].ys.GetIdentifier()] = true
 			yrVAL.yss = []ast.String{yrDollar[1].ys}
 		}
-	case 31:
+	case 35:
 		yrDollar = yrS[yrpt-2 : yrpt+1]
-//line parser/grammar.y:505
+//line parser/grammar.y:581
 		{
 			lexer := asLexer(yrlex)
 			lexer.strings[yrDollar[2].ys.GetIdentifier()] = true
 			yrVAL.yss = append(yrDollar[1].yss, yrDollar[2].ys)
 		}
-	case 32:
+	case 36:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:515
+//line parser/grammar.y:591
 		{
 			if err := validateUTF8(yrDollar[3].s); err != nil {
 				return asLexer(yrlex).setError(
 					gyperror.InvalidUTF8Error, err.Error())
 			}
 		}
-	case 33:
+	case 37:
 		yrDollar = yrS[yrpt-5 : yrpt+1]
-//line parser/grammar.y:522
+//line parser/grammar.y:598
 		{
 			yrVAL.ys = &ast.TextString{
 				BaseString: ast.BaseString{
@@ -1165,9 +1235,9 @@ yrdefault:
 				Value:          yrDollar[3].s,
 			}
 		}
-	case 34:
+	case 38:
 		yrDollar = yrS[yrpt-4 : yrpt+1]
-//line parser/grammar.y:543
+//line parser/grammar.y:619
 		{
 			yrVAL.ys = &ast.RegexpString{
 				BaseString: ast.BaseString{
@@ -1182,9 +1252,9 @@ yrdefault:
 				Regexp:   yrDollar[3].reg,
 			}
 		}
-	case 35:
+	case 39:
 		yrDollar = yrS[yrpt-4 : yrpt+1]
-//line parser/grammar.y:558
+//line parser/grammar.y:634
 		{
 			yrVAL.ys = &ast.HexString{
 				BaseString: ast.BaseString{
@@ -1195,15 +1265,15 @@ yrdefault:
 				Tokens:  yrDollar[3].hexTokens,
 			}
 		}
-	case 36:
+	case 40:
 		yrDollar = yrS[yrpt-0 : yrpt+1]
-//line parser/grammar.y:573
+//line parser/grammar.y:649
 		{
 			yrVAL.smod = stringModifiers{}
 		}
-	case 37:
+	case 41:
 		yrDollar = yrS[yrpt-2 : yrpt+1]
-//line parser/grammar.y:577
+//line parser/grammar.y:653
 		{
 			if yrDollar[1].smod.modifiers&yrDollar[2].smod.modifiers != 0 {
 				return asLexer(yrlex).setError(
@@ -1223,51 +1293,51 @@ yrdefault:
 
 			yrVAL.smod = yrDollar[1].smod
 		}
-	case 38:
+	case 42:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:600
+//line parser/grammar.y:676
 		{
 			yrVAL.smod = stringModifiers{modifiers: ModWide}
 		}
-	case 39:
+	case 43:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:601
+//line parser/grammar.y:677
 		{
 			yrVAL.smod = stringModifiers{modifiers: ModASCII}
 		}
-	case 40:
+	case 44:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:602
+//line parser/grammar.y:678
 		{
 			yrVAL.smod = stringModifiers{modifiers: ModNocase}
 		}
-	case 41:
+	case 45:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:603
+//line parser/grammar.y:679
 		{
 			yrVAL.smod = stringModifiers{modifiers: ModFullword}
 		}
-	case 42:
+	case 46:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:604
+//line parser/grammar.y:680
 		{
 			yrVAL.smod = stringModifiers{modifiers: ModPrivate}
 		}
-	case 43:
+	case 47:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:605
+//line parser/grammar.y:681
 		{
 			yrVAL.smod = stringModifiers{modifiers: ModBase64}
 		}
-	case 44:
+	case 48:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:606
+//line parser/grammar.y:682
 		{
 			yrVAL.smod = stringModifiers{modifiers: ModBase64Wide}
 		}
-	case 45:
+	case 49:
 		yrDollar = yrS[yrpt-4 : yrpt+1]
-//line parser/grammar.y:608
+//line parser/grammar.y:684
 		{
 			if err := validateAscii(yrDollar[3].s); err != nil {
 				return asLexer(yrlex).setError(
@@ -1285,9 +1355,9 @@ yrdefault:
 				Base64Alphabet: yrDollar[3].s,
 			}
 		}
-	case 46:
+	case 50:
 		yrDollar = yrS[yrpt-4 : yrpt+1]
-//line parser/grammar.y:626
+//line parser/grammar.y:702
 		{
 			if err := validateAscii(yrDollar[3].s); err != nil {
 				return asLexer(yrlex).setError(
@@ -1305,9 +1375,9 @@ yrdefault:
 				Base64Alphabet: yrDollar[3].s,
 			}
 		}
-	case 47:
+	case 51:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:644
+//line parser/grammar.y:720
 		{
 			yrVAL.smod = stringModifiers{
 				modifiers: ModXor,
@@ -1315,9 +1385,9 @@ yrdefault:
 				XorMax:    255,
 			}
 		}
-	case 48:
+	case 52:
 		yrDollar = yrS[yrpt-4 : yrpt+1]
-//line parser/grammar.y:652
+//line parser/grammar.y:728
 		{
 			yrVAL.smod = stringModifiers{
 				modifiers: ModXor,
@@ -1325,9 +1395,9 @@ yrdefault:
 				XorMax:    int32(yrDollar[3].i64),
 			}
 		}
-	case 49:
+	case 53:
 		yrDollar = yrS[yrpt-6 : yrpt+1]
-//line parser/grammar.y:660
+//line parser/grammar.y:736
 		{
 			lexer := asLexer(yrlex)
 
@@ -1355,93 +1425,90 @@ yrdefault:
 				XorMax:    int32(yrDollar[5].i64),
 			}
 		}
-	case 50:
+	case 54:
 		yrDollar = yrS[yrpt-0 : yrpt+1]
-//line parser/grammar.y:692
+//line parser/grammar.y:768
 		{
 			yrVAL.mod = 0
 		}
-	case 51:
+	case 55:
 		yrDollar = yrS[yrpt-2 : yrpt+1]
-//line parser/grammar.y:696
+//line parser/grammar.y:772
 		{
 			yrVAL.mod = yrDollar[1].mod | yrDollar[2].mod
 		}
-	case 52:
+	case 56:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:703
+//line parser/grammar.y:779
 		{
 			yrVAL.mod = ModWide
 		}
-	case 53:
+	case 57:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:704
+//line parser/grammar.y:780
 		{
 			yrVAL.mod = ModASCII
 		}
-	case 54:
+	case 58:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:705
+//line parser/grammar.y:781
 		{
 			yrVAL.mod = ModNocase
 		}
-	case 55:
+	case 59:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:706
+//line parser/grammar.y:782
 		{
 			yrVAL.mod = ModFullword
 		}
-	case 56:
+	case 60:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:707
+//line parser/grammar.y:783
 		{
 			yrVAL.mod = ModPrivate
 		}
-	case 57:
+	case 61:
 		yrDollar = yrS[yrpt-0 : yrpt+1]
-//line parser/grammar.y:713
+//line parser/grammar.y:789
 		{
 			yrVAL.mod = 0
 		}
-	case 58:
+	case 62:
 		yrDollar = yrS[yrpt-2 : yrpt+1]
-//line parser/grammar.y:717
+//line parser/grammar.y:793
 		{
 			yrVAL.mod = yrDollar[1].mod | yrDollar[2].mod
 		}
-	case 59:
+	case 63:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:724
+//line parser/grammar.y:800
 		{
 			yrVAL.mod = ModPrivate
 		}
-	case 60:
+	case 64:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:730
+//line parser/grammar.y:806
 		{
-			yrVAL.expr = &ast.Identifier{Identifier: yrDollar[1].s}
+			yrVAL.expr = asLexer(yrlex).nodes.newIdentifier(yrDollar[1].s)
 		}
-	case 61:
+	case 65:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:734
+//line parser/grammar.y:810
 		{
-			yrVAL.expr = &ast.MemberAccess{
-				Container: yrDollar[1].expr,
-				Member:    yrDollar[3].s,
-			}
+			yrVAL.expr = asLexer(yrlex).nodes.newMemberAccess(yrDollar[1].expr, yrDollar[3].s)
 		}
-	case 62:
+	case 66:
 		yrDollar = yrS[yrpt-4 : yrpt+1]
-//line parser/grammar.y:741
+//line parser/grammar.y:814
 		{
 			yrVAL.expr = &ast.Subscripting{
 				Array: yrDollar[1].expr,
 				Index: yrDollar[3].expr,
 			}
 		}
-	case 63:
+	case 67:
 		yrDollar = yrS[yrpt-4 : yrpt+1]
-//line parser/grammar.y:748
+//line parser/grammar.y:821
 		{
 			yrVAL.expr = &ast.FunctionCall{
 				Callable:  yrDollar[1].expr,
@@ -1449,156 +1516,126 @@ yrdefault:
 				Builtin:   false,
 			}
 		}
-	case 64:
+	case 68:
 		yrDollar = yrS[yrpt-0 : yrpt+1]
-//line parser/grammar.y:760
+//line parser/grammar.y:833
 		{
 			yrVAL.exprs = []ast.Expression{}
 		}
-	case 65:
+	case 69:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:764
+//line parser/grammar.y:837
 		{
 			yrVAL.exprs = yrDollar[1].exprs
 		}
-	case 66:
+	case 70:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:771
+//line parser/grammar.y:844
 		{
 			yrVAL.exprs = []ast.Expression{yrDollar[1].expr}
 		}
-	case 67:
+	case 71:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:775
+//line parser/grammar.y:848
 		{
 			yrVAL.exprs = append(yrDollar[1].exprs, yrDollar[3].expr)
 		}
-	case 68:
+	case 72:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:783
+//line parser/grammar.y:856
 		{
 			yrVAL.reg = yrDollar[1].reg
 		}
-	case 69:
+	case 73:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:791
+//line parser/grammar.y:864
 		{
 			yrVAL.expr = yrDollar[1].expr
 		}
-	case 70:
+	case 74:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:799
+//line parser/grammar.y:872
 		{
 			yrVAL.expr = ast.KeywordTrue
 		}
-	case 71:
+	case 75:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:803
+//line parser/grammar.y:876
 		{
 			yrVAL.expr = ast.KeywordFalse
 		}
-	case 72:
+	case 76:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:807
+//line parser/grammar.y:880
 		{
-			yrVAL.expr = &ast.Operation{
-				Operator: ast.OpMatches,
-				Operands: []ast.Expression{yrDollar[1].expr, yrDollar[3].reg},
-			}
+			yrVAL.expr = asLexer(yrlex).nodes.newOperation(ast.OpMatches, yrDollar[1].expr, yrDollar[3].reg)
 		}
-	case 73:
+	case 77:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:814
+//line parser/grammar.y:884
 		{
-			yrVAL.expr = &ast.Operation{
-				Operator: ast.OpContains,
-				Operands: []ast.Expression{yrDollar[1].expr, yrDollar[3].expr},
-			}
+			yrVAL.expr = asLexer(yrlex).nodes.newOperation(ast.OpContains, yrDollar[1].expr, yrDollar[3].expr)
 		}
-	case 74:
+	case 78:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:821
+//line parser/grammar.y:888
 		{
-			yrVAL.expr = &ast.Operation{
-				Operator: ast.OpIContains,
-				Operands: []ast.Expression{yrDollar[1].expr, yrDollar[3].expr},
-			}
+			yrVAL.expr = asLexer(yrlex).nodes.newOperation(ast.OpIContains, yrDollar[1].expr, yrDollar[3].expr)
 		}
-	case 75:
+	case 79:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:828
+//line parser/grammar.y:892
 		{
-			yrVAL.expr = &ast.Operation{
-				Operator: ast.OpStartsWith,
-				Operands: []ast.Expression{yrDollar[1].expr, yrDollar[3].expr},
-			}
+			yrVAL.expr = asLexer(yrlex).nodes.newOperation(ast.OpStartsWith, yrDollar[1].expr, yrDollar[3].expr)
 		}
-	case 76:
+	case 80:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:835
+//line parser/grammar.y:896
 		{
-			yrVAL.expr = &ast.Operation{
-				Operator: ast.OpIStartsWith,
-				Operands: []ast.Expression{yrDollar[1].expr, yrDollar[3].expr},
-			}
+			yrVAL.expr = asLexer(yrlex).nodes.newOperation(ast.OpIStartsWith, yrDollar[1].expr, yrDollar[3].expr)
 		}
-	case 77:
+	case 81:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:842
+//line parser/grammar.y:900
 		{
-			yrVAL.expr = &ast.Operation{
-				Operator: ast.OpEndsWith,
-				Operands: []ast.Expression{yrDollar[1].expr, yrDollar[3].expr},
-			}
+			yrVAL.expr = asLexer(yrlex).nodes.newOperation(ast.OpEndsWith, yrDollar[1].expr, yrDollar[3].expr)
 		}
-	case 78:
+	case 82:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:849
+//line parser/grammar.y:904
 		{
-			yrVAL.expr = &ast.Operation{
-				Operator: ast.OpIEndsWith,
-				Operands: []ast.Expression{yrDollar[1].expr, yrDollar[3].expr},
-			}
+			yrVAL.expr = asLexer(yrlex).nodes.newOperation(ast.OpIEndsWith, yrDollar[1].expr, yrDollar[3].expr)
 		}
-	case 79:
+	case 83:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:856
+//line parser/grammar.y:908
 		{
-			yrVAL.expr = &ast.Operation{
-				Operator: ast.OpIEquals,
-				Operands: []ast.Expression{yrDollar[1].expr, yrDollar[3].expr},
-			}
+			yrVAL.expr = asLexer(yrlex).nodes.newOperation(ast.OpIEquals, yrDollar[1].expr, yrDollar[3].expr)
 		}
-	case 80:
+	case 84:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:863
+//line parser/grammar.y:912
 		{
 			identifier := strings.TrimPrefix(yrDollar[1].s, "$")
 			// Exclude anonymous ($) strings.
 			if identifier != "" {
 				lexer := asLexer(yrlex)
-				if _, ok := lexer.strings[identifier]; !ok {
-					return lexer.setError(
-						gyperror.UndefinedStringIdentifierError,
-						`undefined string identifier: %s`, yrDollar[1].s)
+				if _, ok := lexer.strings[identifier]; !ok && !lexer.standalone {
+					return lexer.setUndefinedStringError(yrDollar[1].s, yrDollar[1].pos, yrDollar[1].end)
 				}
 			}
-			yrVAL.expr = &ast.StringIdentifier{
-				Identifier: identifier,
-			}
+			yrVAL.expr = asLexer(yrlex).nodes.newStringIdentifier(identifier)
 		}
-	case 81:
+	case 85:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:879
+//line parser/grammar.y:924
 		{
 			identifier := strings.TrimPrefix(yrDollar[1].s, "$")
 			// Exclude anonymous ($) strings.
 			if identifier != "" {
 				lexer := asLexer(yrlex)
-				if _, ok := lexer.strings[identifier]; !ok {
-					return lexer.setError(
-						gyperror.UndefinedStringIdentifierError,
-						`undefined string identifier: %s`, yrDollar[1].s)
+				if _, ok := lexer.strings[identifier]; !ok && !lexer.standalone {
+					return lexer.setUndefinedStringError(yrDollar[1].s, yrDollar[1].pos, yrDollar[1].end)
 				}
 			}
 			yrVAL.expr = &ast.StringIdentifier{
@@ -1606,18 +1643,16 @@ yrdefault:
 				At:         yrDollar[3].expr,
 			}
 		}
-	case 82:
+	case 86:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:896
+//line parser/grammar.y:939
 		{
 			identifier := strings.TrimPrefix(yrDollar[1].s, "$")
 			// Exclude anonymous ($) strings.
 			if identifier != "" {
 				lexer := asLexer(yrlex)
-				if _, ok := lexer.strings[identifier]; !ok {
-					return lexer.setError(
-						gyperror.UndefinedStringIdentifierError,
-						`undefined string identifier: %s`, yrDollar[1].s)
+				if _, ok := lexer.strings[identifier]; !ok && !lexer.standalone {
+					return lexer.setUndefinedStringError(yrDollar[1].s, yrDollar[1].pos, yrDollar[1].end)
 				}
 			}
 			yrVAL.expr = &ast.StringIdentifier{
@@ -1625,9 +1660,9 @@ yrdefault:
 				In:         yrDollar[3].rng,
 			}
 		}
-	case 83:
+	case 87:
 		yrDollar = yrS[yrpt-9 : yrpt+1]
-//line parser/grammar.y:913
+//line parser/grammar.y:954
 		{
 			yrVAL.expr = &ast.ForIn{
 				Quantifier: yrDollar[2].expr,
@@ -1636,9 +1671,18 @@ yrdefault:
 				Condition:  yrDollar[8].expr,
 			}
 		}
-	case 84:
+	case 88:
+		yrDollar = yrS[yrpt-6 : yrpt+1]
+//line parser/grammar.y:963
+		{
+			yrVAL.expr = &ast.With{
+				Declarations: yrDollar[2].decls,
+				Condition:    yrDollar[5].expr,
+			}
+		}
+	case 89:
 		yrDollar = yrS[yrpt-8 : yrpt+1]
-//line parser/grammar.y:922
+//line parser/grammar.y:970
 		{
 			yrVAL.expr = &ast.ForOf{
 				Quantifier: yrDollar[2].expr,
@@ -1646,9 +1690,9 @@ yrdefault:
 				Condition:  yrDollar[7].expr,
 			}
 		}
-	case 85:
+	case 90:
 		yrDollar = yrS[yrpt-5 : yrpt+1]
-//line parser/grammar.y:930
+//line parser/grammar.y:978
 		{
 			yrVAL.expr = &ast.Of{
 				Quantifier: yrDollar[1].expr,
@@ -1656,9 +1700,9 @@ yrdefault:
 				In:         yrDollar[5].rng,
 			}
 		}
-	case 86:
+	case 91:
 		yrDollar = yrS[yrpt-5 : yrpt+1]
-//line parser/grammar.y:938
+//line parser/grammar.y:986
 		{
 			yrVAL.expr = &ast.Of{
 				Quantifier: yrDollar[1].expr,
@@ -1666,156 +1710,138 @@ yrdefault:
 				At:         yrDollar[5].expr,
 			}
 		}
-	case 87:
+	case 92:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:946
+//line parser/grammar.y:994
 		{
 			yrVAL.expr = &ast.Of{
 				Quantifier: yrDollar[1].expr,
 				Strings:    yrDollar[3].node,
 			}
 		}
-	case 88:
+	case 93:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:953
+//line parser/grammar.y:1001
 		{
 			yrVAL.expr = &ast.Of{
 				Quantifier: yrDollar[1].expr,
 				Rules:      yrDollar[3].node,
 			}
 		}
-	case 89:
+	case 94:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:960
+//line parser/grammar.y:1008
 		{
 			yrVAL.expr = &ast.Of{
 				Quantifier:  yrDollar[1].expr,
 				TextStrings: yrDollar[3].ss,
 			}
 		}
-	case 90:
+	case 95:
 		yrDollar = yrS[yrpt-4 : yrpt+1]
-//line parser/grammar.y:967
+//line parser/grammar.y:1015
 		{
 			yrVAL.expr = &ast.Of{
 				Quantifier: &ast.Percentage{yrDollar[1].expr},
 				Strings:    yrDollar[4].node,
 			}
 		}
-	case 91:
+	case 96:
 		yrDollar = yrS[yrpt-4 : yrpt+1]
-//line parser/grammar.y:974
+//line parser/grammar.y:1022
 		{
 			yrVAL.expr = &ast.Of{
 				Quantifier: &ast.Percentage{yrDollar[1].expr},
 				Rules:      yrDollar[4].node,
 			}
 		}
-	case 92:
+	case 97:
 		yrDollar = yrS[yrpt-2 : yrpt+1]
-//line parser/grammar.y:981
+//line parser/grammar.y:1029
 		{
 			yrVAL.expr = &ast.Not{yrDollar[2].expr}
 		}
-	case 93:
+	case 98:
 		yrDollar = yrS[yrpt-2 : yrpt+1]
-//line parser/grammar.y:985
+//line parser/grammar.y:1033
 		{
 			yrVAL.expr = &ast.Defined{yrDollar[2].expr}
 		}
-	case 94:
+	case 99:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:989
+//line parser/grammar.y:1037
 		{
-			yrVAL.expr = operation(ast.OpAnd, yrDollar[1].expr, yrDollar[3].expr)
+			yrVAL.expr = asLexer(yrlex).nodes.operation(ast.OpAnd, yrDollar[1].expr, yrDollar[3].expr)
 		}
-	case 95:
+	case 100:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:993
+//line parser/grammar.y:1041
 		{
-			yrVAL.expr = operation(ast.OpOr, yrDollar[1].expr, yrDollar[3].expr)
+			yrVAL.expr = asLexer(yrlex).nodes.operation(ast.OpOr, yrDollar[1].expr, yrDollar[3].expr)
 		}
-	case 96:
+	case 101:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:997
+//line parser/grammar.y:1045
 		{
-			yrVAL.expr = &ast.Operation{
-				Operator: ast.OpLessThan,
-				Operands: []ast.Expression{yrDollar[1].expr, yrDollar[3].expr},
-			}
+			yrVAL.expr = asLexer(yrlex).nodes.newOperation(ast.OpLessThan, yrDollar[1].expr, yrDollar[3].expr)
 		}
-	case 97:
+	case 102:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:1004
+//line parser/grammar.y:1049
 		{
-			yrVAL.expr = &ast.Operation{
-				Operator: ast.OpGreaterThan,
-				Operands: []ast.Expression{yrDollar[1].expr, yrDollar[3].expr},
-			}
+			yrVAL.expr = asLexer(yrlex).nodes.newOperation(ast.OpGreaterThan, yrDollar[1].expr, yrDollar[3].expr)
 		}
-	case 98:
+	case 103:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:1011
+//line parser/grammar.y:1053
 		{
-			yrVAL.expr = &ast.Operation{
-				Operator: ast.OpLessOrEqual,
-				Operands: []ast.Expression{yrDollar[1].expr, yrDollar[3].expr},
-			}
+			yrVAL.expr = asLexer(yrlex).nodes.newOperation(ast.OpLessOrEqual, yrDollar[1].expr, yrDollar[3].expr)
 		}
-	case 99:
+	case 104:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:1018
+//line parser/grammar.y:1057
 		{
-			yrVAL.expr = &ast.Operation{
-				Operator: ast.OpGreaterOrEqual,
-				Operands: []ast.Expression{yrDollar[1].expr, yrDollar[3].expr},
-			}
+			yrVAL.expr = asLexer(yrlex).nodes.newOperation(ast.OpGreaterOrEqual, yrDollar[1].expr, yrDollar[3].expr)
 		}
-	case 100:
+	case 105:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:1025
+//line parser/grammar.y:1061
 		{
-			yrVAL.expr = &ast.Operation{
-				Operator: ast.OpEqual,
-				Operands: []ast.Expression{yrDollar[1].expr, yrDollar[3].expr},
-			}
+			yrVAL.expr = asLexer(yrlex).nodes.newOperation(ast.OpEqual, yrDollar[1].expr, yrDollar[3].expr)
 		}
-	case 101:
+	case 106:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:1032
+//line parser/grammar.y:1065
 		{
-			yrVAL.expr = &ast.Operation{
-				Operator: ast.OpNotEqual,
-				Operands: []ast.Expression{yrDollar[1].expr, yrDollar[3].expr},
-			}
+			yrVAL.expr = asLexer(yrlex).nodes.newOperation(ast.OpNotEqual, yrDollar[1].expr, yrDollar[3].expr)
 		}
-	case 102:
+	case 107:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:1039
+//line parser/grammar.y:1069
 		{
 			yrVAL.expr = yrDollar[1].expr
 		}
-	case 103:
+	case 108:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:1043
+//line parser/grammar.y:1073
 		{
 			yrVAL.expr = &ast.Group{yrDollar[2].expr}
 		}
-	case 104:
+	case 109:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:1051
+//line parser/grammar.y:1081
 		{
 			yrVAL.node = &ast.Enum{Values: yrDollar[2].exprs}
 		}
-	case 105:
+	case 110:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:1055
+//line parser/grammar.y:1085
 		{
 			yrVAL.node = yrDollar[1].rng
 		}
-	case 106:
+	case 111:
 		yrDollar = yrS[yrpt-5 : yrpt+1]
-//line parser/grammar.y:1063
+//line parser/grammar.y:1093
 		{
 			if start, ok := yrDollar[2].expr.(*ast.LiteralInteger); ok {
 				if end, ok := yrDollar[4].expr.(*ast.LiteralInteger); ok {
@@ -1851,75 +1877,69 @@ yrdefault:
 				End:   yrDollar[4].expr,
 			}
 		}
-	case 107:
+	case 112:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:1103
+//line parser/grammar.y:1133
 		{
 			yrVAL.exprs = []ast.Expression{yrDollar[1].expr}
 		}
-	case 108:
+	case 113:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:1107
+//line parser/grammar.y:1137
 		{
 			yrVAL.exprs = append(yrDollar[1].exprs, yrDollar[3].expr)
 		}
-	case 109:
+	case 114:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:1115
+//line parser/grammar.y:1145
 		{
 			yrVAL.node = &ast.Enum{Values: yrDollar[2].exprs}
 		}
-	case 110:
+	case 115:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:1119
+//line parser/grammar.y:1149
 		{
 			lexer := asLexer(yrlex)
-			if len(lexer.strings) == 0 {
-				return lexer.setError(
-					gyperror.UndefinedStringIdentifierError,
-					`undefined string identifier: %s`, ast.KeywordThem)
+			if len(lexer.strings) == 0 && !lexer.standalone {
+				return lexer.setUndefinedStringError(string(ast.KeywordThem), yrDollar[1].pos, yrDollar[1].end)
 			}
 			yrVAL.node = ast.KeywordThem
 		}
-	case 111:
+	case 116:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:1133
+//line parser/grammar.y:1161
 		{
 			yrVAL.exprs = []ast.Expression{yrDollar[1].si}
 		}
-	case 112:
+	case 117:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:1137
+//line parser/grammar.y:1165
 		{
 			yrVAL.exprs = append(yrDollar[1].exprs, yrDollar[3].si)
 		}
-	case 113:
+	case 118:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:1145
+//line parser/grammar.y:1173
 		{
 			identifier := strings.TrimPrefix(yrDollar[1].s, "$")
 			lexer := asLexer(yrlex)
 			// Anonymous strings ($) in string enumerations are an error.
-			if _, ok := lexer.strings[identifier]; !ok || identifier == "" {
-				return lexer.setError(
-					gyperror.UndefinedStringIdentifierError,
-					`undefined string identifier: %s`, yrDollar[1].s)
+			if _, ok := lexer.strings[identifier]; !ok && !lexer.standalone || identifier == "" {
+				return lexer.setUndefinedStringError(yrDollar[1].s, yrDollar[1].pos, yrDollar[1].end)
 			}
 			yrVAL.si = &ast.StringIdentifier{
 				Identifier: identifier,
 			}
 		}
-	case 114:
+	case 119:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:1159
+//line parser/grammar.y:1185
 		{
 			identifier := strings.TrimSuffix(yrDollar[1].s, "*")
 			lexer := asLexer(yrlex)
 			// There must be at least one defined string.
-			if len(identifier) == 0 && len(lexer.strings) == 0 {
-				return lexer.setError(
-					gyperror.UndefinedStringIdentifierError,
-					`undefined string identifier: %s`, yrDollar[1].s)
+			if len(identifier) == 0 && len(lexer.strings) == 0 && !lexer.standalone {
+				return lexer.setUndefinedStringError(yrDollar[1].s, yrDollar[1].pos, yrDollar[1].end)
 			}
 
 			// There must be at least one string that will match the wildcard.
@@ -1931,37 +1951,35 @@ yrdefault:
 					break
 				}
 			}
-			if !match {
-				return lexer.setError(
-					gyperror.UndefinedStringIdentifierError,
-					`undefined string identifier: %s`, yrDollar[1].s)
+			if !match && !lexer.standalone {
+				return lexer.setUndefinedStringError(yrDollar[1].s, yrDollar[1].pos, yrDollar[1].end)
 			}
 			// Can't use "identifier" here as that has the asterisk stripped already.
 			yrVAL.si = &ast.StringIdentifier{
 				Identifier: strings.TrimPrefix(yrDollar[1].s, "$"),
 			}
 		}
-	case 115:
+	case 120:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:1193
+//line parser/grammar.y:1215
 		{
 			yrVAL.node = &ast.Enum{Values: yrDollar[2].exprs}
 		}
-	case 116:
+	case 121:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:1201
+//line parser/grammar.y:1223
 		{
 			yrVAL.exprs = []ast.Expression{yrDollar[1].ident}
 		}
-	case 117:
+	case 122:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:1205
+//line parser/grammar.y:1227
 		{
 			yrVAL.exprs = append(yrDollar[1].exprs, yrDollar[3].ident)
 		}
-	case 118:
+	case 123:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:1213
+//line parser/grammar.y:1235
 		{
 			lexer := asLexer(yrlex)
 			match := false
@@ -1971,7 +1989,7 @@ yrdefault:
 					break
 				}
 			}
-			if !match {
+			if !match && !lexer.standalone {
 				return lexer.setError(
 					gyperror.UndefinedRuleIdentifierError,
 					`undefined rule identifier: %s`, yrDollar[1].s)
@@ -1979,9 +1997,9 @@ yrdefault:
 
 			yrVAL.ident = &ast.Identifier{Identifier: yrDollar[1].s}
 		}
-	case 119:
+	case 124:
 		yrDollar = yrS[yrpt-2 : yrpt+1]
-//line parser/grammar.y:1231
+//line parser/grammar.y:1253
 		{
 			// There must be at least one rule which matches this wildcard
 			lexer := asLexer(yrlex)
@@ -1992,7 +2010,7 @@ yrdefault:
 					break
 				}
 			}
-			if !match {
+			if !match && !lexer.standalone {
 				return lexer.setError(
 					gyperror.UndefinedRuleIdentifierError,
 					`undefined rule identifier: %s`, yrDollar[1].s+"*")
@@ -2004,33 +2022,33 @@ yrdefault:
 			lexer.rule_wildcards[yrDollar[1].s] = true
 			yrVAL.ident = &ast.Identifier{Identifier: yrDollar[1].s + "*"}
 		}
-	case 120:
+	case 125:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:1258
+//line parser/grammar.y:1280
 		{
 			yrVAL.ss = yrDollar[2].ss
 		}
-	case 121:
+	case 126:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:1266
+//line parser/grammar.y:1288
 		{
 			yrVAL.ss = []string{yrDollar[1].s}
 		}
-	case 122:
+	case 127:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:1270
+//line parser/grammar.y:1292
 		{
 			yrVAL.ss = append(yrDollar[1].ss, yrDollar[3].s)
 		}
-	case 123:
+	case 128:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:1278
+//line parser/grammar.y:1300
 		{
 			yrVAL.s = yrDollar[1].s
 		}
-	case 124:
+	case 129:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:1286
+//line parser/grammar.y:1308
 		{
 			switch v := yrDollar[1].expr.(type) {
 			case *ast.Minus:
@@ -2058,69 +2076,90 @@ yrdefault:
 			}
 			yrVAL.expr = yrDollar[1].expr
 		}
-	case 125:
+	case 130:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:1314
+//line parser/grammar.y:1336
 		{
 			yrVAL.expr = ast.KeywordAll
 		}
-	case 126:
+	case 131:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:1318
+//line parser/grammar.y:1340
 		{
 			yrVAL.expr = ast.KeywordAny
 		}
-	case 127:
+	case 132:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:1322
+//line parser/grammar.y:1344
 		{
 			yrVAL.expr = ast.KeywordNone
 		}
-	case 128:
+	case 133:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:1330
+//line parser/grammar.y:1352
 		{
 			yrVAL.ss = []string{yrDollar[1].s}
 		}
-	case 129:
+	case 134:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:1334
+//line parser/grammar.y:1356
 		{
 			yrVAL.ss = append(yrDollar[1].ss, yrDollar[3].s)
 		}
-	case 130:
+	case 135:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:1341
+//line parser/grammar.y:1363
+		{
+			yrVAL.decls = []*ast.WithDeclaration{yrDollar[1].decl}
+		}
+	case 136:
+		yrDollar = yrS[yrpt-3 : yrpt+1]
+//line parser/grammar.y:1367
+		{
+			yrVAL.decls = append(yrDollar[1].decls, yrDollar[3].decl)
+		}
+	case 137:
+		yrDollar = yrS[yrpt-3 : yrpt+1]
+//line parser/grammar.y:1374
+		{
+			yrVAL.decl = &ast.WithDeclaration{
+				Identifier: yrDollar[1].s,
+				Expression: yrDollar[3].expr,
+			}
+		}
+	case 138:
+		yrDollar = yrS[yrpt-1 : yrpt+1]
+//line parser/grammar.y:1384
 		{
 			yrVAL.node = yrDollar[1].expr
 		}
-	case 131:
+	case 139:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:1345
+//line parser/grammar.y:1388
 		{
 			yrVAL.node = yrDollar[1].node
 		}
-	case 132:
+	case 140:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:1353
+//line parser/grammar.y:1396
 		{
 			yrVAL.expr = &ast.Group{yrDollar[2].expr}
 		}
-	case 133:
+	case 141:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:1357
+//line parser/grammar.y:1400
 		{
 			yrVAL.expr = ast.KeywordFilesize
 		}
-	case 134:
+	case 142:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:1361
+//line parser/grammar.y:1404
 		{
 			yrVAL.expr = ast.KeywordEntrypoint
 		}
-	case 135:
+	case 143:
 		yrDollar = yrS[yrpt-4 : yrpt+1]
-//line parser/grammar.y:1365
+//line parser/grammar.y:1408
 		{
 			yrVAL.expr = &ast.FunctionCall{
 				Callable:  &ast.Identifier{Identifier: yrDollar[1].s},
@@ -2128,21 +2167,21 @@ yrdefault:
 				Builtin:   true,
 			}
 		}
-	case 136:
+	case 144:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:1373
+//line parser/grammar.y:1416
 		{
-			yrVAL.expr = &ast.LiteralInteger{yrDollar[1].i64}
+			yrVAL.expr = asLexer(yrlex).nodes.newLiteralInteger(yrDollar[1].i64, yrDollar[1].radix, yrDollar[1].multiplier)
 		}
-	case 137:
+	case 145:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:1377
+//line parser/grammar.y:1420
 		{
 			yrVAL.expr = &ast.LiteralFloat{yrDollar[1].f64}
 		}
-	case 138:
+	case 146:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:1381
+//line parser/grammar.y:1424
 		{
 			if err := validateUTF8(yrDollar[1].s); err != nil {
 				return asLexer(yrlex).setError(
@@ -2151,17 +2190,15 @@ yrdefault:
 
 			yrVAL.expr = &ast.LiteralString{yrDollar[1].s}
 		}
-	case 139:
+	case 147:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:1390
+//line parser/grammar.y:1433
 		{
 			identifier := strings.TrimPrefix(yrDollar[1].s, "#")
 			if identifier != "" {
 				lexer := asLexer(yrlex)
-				if _, ok := lexer.strings[identifier]; !ok {
-					return lexer.setError(
-						gyperror.UndefinedStringIdentifierError,
-						`undefined string identifier: %s`, yrDollar[1].s)
+				if _, ok := lexer.strings[identifier]; !ok && !lexer.standalone {
+					return lexer.setUndefinedStringError(yrDollar[1].s, yrDollar[1].pos, yrDollar[1].end)
 				}
 			}
 			yrVAL.expr = &ast.StringCount{
@@ -2169,34 +2206,30 @@ yrdefault:
 				In:         yrDollar[3].rng,
 			}
 		}
-	case 140:
+	case 148:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:1406
+//line parser/grammar.y:1447
 		{
 			identifier := strings.TrimPrefix(yrDollar[1].s, "#")
 			if identifier != "" {
 				lexer := asLexer(yrlex)
-				if _, ok := lexer.strings[identifier]; !ok {
-					return lexer.setError(
-						gyperror.UndefinedStringIdentifierError,
-						`undefined string identifier: %s`, yrDollar[1].s)
+				if _, ok := lexer.strings[identifier]; !ok && !lexer.standalone {
+					return lexer.setUndefinedStringError(yrDollar[1].s, yrDollar[1].pos, yrDollar[1].end)
 				}
 			}
 			yrVAL.expr = &ast.StringCount{
 				Identifier: identifier,
 			}
 		}
-	case 141:
+	case 149:
 		yrDollar = yrS[yrpt-4 : yrpt+1]
-//line parser/grammar.y:1421
+//line parser/grammar.y:1460
 		{
 			identifier := strings.TrimPrefix(yrDollar[1].s, "@")
 			if identifier != "" {
 				lexer := asLexer(yrlex)
-				if _, ok := lexer.strings[identifier]; !ok {
-					return lexer.setError(
-						gyperror.UndefinedStringIdentifierError,
-						`undefined string identifier: %s`, yrDollar[1].s)
+				if _, ok := lexer.strings[identifier]; !ok && !lexer.standalone {
+					return lexer.setUndefinedStringError(yrDollar[1].s, yrDollar[1].pos, yrDollar[1].end)
 				}
 			}
 			yrVAL.expr = &ast.StringOffset{
@@ -2204,34 +2237,30 @@ yrdefault:
 				Index:      yrDollar[3].expr,
 			}
 		}
-	case 142:
+	case 150:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:1437
+//line parser/grammar.y:1474
 		{
 			identifier := strings.TrimPrefix(yrDollar[1].s, "@")
 			if identifier != "" {
 				lexer := asLexer(yrlex)
-				if _, ok := lexer.strings[identifier]; !ok {
-					return lexer.setError(
-						gyperror.UndefinedStringIdentifierError,
-						`undefined string identifier: %s`, yrDollar[1].s)
+				if _, ok := lexer.strings[identifier]; !ok && !lexer.standalone {
+					return lexer.setUndefinedStringError(yrDollar[1].s, yrDollar[1].pos, yrDollar[1].end)
 				}
 			}
 			yrVAL.expr = &ast.StringOffset{
 				Identifier: identifier,
 			}
 		}
-	case 143:
+	case 151:
 		yrDollar = yrS[yrpt-4 : yrpt+1]
-//line parser/grammar.y:1452
+//line parser/grammar.y:1487
 		{
 			identifier := strings.TrimPrefix(yrDollar[1].s, "!")
 			if identifier != "" {
 				lexer := asLexer(yrlex)
-				if _, ok := lexer.strings[identifier]; !ok {
-					return lexer.setError(
-						gyperror.UndefinedStringIdentifierError,
-						`undefined string identifier: %s`, yrDollar[1].s)
+				if _, ok := lexer.strings[identifier]; !ok && !lexer.standalone {
+					return lexer.setUndefinedStringError(yrDollar[1].s, yrDollar[1].pos, yrDollar[1].end)
 				}
 			}
 			yrVAL.expr = &ast.StringLength{
@@ -2239,104 +2268,102 @@ yrdefault:
 				Index:      yrDollar[3].expr,
 			}
 		}
-	case 144:
+	case 152:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:1468
+//line parser/grammar.y:1501
 		{
 			identifier := strings.TrimPrefix(yrDollar[1].s, "!")
 			if identifier != "" {
 				lexer := asLexer(yrlex)
-				if _, ok := lexer.strings[identifier]; !ok {
-					return lexer.setError(
-						gyperror.UndefinedStringIdentifierError,
-						`undefined string identifier: %s`, yrDollar[1].s)
+				if _, ok := lexer.strings[identifier]; !ok && !lexer.standalone {
+					return lexer.setUndefinedStringError(yrDollar[1].s, yrDollar[1].pos, yrDollar[1].end)
 				}
 			}
 			yrVAL.expr = &ast.StringLength{
 				Identifier: strings.TrimPrefix(yrDollar[1].s, "!"),
 			}
 		}
-	case 145:
+	case 153:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:1483
+//line parser/grammar.y:1514
 		{
 			yrVAL.expr = yrDollar[1].expr
 		}
-	case 146:
+	case 154:
 		yrDollar = yrS[yrpt-2 : yrpt+1]
-//line parser/grammar.y:1487
+//line parser/grammar.y:1518
 		{
 			yrVAL.expr = &ast.Minus{yrDollar[2].expr}
 		}
-	case 147:
+	case 155:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:1491
+//line parser/grammar.y:1522
 		{
-			yrVAL.expr = operation(ast.OpAdd, yrDollar[1].expr, yrDollar[3].expr)
+			yrVAL.expr = asLexer(yrlex).nodes.operation(ast.OpAdd, yrDollar[1].expr, yrDollar[3].expr)
 		}
-	case 148:
+	case 156:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:1495
+//line parser/grammar.y:1526
 		{
-			yrVAL.expr = operation(ast.OpSub, yrDollar[1].expr, yrDollar[3].expr)
+			yrVAL.expr = asLexer(yrlex).nodes.operation(ast.OpSub, yrDollar[1].expr, yrDollar[3].expr)
 		}
-	case 149:
+	case 157:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:1499
+//line parser/grammar.y:1530
 		{
-			yrVAL.expr = operation(ast.OpMul, yrDollar[1].expr, yrDollar[3].expr)
+			yrVAL.expr = asLexer(yrlex).nodes.operation(ast.OpMul, yrDollar[1].expr, yrDollar[3].expr)
 		}
-	case 150:
+	case 158:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:1503
+//line parser/grammar.y:1534
 		{
-			yrVAL.expr = operation(ast.OpDiv, yrDollar[1].expr, yrDollar[3].expr)
+			yrVAL.expr = asLexer(yrlex).nodes.operation(ast.OpDiv, yrDollar[1].expr, yrDollar[3].expr)
 		}
-	case 151:
+	case 159:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:1507
+//line parser/grammar.y:1538
 		{
-			yrVAL.expr = operation(ast.OpMod, yrDollar[1].expr, yrDollar[3].expr)
+			yrVAL.expr = asLexer(yrlex).nodes.operation(ast.OpMod, yrDollar[1].expr, yrDollar[3].expr)
 		}
-	case 152:
+	case 160:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:1511
+//line parser/grammar.y:1542
 		{
-			yrVAL.expr = operation(ast.OpBitXor, yrDollar[1].expr, yrDollar[3].expr)
+			yrVAL.expr = asLexer(yrlex).nodes.operation(ast.OpBitXor, yrDollar[1].expr, yrDollar[3].expr)
 		}
-	case 153:
+	case 161:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:1515
+//line parser/grammar.y:1546
 		{
-			yrVAL.expr = operation(ast.OpBitAnd, yrDollar[1].expr, yrDollar[3].expr)
+			yrVAL.expr = asLexer(yrlex).nodes.operation(ast.OpBitAnd, yrDollar[1].expr, yrDollar[3].expr)
 		}
-	case 154:
+	case 162:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:1519
+//line parser/grammar.y:1550
 		{
-			yrVAL.expr = operation(ast.OpBitOr, yrDollar[1].expr, yrDollar[3].expr)
+			yrVAL.expr = asLexer(yrlex).nodes.operation(ast.OpBitOr, yrDollar[1].expr, yrDollar[3].expr)
 		}
-	case 155:
+	case 163:
 		yrDollar = yrS[yrpt-2 : yrpt+1]
-//line parser/grammar.y:1523
+//line parser/grammar.y:1554
 		{
 			yrVAL.expr = &ast.BitwiseNot{yrDollar[2].expr}
 		}
-	case 156:
+	case 164:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:1527
+//line parser/grammar.y:1558
 		{
-			yrVAL.expr = operation(ast.OpShiftLeft, yrDollar[1].expr, yrDollar[3].expr)
+			yrVAL.expr = asLexer(yrlex).nodes.operation(ast.OpShiftLeft, yrDollar[1].expr, yrDollar[3].expr)
 		}
-	case 157:
+	case 165:
 		yrDollar = yrS[yrpt-3 : yrpt+1]
-//line parser/grammar.y:1531
+//line parser/grammar.y:1562
 		{
-			yrVAL.expr = operation(ast.OpShiftRight, yrDollar[1].expr, yrDollar[3].expr)
+			yrVAL.expr = asLexer(yrlex).nodes.operation(ast.OpShiftRight, yrDollar[1].expr, yrDollar[3].expr)
 		}
-	case 158:
+	case 166:
 		yrDollar = yrS[yrpt-1 : yrpt+1]
-//line parser/grammar.y:1535
+//line parser/grammar.y:1566
 		{
 			yrVAL.expr = yrDollar[1].reg
 		}
@@ -0,0 +1,147 @@
+package parser
+
+import (
+	"io"
+	"strings"
+)
+
+// SymbolKind is the type of symbols returned by Symbols.
+type SymbolKind int
+
+// Kinds of symbols.
+const (
+	// Identifier of a rule in the rule's declaration.
+	RuleDeclaration SymbolKind = iota
+	// Identifier of a string in the "strings" section of a rule.
+	StringDeclaration
+	// Reference to a string in a condition, like $a, #a, @a or !a.
+	StringReference
+	// String wildcard in a condition, like $a* in "any of ($a*)".
+	StringWildcard
+	// Identifier in a condition. This can be a reference to a rule, a module,
+	// an external variable, or a loop variable. Identifiers that appear after
+	// a dot, like "bar" in "foo.bar", are not included.
+	IdentifierReference
+	// Rule wildcard in a condition, like foo* in "any of (foo*)".
+	RuleWildcard
+)
+
+// Symbol represents an occurrence of an identifier in the source code.
+type Symbol struct {
+	Kind SymbolKind
+	// Name of the symbol. For strings this doesn't include the prefix ($, #,
+	// @ or !), and for wildcards it doesn't include the trailing asterisk.
+	// Anonymous strings have an empty name.
+	Name string
+	// Index of the rule where the symbol appears, starting at 0.
+	Rule int
+	// Position of the symbol in the source code. This includes the prefix of
+	// strings and the asterisk in wildcards.
+	Span
+}
+
+// NameSpan returns the position within the source code of the symbol's name,
+// which doesn't include prefixes nor asterisks.
+func (s *Symbol) NameSpan() Span {
+	start := s.End - len(s.Name)
+	if s.Kind == StringWildcard || s.Kind == RuleWildcard {
+		start--
+	}
+	return Span{Start: start, End: start + len(s.Name)}
+}
+
+// Sections of a rule, used by Symbols for tracking where each symbol
+// appears.
+const (
+	sectionNone = iota
+	sectionRuleIdentifier
+	sectionHeader
+	sectionMeta
+	sectionStrings
+	sectionCondition
+)
+
+// Symbols returns the rule identifiers, string identifiers and other
+// identifiers found in the provided YARA source code, in the same order in
+// which they appear. This only requires the source code to be lexically
+// correct, errors in the syntax are ignored. If a lexical error is found
+// the symbols found until that point are returned together with the error.
+func Symbols(input io.Reader) ([]Symbol, error) {
+	scanner := NewScanner()
+	scanner.In = input
+
+	var symbols []Symbol
+	var prev YYtype
+	section := sectionNone
+	rule := -1
+
+	for {
+		t := scanner.Lex()
+		if t.Error.Code != 0 {
+			t.Error.Line = scanner.Lineno
+			return symbols, t.Error
+		}
+		if t.Token == eof {
+			return symbols, nil
+		}
+		// An identifier followed by an asterisk is a rule wildcard if the
+		// asterisk is followed by a comma or closing parenthesis, otherwise
+		// the asterisk is a multiplication.
+		if prev.Token == '*' && (t.Token == ',' || t.Token == ')') {
+			if n := len(symbols); n > 0 && symbols[n-1].Kind == IdentifierReference &&
+				symbols[n-1].End == prev.StartPos {
+				symbols[n-1].Kind = RuleWildcard
+				symbols[n-1].End = prev.EndPos
+			}
+		}
+		switch t.Token {
+		case _RULE_:
+			rule++
+			section = sectionRuleIdentifier
+		case _META_:
+			section = sectionMeta
+		case _STRINGS_:
+			section = sectionStrings
+		case _CONDITION_:
+			section = sectionCondition
+		case '}':
+			if section == sectionCondition {
+				section = sectionNone
+			}
+		case _IDENTIFIER_:
+			switch {
+			case section == sectionRuleIdentifier:
+				symbols = append(symbols, newSymbol(RuleDeclaration, t.Value.s, rule, t))
+				section = sectionHeader
+			case section == sectionCondition && prev.Token != '.':
+				symbols = append(symbols, newSymbol(IdentifierReference, t.Value.s, rule, t))
+			}
+		case _STRING_IDENTIFIER_:
+			name := strings.TrimPrefix(t.Value.s, "$")
+			if section == sectionStrings {
+				symbols = append(symbols, newSymbol(StringDeclaration, name, rule, t))
+			} else if section == sectionCondition {
+				symbols = append(symbols, newSymbol(StringReference, name, rule, t))
+			}
+		case _STRING_COUNT_, _STRING_OFFSET_, _STRING_LENGTH_:
+			if section == sectionCondition {
+				symbols = append(symbols, newSymbol(StringReference, t.Value.s[1:], rule, t))
+			}
+		case _STRING_IDENTIFIER_WITH_WILDCARD_:
+			if section == sectionCondition {
+				name := strings.TrimSuffix(t.Value.s[1:], "*")
+				symbols = append(symbols, newSymbol(StringWildcard, name, rule, t))
+			}
+		}
+		prev = t
+	}
+}
+
+func newSymbol(kind SymbolKind, name string, rule int, t YYtype) Symbol {
+	return Symbol{
+		Kind: kind,
+		Name: name,
+		Rule: rule,
+		Span: Span{Start: t.StartPos, End: t.EndPos},
+	}
+}
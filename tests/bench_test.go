@@ -0,0 +1,135 @@
+package tests
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/VirusTotal/gyp"
+	"github.com/VirusTotal/gyp/ast"
+)
+
+// Number of copies of testdata/corpus.yar in the ruleset used by benchmarks,
+// which makes it about 1 MB.
+const benchmarkCopies = 100
+
+var (
+	ruleNameRegexp     = regexp.MustCompile(`rule\s+(\w+)`)
+	ruleWildcardRegexp = regexp.MustCompile(`([^$\w])([A-Za-z_]\w*\*)`)
+)
+
+// benchmarkCorpus returns a ruleset with several copies of the rules in
+// testdata/corpus.yar. Each copy has a different prefix in rule identifiers
+// and wildcards.
+func benchmarkCorpus(b *testing.B) string {
+	data, err := ioutil.ReadFile("testdata/corpus.yar")
+	if err != nil {
+		b.Fatal(err)
+	}
+	corpus := string(data)
+	var names []string
+	for _, m := range ruleNameRegexp.FindAllStringSubmatch(corpus, -1) {
+		names = append(names, m[1])
+	}
+	rename := regexp.MustCompile(`\b(` + strings.Join(names, "|") + `)\b`)
+	var s strings.Builder
+	for i := 0; i < benchmarkCopies; i++ {
+		prefix := fmt.Sprintf("c%d_", i)
+		c := rename.ReplaceAllString(corpus, prefix+"${1}")
+		c = ruleWildcardRegexp.ReplaceAllString(c, "${1}"+prefix+"${2}")
+		s.WriteString(c)
+	}
+	return s.String()
+}
+
+func benchmarkRuleSet(b *testing.B) *ast.RuleSet {
+	rs, err := gyp.ParseString(benchmarkCorpus(b))
+	if err != nil {
+		b.Fatal(err)
+	}
+	return rs
+}
+
+func TestBenchmarkCorpus(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/corpus.yar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs, err := gyp.ParseString(string(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	if err := rs.WriteSource(&b); err != nil {
+		t.Fatal(err)
+	}
+	_, err = gyp.ParseString(b.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	corpus := benchmarkCorpus(b)
+	b.SetBytes(int64(len(corpus)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := gyp.ParseString(corpus); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkWriteSource(b *testing.B) {
+	rs := benchmarkRuleSet(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := rs.WriteSource(ioutil.Discard); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkAsProto(b *testing.B) {
+	rs := benchmarkRuleSet(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rs.AsProto()
+	}
+}
+
+func BenchmarkSerializer(b *testing.B) {
+	pb := benchmarkRuleSet(b).AsProto()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := gyp.NewSerializer(ioutil.Discard).Serialize(pb); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+type nodeCounter struct {
+	nodes int
+}
+
+func (c *nodeCounter) PreOrderVisit(ast.Node) {
+	c.nodes++
+}
+
+func BenchmarkDepthFirstSearch(b *testing.B) {
+	rs := benchmarkRuleSet(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c := &nodeCounter{}
+		for _, r := range rs.Rules {
+			ast.DepthFirstSearch(r, c)
+		}
+	}
+}
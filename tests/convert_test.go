@@ -0,0 +1,201 @@
+package tests
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/VirusTotal/gyp"
+	"github.com/VirusTotal/gyp/ast"
+	"github.com/VirusTotal/gyp/convert"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var converters = []struct {
+	name  string
+	write func(io.Writer, *ast.RuleSet) error
+	read  func(io.Reader) (*ast.RuleSet, error)
+}{
+	{"YAML", convert.WriteYAML, convert.ReadYAML},
+	{"TOML", convert.WriteTOML, convert.ReadTOML},
+}
+
+func TestConvertRoundTrip(t *testing.T) {
+	for _, c := range converters {
+		for _, source := range []string{testRules, cstSource} {
+			ruleset, err := gyp.ParseString(source)
+			require.NoError(t, err)
+			var expected strings.Builder
+			require.NoError(t, ruleset.WriteSource(&expected))
+
+			var doc bytes.Buffer
+			require.NoError(t, c.write(&doc, ruleset), c.name)
+			decoded, err := c.read(&doc)
+			require.NoError(t, err, c.name)
+			var output strings.Builder
+			require.NoError(t, decoded.WriteSource(&output))
+			assert.Equal(t, expected.String(), output.String(), c.name)
+		}
+	}
+}
+
+func TestConvertMetaTypes(t *testing.T) {
+	ruleset, err := gyp.ParseString(`
+rule foo {
+  meta:
+    s = "true"
+    i = 1
+    n = -5
+    b = true
+  condition:
+    true
+}`)
+	require.NoError(t, err)
+	for _, c := range converters {
+		var doc bytes.Buffer
+		require.NoError(t, c.write(&doc, ruleset), c.name)
+		decoded, err := c.read(&doc)
+		require.NoError(t, err, c.name)
+		assert.Equal(t, []*ast.Meta{
+			{Key: "s", Value: "true"},
+			{Key: "i", Value: int64(1)},
+			{Key: "n", Value: int64(-5)},
+			{Key: "b", Value: true},
+		}, decoded.Rules[0].Meta, c.name)
+	}
+}
+
+func TestYAMLEncoding(t *testing.T) {
+	ruleset, err := gyp.ParseString(`
+import "pe"
+rule foo : bar {
+  meta:
+    author = "someone"
+  strings:
+    $a = "foo" wide xor(1-2)
+    $b = { 01 ?2 [2-] ( 03 | 04 05 ) }
+    $c = /ba+r/is private
+  condition:
+    $a and #b > 0x10
+}`)
+	require.NoError(t, err)
+	var b strings.Builder
+	require.NoError(t, convert.WriteYAML(&b, ruleset))
+	assert.Equal(t, `version: 1
+imports:
+- pe
+rules:
+- identifier: foo
+  tags:
+  - bar
+  meta:
+  - key: author
+    value: someone
+  strings:
+  - identifier: a
+    text: foo
+    wide: true
+    xor:
+      min: 1
+      max: 2
+  - identifier: b
+    hex: 01 ?2 [2-] ( 03 | 04 05 )
+  - identifier: c
+    regexp: /ba+r/is
+    private: true
+  condition: '$a and #b > 0x10'
+`, b.String())
+}
+
+func TestConvertErrors(t *testing.T) {
+	for _, test := range []struct {
+		yaml string
+		err  string
+	}{
+		{
+			"rules: []",
+			`missing "version" field`,
+		},
+		{
+			"version: 2\nrules: []",
+			"unsupported version: 2",
+		},
+		{
+			"version: 1\nrules: [{identifier: foo}]",
+			`rule "foo": missing "condition" field`,
+		},
+		{
+			"version: 1\nrules: [{identifier: foo, condition: 'true and'}]",
+			`rule "foo": invalid condition`,
+		},
+		{
+			"version: 1\nrules: [{identifier: foo, condition: 'true', meta: [{key: x, value: 1.5}]}]",
+			`rule "foo": meta "x": unsupported value type float64`,
+		},
+		{
+			"version: 1\nrules: [{identifier: foo, condition: 'true', strings: [{identifier: a, text: a, hex: '01'}]}]",
+			`rule "foo": string $a: exactly one of "text", "hex" or "regexp" is required`,
+		},
+		{
+			"version: 1\nrules: [{identifier: foo, condition: 'true', strings: [{identifier: a, text: 'a\" wide \"'}]}]",
+			`rule "foo": string $a:`,
+		},
+		{
+			"version: 1\nrules: [{identifier: foo, condition: 'true', strings: [{identifier: a, regexp: '/ab/ nocase wide'}]}]",
+			`rule "foo": string $a: invalid regexp: "/ab/ nocase wide"`,
+		},
+		{
+			"version: 1\nrules: [{identifier: foo, condition: 'true', strings: [{identifier: a, regexp: '/ab/ nocase', nocase: true}]}]",
+			`rule "foo": string $a:`,
+		},
+		{
+			"version: 1\nrules: [{identifier: foo, condition: 'true', strings: [{identifier: a, hex: '01 } private {'}]}]",
+			`rule "foo": string $a:`,
+		},
+		{
+			"version: 1\nrules: [{identifier: foo, condition: 'true', strings: [{identifier: a, text: a, base64: {alphabet: 'AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA\") base64wide(\"AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA'}}]}]",
+			`rule "foo": string $a: invalid base64 alphabet`,
+		},
+		{
+			"version: 1\nrules: [{identifier: foo, condition: 'true', strings: [{identifier: a, text: a, base64: {alphabet: 'AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA'}, base64wide: {alphabet: 'BBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBB'}}]}]",
+			`rule "foo": string $a: invalid base64`,
+		},
+		{
+			"version: 1\nrules: [{identifier: foo, condition: '$b'}]",
+			"invalid ruleset",
+		},
+		{
+			"version: 1\nrules: [{identifier: foo, condition: 'true', color: red}]",
+			"field color not found",
+		},
+	} {
+		_, err := convert.ReadYAML(strings.NewReader(test.yaml))
+		if assert.Error(t, err, test.yaml) {
+			assert.Contains(t, err.Error(), test.err, test.yaml)
+		}
+	}
+}
+
+func TestConvertMissingCondition(t *testing.T) {
+	ruleset := &ast.RuleSet{Rules: []*ast.Rule{{Identifier: "foo"}}}
+	for _, c := range converters {
+		err := c.write(ioutil.Discard, ruleset)
+		assert.IsType(t, &ast.ValidationError{}, err, c.name)
+		assert.EqualError(t, err, "rule foo: condition: missing condition", c.name)
+	}
+}
+
+func TestTOMLUnknownKey(t *testing.T) {
+	_, err := convert.ReadTOML(strings.NewReader(`
+version = 1
+
+[[rules]]
+identifier = "foo"
+condition = "true"
+color = "red"
+`))
+	assert.EqualError(t, err, `unknown key "rules.color"`)
+}
@@ -0,0 +1,183 @@
+package tests
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/VirusTotal/gyp"
+	"github.com/VirusTotal/gyp/ast"
+	"github.com/VirusTotal/gyp/parser"
+	"github.com/VirusTotal/gyp/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const cstSource = `/*
+  Some rules.
+*/
+import "pe"
+
+// The first rule.
+rule foo : tag1 tag2 {
+  meta:
+    author = "someone"  // The author.
+    version = 0x2
+  strings:
+    $a = "foo\x41"   wide ascii
+    $b = {01 02 ?? [2-4] 03}
+  condition:
+    $a and #b > 0x10 and
+    filesize < 1MB // Not too large.
+}
+
+rule bar { condition: foo and (pe.number_of_sections > 0o10 or true) }
+`
+
+func writeCST(t *testing.T, cst *parser.CST) string {
+	var b strings.Builder
+	require.NoError(t, cst.WriteSource(&b))
+	return b.String()
+}
+
+func TestCSTUnmodified(t *testing.T) {
+	cst, err := gyp.ParseCST(strings.NewReader(cstSource))
+	require.NoError(t, err)
+	assert.Equal(t, cstSource, writeCST(t, cst))
+}
+
+func TestCSTModified(t *testing.T) {
+	tests := []struct {
+		modify   func(rs *ast.RuleSet)
+		expected string
+	}{
+		{
+			// Change a literal in the condition.
+			func(rs *ast.RuleSet) {
+				operands := rs.Rules[0].Condition.(*ast.Operation).Operands
+				operands[1].(*ast.Operation).Operands[1] = &ast.LiteralInteger{Value: 32}
+			},
+			strings.Replace(cstSource, "#b > 0x10", "#b > 32", 1),
+		},
+		{
+			// Add an operand at the end of the condition.
+			func(rs *ast.RuleSet) {
+				op := rs.Rules[1].Condition.(*ast.Operation)
+				op.Operands = append(op.Operands, &ast.StringIdentifier{Identifier: "a"})
+				rs.Rules[1].Strings = []ast.String{&ast.TextString{
+					BaseString: ast.BaseString{Identifier: "a"},
+					Value:      "bar",
+				}}
+			},
+			strings.Replace(cstSource,
+				"rule bar { condition: foo and (pe.number_of_sections > 0o10 or true) }",
+				"rule bar {\n  strings:\n    $a = \"bar\"\n  condition: foo and (pe.number_of_sections > 0o10 or true) and $a }", 1),
+		},
+		{
+			// Rename a rule and its references.
+			func(rs *ast.RuleSet) {
+				if err := utils.RenameRule(rs, "foo", "baz"); err != nil {
+					panic(err)
+				}
+			},
+			strings.Replace(strings.Replace(cstSource,
+				"rule foo :", "rule baz :", 1),
+				"condition: foo and", "condition: baz and", 1),
+		},
+		{
+			// Change the modifiers of a string.
+			func(rs *ast.RuleSet) {
+				rs.Rules[0].Strings[0].(*ast.TextString).Nocase = true
+			},
+			strings.Replace(cstSource, `"foo\x41"   wide ascii`, `"foo\x41" ascii wide nocase`, 1),
+		},
+		{
+			// Remove a rule and the import.
+			func(rs *ast.RuleSet) {
+				rs.Imports = nil
+				rs.Rules = rs.Rules[:1]
+			},
+			strings.Replace(strings.Replace(cstSource,
+				"import \"pe\"\n", "", 1),
+				"\nrule bar { condition: foo and (pe.number_of_sections > 0o10 or true) }\n", "", 1),
+		},
+		{
+			// Add a rule.
+			func(rs *ast.RuleSet) {
+				rs.Rules = append(rs.Rules, &ast.Rule{
+					Identifier: "qux",
+					Condition:  &ast.Identifier{Identifier: "bar"},
+				})
+			},
+			cstSource + "\nrule qux {\n  condition:\n    bar\n}\n",
+		},
+		{
+			// Make a rule private.
+			func(rs *ast.RuleSet) {
+				rs.Rules[1].Private = true
+			},
+			strings.Replace(cstSource, "rule bar {", "private rule bar {", 1),
+		},
+	}
+	for _, test := range tests {
+		cst, err := gyp.ParseCST(strings.NewReader(cstSource))
+		require.NoError(t, err)
+		test.modify(cst.RuleSet)
+		assert.Equal(t, test.expected, writeCST(t, cst))
+	}
+}
+
+// TestCSTRandomModifications replaces random expressions in the conditions
+// and checks that the output produced by the CST is equivalent to the one
+// produced by the modified RuleSet.
+func TestCSTRandomModifications(t *testing.T) {
+	rand.Seed(1)
+	for i := 0; i < 200; i++ {
+		cst, err := gyp.ParseCST(strings.NewReader(cstSource))
+		require.NoError(t, err)
+		for n := rand.Intn(3); n >= 0; n-- {
+			rule := cst.RuleSet.Rules[rand.Intn(len(cst.RuleSet.Rules))]
+			var exprs []*ast.Expression
+			collectExpressions(&rule.Condition, &exprs)
+			expr := exprs[rand.Intn(len(exprs))]
+			switch rand.Intn(3) {
+			case 0:
+				*expr = &ast.LiteralInteger{Value: rand.Int63n(100)}
+			case 1:
+				*expr = &ast.Operation{
+					Operator: ast.OpAnd,
+					Operands: []ast.Expression{*expr, ast.KeywordTrue},
+				}
+			case 2:
+				*expr = &ast.Not{Expression: *expr}
+			}
+		}
+		var expected strings.Builder
+		require.NoError(t, cst.RuleSet.WriteSource(&expected))
+		// Some modifications produce expressions that are not valid, like
+		// using a boolean as an operand of an arithmetic operation.
+		if _, err := gyp.ParseString(expected.String()); err != nil {
+			continue
+		}
+		output := writeCST(t, cst)
+		rs, err := gyp.ParseString(output)
+		require.NoError(t, err, output)
+		var actual strings.Builder
+		require.NoError(t, rs.WriteSource(&actual))
+		assert.Equal(t, expected.String(), actual.String(), output)
+	}
+}
+
+// collectExpressions appends to exprs pointers to the expression and the
+// operands of the operations it contains.
+func collectExpressions(expr *ast.Expression, exprs *[]*ast.Expression) {
+	*exprs = append(*exprs, expr)
+	switch e := (*expr).(type) {
+	case *ast.Operation:
+		for i := range e.Operands {
+			collectExpressions(&e.Operands[i], exprs)
+		}
+	case *ast.Group:
+		collectExpressions(&e.Expression, exprs)
+	}
+}
@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/VirusTotal/gyp"
+	gyperror "github.com/VirusTotal/gyp/error"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func renderError(t *testing.T, source string, color bool) string {
+	_, err := gyp.ParseString(source)
+	yaraErr, ok := err.(gyperror.Error)
+	require.True(t, ok, "unexpected error: %v", err)
+	var b strings.Builder
+	require.NoError(t, gyperror.Render(&b, []byte(source), yaraErr, gyperror.RenderOptions{
+		Filename: "test.yar",
+		Color:    color,
+	}))
+	return b.String()
+}
+
+func TestRenderDiagnostic(t *testing.T) {
+	tests := []struct {
+		source   string
+		expected string
+	}{
+		{
+			"rule a {\n  strings:\n    $abc = \"foo\"\n    $xyz = \"bar\"\n  condition:\n    #abd > 0 and $xyz\n}",
+			"error[E0023 UndefinedStringIdentifier]: undefined string identifier: #abd\n" +
+				" --> test.yar:6:5\n" +
+				"  |\n" +
+				"6 |     #abd > 0 and $xyz\n" +
+				"  |     ^^^^\n" +
+				"  = hint: did you mean `#abc`?\n",
+		},
+		{
+			"rule a {\n  strings:\n    $abc = \"foo\"\n  condition:\n\t$foo\n}",
+			"error[E0023 UndefinedStringIdentifier]: undefined string identifier: $foo\n" +
+				" --> test.yar:5:2\n" +
+				"  |\n" +
+				"5 | \t$foo\n" +
+				"  | \t^^^^\n",
+		},
+		{
+			"rule a {\n  condition:\n    true and\n}",
+			"error[E0002 Lexical]: syntax error: unexpected '}'\n" +
+				" --> test.yar:4:1\n" +
+				"  |\n" +
+				"4 | }\n" +
+				"  | ^\n",
+		},
+		{
+			"rule a { strings: $a = \"\\q\" condition: $a }",
+			"error[E0008 IllegalEscapeSequence]: illegal escape sequence\n" +
+				" --> test.yar:1:25\n" +
+				"  |\n" +
+				"1 | rule a { strings: $a = \"\\q\" condition: $a }\n" +
+				"  |                         ^^\n",
+		},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.expected, renderError(t, test.source, false))
+	}
+}
+
+func TestRenderDiagnosticColor(t *testing.T) {
+	expected := "\x1b[1;31merror[E0002 Lexical]\x1b[0m\x1b[1m: syntax error: unexpected $end, expecting _CONDITION_\x1b[0m\n" +
+		"\x1b[1;34m --> \x1b[0mtest.yar:1:9\n" +
+		"\x1b[1;34m  |\x1b[0m\n" +
+		"\x1b[1;34m1 | \x1b[0mrule a { \n" +
+		"\x1b[1;34m  | \x1b[0m        \x1b[1;31m^\x1b[0m\n"
+	assert.Equal(t, expected, renderError(t, "rule a { ", true))
+}
+
+func TestRenderDiagnosticUnknownPosition(t *testing.T) {
+	err := gyperror.Error{
+		Code:    gyperror.DuplicateRuleError,
+		Message: `duplicate rule "a"`,
+		Line:    2,
+	}
+	var b strings.Builder
+	assert.NoError(t, gyperror.Render(&b, []byte("rule a { condition: true }\nrule a { condition: true }\n"), err, gyperror.RenderOptions{}))
+	assert.Equal(t, "error[E0003 DuplicateRule]: duplicate rule \"a\"\n"+
+		" --> <input>:2\n"+
+		"  |\n"+
+		"2 | rule a { condition: true }\n", b.String())
+}
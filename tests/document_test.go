@@ -0,0 +1,177 @@
+package tests
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/VirusTotal/gyp"
+	"github.com/VirusTotal/gyp/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+const documentSource = `import "pe"
+
+rule a {
+  strings:
+    $a = "foo"
+  condition:
+    $a
+}
+
+// Some comment.
+rule b : tag {
+  condition:
+    a and pe.number_of_sections > 1
+}
+
+global private rule c
+{
+  strings:
+    $a = /foo/
+    $b = { 01 02 }
+  condition:
+    any of them
+}
+
+rule d { condition: any of (a, b*) }
+
+/* Another comment */
+rule e { condition: true }
+`
+
+// assertDocumentMatchesFullParse checks that the document's RuleSet is the
+// same that would be obtained by parsing the whole document's source.
+func assertDocumentMatchesFullParse(t *testing.T, doc *parser.Document) bool {
+	rs, err := gyp.ParseString(doc.Source())
+	if err != nil {
+		return assert.EqualError(t, doc.Err(), err.Error(), doc.Source()) &&
+			assert.Nil(t, doc.RuleSet())
+	}
+	if !assert.NoError(t, doc.Err(), doc.Source()) {
+		return false
+	}
+	var expected, actual strings.Builder
+	assert.NoError(t, rs.WriteSource(&expected))
+	assert.NoError(t, doc.RuleSet().WriteSource(&actual))
+	if !assert.Equal(t, expected.String(), actual.String(), doc.Source()) {
+		return false
+	}
+	for i, rule := range rs.Rules {
+		if !assert.Equal(t, rule.LineNo, doc.RuleSet().Rules[i].LineNo, doc.Source()) {
+			return false
+		}
+		for j, s := range rule.Strings {
+			if !assert.Equal(t, s.GetLineNo(), doc.RuleSet().Rules[i].Strings[j].GetLineNo(), doc.Source()) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func replaceEdit(src, old, new string) parser.Edit {
+	start := strings.Index(src, old)
+	return parser.Edit{Start: start, End: start + len(old), Text: new}
+}
+
+func TestDocumentEdits(t *testing.T) {
+	doc := parser.NewDocument(documentSource)
+	assert.NoError(t, doc.Err())
+	rules := doc.RuleSet().Rules
+
+	// Editing the condition of rule "b" doesn't affect other rules.
+	assert.NoError(t, doc.Apply(replaceEdit(doc.Source(), "> 1", "> 2\n")))
+	assertDocumentMatchesFullParse(t, doc)
+	assert.Same(t, rules[0], doc.RuleSet().Rules[0])
+	assert.True(t, rules[1] != doc.RuleSet().Rules[1])
+	assert.Equal(t, 17, doc.RuleSet().Rules[2].LineNo)
+	assert.Equal(t, 20, doc.RuleSet().Rules[2].Strings[0].GetLineNo())
+	// The rules after the edit were shifted one line down, but the previous
+	// ruleset still has the old line numbers.
+	assert.Equal(t, 16, rules[2].LineNo)
+	assert.Equal(t, 19, rules[2].Strings[0].GetLineNo())
+	assert.Equal(t, 28, rules[4].LineNo)
+	assert.Same(t, rules[2].Condition, doc.RuleSet().Rules[2].Condition)
+
+	// Editing a rule without adding or removing lines doesn't copy the
+	// following rules.
+	rules = doc.RuleSet().Rules
+	assert.NoError(t, doc.Apply(replaceEdit(doc.Source(), "> 2", "> 3")))
+	assertDocumentMatchesFullParse(t, doc)
+	assert.Same(t, rules[0], doc.RuleSet().Rules[0])
+	assert.Same(t, rules[2], doc.RuleSet().Rules[2])
+	assert.Same(t, rules[4], doc.RuleSet().Rules[4])
+
+	// Adding a new rule between existing rules.
+	assert.NoError(t, doc.Apply(replaceEdit(doc.Source(), "/* Another", "rule f { condition: false }\n/* Another")))
+	assertDocumentMatchesFullParse(t, doc)
+	assert.Len(t, doc.RuleSet().Rules, 6)
+
+	// Renaming a rule to the name of a later rule produces an error.
+	assert.Error(t, doc.Apply(replaceEdit(doc.Source(), "rule a {", "rule e {")))
+	assertDocumentMatchesFullParse(t, doc)
+
+	// Fixing the error.
+	assert.NoError(t, doc.Apply(replaceEdit(doc.Source(), "rule e {", "rule a {")))
+	assertDocumentMatchesFullParse(t, doc)
+
+	// Renaming a rule so that it matches a previously used wildcard.
+	assert.Error(t, doc.Apply(replaceEdit(doc.Source(), "rule e {", "rule b2 {")))
+	assertDocumentMatchesFullParse(t, doc)
+	assert.NoError(t, doc.Apply(replaceEdit(doc.Source(), "rule b2 {", "rule e {")))
+
+	// Renaming a rule referenced by a later rule.
+	assert.Error(t, doc.Apply(replaceEdit(doc.Source(), "rule a {", "rule x {")))
+	assertDocumentMatchesFullParse(t, doc)
+	assert.NoError(t, doc.Apply(replaceEdit(doc.Source(), "rule x {", "rule a {")))
+
+	// Opening a comment comments out the following rules until the end of
+	// the next comment.
+	assert.NoError(t, doc.Apply(replaceEdit(doc.Source(), "// Some comment.", "/* Some comment.")))
+	assertDocumentMatchesFullParse(t, doc)
+	assert.Len(t, doc.RuleSet().Rules, 2)
+
+	// Editing the imports.
+	assert.NoError(t, doc.Apply(replaceEdit(doc.Source(), "/* Some comment.", "// Some comment.")))
+	assert.NoError(t, doc.Apply(replaceEdit(doc.Source(), `import "pe"`, `import "elf"`)))
+	assertDocumentMatchesFullParse(t, doc)
+	assert.Equal(t, []string{"elf"}, doc.RuleSet().Imports)
+
+	// Out of bounds edits are rejected.
+	assert.Error(t, doc.Apply(parser.Edit{Start: 0, End: len(doc.Source()) + 1}))
+}
+
+func TestDocumentRandomEdits(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	// Fragments that are inserted at random positions.
+	fragments := []string{
+		"", " ", "\n", "}", "{", "rule", "rule z { condition: true }\n",
+		"a", "$a", "and", "/*", "*/", "//", "\"", "not ", "(", ")",
+	}
+	doc := parser.NewDocument(documentSource)
+	for i := 0; i < 500; i++ {
+		src := doc.Source()
+		var e parser.Edit
+		e.Start = r.Intn(len(src) + 1)
+		e.End = e.Start + r.Intn(3)
+		if e.End > len(src) {
+			e.End = len(src)
+		}
+		e.Text = fragments[r.Intn(len(fragments))]
+		doc.Apply(e)
+		if !assertDocumentMatchesFullParse(t, doc) {
+			break
+		}
+		// The edit is reverted right after being applied, so the document
+		// doesn't degrade into garbage.
+		doc.Apply(parser.Edit{
+			Start: e.Start,
+			End:   e.Start + len(e.Text),
+			Text:  src[e.Start:e.End],
+		})
+		if !assertDocumentMatchesFullParse(t, doc) {
+			break
+		}
+	}
+}
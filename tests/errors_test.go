@@ -0,0 +1,78 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/VirusTotal/gyp"
+	gyperror "github.com/VirusTotal/gyp/error"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorCodes(t *testing.T) {
+	// Code numbers must never change.
+	assert.Equal(t, 1, int(gyperror.UnknownError))
+	assert.Equal(t, 23, int(gyperror.UndefinedStringIdentifierError))
+	assert.Equal(t, 25, int(gyperror.InvalidValueError))
+
+	assert.Equal(t, "E0023", gyperror.UndefinedStringIdentifierError.ID())
+	assert.Equal(t, "UndefinedStringIdentifier", gyperror.UndefinedStringIdentifierError.Name())
+	assert.Equal(t, "E0023 UndefinedStringIdentifier", gyperror.UndefinedStringIdentifierError.String())
+	assert.Equal(t, "E0999 Unknown", gyperror.Code(999).String())
+}
+
+func TestErrorLocation(t *testing.T) {
+	_, err := gyp.ParseString(`
+rule foo { condition: true }
+rule bar {
+  strings:
+    $a = "a"
+  condition:
+    $a and $b
+}`)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, gyperror.UndefinedStringIdentifierError))
+	assert.False(t, errors.Is(err, gyperror.LexicalError))
+
+	var yaraErr gyperror.Error
+	require.True(t, errors.As(err, &yaraErr))
+	assert.Equal(t, 7, yaraErr.Line)
+	assert.Equal(t, 12, yaraErr.Column)
+	assert.Equal(t, "bar", yaraErr.Rule)
+	assert.Nil(t, errors.Unwrap(err))
+
+	// Errors outside rules don't have a rule.
+	_, err = gyp.ParseString("rule foo { condition: true }\nimport")
+	require.True(t, errors.As(err, &yaraErr))
+	assert.Equal(t, "", yaraErr.Rule)
+	assert.Equal(t, 2, yaraErr.Line)
+}
+
+func TestHexErrorLocation(t *testing.T) {
+	source := `rule foo {
+  strings:
+    $a = {
+      01 02
+      (03 [-] 04 | 05)
+    }
+  condition:
+    $a
+}`
+	_, err := gyp.ParseString(source)
+	var yaraErr gyperror.Error
+	require.True(t, errors.As(err, &yaraErr), "unexpected error: %v", err)
+	assert.True(t, errors.Is(err, gyperror.UnboundedJumpInsideAlternationError))
+	// The error is located at the hex string.
+	assert.Equal(t, 3, yaraErr.Line)
+	assert.Equal(t, 10, yaraErr.Column)
+	assert.Equal(t, "foo", yaraErr.Rule)
+	assert.Equal(t, "{\n      01 02\n      (03 [-] 04 | 05)\n    }", source[yaraErr.StartPos:yaraErr.EndPos])
+
+	// The error from the hex parser is wrapped, it's located within the hex
+	// string.
+	var hexErr gyperror.Error
+	require.True(t, errors.As(errors.Unwrap(err), &hexErr))
+	assert.Equal(t, yaraErr.Code, hexErr.Code)
+	assert.Equal(t, 3, hexErr.Line)
+}
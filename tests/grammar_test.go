@@ -369,6 +369,11 @@ rule RULE_SET_PERCENT {
     50% of (PERCENT*)
 }
 
+rule OF_TEXT_STRINGS {
+  condition:
+    any of ("a", "b\x00")
+}
+
 rule RULE_SET_IDENTIFIER_ISSUE_1631 {
   condition:
     for all a in (0..3) : (a * 0 == 0)
@@ -386,6 +391,21 @@ rule RULE_STRING_SET_AT_EXPRESSION {
   condition:
     any of them at 0
 }
+
+rule WITH_STATEMENT {
+  condition:
+    with last = pe.number_of_sections - 1, name = pe.sections[last].name : (name == ".rsrc" and with n = last : (n > 0))
+}
+
+rule WITH_BOOLEAN_DECLARATIONS {
+  condition:
+    with big = filesize > 10, is_pe = uint16(0) == 23117 or not defined pe.number_of_sections : (big and is_pe)
+}
+
+rule INTEGER_REPRESENTATIONS {
+  condition:
+    uint16(0) == 0x4D5A and uint8(0o17) == 0 and filesize > 1KB and filesize < 2MB and for any i in (0x10..0o40) : (i == 1)
+}
 `
 
 func TestRulesetParsing(t *testing.T) {
@@ -415,6 +435,37 @@ func TestParsing(t *testing.T) {
 	assert.Equal(t, testRules, output)
 }
 
+func TestCanonicalIntegers(t *testing.T) {
+	ruleset, err := gyp.ParseString(`
+rule foo {
+  strings:
+    $a = "foo"
+  condition:
+    uint16(0) == 0x4D5A and filesize < 2MB and any of them in (0o10..1KB)
+}`)
+	assert.NoError(t, err)
+	expected := `
+rule foo {
+  strings:
+    $a = "foo"
+  condition:
+    uint16(0) == 19802 and filesize < 2097152 and any of them in (8..1024)
+}
+`
+	var b strings.Builder
+	serializer := gyp.NewSerializer(&b)
+	serializer.SetCanonicalIntegers(true)
+	assert.NoError(t, serializer.Serialize(ruleset.AsProto()))
+	assert.Equal(t, expected, b.String())
+
+	for _, rule := range ruleset.Rules {
+		ast.CanonicalizeIntegers(rule)
+	}
+	b.Reset()
+	assert.NoError(t, ruleset.WriteSource(&b))
+	assert.Equal(t, expected, b.String())
+}
+
 func TestProtoSerialization(t *testing.T) {
 	// Parse rule and build AST.
 	ruleset, err := gyp.ParseString(testRules)
@@ -491,7 +542,7 @@ func TestUndefinedStringIdentifier(t *testing.T) {
 			$s
 	}`)
 	if assert.Error(t, err) {
-		assert.Equal(t, `line 7: undefined string identifier: $s`, err.Error())
+		assert.Equal(t, `line 6: undefined string identifier: $s`, err.Error())
 	}
 }
 
@@ -527,7 +578,7 @@ func TestUndefinedStringIdentifierWildcardExpansion(t *testing.T) {
 			1 of ($s*) and $x
 	}`)
 	if assert.Error(t, err) {
-		assert.Equal(t, `line 7: undefined string identifier: $x`, err.Error())
+		assert.Equal(t, `line 6: undefined string identifier: $x`, err.Error())
 	}
 }
 
@@ -629,7 +680,7 @@ func TestUndefinedStringAt(t *testing.T) {
 			$s at 10
 	}`)
 	if assert.Error(t, err) {
-		assert.Equal(t, `line 5: undefined string identifier: $s`, err.Error())
+		assert.Equal(t, `line 4: undefined string identifier: $s`, err.Error())
 	}
 }
 
@@ -0,0 +1,135 @@
+package tests
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/VirusTotal/gyp"
+	"github.com/VirusTotal/gyp/ast"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	ruleset, err := gyp.ParseString(testRules)
+	require.NoError(t, err)
+	data, err := json.Marshal(ruleset)
+	require.NoError(t, err)
+
+	var decoded ast.RuleSet
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	var b strings.Builder
+	require.NoError(t, decoded.WriteSource(&b))
+	assert.Equal(t, testRules, b.String())
+}
+
+func TestJSONEncoding(t *testing.T) {
+	ruleset, err := gyp.ParseString(`
+rule foo : bar {
+  meta:
+    author = "someone"
+    version = 2
+  strings:
+    $a = "foo" wide xor(1-2)
+    $b = { 01 ?2 [2-] ( 03 | 04 05 ) }
+  condition:
+    $a at 0x10 and not #b > 1
+}`)
+	require.NoError(t, err)
+	data, err := json.Marshal(ruleset)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+  "version": 2,
+  "rules": [{
+    "line_no": 2,
+    "identifier": "foo",
+    "tags": ["bar"],
+    "meta": [
+      {"key": "author", "value": "someone"},
+      {"key": "version", "value": 2}
+    ],
+    "strings": [
+      {"type": "text_string", "identifier": "a", "line_no": 7, "value": "foo", "wide": true, "xor": true, "xor_min": 1, "xor_max": 2},
+      {"type": "hex_string", "identifier": "b", "line_no": 8, "tokens": [
+        {"type": "hex_bytes", "bytes": [1, 2], "masks": [255, 15], "nots": [false, false]},
+        {"type": "hex_jump", "start": 2},
+        {"type": "hex_or", "alternatives": [
+          [{"type": "hex_bytes", "bytes": [3], "masks": [255], "nots": [false]}],
+          [{"type": "hex_bytes", "bytes": [4, 5], "masks": [255, 255], "nots": [false, false]}]
+        ]}
+      ]}
+    ],
+    "condition": {"type": "operation", "operator": "and", "operands": [
+      {"type": "string_identifier", "identifier": "a", "at": {"type": "literal_integer", "value": 16, "radix": 16}},
+      {"type": "not", "expression": {"type": "operation", "operator": ">", "operands": [
+        {"type": "string_count", "identifier": "b"},
+        {"type": "literal_integer", "value": 1}
+      ]}}
+    ]}
+  }]
+}`, string(data))
+}
+
+// In version 1 of the encoding unbounded hex jumps have end 0.
+func TestJSONVersion1(t *testing.T) {
+	var ruleset ast.RuleSet
+	require.NoError(t, json.Unmarshal([]byte(`{"version": 1, "rules": [{
+  "identifier": "foo",
+  "strings": [
+    {"type": "hex_string", "identifier": "a", "tokens": [
+      {"type": "hex_bytes", "bytes": [1], "masks": [255], "nots": [false]},
+      {"type": "hex_jump", "start": 2, "end": 0},
+      {"type": "hex_bytes", "bytes": [2], "masks": [255], "nots": [false]}
+    ]}
+  ],
+  "condition": {"type": "keyword", "keyword": "true"}
+}]}`), &ruleset))
+	var b strings.Builder
+	require.NoError(t, ruleset.WriteSource(&b))
+	assert.Equal(t, `
+rule foo {
+  strings:
+    $a = { 01 [2-] 02 }
+  condition:
+    true
+}
+`, b.String())
+}
+
+func TestJSONErrors(t *testing.T) {
+	tests := []struct {
+		json string
+		err  string
+	}{
+		{`{"rules": []}`, `missing "version" field`},
+		{`{"version": 3, "rules": []}`, `unsupported JSON version: 3`},
+		{`{"version": 1}`, `missing "rules" field`},
+		{`{"version": 1, "rules": [{"identifier": "foo", "condition": {"type": "foo"}}]}`,
+			`invalid "rules" field: invalid "condition" field: unknown type "foo"`},
+		{`{"version": 1, "rules": [{"identifier": "foo", "condition": {"type": "hex_jump", "start": 1, "end": 2}}]}`,
+			`invalid "rules" field: invalid "condition" field: hex_jump is not a valid expression`},
+		{`{"version": 1, "rules": [{"identifier": "foo", "condition": {"type": "keyword", "keyword": "foo"}}]}`,
+			`invalid "rules" field: invalid "condition" field: invalid keyword "foo"`},
+		{`{"version": 1, "rules": [{"identifier": "foo", "condition": {"type": "not"}}]}`,
+			`invalid "rules" field: invalid "condition" field: missing "expression" field`},
+	}
+	for _, test := range tests {
+		var ruleset ast.RuleSet
+		err := json.Unmarshal([]byte(test.json), &ruleset)
+		if assert.Error(t, err, test.json) {
+			assert.Equal(t, test.err, err.Error())
+		}
+	}
+}
+
+// TestJSONSchemaUpToDate checks that the published JSON Schema is the one
+// generated from the current types. Run "go generate ./ast" if it fails.
+func TestJSONSchemaUpToDate(t *testing.T) {
+	published, err := ioutil.ReadFile("../ast/ruleset.schema.json")
+	require.NoError(t, err)
+	schema, err := ast.JSONSchema()
+	require.NoError(t, err)
+	assert.Equal(t, string(schema)+"\n", string(published))
+}
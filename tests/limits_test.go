@@ -0,0 +1,143 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/VirusTotal/gyp"
+	"github.com/VirusTotal/gyp/ast"
+	gyperror "github.com/VirusTotal/gyp/error"
+	"github.com/VirusTotal/gyp/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const limitsRules = `
+rule a {
+  strings:
+    $a = "foo"
+    $b = { 01 02 ( 03 | 04 05 ) [2] 06 }
+  condition:
+    ($a or $b) and filesize < 100
+}
+rule b {
+  condition:
+    a
+}`
+
+func TestParseWithLimits(t *testing.T) {
+	deep := "rule deep { condition: " + strings.Repeat("(", 10000) + "true" + strings.Repeat(")", 10000) + " }"
+	tests := []struct {
+		source string
+		limits parser.Limits
+		code   gyperror.Code
+	}{
+		{limitsRules, parser.Limits{MaxInputSize: 100}, gyperror.InputTooLargeError},
+		{limitsRules, parser.Limits{MaxRules: 1}, gyperror.TooManyRulesError},
+		{limitsRules, parser.Limits{MaxStringsPerRule: 1}, gyperror.TooManyStringsError},
+		{limitsRules, parser.Limits{MaxHexTokensPerString: 7}, gyperror.TooManyHexTokensError},
+		{limitsRules, parser.Limits{MaxNestingDepth: 3}, gyperror.NestingTooDeepError},
+		{limitsRules, parser.Limits{MaxNodes: 20}, gyperror.TooManyNodesError},
+		{deep, parser.Limits{MaxNestingDepth: 100}, gyperror.NestingTooDeepError},
+	}
+	for _, test := range tests {
+		_, err := gyp.ParseWithLimits(strings.NewReader(test.source), test.limits)
+		assert.True(t, errors.Is(err, test.code), "expecting %s with %+v, got %v", test.code, test.limits, err)
+	}
+
+	// The rules are within these limits.
+	limits := parser.Limits{
+		MaxInputSize:          len(limitsRules),
+		MaxRules:              2,
+		MaxStringsPerRule:     2,
+		MaxHexTokensPerString: 8,
+		MaxNestingDepth:       4,
+		MaxNodes:              21,
+	}
+	rs, err := gyp.ParseWithLimits(strings.NewReader(limitsRules), limits)
+	require.NoError(t, err)
+	assert.Len(t, rs.Rules, 2)
+
+	var rules []string
+	_, err = gyp.ParseStreamWithLimits(context.Background(), strings.NewReader(limitsRules), parser.Limits{MaxRules: 1}, func(r *ast.Rule) error {
+		rules = append(rules, r.Identifier)
+		return nil
+	})
+	assert.True(t, errors.Is(err, gyperror.TooManyRulesError))
+	assert.Equal(t, []string{"a"}, rules)
+}
+
+// The limits are enforced while parsing, so inputs that exceed them are
+// rejected before building their syntax tree. The scanner reads the whole
+// input in memory, which is bounded by MaxInputSize, but parsing it shouldn't
+// allocate much more than that.
+func TestParseWithLimitsAllocations(t *testing.T) {
+	n := 1 << 20
+	tests := []struct {
+		source string
+		limits parser.Limits
+		code   gyperror.Code
+	}{
+		{
+			"rule a { condition: " + strings.Repeat("(", n) + "true" + strings.Repeat(")", n) + " }",
+			parser.Limits{MaxNestingDepth: 100},
+			gyperror.NestingTooDeepError,
+		},
+		{
+			"rule a { condition: " + strings.Repeat("not ", n) + "true }",
+			parser.Limits{MaxNestingDepth: 100},
+			gyperror.NestingTooDeepError,
+		},
+		{
+			"rule a { condition: true" + strings.Repeat(" and true", n) + " }",
+			parser.Limits{MaxNodes: 100},
+			gyperror.TooManyNodesError,
+		},
+	}
+	for _, test := range tests {
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		_, err := gyp.ParseWithLimits(strings.NewReader(test.source), test.limits)
+		runtime.ReadMemStats(&after)
+		assert.True(t, errors.Is(err, test.code), "expecting %s with %+v, got %v", test.code, test.limits, err)
+		assert.Less(t, after.TotalAlloc-before.TotalAlloc, uint64(4*len(test.source)), "allocated bytes with %+v", test.limits)
+	}
+}
+
+func TestDepthFirstSearchDeepTree(t *testing.T) {
+	var expr ast.Expression = ast.KeywordTrue
+	for i := 0; i < 1000000; i++ {
+		expr = &ast.Not{Expression: expr}
+	}
+	v := &depthVisitor{}
+	ast.DepthFirstSearch(expr, v)
+	assert.Equal(t, 1000001, v.max)
+	assert.Equal(t, 0, v.depth)
+}
+
+type depthVisitor struct {
+	depth, max int
+}
+
+func (v *depthVisitor) PreOrderVisit(n ast.Node) {
+	v.depth++
+	if v.depth > v.max {
+		v.max = v.depth
+	}
+}
+
+func (v *depthVisitor) PostOrderVisit(n ast.Node) {
+	v.depth--
+}
+
+// The range and offset in "of" expressions are part of the tree.
+func TestDepthFirstSearchOf(t *testing.T) {
+	expr, err := parser.ParseExpression(strings.NewReader("any of them in (0..filesize) and any of them at (1 + 2)"))
+	require.NoError(t, err)
+	v := &depthVisitor{}
+	ast.DepthFirstSearch(expr, v)
+	assert.Equal(t, 5, v.max)
+}
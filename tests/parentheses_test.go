@@ -0,0 +1,189 @@
+package tests
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/VirusTotal/gyp"
+	"github.com/VirusTotal/gyp/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+var booleanOperators = []ast.OperatorType{ast.OpAnd, ast.OpOr}
+
+var comparisonOperators = []ast.OperatorType{
+	ast.OpEqual, ast.OpNotEqual, ast.OpLessThan, ast.OpGreaterThan,
+	ast.OpLessOrEqual, ast.OpGreaterOrEqual,
+}
+
+var primaryOperators = []ast.OperatorType{
+	ast.OpBitOr, ast.OpBitXor, ast.OpBitAnd, ast.OpShiftLeft, ast.OpShiftRight,
+	ast.OpAdd, ast.OpSub, ast.OpMul, ast.OpDiv, ast.OpMod,
+}
+
+// expressionGenerator generates random expressions that are valid in a rule
+// condition. The expressions don't contain any Group node, parentheses are
+// expected to be added by WriteSource where required.
+type expressionGenerator struct {
+	rand *rand.Rand
+}
+
+func (g *expressionGenerator) operator(operators []ast.OperatorType) ast.OperatorType {
+	return operators[g.rand.Intn(len(operators))]
+}
+
+func (g *expressionGenerator) primary(depth int) ast.Expression {
+	n := 3
+	if depth > 0 {
+		n = 6
+	}
+	switch g.rand.Intn(n) {
+	case 0:
+		return &ast.LiteralInteger{Value: g.rand.Int63n(100)}
+	case 1:
+		return &ast.Identifier{Identifier: "x"}
+	case 2:
+		return ast.KeywordFilesize
+	case 3:
+		return &ast.Minus{Expression: g.primary(depth - 1)}
+	case 4:
+		return &ast.BitwiseNot{Expression: g.primary(depth - 1)}
+	default:
+		return &ast.Operation{
+			Operator: g.operator(primaryOperators),
+			Operands: []ast.Expression{g.primary(depth - 1), g.primary(depth - 1)},
+		}
+	}
+}
+
+// rangeBound generates a primary expression that can be used as a range
+// bound, which can't be a negative integer literal.
+func (g *expressionGenerator) rangeBound(depth int) ast.Expression {
+	for {
+		e := g.primary(depth)
+		if m, ok := e.(*ast.Minus); ok {
+			if _, ok := m.Expression.(*ast.LiteralInteger); ok {
+				continue
+			}
+		}
+		return e
+	}
+}
+
+// rangeOf generates a range. If both bounds are integer literals the lower
+// bound must be less than the upper bound.
+func (g *expressionGenerator) rangeOf(depth int) *ast.Range {
+	for {
+		r := &ast.Range{Start: g.rangeBound(depth), End: g.rangeBound(depth)}
+		start, ok1 := r.Start.(*ast.LiteralInteger)
+		end, ok2 := r.End.(*ast.LiteralInteger)
+		if ok1 && ok2 && start.Value >= end.Value {
+			continue
+		}
+		return r
+	}
+}
+
+func (g *expressionGenerator) boolean(depth int) ast.Expression {
+	n := 4
+	if depth > 0 {
+		n = 8
+	}
+	switch g.rand.Intn(n) {
+	case 0:
+		return ast.KeywordTrue
+	case 1:
+		return &ast.StringIdentifier{Identifier: "a"}
+	case 2:
+		return &ast.StringIdentifier{Identifier: "a", At: g.primary(depth)}
+	case 3:
+		return &ast.Operation{
+			Operator: g.operator(comparisonOperators),
+			Operands: []ast.Expression{g.primary(depth), g.primary(depth)},
+		}
+	case 4:
+		return &ast.Not{Expression: g.boolean(depth - 1)}
+	case 5:
+		return &ast.Defined{Expression: g.boolean(depth - 1)}
+	case 6:
+		return &ast.StringIdentifier{Identifier: "a", In: g.rangeOf(depth - 1)}
+	default:
+		return &ast.Operation{
+			Operator: g.operator(booleanOperators),
+			Operands: []ast.Expression{g.boolean(depth - 1), g.boolean(depth - 1)},
+		}
+	}
+}
+
+// normalize removes Group nodes from an expression and merges operations
+// where the left-most operand is an operation with the same operator, as the
+// parser does with left-associative operators. Two expressions are equivalent
+// if their normalized forms are equal.
+func normalize(e ast.Expression) ast.Expression {
+	switch v := e.(type) {
+	case *ast.Group:
+		return normalize(v.Expression)
+	case *ast.Not:
+		return &ast.Not{Expression: normalize(v.Expression)}
+	case *ast.Defined:
+		return &ast.Defined{Expression: normalize(v.Expression)}
+	case *ast.Minus:
+		return &ast.Minus{Expression: normalize(v.Expression)}
+	case *ast.BitwiseNot:
+		return &ast.BitwiseNot{Expression: normalize(v.Expression)}
+	case *ast.StringIdentifier:
+		s := &ast.StringIdentifier{Identifier: v.Identifier}
+		if v.At != nil {
+			s.At = normalize(v.At)
+		}
+		if v.In != nil {
+			s.In = &ast.Range{
+				Start: normalize(v.In.Start),
+				End:   normalize(v.In.End),
+			}
+		}
+		return s
+	case *ast.Operation:
+		operands := make([]ast.Expression, 0, len(v.Operands))
+		for i, operand := range v.Operands {
+			operand = normalize(operand)
+			if o, ok := operand.(*ast.Operation); ok && i == 0 && o.Operator == v.Operator {
+				operands = append(operands, o.Operands...)
+			} else {
+				operands = append(operands, operand)
+			}
+		}
+		return &ast.Operation{Operator: v.Operator, Operands: operands}
+	}
+	return e
+}
+
+func TestParenthesesRoundTrip(t *testing.T) {
+	g := &expressionGenerator{rand: rand.New(rand.NewSource(1))}
+	for i := 0; i < 2000; i++ {
+		rule := &ast.Rule{
+			Identifier: "foo",
+			Strings: []ast.String{
+				&ast.TextString{
+					BaseString: ast.BaseString{Identifier: "a"},
+					Value:      "foo",
+				},
+			},
+			Condition: g.boolean(4),
+		}
+		var b strings.Builder
+		if !assert.NoError(t, rule.WriteSource(&b)) {
+			break
+		}
+		rs, err := gyp.ParseString(b.String())
+		if !assert.NoError(t, err, b.String()) {
+			break
+		}
+		if !assert.Equal(t,
+			normalize(rule.Condition),
+			normalize(rs.Rules[0].Condition), b.String()) {
+			break
+		}
+	}
+}
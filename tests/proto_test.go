@@ -0,0 +1,267 @@
+package tests
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/VirusTotal/gyp"
+	"github.com/VirusTotal/gyp/ast"
+	"github.com/VirusTotal/gyp/pb"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvalidProto(t *testing.T) {
+	tests := []struct {
+		json string
+		err  string
+	}{
+		{
+			`{"rules": [{"condition": {"boolValue": true}}]}`,
+			`rules[0].identifier: missing rule identifier`,
+		},
+		{
+			`{"rules": [{"identifier": "a"}]}`,
+			`rule a: condition: missing expression`,
+		},
+		{
+			`{"rules": [{"identifier": "a", "condition": {}}]}`,
+			`rule a: condition: missing expression`,
+		},
+		{
+			`{"rules": [{"identifier": "a", "condition": {"andExpression": {"terms": [{"boolValue": true}]}}}]}`,
+			`rule a: condition.and_expression.terms: expecting two or more terms`,
+		},
+		{
+			`{"rules": [{"identifier": "a", "condition": {"orExpression": {"terms": [{"boolValue": true}, {}]}}}]}`,
+			`rule a: condition.or_expression.terms[1]: missing expression`,
+		},
+		{
+			`{"rules": [{"identifier": "a", "condition": {"binaryExpression": {"operator": "EQ", "left": {"numberValue": 1}}}}]}`,
+			`rule a: condition.binary_expression.right: missing expression`,
+		},
+		{
+			`{"rules": [{"identifier": "a", "condition": {"binaryExpression": {"operator": "EQ", "left": {"numberValue": 1}, "right": {"notExpression": {}}}}}]}`,
+			`rule a: condition.binary_expression.right.not_expression: missing expression`,
+		},
+		{
+			`{"rules": [{"identifier": "a", "condition": {"binaryExpression": {"operator": "AT", "left": {"numberValue": 1}, "right": {"numberValue": 1}}}}]}`,
+			`rule a: condition.binary_expression.left: expecting a string identifier`,
+		},
+		{
+			`{"rules": [{"identifier": "a", "condition": {"numberValue": 1, "numberRepresentation": {"radix": 3}}}]}`,
+			`rule a: condition.number_representation.radix: invalid radix 3`,
+		},
+		{
+			`{"rules": [{"identifier": "a", "condition": {"identifier": {"items": [{"index": {"numberValue": 1}}]}}}]}`,
+			`rule a: condition.identifier.items[0]: index can't be the left-most item in an identifier`,
+		},
+		{
+			`{"rules": [{"identifier": "a", "condition": {"forInExpression": {"forExpression": {"keyword": "ALL"}, "identifiers": ["i"], "expression": {"boolValue": true}}}}]}`,
+			`rule a: condition.for_in_expression.iterator: missing iterator`,
+		},
+		{
+			`{"rules": [{"identifier": "a", "condition": {"forOfExpression": {"forExpression": {"keyword": "ALL"}}}}]}`,
+			`rule a: condition.for_of_expression: expecting one string set, rule set or text string set`,
+		},
+		{
+			`{"rules": [{"identifier": "a", "condition": {"withExpression": {"declarations": [{"identifier": "x"}], "expression": {"boolValue": true}}}}]}`,
+			`rule a: condition.with_expression.declarations[0].expression: missing expression`,
+		},
+		{
+			`{"rules": [{"identifier": "a", "condition": {"integerFunction": {"function": "int64", "argument": {"numberValue": 0}}}}]}`,
+			`rule a: condition.integer_function.function: unknown function "int64"`,
+		},
+		{
+			`{"rules": [{"identifier": "a", "strings": [{"id": "$a"}], "condition": {"boolValue": true}}]}`,
+			`rule a: strings[0].value: missing string value`,
+		},
+		{
+			`{"rules": [{"identifier": "a", "strings": [{"id": "$a", "hex": {"token": [{"sequence": {"value": "AQI=", "mask": "/w=="}}]}}], "condition": {"boolValue": true}}]}`,
+			`rule a: strings[0].hex.token[0].sequence.mask: expecting 2 masks, got 1`,
+		},
+		{
+			`{"rules": [{"identifier": "a", "strings": [{"id": "$a", "hex": {"token": [{"alternative": {"tokens": [{"token": [{"sequence": {"value": "AQ==", "mask": "AQ=="}}]}]}}]}}], "condition": {"boolValue": true}}]}`,
+			`rule a: strings[0].hex.token[0].alternative.tokens[0].token[0].sequence.mask: invalid mask 01`,
+		},
+		{
+			`{"rules": [{"identifier": "a", "meta": [{"key": "foo", "text": "\\q"}], "condition": {"boolValue": true}}]}`,
+			`rule a: meta[0].text: invalid escaped string "\\q"`,
+		},
+	}
+	for _, test := range tests {
+		var rs pb.RuleSet
+		require.NoError(t, jsonpb.UnmarshalString(test.json, &rs), test.json)
+		_, err := ast.NewRuleSetFromProto(&rs)
+		if assert.Error(t, err, test.json) {
+			assert.IsType(t, &ast.ValidationError{}, err)
+			assert.Equal(t, test.err, err.Error())
+		}
+		assert.Panics(t, func() { ast.RuleSetFromProto(&rs) }, test.json)
+	}
+}
+
+func TestWriteSourceErrors(t *testing.T) {
+	rule := &ast.Rule{Identifier: "a"}
+	assert.EqualError(t, rule.WriteSource(ioutil.Discard), "rule a: condition: missing condition")
+
+	rule.Condition = &ast.Operation{Operator: ast.OpAnd, Operands: []ast.Expression{ast.KeywordTrue}}
+	assert.EqualError(t, rule.WriteSource(ioutil.Discard), "rule a: condition.operands: expecting two or more operands")
+
+	meta := &ast.Meta{Key: "foo", Value: int64(1)}
+	_, err := meta.Unescape()
+	assert.EqualError(t, err, "meta foo is not a string")
+}
+
+// Rulesets that don't come from the parser, like the ones decoded from JSON,
+// may not be valid. ToProto returns an error for them instead of panicking.
+func TestToProtoErrors(t *testing.T) {
+	tests := []struct {
+		condition ast.Expression
+		err       string
+	}{
+		{
+			ast.KeywordThem,
+			`rule a: condition: unexpected keyword "them"`,
+		},
+		{
+			&ast.Operation{Operator: "xor", Operands: []ast.Expression{ast.KeywordTrue, ast.KeywordFalse}},
+			`rule a: condition.operator: unexpected operator "xor"`,
+		},
+		{
+			&ast.Not{},
+			`rule a: condition.expression: missing expression`,
+		},
+		{
+			&ast.Of{Quantifier: ast.KeywordAny},
+			`rule a: condition: expecting one string set, rule set or text string set`,
+		},
+		{
+			&ast.Of{Quantifier: ast.KeywordAny, Strings: ast.KeywordAll},
+			`rule a: condition.strings: unexpected keyword "all"`,
+		},
+		{
+			&ast.Of{Quantifier: ast.KeywordAny, Strings: &ast.Enum{Values: []ast.Expression{&ast.Identifier{Identifier: "a"}}}},
+			`rule a: condition.strings.values[0]: expecting a string identifier, got *ast.Identifier`,
+		},
+		{
+			&ast.ForOf{Quantifier: ast.KeywordAny, Strings: ast.KeywordAll, Condition: ast.KeywordTrue},
+			`rule a: condition.strings: unexpected keyword "all"`,
+		},
+		{
+			&ast.MemberAccess{Container: &ast.LiteralInteger{Value: 1}, Member: "b"},
+			`rule a: condition.container: expecting an identifier, got *ast.LiteralInteger`,
+		},
+	}
+	for _, test := range tests {
+		rs := &ast.RuleSet{Rules: []*ast.Rule{{Identifier: "a", Condition: test.condition}}}
+		_, err := rs.ToProto()
+		if assert.Error(t, err, test.err) {
+			assert.IsType(t, &ast.ValidationError{}, err)
+			assert.Equal(t, test.err, err.Error())
+		}
+		var b strings.Builder
+		assert.EqualError(t, rs.WriteSource(&b), test.err)
+	}
+
+	rs := &ast.RuleSet{Rules: []*ast.Rule{{
+		Identifier: "a",
+		Meta:       []*ast.Meta{{Key: "foo", Value: `\q`}},
+		Condition:  ast.KeywordTrue,
+	}}}
+	_, err := rs.ToProto()
+	assert.EqualError(t, err, `rule a: meta[0].value: invalid escaped string "\\q"`)
+}
+
+// Keywords other than all, any and none are quantifiers that are expressions.
+func TestKeywordQuantifierProto(t *testing.T) {
+	source := `
+rule a {
+  strings:
+    $a = "a"
+  condition:
+    for filesize i in (1) : (true) and entrypoint of them
+}
+`
+	rs, err := gyp.ParseString(source)
+	require.NoError(t, err)
+	rs, err = ast.NewRuleSetFromProto(rs.AsProto())
+	require.NoError(t, err)
+	var b strings.Builder
+	assert.NoError(t, rs.WriteSource(&b))
+	assert.Equal(t, source, b.String())
+}
+
+func FuzzRuleSetFromProto(f *testing.F) {
+	for _, source := range []string{testRules, cstSource} {
+		rs, err := gyp.ParseString(source)
+		require.NoError(f, err)
+		data, err := proto.Marshal(rs.AsProto())
+		require.NoError(f, err)
+		f.Add(data)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var pbRuleset pb.RuleSet
+		if err := proto.Unmarshal(data, &pbRuleset); err != nil {
+			return
+		}
+		rs, err := ast.NewRuleSetFromProto(&pbRuleset)
+		if err != nil {
+			return
+		}
+		// A valid ruleset can be written, and converted back to a protobuf.
+		var source, serialized strings.Builder
+		if err := rs.WriteSource(&source); err != nil {
+			t.Fatalf("WriteSource failed: %s", err)
+		}
+		if err := gyp.NewSerializer(&serialized).Serialize(rs.AsProto()); err != nil {
+			t.Fatalf("Serialize failed: %s", err)
+		}
+		// The protobuf may not be valid YARA, for instance, it can reference
+		// undefined strings. If it is, the source written by WriteSource and
+		// the serializer must both produce the original ruleset.
+		parsed, err := gyp.ParseString(source.String())
+		if err != nil {
+			return
+		}
+		expected := source.String()
+		assert.Equal(t, expected, protoRoundTrip(t, parsed), "WriteSource")
+		parsed, err = gyp.ParseString(serialized.String())
+		require.NoError(t, err, "source: %q", serialized.String())
+		assert.Equal(t, expected, protoRoundTrip(t, parsed), "Serialize")
+	})
+}
+
+// protoRoundTrip converts the ruleset to a protobuf and back, and returns its
+// source code. Rulesets that differ only in things not represented in the
+// protobufs, like groups and line numbers, produce the same source code.
+func protoRoundTrip(t *testing.T, rs *ast.RuleSet) string {
+	var b strings.Builder
+	require.NoError(t, ast.RuleSetFromProto(rs.AsProto()).WriteSource(&b))
+	return b.String()
+}
+
+// Rulesets produced by the parser can always be converted to protobufs,
+// serialized, and converted back.
+func FuzzParseToProto(f *testing.F) {
+	corpus, err := ioutil.ReadFile("testdata/corpus.yar")
+	require.NoError(f, err)
+	for _, source := range []string{testRules, cstSource, string(corpus)} {
+		f.Add(source)
+	}
+	f.Fuzz(func(t *testing.T, source string) {
+		rs, err := gyp.ParseString(source)
+		if err != nil {
+			return
+		}
+		pbRuleset, err := rs.ToProto()
+		require.NoError(t, err, "source: %q", source)
+		var b strings.Builder
+		require.NoError(t, gyp.NewSerializer(&b).Serialize(pbRuleset), "source: %q", source)
+		_, err = ast.NewRuleSetFromProto(pbRuleset)
+		require.NoError(t, err, "source: %q", source)
+	})
+}
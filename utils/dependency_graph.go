@@ -0,0 +1,306 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/VirusTotal/gyp/ast"
+)
+
+// DependencyGraph is the graph of dependencies between the rules in a
+// ruleset. A rule depends on another one if its condition references it,
+// either directly or with a wildcard like "any of (foo*)". The graph also
+// records the modules used by each rule.
+type DependencyGraph struct {
+	rules []*ast.Rule
+	index map[string]int
+	// Indexes of the rules each rule depends on, in ascending order.
+	dependencies [][]int
+	// Modules used by each rule, in order of appearance.
+	modules [][]string
+}
+
+// Edge is an edge in a DependencyGraph, meaning that rule From depends on
+// rule To.
+type Edge struct {
+	From string
+	To   string
+}
+
+// CycleError is the error returned when rules depend on each other in a
+// cycle.
+type CycleError struct {
+	// Identifiers of the rules in the cycle, each rule depends on the next
+	// one and the last one depends on the first.
+	Rules []string
+}
+
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("dependency cycle: %s -> %s", strings.Join(e.Rules, " -> "), e.Rules[0])
+}
+
+// NewDependencyGraph returns the dependency graph for the rules in rs.
+func NewDependencyGraph(rs *ast.RuleSet) *DependencyGraph {
+	g := &DependencyGraph{
+		rules:        rs.Rules,
+		index:        make(map[string]int, len(rs.Rules)),
+		dependencies: make([][]int, len(rs.Rules)),
+		modules:      make([][]string, len(rs.Rules)),
+	}
+	for i, rule := range rs.Rules {
+		g.index[rule.Identifier] = i
+	}
+	for i, rule := range rs.Rules {
+		v := &dependencyVisitor{graph: g, dependencies: make(map[int]bool)}
+		v.visit(rule.Condition, nil)
+		delete(v.dependencies, i)
+		for j := range v.dependencies {
+			g.dependencies[i] = append(g.dependencies[i], j)
+		}
+		sort.Ints(g.dependencies[i])
+		g.modules[i] = v.modules
+	}
+	return g
+}
+
+// Rule returns the rule with the given identifier, or nil if there is no
+// such rule.
+func (g *DependencyGraph) Rule(identifier string) *ast.Rule {
+	if i, ok := g.index[identifier]; ok {
+		return g.rules[i]
+	}
+	return nil
+}
+
+// Dependencies returns the identifiers of the rules that the given rule
+// depends on directly, in the order in which they appear in the ruleset.
+func (g *DependencyGraph) Dependencies(rule string) []string {
+	i, ok := g.index[rule]
+	if !ok {
+		return nil
+	}
+	return g.identifiers(g.dependencies[i])
+}
+
+// Dependents returns the identifiers of the rules that depend directly on
+// the given rule, in the order in which they appear in the ruleset.
+func (g *DependencyGraph) Dependents(rule string) []string {
+	i, ok := g.index[rule]
+	if !ok {
+		return nil
+	}
+	var dependents []int
+	for j, dependencies := range g.dependencies {
+		if k := sort.SearchInts(dependencies, i); k < len(dependencies) && dependencies[k] == i {
+			dependents = append(dependents, j)
+		}
+	}
+	return g.identifiers(dependents)
+}
+
+// Modules returns the modules used by the given rule. Only the identifiers
+// at the root of a member access, like "pe" in "pe.is_dll()", are considered
+// modules.
+func (g *DependencyGraph) Modules(rule string) []string {
+	if i, ok := g.index[rule]; ok {
+		return g.modules[i]
+	}
+	return nil
+}
+
+// Edges returns all the edges in the graph, sorted by the position in the
+// ruleset of the dependent rule first, and of the dependency second.
+func (g *DependencyGraph) Edges() []Edge {
+	var edges []Edge
+	for i, dependencies := range g.dependencies {
+		for _, j := range dependencies {
+			edges = append(edges, Edge{g.rules[i].Identifier, g.rules[j].Identifier})
+		}
+	}
+	return edges
+}
+
+// TopologicalSort returns the rules sorted so that every rule appears after
+// the rules it depends on. Apart from that, the order of the rules in the
+// ruleset is preserved. If there is a cycle a *CycleError is returned.
+func (g *DependencyGraph) TopologicalSort() ([]*ast.Rule, error) {
+	sorted, err := g.sort(g.all(), true)
+	if err != nil {
+		return nil, err
+	}
+	return g.rulesAt(sorted), nil
+}
+
+// sortAll is like TopologicalSort, but ignores the edges that close cycles.
+func (g *DependencyGraph) sortAll() []*ast.Rule {
+	sorted, _ := g.sort(g.all(), false)
+	return g.rulesAt(sorted)
+}
+
+// TransitiveDependencies returns the rules that the given rules depend on,
+// directly or indirectly, sorted so that every rule appears after the rules
+// it depends on. The given rules are not included in the result. Cycles are
+// not reported as errors, the rules in a cycle are included in the order in
+// which they are found.
+func (g *DependencyGraph) TransitiveDependencies(rules ...string) ([]*ast.Rule, error) {
+	start := make([]int, len(rules))
+	excluded := make(map[int]bool, len(rules))
+	for i, rule := range rules {
+		j, ok := g.index[rule]
+		if !ok {
+			return nil, fmt.Errorf("%s does not exist in the ruleset", rule)
+		}
+		start[i] = j
+		excluded[j] = true
+	}
+	sorted, _ := g.sort(start, false)
+	var dependencies []int
+	for _, i := range sorted {
+		if !excluded[i] {
+			dependencies = append(dependencies, i)
+		}
+	}
+	return g.rulesAt(dependencies), nil
+}
+
+// sort returns the rules reachable from the start rules, in topological
+// order. If strict is true an error is returned when a cycle is found,
+// otherwise the edges that close cycles are ignored.
+func (g *DependencyGraph) sort(start []int, strict bool) ([]int, error) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(g.rules))
+	var sorted []int
+	// Rules being visited, used for reporting cycles.
+	var path []int
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case visited:
+			return nil
+		case visiting:
+			if !strict {
+				return nil
+			}
+			for k, j := range path {
+				if j == i {
+					return &CycleError{Rules: g.identifiers(path[k:])}
+				}
+			}
+		}
+		state[i] = visiting
+		path = append(path, i)
+		for _, j := range g.dependencies[i] {
+			if err := visit(j); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[i] = visited
+		sorted = append(sorted, i)
+		return nil
+	}
+	for _, i := range start {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+	}
+	return sorted, nil
+}
+
+// all returns the indexes of all the rules.
+func (g *DependencyGraph) all() []int {
+	all := make([]int, len(g.rules))
+	for i := range all {
+		all[i] = i
+	}
+	return all
+}
+
+func (g *DependencyGraph) identifiers(indexes []int) []string {
+	identifiers := make([]string, len(indexes))
+	for i, j := range indexes {
+		identifiers[i] = g.rules[j].Identifier
+	}
+	return identifiers
+}
+
+func (g *DependencyGraph) rulesAt(indexes []int) []*ast.Rule {
+	rules := make([]*ast.Rule, len(indexes))
+	for i, j := range indexes {
+		rules[i] = g.rules[j]
+	}
+	return rules
+}
+
+// dependencyVisitor collects the rules and modules referenced by a condition.
+type dependencyVisitor struct {
+	graph        *DependencyGraph
+	dependencies map[int]bool
+	modules      []string
+}
+
+// visit walks the node's syntax tree. The loopVars argument contains the
+// variables defined by the "for ... in" loops and "with" statements enclosing
+// the node, which shadow rules and modules with the same name.
+func (v *dependencyVisitor) visit(node ast.Node, loopVars []string) {
+	if node == nil {
+		return
+	}
+	switch n := node.(type) {
+	case *ast.Identifier:
+		if strings.HasSuffix(n.Identifier, "*") {
+			prefix := strings.TrimSuffix(n.Identifier, "*")
+			for i, rule := range v.graph.rules {
+				if strings.HasPrefix(rule.Identifier, prefix) {
+					v.dependencies[i] = true
+				}
+			}
+		} else if i, ok := v.graph.index[n.Identifier]; ok && !sliceContains(n.Identifier, loopVars) {
+			v.dependencies[i] = true
+		}
+		return
+	case *ast.ForIn:
+		v.visit(n.Quantifier, loopVars)
+		v.visit(n.Iterator, loopVars)
+		v.visit(n.Condition, append(loopVars[:len(loopVars):len(loopVars)], n.Variables...))
+		return
+	case *ast.With:
+		for _, d := range n.Declarations {
+			v.visit(d.Expression, loopVars)
+			loopVars = append(loopVars[:len(loopVars):len(loopVars)], d.Identifier)
+		}
+		v.visit(n.Condition, loopVars)
+		return
+	case *ast.MemberAccess:
+		if root, ok := n.Container.(*ast.Identifier); ok {
+			if !sliceContains(root.Identifier, loopVars) && !sliceContains(root.Identifier, v.modules) {
+				v.modules = append(v.modules, root.Identifier)
+			}
+			return
+		}
+	case *ast.FunctionCall:
+		// Functions called by their name are built-in functions like uint8,
+		// not rules.
+		if _, ok := n.Callable.(*ast.Identifier); !ok {
+			v.visit(n.Callable, loopVars)
+		}
+		for _, arg := range n.Arguments {
+			v.visit(arg, loopVars)
+		}
+		return
+	case *ast.Subscripting:
+		if _, ok := n.Array.(*ast.Identifier); !ok {
+			v.visit(n.Array, loopVars)
+		}
+		v.visit(n.Index, loopVars)
+		return
+	}
+	for _, child := range children(node) {
+		v.visit(child, loopVars)
+	}
+}
@@ -0,0 +1,134 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/VirusTotal/gyp/ast"
+	"github.com/google/go-cmp/cmp"
+)
+
+const graphRules = `
+import "pe"
+rule pe_1 { condition: pe.is_dll() }
+rule pe_2 { condition: pe.sections[0].name == ".text" }
+rule pe { condition: true }
+rule a { condition: any of (pe_*) and for any pe in (0..1): (pe == 1) }
+rule b { condition: pe and a }
+rule c { condition: for any i in (0..pe.number_of_sections): (pe.sections[i].name == ".rsrc") }
+rule d { condition: uint16(0) == 0x5A4D and math.entropy(0, filesize) > 7 }
+rule e { condition: with x = d, d = pe.number_of_sections : (x and d > 1 and a) }
+`
+
+func identifiers(rules []*ast.Rule) []string {
+	var result []string
+	for _, rule := range rules {
+		result = append(result, rule.Identifier)
+	}
+	return result
+}
+
+func TestDependencyGraph(t *testing.T) {
+	graph := NewDependencyGraph(parseRules(t, graphRules))
+	tests := []struct {
+		rule         string
+		dependencies []string
+		dependents   []string
+		modules      []string
+	}{
+		{"pe_1", []string{}, []string{"a"}, []string{"pe"}},
+		{"pe_2", []string{}, []string{"a"}, []string{"pe"}},
+		{"pe", []string{}, []string{"b"}, nil},
+		{"a", []string{"pe_1", "pe_2"}, []string{"b", "e"}, nil},
+		{"b", []string{"pe", "a"}, []string{}, nil},
+		{"c", []string{}, []string{}, []string{"pe"}},
+		{"d", []string{}, []string{"e"}, []string{"math"}},
+		{"e", []string{"a", "d"}, []string{}, []string{"pe"}},
+	}
+	for _, test := range tests {
+		if deps := graph.Dependencies(test.rule); !cmp.Equal(test.dependencies, deps) {
+			t.Errorf("Dependencies of %s: expected %v, got %v", test.rule, test.dependencies, deps)
+		}
+		if deps := graph.Dependents(test.rule); !cmp.Equal(test.dependents, deps) {
+			t.Errorf("Dependents of %s: expected %v, got %v", test.rule, test.dependents, deps)
+		}
+		if modules := graph.Modules(test.rule); !cmp.Equal(test.modules, modules) {
+			t.Errorf("Modules of %s: expected %v, got %v", test.rule, test.modules, modules)
+		}
+	}
+	expectedEdges := []Edge{{"a", "pe_1"}, {"a", "pe_2"}, {"b", "pe"}, {"b", "a"}, {"e", "a"}, {"e", "d"}}
+	if edges := graph.Edges(); !cmp.Equal(expectedEdges, edges) {
+		t.Errorf("Expected edges %v, got %v", expectedEdges, edges)
+	}
+	if graph.Rule("f") != nil || graph.Dependencies("f") != nil {
+		t.Error("Expected no rule f")
+	}
+}
+
+func TestTopologicalSort(t *testing.T) {
+	graph := NewDependencyGraph(parseRules(t, `
+rule x1 { condition: true }
+rule x2 { condition: true }
+rule a { condition: c and any of (x*) }
+rule c { condition: e }
+rule d { condition: true }
+rule e { condition: true }`))
+	sorted, err := graph.TopologicalSort()
+	if err != nil {
+		t.Fatalf("TopologicalSort failed: %s", err)
+	}
+	expected := []string{"x1", "x2", "e", "c", "a", "d"}
+	if result := identifiers(sorted); !cmp.Equal(expected, result) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestTopologicalSortCycle(t *testing.T) {
+	ruleset := parseRules(t, `
+rule a { condition: b }
+rule b { condition: c }
+rule c { condition: any of (a*) }
+rule d { condition: d }`)
+	_, err := NewDependencyGraph(ruleset).TopologicalSort()
+	if err == nil || err.Error() != "dependency cycle: a -> b -> c -> a" {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cycle, ok := err.(*CycleError); !ok || !cmp.Equal([]string{"a", "b", "c"}, cycle.Rules) {
+		t.Errorf("Unexpected error: %#v", err)
+	}
+
+	// SortRules doesn't fail nor loop forever with cycles.
+	expected := []string{"c", "b", "a", "d"}
+	if result := identifiers(SortRules(*ruleset).Rules); !cmp.Equal(expected, result) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestTransitiveDependencies(t *testing.T) {
+	graph := NewDependencyGraph(parseRules(t, graphRules))
+	dependencies, err := graph.TransitiveDependencies("b", "c")
+	if err != nil {
+		t.Fatalf("TransitiveDependencies failed: %s", err)
+	}
+	expected := []string{"pe", "pe_1", "pe_2", "a"}
+	if result := identifiers(dependencies); !cmp.Equal(expected, result) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+	if _, err := graph.TransitiveDependencies("f"); err == nil || err.Error() != "f does not exist in the ruleset" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestGetDependenciesForRulesShadowedModule(t *testing.T) {
+	ruleset := parseRules(t, graphRules)
+	dependencies, err := GetDependenciesForRules(*ruleset, "a", "b")
+	if err != nil {
+		t.Fatalf("GetDependenciesForRules failed: %s", err)
+	}
+	if dependencies.Imports != nil {
+		t.Errorf("Expected no imports, got %v", dependencies.Imports)
+	}
+	expected := []string{"pe_1", "pe_2", "pe", "a"}
+	if result := identifiers(dependencies.Rules); !cmp.Equal(expected, result) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
@@ -13,35 +13,24 @@ type queueT struct {
 	ignoreList []string
 }
 
-// GetRulesSubset will recursively find dependencies for a list of rules
+// GetRulesSubset will recursively find dependencies for a list of rules. The
+// rules in the result are sorted so that every rule appears after the rules
+// it depends on, and the imports are the modules used by the given rules and
+// their dependencies.
 func GetRulesSubset(ruleset ast.RuleSet, ruleNames ...string) (ast.RuleSet, error) {
-	queue := make(map[string]struct{}) // List of rules to get dependencies for
-	processed := []string{}            // List of rules where dependencies have already been found
+	graph := NewDependencyGraph(&ruleset)
+	dependencies, err := graph.TransitiveDependencies(ruleNames...)
+	if err != nil {
+		return ast.RuleSet{}, err
+	}
 	results := ast.RuleSet{}
 	for _, ruleName := range ruleNames {
-		queue[ruleName] = struct{}{}
+		results.Imports = appendMissing(results.Imports, graph.Modules(ruleName))
 	}
-
-	for len(queue) > 0 {
-		for k := range queue {
-			dependencies, err := GetDependenciesForRules(ruleset, k)
-			if err != nil {
-				return ast.RuleSet{}, err
-			}
-			for _, rule := range dependencies.Rules {
-				if _, ok := queue[rule.Identifier]; !ok && !sliceContains(rule.Identifier, processed) {
-					results.Rules = append([]*ast.Rule{rule}, results.Rules...)
-					queue[rule.Identifier] = struct{}{}
-				}
-			}
-			for _, imprt := range dependencies.Imports {
-				if !sliceContains(imprt, results.Imports) {
-					results.Imports = append(results.Imports, imprt)
-				}
-			}
-			processed = append(processed, k)
-			delete(queue, k)
-		}
+	for _, rule := range dependencies {
+		results.Imports = appendMissing(results.Imports, graph.Modules(rule.Identifier))
+		rule := *rule
+		results.Rules = append(results.Rules, &rule)
 	}
 	return results, nil
 }
@@ -50,115 +39,61 @@ func GetRulesSubset(ruleset ast.RuleSet, ruleNames ...string) (ast.RuleSet, erro
 // if the rule has dependencies. If dependencies are found they will be moved
 // in the ruleset to before the rule that depends of them. If the ruleset
 // does not contain rules with dependencies the order of the rules will not change.
+// Rules that depend on each other in a cycle are left in the order in which
+// they are found, use DependencyGraph.TopologicalSort for detecting cycles.
 func SortRules(rs ast.RuleSet) ast.RuleSet {
-	var goodOrder []*ast.Rule
-	type nodeT struct {
-		rule         *ast.Rule
-		dependencies []string
-	}
-	var nodes []nodeT
-	for _, rule := range rs.Rules {
-		var node nodeT
-		node.rule = rule
-		drs, err := GetDependenciesForRules(rs, rule.Identifier)
-		if err != nil {
-			panic(err.Error())
-		}
-		for _, dr := range drs.Rules {
-			node.dependencies = append(node.dependencies, dr.Identifier)
-		}
-		nodes = append(nodes, node)
-	}
-
-	for len(goodOrder) != len(rs.Rules) {
-		for i := len(nodes) - 1; i >= 0; i-- {
-			node := nodes[i]
-			if len(node.dependencies) == 0 {
-				// Rule has no dependencies
-				goodOrder = append([]*ast.Rule{node.rule}, goodOrder...) // Add to goodOrder
-				nodes = append(nodes[:i], nodes[i+1:]...)                // Delete from nodes
-				continue
-			}
-			var curDepBuffer []string // Contains list of rules already in the rule buffer
-			for _, gor := range goodOrder {
-				curDepBuffer = append(curDepBuffer, gor.Identifier)
-			}
-			if len(curDepBuffer) < len(node.dependencies) {
-				// Not enough rules exist for all dependencies to be there, skip to next rule
-				continue
-			}
-			var dc int
-			for _, dep := range node.dependencies {
-				if sliceContains(dep, curDepBuffer) {
-					dc++
-				}
-			}
-			if dc == len(node.dependencies) {
-				// All dependencies are there, place rule on the buffer
-				goodOrder = append(goodOrder, node.rule)  // Add to goodOrder buffer
-				nodes = append(nodes[:i], nodes[i+1:]...) // Delete from nodes
-				continue
-			}
-
-		}
-	}
-	rs.Rules = goodOrder
+	rs.Rules = NewDependencyGraph(&rs).sortAll()
 	return rs
 }
 
-// GetDependenciesForRules returns a ruleset containing dependencies (direct/indirect) for a list of given rules
+// GetDependenciesForRules returns a ruleset containing the direct dependencies
+// for a list of given rules, and the modules they use as imports
 func GetDependenciesForRules(ruleset ast.RuleSet, ruleNames ...string) (ast.RuleSet, error) {
 	// Make sure ruleNames and ruleset are not empty
 	if len(ruleset.Rules) == 0 {
 		return ast.RuleSet{}, fmt.Errorf("ruleset does not contain any rules")
 	}
 
-	// Ensure ruleNames are unique
-	ruleNamesM := make(map[string]struct{})
-	for _, ruleName := range ruleNames {
-		ruleNamesM[ruleName] = struct{}{}
-	}
-
+	graph := NewDependencyGraph(&ruleset)
 	var dependencies ast.RuleSet
-	for ruleName := range ruleNamesM {
-		var rule ast.Rule
-		for i := range ruleset.Rules {
-			if ruleset.Rules[i].Identifier == ruleName {
-				rule = *ruleset.Rules[i]
-				break
-			}
-		}
-		if rule.Identifier == "" {
+	var seen []string
+	for _, ruleName := range ruleNames {
+		if graph.Rule(ruleName) == nil {
 			return ast.RuleSet{}, fmt.Errorf("%s does not exist in the ruleset", ruleName)
 		}
-		ruleIdents := GetUsedIdentifiers(rule)
-		for ident := range ruleIdents {
-			// Get Imports
-			if sliceContains(ident, yaraModules) {
-				dependencies.Imports = append(dependencies.Imports, ident)
-			} else {
-				// Get Rules
-				for _, rule := range ruleset.Rules {
-					if rule.Identifier == ident {
-						rule := *rule
-						dependencies.Rules = append(dependencies.Rules, &rule)
-						break
-					}
-				}
+		dependencies.Imports = appendMissing(dependencies.Imports, graph.Modules(ruleName))
+		for _, dependency := range graph.Dependencies(ruleName) {
+			if sliceContains(dependency, seen) {
+				continue
 			}
+			seen = append(seen, dependency)
+			rule := *graph.Rule(dependency)
+			dependencies.Rules = append(dependencies.Rules, &rule)
 		}
 	}
 	return dependencies, nil
 }
 
 // GetUsedIdentifiers will find all the identifiers (excluding ForLoop
-// variables and Builtin FuncCalls) and the number of times each identifier is
+// variables, identifiers declared by With and Builtin FuncCalls) and the number of times each identifier is
 // seen for a given YARA rule
 func GetUsedIdentifiers(rule ast.Rule) map[string]int {
 	ruleIdentifiers := make(map[string]int)                          // ruleIdentifiers contains [identifier]numOfTimesSeen
 	pendingRules := append([]queueT{}, queueT{node: rule.Condition}) // pendingRules contains all the nodes to be processed
 	for len(pendingRules) > 0 {
 		pendingRule := &pendingRules[0]
+		if with, ok := pendingRule.node.(*ast.With); ok {
+			// With node found, the identifiers it declares are ignored in the
+			// declarations that follow them and in its condition
+			ignoreList := pendingRule.ignoreList
+			for _, declaration := range with.Declarations {
+				pendingRules = append(pendingRules, queueT{node: declaration.Expression, ignoreList: ignoreList})
+				ignoreList = append(ignoreList[:len(ignoreList):len(ignoreList)], declaration.Identifier)
+			}
+			pendingRules = append(pendingRules, queueT{node: with.Condition, ignoreList: ignoreList})
+			pendingRules = append(pendingRules[:0], pendingRules[1:]...) // Delete node from pendingRules
+			continue
+		}
 		if _, ok := pendingRule.node.(*ast.ForIn); ok {
 			// ForIn node found, extract loop variables and add them to the ignoreList
 			varsToIgnore := pendingRule.node.(*ast.ForIn).Variables
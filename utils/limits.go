@@ -0,0 +1,235 @@
+package utils
+
+import (
+	"fmt"
+
+	"github.com/VirusTotal/gyp/ast"
+	gyperror "github.com/VirusTotal/gyp/error"
+)
+
+// CompilerLimits contains the limits enforced by the YARA compiler, which
+// rejects rules that gyp accepts if they exceed any of them. A zero value in
+// any of the fields means the default value in libyara, and a negative value
+// disables the corresponding check.
+type CompilerLimits struct {
+	// Maximum number of strings in a rule (YR_MAX_STRINGS_PER_RULE).
+	MaxStringsPerRule int
+	// Maximum length of a bounded jump in a hex string (RE_MAX_RANGE).
+	MaxHexJump int
+	// Maximum nesting depth of "for" loops (YR_MAX_LOOP_NESTING).
+	MaxLoopNesting int
+	// Maximum number of alternatives in a hex string or regular expression,
+	// counted as the number of "|" operators in it (RE_MAX_SPLIT_ID).
+	MaxAlternatives int
+	// Maximum length of identifiers, like rule, tag, string and variable
+	// names (YR_MAX_IDENTIFIER_LENGTH).
+	MaxIdentifierLength int
+}
+
+// LibyaraLimits are the default limits in libyara.
+var LibyaraLimits = CompilerLimits{
+	MaxStringsPerRule:   10000,
+	MaxHexJump:          32767,
+	MaxLoopNesting:      4,
+	MaxAlternatives:     128,
+	MaxIdentifierLength: 128,
+}
+
+// withDefaults returns the limits with zero values replaced by the default
+// ones in libyara.
+func (l CompilerLimits) withDefaults() CompilerLimits {
+	if l.MaxStringsPerRule == 0 {
+		l.MaxStringsPerRule = LibyaraLimits.MaxStringsPerRule
+	}
+	if l.MaxHexJump == 0 {
+		l.MaxHexJump = LibyaraLimits.MaxHexJump
+	}
+	if l.MaxLoopNesting == 0 {
+		l.MaxLoopNesting = LibyaraLimits.MaxLoopNesting
+	}
+	if l.MaxAlternatives == 0 {
+		l.MaxAlternatives = LibyaraLimits.MaxAlternatives
+	}
+	if l.MaxIdentifierLength == 0 {
+		l.MaxIdentifierLength = LibyaraLimits.MaxIdentifierLength
+	}
+	return l
+}
+
+// exceeds returns true if n exceeds the limit, which is disabled if negative.
+func exceeds(n, limit int) bool {
+	return limit >= 0 && n > limit
+}
+
+// ValidateLimits checks the rules in a ruleset against the limits of the YARA
+// compiler, and returns an error for each violation. The errors have the
+// identifier of the rule and the line where the violation occurs, if known.
+func ValidateLimits(rs *ast.RuleSet, limits CompilerLimits) []gyperror.Error {
+	v := &limitsValidator{limits: limits.withDefaults()}
+	for _, rule := range rs.Rules {
+		v.validateRule(rule)
+	}
+	return v.errors
+}
+
+type limitsValidator struct {
+	limits CompilerLimits
+	errors []gyperror.Error
+	// Rule being validated.
+	rule *ast.Rule
+	// Current nesting depth of "for" loops.
+	loops int
+}
+
+func (v *limitsValidator) addError(code gyperror.Code, line int, format string, a ...interface{}) {
+	v.errors = append(v.errors, gyperror.Error{
+		Code:    code,
+		Message: fmt.Sprintf(format, a...),
+		Line:    line,
+		Rule:    v.rule.Identifier,
+	})
+}
+
+func (v *limitsValidator) checkIdentifier(identifier string, line int) {
+	if exceeds(len(identifier), v.limits.MaxIdentifierLength) {
+		v.addError(gyperror.IdentifierTooLongError, line,
+			`identifier "%s" is too long, the maximum length is %d`, identifier, v.limits.MaxIdentifierLength)
+	}
+}
+
+func (v *limitsValidator) checkRegexp(r *ast.LiteralRegexp, line int) {
+	if n := countRegexpAlternatives(r.Value); exceeds(n, v.limits.MaxAlternatives) {
+		v.addError(gyperror.TooManyAlternativesError, line,
+			"regular expression /%s/ has %d alternatives, the maximum is %d", r.Value, n, v.limits.MaxAlternatives)
+	}
+}
+
+func (v *limitsValidator) validateRule(rule *ast.Rule) {
+	v.rule = rule
+	v.loops = 0
+	v.checkIdentifier(rule.Identifier, rule.LineNo)
+	for _, tag := range rule.Tags {
+		v.checkIdentifier(tag, rule.LineNo)
+	}
+	for _, meta := range rule.Meta {
+		v.checkIdentifier(meta.Key, rule.LineNo)
+	}
+	if exceeds(len(rule.Strings), v.limits.MaxStringsPerRule) {
+		v.addError(gyperror.TooManyStringsError, rule.LineNo,
+			`rule "%s" has %d strings, the maximum is %d`, rule.Identifier, len(rule.Strings), v.limits.MaxStringsPerRule)
+	}
+	for _, s := range rule.Strings {
+		v.checkIdentifier(s.GetIdentifier(), s.GetLineNo())
+		switch s := s.(type) {
+		case *ast.HexString:
+			v.validateHexTokens(s)
+		case *ast.RegexpString:
+			v.checkRegexp(s.Regexp, s.LineNo)
+		}
+	}
+	if rule.Condition != nil {
+		ast.DepthFirstSearch(rule.Condition, v)
+	}
+}
+
+func (v *limitsValidator) validateHexTokens(s *ast.HexString) {
+	alternatives := 0
+	pending := []ast.HexTokens{s.Tokens}
+	for len(pending) > 0 {
+		tokens := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		for _, t := range tokens {
+			switch t := t.(type) {
+			case *ast.HexJump:
+				if exceeds(t.Start, v.limits.MaxHexJump) ||
+					t.End != nil && exceeds(*t.End, v.limits.MaxHexJump) {
+					v.addError(gyperror.JumpTooLargeError, s.LineNo,
+						"jump in string $%s is too large, the maximum is %d",
+						s.Identifier, v.limits.MaxHexJump)
+				}
+			case *ast.HexOr:
+				alternatives += len(t.Alternatives) - 1
+				for _, alt := range t.Alternatives {
+					if alt, ok := alt.(ast.HexTokens); ok {
+						pending = append(pending, alt)
+					} else {
+						pending = append(pending, ast.HexTokens{alt})
+					}
+				}
+			}
+		}
+	}
+	if exceeds(alternatives, v.limits.MaxAlternatives) {
+		v.addError(gyperror.TooManyAlternativesError, s.LineNo,
+			"string $%s has %d alternatives, the maximum is %d", s.Identifier, alternatives, v.limits.MaxAlternatives)
+	}
+}
+
+// PreOrderVisit implements ast.PreOrderVisitor for validating conditions.
+func (v *limitsValidator) PreOrderVisit(node ast.Node) {
+	line := v.rule.LineNo
+	switch n := node.(type) {
+	case *ast.Identifier:
+		v.checkIdentifier(n.Identifier, line)
+	case *ast.MemberAccess:
+		v.checkIdentifier(n.Member, line)
+	case *ast.LiteralRegexp:
+		v.checkRegexp(n, line)
+	case *ast.WithDeclaration:
+		v.checkIdentifier(n.Identifier, line)
+	case *ast.ForIn:
+		for _, variable := range n.Variables {
+			v.checkIdentifier(variable, line)
+		}
+		v.enterLoop()
+	case *ast.ForOf:
+		v.enterLoop()
+	}
+}
+
+// PostOrderVisit implements ast.PostOrderVisitor for validating conditions.
+func (v *limitsValidator) PostOrderVisit(node ast.Node) {
+	switch node.(type) {
+	case *ast.ForIn, *ast.ForOf:
+		v.loops--
+	}
+}
+
+func (v *limitsValidator) enterLoop() {
+	v.loops++
+	// Report only the first loop that exceeds the limit, not the ones nested
+	// inside it.
+	if v.loops-1 == v.limits.MaxLoopNesting {
+		v.addError(gyperror.LoopNestingTooDeepError, v.rule.LineNo,
+			"loops are nested too deeply, the maximum depth is %d", v.limits.MaxLoopNesting)
+	}
+}
+
+// countRegexpAlternatives returns the number of "|" operators in a regular
+// expression, ignoring the ones that are escaped or inside character classes.
+func countRegexpAlternatives(re string) int {
+	count := 0
+	inClass := false
+	for i := 0; i < len(re); i++ {
+		switch c := re[i]; {
+		case c == '\\':
+			i++
+		case inClass:
+			if c == ']' {
+				inClass = false
+			}
+		case c == '[':
+			inClass = true
+			// A "]" right after "[" or "[^" is part of the class.
+			if i+1 < len(re) && re[i+1] == '^' {
+				i++
+			}
+			if i+1 < len(re) && re[i+1] == ']' {
+				i++
+			}
+		case c == '|':
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	gyperror "github.com/VirusTotal/gyp/error"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateLimits(t *testing.T) {
+	longIdentifier := strings.Repeat("a", 129)
+	tests := []struct {
+		rules  string
+		limits CompilerLimits
+		codes  []gyperror.Code
+	}{
+		{
+			rules: `
+rule a {
+  strings:
+    $a = { 01 [0-32767] 02 [40000-] 03 }
+    $b = /foo|bar|baz/
+  condition:
+    for all i in (1..2) : (for all j in (1..2) : (for any of ($*) : (#a == i + j)))
+}`,
+			codes: []gyperror.Code{gyperror.JumpTooLargeError},
+		},
+		{
+			rules: `
+rule a {
+  strings:
+    $a = { 01 ( 02 | 03 | 04 ) 05 ( 06 | 07 ) }
+    $b = /a|[|]|\|/
+  condition:
+    $a and $b and pe.sections[0].name matches /x|y|z|w/
+}`,
+			limits: CompilerLimits{MaxAlternatives: 2},
+			codes: []gyperror.Code{
+				gyperror.TooManyAlternativesError,
+				gyperror.TooManyAlternativesError,
+			},
+		},
+		{
+			rules: `
+rule a {
+  strings:
+    $a = "foo"
+    $b = "bar"
+    $c = "baz"
+  condition:
+    for any i in (1..2) : (for any j in (1..2) : (for any k in (1..2) : (i + j + k == 3)))
+}`,
+			limits: CompilerLimits{MaxStringsPerRule: 2, MaxLoopNesting: 1},
+			codes: []gyperror.Code{
+				gyperror.TooManyStringsError,
+				gyperror.LoopNestingTooDeepError,
+			},
+		},
+		{
+			rules: `
+rule a {
+  strings:
+    $a = "foo"
+    $b = "bar"
+    $c = "baz"
+  condition:
+    for any i in (1..2) : (for any j in (1..2) : (for any k in (1..2) : (i + j + k == 3)))
+}`,
+			limits: CompilerLimits{MaxStringsPerRule: -1, MaxLoopNesting: -1},
+		},
+		{
+			rules: `
+rule ` + longIdentifier + ` : ` + longIdentifier + ` {
+  meta:
+    ` + longIdentifier + ` = 1
+  strings:
+    $` + longIdentifier + ` = "foo"
+  condition:
+    $` + longIdentifier + ` and
+    for any ` + longIdentifier + ` in (1..2) : (` + longIdentifier + ` == 1) and
+    with ` + longIdentifier + ` = 1 : (` + longIdentifier + ` == 1) and
+    pe.` + longIdentifier + `
+}`,
+			codes: []gyperror.Code{
+				gyperror.IdentifierTooLongError, // rule
+				gyperror.IdentifierTooLongError, // tag
+				gyperror.IdentifierTooLongError, // meta
+				gyperror.IdentifierTooLongError, // string
+				gyperror.IdentifierTooLongError, // loop variable
+				gyperror.IdentifierTooLongError, // loop variable in condition
+				gyperror.IdentifierTooLongError, // with declaration
+				gyperror.IdentifierTooLongError, // with identifier in condition
+				gyperror.IdentifierTooLongError, // member
+			},
+		},
+	}
+	for _, test := range tests {
+		errs := ValidateLimits(parseRules(t, test.rules), test.limits)
+		var codes []gyperror.Code
+		for _, err := range errs {
+			assert.Equal(t, "a", err.Rule[:1])
+			codes = append(codes, err.Code)
+		}
+		assert.Equal(t, test.codes, codes, "%v", errs)
+	}
+}
+
+func TestValidateLimitsLocation(t *testing.T) {
+	errs := ValidateLimits(parseRules(t, `
+rule a { condition: true }
+rule b {
+  strings:
+    $a = "foo"
+    $b = { 01 [0-100] 02 }
+  condition:
+    all of them
+}`), CompilerLimits{MaxHexJump: 99})
+	assert.Equal(t, []gyperror.Error{{
+		Code:    gyperror.JumpTooLargeError,
+		Message: "jump in string $b is too large, the maximum is 99",
+		Line:    6,
+		Rule:    "b",
+	}}, errs)
+}
@@ -0,0 +1,157 @@
+package utils
+
+import (
+	"fmt"
+
+	"github.com/VirusTotal/gyp/ast"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+// MergeStrategy determines how Merge resolves the conflicts between rules
+// that have the same identifier but are not identical.
+type MergeStrategy int
+
+const (
+	// MergeError makes Merge fail if there is any conflict.
+	MergeError MergeStrategy = iota
+	// MergeKeepFirst keeps the rule that was already in the destination
+	// ruleset, discarding the other one.
+	MergeKeepFirst
+	// MergeKeepLast keeps the rule from the merged ruleset, which replaces
+	// the one in the destination ruleset.
+	MergeKeepLast
+	// MergeRename keeps both rules, the one from the merged ruleset is
+	// renamed by adding a prefix to its identifier.
+	MergeRename
+)
+
+// MergeOptions contains the options for Merge.
+type MergeOptions struct {
+	Strategy MergeStrategy
+	// Prefix added to the identifiers of the conflicting rules when using
+	// the MergeRename strategy.
+	Prefix string
+}
+
+// ruleComparer ignores line numbers, as well as the difference between nil
+// and empty slices, when comparing rules.
+var ruleComparer = cmp.Options{
+	cmpopts.IgnoreFields(ast.Rule{}, "LineNo"),
+	cmpopts.IgnoreFields(ast.BaseString{}, "LineNo"),
+	cmpopts.EquateEmpty(),
+}
+
+// Merge adds the imports, includes and rules from src to dst. Imports and
+// includes already in dst are not repeated, and neither are rules that are
+// identical to a rule in dst. Rules with the same identifier as some rule in
+// dst, but with differences other than their line numbers, are resolved
+// according to opts.Strategy. References to renamed rules are updated in
+// the conditions of the rules in src, and the rules in dst that reference a
+// rule replaced by MergeKeepLast will reference the new rule.
+//
+// Rules are moved as necessary so that every rule appears after the rules it
+// depends on, including the ones matched by wildcards like "any of (foo*)",
+// as YARA requires.
+//
+// The rules in src are added to dst without making a copy, and they may be
+// modified by MergeRename. If an error is returned, both rulesets are left
+// untouched.
+func Merge(dst, src *ast.RuleSet, opts MergeOptions) error {
+	dstRules := make(map[string]int, len(dst.Rules))
+	for i, rule := range dst.Rules {
+		dstRules[rule.Identifier] = i
+	}
+	srcRules := make(map[string]bool, len(src.Rules))
+	for _, rule := range src.Rules {
+		srcRules[rule.Identifier] = true
+	}
+	for _, rule := range dst.Rules {
+		if module, ok := conflictingModule(rule, src.Imports); ok {
+			return fmt.Errorf("rule %s conflicts with module %s", rule.Identifier, module)
+		}
+	}
+
+	// Rules from src that are not added to dst, and rules in dst that are
+	// replaced by rules from src.
+	skipped := make(map[*ast.Rule]bool)
+	replaced := make(map[int]*ast.Rule)
+	var renames [][2]string
+	for _, rule := range src.Rules {
+		i, exists := dstRules[rule.Identifier]
+		module, isModule := conflictingModule(rule, dst.Imports)
+		if exists && cmp.Equal(dst.Rules[i], rule, ruleComparer) {
+			skipped[rule] = true
+			continue
+		}
+		if !exists && !isModule {
+			continue
+		}
+		switch {
+		case opts.Strategy == MergeRename:
+			newName := opts.Prefix + rule.Identifier
+			if _, ok := dstRules[newName]; ok || srcRules[newName] {
+				return fmt.Errorf("can't rename %s to %s: rule already exists", rule.Identifier, newName)
+			}
+			renames = append(renames, [2]string{rule.Identifier, newName})
+		case isModule:
+			return fmt.Errorf("rule %s conflicts with module %s", rule.Identifier, module)
+		case opts.Strategy == MergeKeepFirst:
+			skipped[rule] = true
+		case opts.Strategy == MergeKeepLast:
+			replaced[i] = rule
+			skipped[rule] = true
+		default:
+			return fmt.Errorf("conflicting definitions of rule %s", rule.Identifier)
+		}
+	}
+
+	for i, rename := range renames {
+		if err := RenameRule(src, rename[0], rename[1]); err != nil {
+			// Undo the renames already done, which can't fail.
+			for j := i - 1; j >= 0; j-- {
+				RenameRule(src, renames[j][1], renames[j][0])
+			}
+			return err
+		}
+	}
+
+	rules := make([]*ast.Rule, 0, len(dst.Rules)+len(src.Rules))
+	for i, rule := range dst.Rules {
+		if r, ok := replaced[i]; ok {
+			rule = r
+		}
+		rules = append(rules, rule)
+	}
+	for _, rule := range src.Rules {
+		if !skipped[rule] {
+			rules = append(rules, rule)
+		}
+	}
+
+	dst.Imports = appendMissing(dst.Imports, src.Imports)
+	dst.Includes = appendMissing(dst.Includes, src.Includes)
+	dst.Rules = NewDependencyGraph(&ast.RuleSet{Rules: rules}).sortAll()
+	return nil
+}
+
+// appendMissing appends the strings in src that are not already in dst.
+func appendMissing(dst, src []string) []string {
+	for _, s := range src {
+		if !sliceContains(s, dst) {
+			dst = append(dst, s)
+		}
+	}
+	return dst
+}
+
+// conflictingModule returns the module among imports that has the rule's
+// identifier as its name, which YARA doesn't allow.
+func conflictingModule(rule *ast.Rule, imports []string) (string, bool) {
+	for _, module := range imports {
+		if module == rule.Identifier {
+			return module, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,171 @@
+package utils
+
+import (
+	"testing"
+)
+
+const mergeDst = `
+import "pe"
+rule foo { condition: pe.is_dll() }
+private rule bar { condition: true }
+rule baz { condition: foo and bar }
+`
+
+const mergeSrc = `
+import "math"
+import "pe"
+rule foo { condition: pe.is_dll() }
+private rule bar { condition: false }
+rule qux { condition: bar and math.entropy(0, filesize) > 7 }
+`
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		strategy MergeStrategy
+		expected string
+	}{
+		{MergeKeepFirst, `
+import "pe"
+import "math"
+rule foo { condition: pe.is_dll() }
+private rule bar { condition: true }
+rule baz { condition: foo and bar }
+rule qux { condition: bar and math.entropy(0, filesize) > 7 }
+`},
+		{MergeKeepLast, `
+import "pe"
+import "math"
+rule foo { condition: pe.is_dll() }
+private rule bar { condition: false }
+rule baz { condition: foo and bar }
+rule qux { condition: bar and math.entropy(0, filesize) > 7 }
+`},
+		{MergeRename, `
+import "pe"
+import "math"
+rule foo { condition: pe.is_dll() }
+private rule bar { condition: true }
+rule baz { condition: foo and bar }
+private rule vendor_bar { condition: false }
+rule qux { condition: vendor_bar and math.entropy(0, filesize) > 7 }
+`},
+	}
+	for _, test := range tests {
+		dst := parseRules(t, mergeDst)
+		src := parseRules(t, mergeSrc)
+		if err := Merge(dst, src, MergeOptions{Strategy: test.strategy, Prefix: "vendor_"}); err != nil {
+			t.Fatalf("Merge failed with strategy %d: %s", test.strategy, err)
+		}
+		if output, expected := writeRules(t, dst), writeRules(t, parseRules(t, test.expected)); output != expected {
+			t.Errorf("Merge with strategy %d, expected:\n%s\ngot:\n%s", test.strategy, expected, output)
+		}
+	}
+}
+
+func TestMergeError(t *testing.T) {
+	dst := parseRules(t, mergeDst)
+	src := parseRules(t, mergeSrc)
+	err := Merge(dst, src, MergeOptions{})
+	if err == nil || err.Error() != "conflicting definitions of rule bar" {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if writeRules(t, dst) != writeRules(t, parseRules(t, mergeDst)) {
+		t.Error("Merge modified the destination ruleset")
+	}
+
+	// Identical rules are not conflicts.
+	dst = parseRules(t, mergeDst)
+	src = parseRules(t, "\n\n"+mergeDst)
+	if err := Merge(dst, src, MergeOptions{}); err != nil {
+		t.Fatalf("Merge failed: %s", err)
+	}
+	if output, expected := writeRules(t, dst), writeRules(t, parseRules(t, mergeDst)); output != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, output)
+	}
+}
+
+func TestMergeRenameErrors(t *testing.T) {
+	tests := []struct {
+		dst string
+		src string
+		err string
+	}{
+		{
+			`rule foo { condition: true }`,
+			`rule foo { condition: false } rule x_foo { condition: true }`,
+			"can't rename foo to x_foo: rule already exists",
+		},
+		{
+			`rule foo { condition: true }`,
+			`rule foo { condition: false } rule bar { condition: any of (f*) }`,
+			"can't rename foo in rule bar: it would change the meaning of f*",
+		},
+		{
+			`rule foo { condition: true } rule goo { condition: true }`,
+			`rule foo { condition: false } rule goo { condition: false } rule baz { condition: foo and any of (g*) }`,
+			"can't rename goo in rule baz: it would change the meaning of g*",
+		},
+		{
+			`rule pe { condition: true }`,
+			`import "pe" rule foo { condition: true }`,
+			"rule pe conflicts with module pe",
+		},
+	}
+	for _, test := range tests {
+		dst := parseRules(t, test.dst)
+		src := parseRules(t, test.src)
+		srcBefore := writeRules(t, src)
+		err := Merge(dst, src, MergeOptions{Strategy: MergeRename, Prefix: "x_"})
+		if err == nil || err.Error() != test.err {
+			t.Errorf("Expected error %q, got %v", test.err, err)
+		}
+		if writeRules(t, src) != srcBefore {
+			t.Errorf("Merge modified the source ruleset:\n%s", writeRules(t, src))
+		}
+	}
+}
+
+func TestMergeModuleConflict(t *testing.T) {
+	dst := parseRules(t, `import "math" rule foo { condition: true }`)
+	src := parseRules(t, `rule math { condition: true } rule bar { condition: math }`)
+	err := Merge(dst, src, MergeOptions{Strategy: MergeKeepFirst})
+	if expected := "rule math conflicts with module math"; err == nil || err.Error() != expected {
+		t.Fatalf("Expected error %q, got %v", expected, err)
+	}
+	if err := Merge(dst, src, MergeOptions{Strategy: MergeRename, Prefix: "x_"}); err != nil {
+		t.Fatalf("Merge failed: %s", err)
+	}
+	expected := writeRules(t, parseRules(t, `
+import "math"
+rule foo { condition: true }
+rule x_math { condition: true }
+rule bar { condition: x_math }`))
+	if output := writeRules(t, dst); output != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, output)
+	}
+}
+
+func TestMergeOrder(t *testing.T) {
+	dst := parseRules(t, `
+rule a { condition: true }
+rule b { condition: a }
+rule d0 { condition: true }
+rule c { condition: any of (d*) }`)
+	src := parseRules(t, `
+rule e { condition: true }
+rule a { condition: e }
+rule d1 { condition: true }`)
+	if err := Merge(dst, src, MergeOptions{Strategy: MergeKeepLast}); err != nil {
+		t.Fatalf("Merge failed: %s", err)
+	}
+	expected := writeRules(t, parseRules(t, `
+rule e { condition: true }
+rule a { condition: e }
+rule b { condition: a }
+rule d0 { condition: true }
+rule d1 { condition: true }
+rule c { condition: any of (d*) }`))
+	if output := writeRules(t, dst); output != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, output)
+	}
+}
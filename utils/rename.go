@@ -0,0 +1,264 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/VirusTotal/gyp/ast"
+)
+
+var (
+	ruleIdentifierRegexp   = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	stringIdentifierRegexp = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+)
+
+// yaraKeywords contains the reserved words that can't be used as rule
+// identifiers, sorted alphabetically.
+var yaraKeywords = []string{
+	"all", "and", "any", "ascii", "at", "base64", "base64wide", "condition",
+	"contains", "defined", "endswith", "entrypoint", "false", "filesize",
+	"for", "fullword", "global", "icontains", "iendswith", "iequals",
+	"import", "in", "include", "int16", "int16be", "int32", "int32be", "int8",
+	"int8be", "istartswith", "matches", "meta", "nocase", "none", "not", "of",
+	"or", "private", "rule", "startswith", "strings", "them", "true", "uint16",
+	"uint16be", "uint32", "uint32be", "uint8", "uint8be", "wide", "with", "xor",
+}
+
+// Keywords returns the reserved words that can't be used as rule identifiers,
+// sorted alphabetically.
+func Keywords() []string {
+	return append([]string(nil), yaraKeywords...)
+}
+
+// IsKeyword returns true if s is a reserved word that can't be used as a rule
+// identifier.
+func IsKeyword(s string) bool {
+	return sliceContains(s, yaraKeywords)
+}
+
+// RenameRule renames the rule identified by oldName to newName, updating
+// every reference to the rule in the conditions of all the rules in the
+// ruleset. An error is returned, and the ruleset is left untouched, if the
+// rule doesn't exist, if newName is not a valid identifier or is already in
+// use, or if the rename would change the set of rules matched by a rule
+// wildcard like "any of (foo*)".
+func RenameRule(rs *ast.RuleSet, oldName, newName string) error {
+	var renamed *ast.Rule
+	for _, rule := range rs.Rules {
+		if rule.Identifier == oldName {
+			renamed = rule
+		}
+		if rule.Identifier == newName {
+			return fmt.Errorf("rule %s already exists", newName)
+		}
+	}
+	if renamed == nil {
+		return fmt.Errorf("%s does not exist in the ruleset", oldName)
+	}
+	if !ruleIdentifierRegexp.MatchString(newName) || IsKeyword(newName) {
+		return fmt.Errorf("invalid rule identifier: %s", newName)
+	}
+	if sliceContains(newName, rs.Imports) || sliceContains(newName, yaraModules) {
+		return fmt.Errorf("%s is the name of a module", newName)
+	}
+
+	var refs []*ast.Identifier
+	for _, rule := range rs.Rules {
+		r := &ruleRenamer{oldName: oldName, newName: newName}
+		r.visit(rule.Condition, nil)
+		if r.err != nil {
+			return fmt.Errorf("can't rename %s in rule %s: %s", oldName, rule.Identifier, r.err)
+		}
+		refs = append(refs, r.refs...)
+	}
+
+	renamed.Identifier = newName
+	for _, ref := range refs {
+		ref.Identifier = newName
+	}
+	return nil
+}
+
+// RenameString renames the string identified by oldName to newName, updating
+// every reference to the string in the rule's condition, including $a, #a,
+// @a, !a and string enumerations like "any of ($a, $b)". Identifiers don't
+// include the $ prefix. An error is returned, and the rule is left untouched,
+// if the string doesn't exist, if newName is not a valid identifier or is
+// already in use, or if the rename would change the set of strings matched
+// by a wildcard like "any of ($a*)".
+func RenameString(rule *ast.Rule, oldName, newName string) error {
+	var renamed *ast.BaseString
+	for _, s := range rule.Strings {
+		if s.GetIdentifier() == oldName {
+			renamed = baseString(s)
+		}
+		if s.GetIdentifier() == newName {
+			return fmt.Errorf("string $%s already exists", newName)
+		}
+	}
+	if oldName == "" || renamed == nil {
+		return fmt.Errorf("string $%s does not exist in rule %s", oldName, rule.Identifier)
+	}
+	if !stringIdentifierRegexp.MatchString(newName) {
+		return fmt.Errorf("invalid string identifier: $%s", newName)
+	}
+
+	r := &stringRenamer{oldName: oldName, newName: newName}
+	r.visit(rule.Condition)
+	if r.err != nil {
+		return fmt.Errorf("can't rename $%s in rule %s: %s", oldName, rule.Identifier, r.err)
+	}
+
+	renamed.Identifier = newName
+	for _, ref := range r.refs {
+		switch n := ref.(type) {
+		case *ast.StringIdentifier:
+			n.Identifier = newName
+		case *ast.StringCount:
+			n.Identifier = newName
+		case *ast.StringOffset:
+			n.Identifier = newName
+		case *ast.StringLength:
+			n.Identifier = newName
+		}
+	}
+	return nil
+}
+
+// baseString returns the BaseString embedded in s.
+func baseString(s ast.String) *ast.BaseString {
+	switch v := s.(type) {
+	case *ast.TextString:
+		return &v.BaseString
+	case *ast.HexString:
+		return &v.BaseString
+	case *ast.RegexpString:
+		return &v.BaseString
+	}
+	panic(fmt.Sprintf("unexpected string type: %T", s))
+}
+
+// changesWildcard returns true if the wildcard, which includes the trailing
+// asterisk, matches only one of the old and new names.
+func changesWildcard(wildcard, oldName, newName string) bool {
+	prefix := strings.TrimSuffix(wildcard, "*")
+	return strings.HasPrefix(oldName, prefix) != strings.HasPrefix(newName, prefix)
+}
+
+// children returns the node's children, including the "in" and "at"
+// conditions of Of expressions.
+func children(node ast.Node) []ast.Node {
+	if of, ok := node.(*ast.Of); ok {
+		nodes := of.Children()
+		if of.In != nil {
+			nodes = append(nodes, of.In)
+		}
+		if of.At != nil {
+			nodes = append(nodes, of.At)
+		}
+		return nodes
+	}
+	return node.Children()
+}
+
+// ruleRenamer collects the identifiers in a condition that reference a rule.
+type ruleRenamer struct {
+	oldName string
+	newName string
+	refs    []*ast.Identifier
+	err     error
+}
+
+// visit walks the node's syntax tree. The loopVars argument contains the
+// variables defined by the "for ... in" loops and "with" statements enclosing
+// the node, which shadow rules with the same name.
+func (r *ruleRenamer) visit(node ast.Node, loopVars []string) {
+	if node == nil || r.err != nil {
+		return
+	}
+	switch n := node.(type) {
+	case *ast.Identifier:
+		if strings.HasSuffix(n.Identifier, "*") {
+			if changesWildcard(n.Identifier, r.oldName, r.newName) {
+				r.err = fmt.Errorf("it would change the meaning of %s", n.Identifier)
+			}
+		} else if n.Identifier == r.oldName && !sliceContains(r.oldName, loopVars) {
+			if sliceContains(r.newName, loopVars) {
+				r.err = fmt.Errorf("%s is a loop variable", r.newName)
+			}
+			r.refs = append(r.refs, n)
+		}
+		return
+	case *ast.ForIn:
+		r.visit(n.Quantifier, loopVars)
+		r.visit(n.Iterator, loopVars)
+		r.visit(n.Condition, append(loopVars[:len(loopVars):len(loopVars)], n.Variables...))
+		return
+	case *ast.With:
+		for _, d := range n.Declarations {
+			r.visit(d.Expression, loopVars)
+			loopVars = append(loopVars[:len(loopVars):len(loopVars)], d.Identifier)
+		}
+		r.visit(n.Condition, loopVars)
+		return
+	case *ast.MemberAccess:
+		// Modules and structures can't be rules, only the expressions used
+		// in them can reference rules.
+		if _, ok := n.Container.(*ast.Identifier); ok {
+			return
+		}
+	case *ast.FunctionCall:
+		if _, ok := n.Callable.(*ast.Identifier); !ok {
+			r.visit(n.Callable, loopVars)
+		}
+		for _, arg := range n.Arguments {
+			r.visit(arg, loopVars)
+		}
+		return
+	case *ast.Subscripting:
+		if _, ok := n.Array.(*ast.Identifier); !ok {
+			r.visit(n.Array, loopVars)
+		}
+		r.visit(n.Index, loopVars)
+		return
+	}
+	for _, child := range children(node) {
+		r.visit(child, loopVars)
+	}
+}
+
+// stringRenamer collects the nodes in a condition that reference a string.
+type stringRenamer struct {
+	oldName string
+	newName string
+	refs    []ast.Node
+	err     error
+}
+
+func (r *stringRenamer) visit(node ast.Node) {
+	if node == nil || r.err != nil {
+		return
+	}
+	var identifier string
+	switch n := node.(type) {
+	case *ast.StringIdentifier:
+		identifier = n.Identifier
+	case *ast.StringCount:
+		identifier = n.Identifier
+	case *ast.StringOffset:
+		identifier = n.Identifier
+	case *ast.StringLength:
+		identifier = n.Identifier
+	}
+	if strings.HasSuffix(identifier, "*") {
+		if changesWildcard(identifier, r.oldName, r.newName) {
+			r.err = fmt.Errorf("it would change the meaning of $%s", identifier)
+		}
+	} else if identifier != "" && identifier == r.oldName {
+		r.refs = append(r.refs, node)
+	}
+	for _, child := range children(node) {
+		r.visit(child)
+	}
+}
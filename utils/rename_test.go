@@ -0,0 +1,165 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/VirusTotal/gyp"
+	"github.com/VirusTotal/gyp/ast"
+)
+
+func parseRules(t *testing.T, rules string) *ast.RuleSet {
+	ruleset, err := gyp.ParseString(rules)
+	if err != nil {
+		t.Fatalf("Unable to parse rules: %s", err)
+	}
+	return ruleset
+}
+
+func writeRules(t *testing.T, ruleset *ast.RuleSet) string {
+	var b strings.Builder
+	if err := ruleset.WriteSource(&b); err != nil {
+		t.Fatalf("Unable to write rules: %s", err)
+	}
+	return b.String()
+}
+
+func TestRenameRule(t *testing.T) {
+	ruleset := parseRules(t, `
+rule foo { condition: true }
+rule baz1 { condition: true }
+rule bar { condition: foo and any of (foo, baz*) }
+rule qux { condition: for any foo in (1..2): (foo == 1) or not foo }
+rule quux { condition: uint8(0) == 0 and foo }
+rule corge { condition: with x = foo, foo = 1 : (foo == 1) }`)
+	expected := parseRules(t, `
+rule abc { condition: true }
+rule baz1 { condition: true }
+rule bar { condition: abc and any of (abc, baz*) }
+rule qux { condition: for any foo in (1..2): (foo == 1) or not abc }
+rule quux { condition: uint8(0) == 0 and abc }
+rule corge { condition: with x = abc, foo = 1 : (foo == 1) }`)
+	if err := RenameRule(ruleset, "foo", "abc"); err != nil {
+		t.Fatalf("RenameRule returned an error (%s)", err)
+	}
+	if writeRules(t, ruleset) != writeRules(t, expected) {
+		t.Fatalf("Expected %s, but got %s", writeRules(t, expected), writeRules(t, ruleset))
+	}
+}
+
+func TestRenameRuleMatchedByWildcard(t *testing.T) {
+	// Renaming foo1 to foo2 doesn't change the meaning of foo*.
+	ruleset := parseRules(t, `
+rule foo1 { condition: true }
+rule bar { condition: any of (foo*) and foo1 }`)
+	if err := RenameRule(ruleset, "foo1", "foo2"); err != nil {
+		t.Fatalf("RenameRule returned an error (%s)", err)
+	}
+	if ruleset.Rules[0].Identifier != "foo2" {
+		t.Fatalf("Expected foo2, but got %s", ruleset.Rules[0].Identifier)
+	}
+}
+
+func TestRenameRuleErrors(t *testing.T) {
+	rules := `
+import "pe"
+rule foo { condition: true }
+rule baz { condition: foo and for any i in (1..2): (i == 1 and foo) }
+rule corge { condition: with j = 1 : (j == 1 and foo) }
+rule bar { condition: any of (fo*) }`
+	tests := []struct {
+		oldName string
+		newName string
+		err     string
+	}{
+		{"qux", "quux", "qux does not exist in the ruleset"},
+		{"foo", "bar", "rule bar already exists"},
+		{"foo", "1foo", "invalid rule identifier: 1foo"},
+		{"foo", "filesize", "invalid rule identifier: filesize"},
+		{"foo", "pe", "pe is the name of a module"},
+		{"foo", "abc", "can't rename foo in rule bar: it would change the meaning of fo*"},
+		{"bar", "fox", "can't rename bar in rule bar: it would change the meaning of fo*"},
+		{"foo", "i", "can't rename foo in rule baz: i is a loop variable"},
+		{"foo", "j", "can't rename foo in rule corge: j is a loop variable"},
+	}
+	for _, test := range tests {
+		ruleset := parseRules(t, rules)
+		before := writeRules(t, ruleset)
+		err := RenameRule(ruleset, test.oldName, test.newName)
+		if err == nil || err.Error() != test.err {
+			t.Fatalf("Expected error %q, but got %v", test.err, err)
+		}
+		if writeRules(t, ruleset) != before {
+			t.Fatalf("RenameRule modified the ruleset after failing")
+		}
+	}
+}
+
+func TestRenameString(t *testing.T) {
+	ruleset := parseRules(t, `
+rule foo {
+  strings:
+    $a = "foo"
+    $b1 = { 01 02 }
+    $b2 = /bar/
+    $ = "anonymous"
+  condition:
+    $a at @a[1] and #a in (0..!a) > 1 and any of ($a, $b*) and
+    for all of ($a, $b1) : ($ and @ > 0) and 1 of them at @a
+}`)
+	expected := parseRules(t, `
+rule foo {
+  strings:
+    $xyz = "foo"
+    $b1 = { 01 02 }
+    $b2 = /bar/
+    $ = "anonymous"
+  condition:
+    $xyz at @xyz[1] and #xyz in (0..!xyz) > 1 and any of ($xyz, $b*) and
+    for all of ($xyz, $b1) : ($ and @ > 0) and 1 of them at @xyz
+}`)
+	if err := RenameString(ruleset.Rules[0], "a", "xyz"); err != nil {
+		t.Fatalf("RenameString returned an error (%s)", err)
+	}
+	if writeRules(t, ruleset) != writeRules(t, expected) {
+		t.Fatalf("Expected %s, but got %s", writeRules(t, expected), writeRules(t, ruleset))
+	}
+	// The string is matched by $b* before and after the rename.
+	if err := RenameString(ruleset.Rules[0], "b1", "b3"); err != nil {
+		t.Fatalf("RenameString returned an error (%s)", err)
+	}
+}
+
+func TestRenameStringErrors(t *testing.T) {
+	rules := `
+rule foo {
+  strings:
+    $a = "foo"
+    $b1 = "bar"
+  condition:
+    $a and any of ($b*)
+}`
+	tests := []struct {
+		oldName string
+		newName string
+		err     string
+	}{
+		{"c", "d", "string $c does not exist in rule foo"},
+		{"", "d", "string $ does not exist in rule foo"},
+		{"a", "b1", "string $b1 already exists"},
+		{"a", "a-b", "invalid string identifier: $a-b"},
+		{"a", "b2", "can't rename $a in rule foo: it would change the meaning of $b*"},
+		{"b1", "c", "can't rename $b1 in rule foo: it would change the meaning of $b*"},
+	}
+	for _, test := range tests {
+		ruleset := parseRules(t, rules)
+		before := writeRules(t, ruleset)
+		err := RenameString(ruleset.Rules[0], test.oldName, test.newName)
+		if err == nil || err.Error() != test.err {
+			t.Fatalf("Expected error %q, but got %v", test.err, err)
+		}
+		if writeRules(t, ruleset) != before {
+			t.Fatalf("RenameString modified the rule after failing")
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/VirusTotal/gyp/ast"
+)
+
+// GroupFunc returns the names of the files where a rule must be written when
+// splitting a ruleset. It's called once for each rule, in the order in which
+// they appear in the ruleset.
+type GroupFunc func(rule *ast.Rule) []string
+
+// SplitOptions contains the options for Split.
+type SplitOptions struct {
+	// Group determines the files where each rule is written. If nil, ByRule
+	// is used.
+	Group GroupFunc
+	// If not empty, the rules needed by more than one file are moved to a
+	// file with this name, which is included by the other files. Otherwise
+	// such rules are written to all the files that need them.
+	Common string
+}
+
+// SplitFile is one of the files produced by Split.
+type SplitFile struct {
+	Name    string
+	RuleSet *ast.RuleSet
+}
+
+var unsafeFileNameChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// Names of the files for the rules without tags or without the metadata key
+// used for grouping them. They start with a character that fileName replaces,
+// so they can't be the name of the file for some tag or metadata value.
+const (
+	untaggedFileName = "@untagged.yar"
+	otherFileName    = "@other.yar"
+)
+
+// fileName returns a file name for the given name, replacing the characters
+// that are not safe in file names by underscores.
+func fileName(name string) string {
+	return unsafeFileNameChars.ReplaceAllString(name, "_") + ".yar"
+}
+
+// ByRule is a GroupFunc that writes each rule into its own file.
+func ByRule(rule *ast.Rule) []string {
+	return []string{fileName(rule.Identifier)}
+}
+
+// ByTag is a GroupFunc that writes the rules into one file per tag. Rules with
+// multiple tags are written to multiple files, and rules without tags are
+// written to "@untagged.yar".
+func ByTag(rule *ast.Rule) []string {
+	if len(rule.Tags) == 0 {
+		return []string{untaggedFileName}
+	}
+	names := make([]string, len(rule.Tags))
+	for i, tag := range rule.Tags {
+		names[i] = fileName(tag)
+	}
+	return names
+}
+
+// ByMeta returns a GroupFunc that writes the rules into one file for each
+// value of the given metadata key. Rules that don't have the key are written
+// to "@other.yar".
+func ByMeta(key string) GroupFunc {
+	return func(rule *ast.Rule) []string {
+		var names []string
+		for _, m := range rule.Meta {
+			if m.Key == key {
+				names = append(names, fileName(fmt.Sprint(m.Value)))
+			}
+		}
+		if len(names) == 0 {
+			return []string{otherFileName}
+		}
+		return names
+	}
+}
+
+// BySize returns a GroupFunc that writes the rules into files with at most n
+// rules each, not counting the rules they depend on. The files are named
+// "0.yar", "1.yar" and so on.
+func BySize(n int) GroupFunc {
+	if n < 1 {
+		n = 1
+	}
+	count := 0
+	return func(rule *ast.Rule) []string {
+		name := fmt.Sprintf("%d.yar", count/n)
+		count++
+		return []string{name}
+	}
+}
+
+// Split splits a ruleset into multiple files, as determined by opts.Group.
+// Each file contains the rules assigned to it, plus the rules they depend on
+// and the imports they need, so that it can be compiled on its own. Private
+// rules are written only to the files that depend on them, while global
+// rules, which affect all the other rules, are written to all of the files.
+// The includes in the ruleset are kept in every file, or only in the common
+// file if there is one.
+//
+// The files are returned in the order in which they receive their first
+// rule, and the rules in each file are in the same order as in the ruleset.
+func Split(rs *ast.RuleSet, opts SplitOptions) ([]SplitFile, error) {
+	if opts.Group == nil {
+		opts.Group = ByRule
+	}
+	var names []string
+	assigned := make(map[string][]string)
+	var globals []string
+	for _, rule := range rs.Rules {
+		if rule.Global {
+			globals = append(globals, rule.Identifier)
+			continue
+		}
+		if rule.Private {
+			continue
+		}
+		for _, name := range opts.Group(rule) {
+			if name == opts.Common {
+				return nil, fmt.Errorf("rule %s can't be written to the common file %s", rule.Identifier, name)
+			}
+			if _, ok := assigned[name]; !ok {
+				names = append(names, name)
+			}
+			assigned[name] = append(assigned[name], rule.Identifier)
+		}
+	}
+
+	// Find all the rules needed by each file, and how many files need each
+	// rule.
+	graph := NewDependencyGraph(rs)
+	needed := make(map[string]map[string]bool, len(names))
+	count := make(map[string]int)
+	for _, name := range names {
+		identifiers := append(append([]string{}, assigned[name]...), globals...)
+		dependencies, err := graph.TransitiveDependencies(identifiers...)
+		if err != nil {
+			return nil, err
+		}
+		needed[name] = make(map[string]bool)
+		for _, identifier := range identifiers {
+			needed[name][identifier] = true
+		}
+		for _, rule := range dependencies {
+			needed[name][rule.Identifier] = true
+		}
+		for identifier := range needed[name] {
+			count[identifier]++
+		}
+	}
+
+	var files []SplitFile
+	includes := rs.Includes
+	if opts.Common != "" {
+		common := make(map[string]bool)
+		for identifier, n := range count {
+			if n > 1 {
+				common[identifier] = true
+			}
+		}
+		if len(common) > 0 {
+			for _, name := range names {
+				for identifier := range common {
+					delete(needed[name], identifier)
+				}
+			}
+			files = append(files, SplitFile{opts.Common, subset(rs, graph, common, rs.Includes)})
+			// The includes in the ruleset are in the common file already.
+			includes = []string{opts.Common}
+		}
+	}
+	for _, name := range names {
+		files = append(files, SplitFile{name, subset(rs, graph, needed[name], includes)})
+	}
+	return files, nil
+}
+
+// subset returns a ruleset with the given includes, the rules in rs whose
+// identifiers are in rules, and the imports used by those rules.
+func subset(rs *ast.RuleSet, graph *DependencyGraph, rules map[string]bool, includes []string) *ast.RuleSet {
+	result := &ast.RuleSet{
+		Imports:  make([]string, 0),
+		Includes: includes,
+		Rules:    make([]*ast.Rule, 0, len(rules)),
+	}
+	var used []string
+	for _, rule := range rs.Rules {
+		if rules[rule.Identifier] {
+			result.Rules = append(result.Rules, rule)
+			used = appendMissing(used, graph.Modules(rule.Identifier))
+		}
+	}
+	for _, imp := range rs.Imports {
+		if sliceContains(imp, used) {
+			result.Imports = append(result.Imports, imp)
+		}
+	}
+	return result
+}
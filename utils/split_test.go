@@ -0,0 +1,166 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/VirusTotal/gyp"
+	"github.com/VirusTotal/gyp/ast"
+	"github.com/google/go-cmp/cmp"
+)
+
+const splitRules = `
+import "pe"
+import "math"
+global rule is_pe { condition: uint16(0) == 0x5A4D }
+private rule dll { condition: pe.is_dll() }
+private rule high_entropy { condition: math.entropy(0, filesize) > 7 }
+rule a : foo { meta: family = "x" condition: dll }
+rule b : foo bar { meta: family = "y" condition: high_entropy and a }
+rule c { meta: family = "x" condition: any of (a, b*) }
+rule d { condition: is_pe }
+`
+
+// summary returns a string with the imports, includes and rule identifiers
+// in a ruleset.
+func summary(rs *ast.RuleSet) string {
+	var rules []string
+	for _, r := range rs.Rules {
+		rules = append(rules, r.Identifier)
+	}
+	return fmt.Sprintf("imports: %s; includes: %s; rules: %s",
+		strings.Join(rs.Imports, ","), strings.Join(rs.Includes, ","), strings.Join(rules, ","))
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     SplitOptions
+		expected []string
+	}{
+		{
+			"by rule",
+			SplitOptions{Group: ByRule},
+			[]string{
+				"a.yar imports: pe; includes: ; rules: is_pe,dll,a",
+				"b.yar imports: pe,math; includes: ; rules: is_pe,dll,high_entropy,a,b",
+				"c.yar imports: pe,math; includes: ; rules: is_pe,dll,high_entropy,a,b,c",
+				"d.yar imports: ; includes: ; rules: is_pe,d",
+			},
+		},
+		{
+			"by tag",
+			SplitOptions{Group: ByTag},
+			[]string{
+				"foo.yar imports: pe,math; includes: ; rules: is_pe,dll,high_entropy,a,b",
+				"bar.yar imports: pe,math; includes: ; rules: is_pe,dll,high_entropy,a,b",
+				"@untagged.yar imports: pe,math; includes: ; rules: is_pe,dll,high_entropy,a,b,c,d",
+			},
+		},
+		{
+			"by meta",
+			SplitOptions{Group: ByMeta("family")},
+			[]string{
+				"x.yar imports: pe,math; includes: ; rules: is_pe,dll,high_entropy,a,b,c",
+				"y.yar imports: pe,math; includes: ; rules: is_pe,dll,high_entropy,a,b",
+				"@other.yar imports: ; includes: ; rules: is_pe,d",
+			},
+		},
+		{
+			"by rule with common file",
+			SplitOptions{Group: ByRule, Common: "common.yar"},
+			[]string{
+				"common.yar imports: pe,math; includes: inc.yar; rules: is_pe,dll,high_entropy,a,b",
+				"a.yar imports: ; includes: common.yar; rules: ",
+				"b.yar imports: ; includes: common.yar; rules: ",
+				"c.yar imports: ; includes: common.yar; rules: c",
+				"d.yar imports: ; includes: common.yar; rules: d",
+			},
+		},
+		{
+			"by size",
+			SplitOptions{Group: BySize(2)},
+			[]string{
+				"0.yar imports: pe,math; includes: ; rules: is_pe,dll,high_entropy,a,b",
+				"1.yar imports: pe,math; includes: ; rules: is_pe,dll,high_entropy,a,b,c,d",
+			},
+		},
+	}
+	for _, test := range tests {
+		source := splitRules
+		if test.opts.Common != "" {
+			source = `include "inc.yar"` + source
+		}
+		files, err := Split(parseRules(t, source), test.opts)
+		if err != nil {
+			t.Fatalf("%s: %s", test.name, err)
+		}
+		var summaries []string
+		sources := make(map[string]string)
+		for _, f := range files {
+			summaries = append(summaries, f.Name+" "+summary(f.RuleSet))
+			sources[f.Name] = writeRules(t, f.RuleSet)
+		}
+		if !cmp.Equal(test.expected, summaries) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, summaries)
+		}
+		// Files are valid on their own, or after including the common file.
+		for _, f := range files {
+			source := sources[f.Name]
+			if test.opts.Common != "" && f.Name != test.opts.Common {
+				source = sources[test.opts.Common] + source
+			}
+			if _, err := gyp.ParseString(source); err != nil {
+				t.Errorf("%s: %s is not valid: %s", test.name, f.Name, err)
+			}
+		}
+	}
+}
+
+func TestSplitCommonFileConflict(t *testing.T) {
+	_, err := Split(parseRules(t, splitRules), SplitOptions{Group: ByRule, Common: "a.yar"})
+	if err == nil || err.Error() != "rule a can't be written to the common file a.yar" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestSplitByMetaOther(t *testing.T) {
+	rs := parseRules(t, `
+rule a { meta: family = "other" condition: true }
+rule b { condition: true }`)
+	files, err := Split(rs, SplitOptions{Group: ByMeta("family")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var summaries []string
+	for _, f := range files {
+		summaries = append(summaries, f.Name+" "+summary(f.RuleSet))
+	}
+	expected := []string{
+		"other.yar imports: ; includes: ; rules: a",
+		"@other.yar imports: ; includes: ; rules: b",
+	}
+	if !cmp.Equal(expected, summaries) {
+		t.Errorf("expected %v, got %v", expected, summaries)
+	}
+}
+
+func TestSplitDefaultGroup(t *testing.T) {
+	files, err := Split(parseRules(t, splitRules), SplitOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := Split(parseRules(t, splitRules), SplitOptions{Group: ByRule})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != len(expected) {
+		t.Fatalf("expected %d files, got %d", len(expected), len(files))
+	}
+	for i := range files {
+		if files[i].Name != expected[i].Name {
+			t.Errorf("expected %s, got %s", expected[i].Name, files[i].Name)
+		}
+	}
+}
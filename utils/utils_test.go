@@ -32,6 +32,12 @@ func TestInLoopWithNoIdents(t *testing.T) {
 	testGetYARARuleDependencies(t, condition, expected)
 }
 
+func TestWithIdents(t *testing.T) {
+	condition := `with a = foo, b = a + bar : (b and with c = b : (c or baz)) and a`
+	expected := map[string]int{"foo": 0, "bar": 0, "baz": 0, "a": 0}
+	testGetYARARuleDependencies(t, condition, expected)
+}
+
 func TestLotsOfIdents(t *testing.T) {
 	condition := `pe.exports("foo0")
     or pe.exports("foo1")